@@ -3,19 +3,97 @@ package canned
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 
 	"github.com/influxdata/chronograf"
 )
 
 //go:generate go-bindata -o bin_gen.go -ignore README|apps|.sh|go -pkg canned .
 
-// BinLayoutsStore represents a layout store using data generated by go-bindata
+// BinLayoutsStore represents a layout store using data generated by go-bindata.
+// Layouts are decoded once and cached.
 type BinLayoutsStore struct {
 	Logger chronograf.Logger
+
+	mu      sync.Mutex
+	layouts []chronograf.Layout // layouts are the decoded layouts once warmed
+	warming *layoutsFlight      // warming is the in-progress decoding of the layouts
+	load    func() ([]chronograf.Layout, error)
+}
+
+// layoutsFlight is a decoding of the layouts shared by concurrent callers
+type layoutsFlight struct {
+	done    chan struct{}
+	layouts []chronograf.Layout
+	err     error
 }
 
-// All returns the set of all layouts
+// All returns the set of all layouts.  The first callers share a single
+// decoding of the layouts.  If decoding fails nothing is cached so the next
+// call tries again.
 func (s *BinLayoutsStore) All(ctx context.Context) ([]chronograf.Layout, error) {
+	layouts, err := s.cached()
+	if err != nil {
+		return nil, err
+	}
+	return copyLayouts(layouts), nil
+}
+
+// cached returns the decoded layouts, decoding them if not yet cached.  The
+// layouts are shared so must be copied before they are returned to callers.
+func (s *BinLayoutsStore) cached() ([]chronograf.Layout, error) {
+	s.mu.Lock()
+	if s.layouts != nil {
+		defer s.mu.Unlock()
+		return s.layouts, nil
+	}
+
+	f := s.warming
+	if f != nil {
+		s.mu.Unlock()
+		<-f.done
+	} else {
+		f = &layoutsFlight{done: make(chan struct{})}
+		s.warming = f
+		s.mu.Unlock()
+		s.warm(f)
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.layouts, nil
+}
+
+// errWarmingPanicked is returned to the callers sharing a decoding of the
+// layouts that panicked
+var errWarmingPanicked = fmt.Errorf("decoding layouts panicked")
+
+// warm decodes the layouts of the flight f.  The callers sharing f are
+// released and warming is cleared even if decoding panics, so they do not
+// block forever and the next call tries again.
+func (s *BinLayoutsStore) warm(f *layoutsFlight) {
+	f.err = errWarmingPanicked
+	defer func() {
+		s.mu.Lock()
+		if f.err == nil {
+			s.layouts = f.layouts
+		}
+		s.warming = nil
+		s.mu.Unlock()
+		close(f.done)
+	}()
+
+	load := s.load
+	if load == nil {
+		load = s.decode
+	}
+	f.layouts, f.err = load()
+}
+
+// decode reads all layouts from the bindata assets
+func (s *BinLayoutsStore) decode() ([]chronograf.Layout, error) {
 	names := AssetNames()
 	layouts := make([]chronograf.Layout, len(names))
 	for i, name := range names {
@@ -42,6 +120,36 @@ func (s *BinLayoutsStore) All(ctx context.Context) ([]chronograf.Layout, error)
 	return layouts, nil
 }
 
+// copyLayouts copies the cells of cached layouts so callers may modify them
+func copyLayouts(layouts []chronograf.Layout) []chronograf.Layout {
+	res := make([]chronograf.Layout, len(layouts))
+	for i, layout := range layouts {
+		cells := make([]chronograf.Cell, len(layout.Cells))
+		for j, cell := range layout.Cells {
+			if cell.Axes != nil {
+				axes := make(map[string]chronograf.Axis, len(cell.Axes))
+				for k, v := range cell.Axes {
+					axes[k] = v
+				}
+				cell.Axes = axes
+			}
+			if cell.Queries != nil {
+				cell.Queries = append([]chronograf.Query{}, cell.Queries...)
+			}
+			if cell.CellColors != nil {
+				cell.CellColors = append([]chronograf.CellColor{}, cell.CellColors...)
+			}
+			cells[j] = cell
+		}
+		if layout.Cells == nil {
+			cells = nil
+		}
+		layout.Cells = cells
+		res[i] = layout
+	}
+	return res
+}
+
 // Get retrieves Layout if `ID` exists.
 func (s *BinLayoutsStore) Get(ctx context.Context, ID string) (chronograf.Layout, error) {
 	layouts, err := s.All(ctx)
@@ -65,3 +173,164 @@ func (s *BinLayoutsStore) Get(ctx context.Context, ID string) (chronograf.Layout
 		Error("Layout not found")
 	return chronograf.Layout{}, chronograf.ErrLayoutNotFound
 }
+
+// GetMany retrieves the layouts with ids in the order requested in a single
+// pass over the layouts.  IDs without a layout are listed in notFound
+// rather than failing the retrieval.  IDs requested more than once are
+// retrieved once.
+func (s *BinLayoutsStore) GetMany(ctx context.Context, ids []string) (layouts []chronograf.Layout, notFound []string, err error) {
+	all, err := s.cached()
+	if err != nil {
+		s.Logger.
+			WithField("component", "apps").
+			Error("Invalid Layout: ", err)
+		return nil, nil, chronograf.ErrLayoutInvalid
+	}
+
+	wanted := make(map[string]int, len(ids))
+	for _, id := range ids {
+		wanted[id] = -1
+	}
+	for i, layout := range all {
+		if j, ok := wanted[layout.ID]; ok && j < 0 {
+			wanted[layout.ID] = i
+		}
+	}
+
+	layouts = []chronograf.Layout{}
+	notFound = []string{}
+	for _, id := range ids {
+		i, ok := wanted[id]
+		if !ok {
+			continue
+		}
+		delete(wanted, id)
+		if i < 0 {
+			notFound = append(notFound, id)
+			continue
+		}
+		layouts = append(layouts, all[i])
+	}
+	return copyLayouts(layouts), notFound, nil
+}
+
+// GetCell retrieves the cell with cellID of the layout with layoutID.
+// ErrLayoutNotFound is returned if the layout does not exist and
+// ErrLayoutCellNotFound if the layout has no such cell.
+func (s *BinLayoutsStore) GetCell(ctx context.Context, layoutID, cellID string) (chronograf.Cell, error) {
+	layout, err := s.Get(ctx, layoutID)
+	if err != nil {
+		return chronograf.Cell{}, err
+	}
+
+	for _, cell := range layout.Cells {
+		if cell.I == cellID {
+			return cell, nil
+		}
+	}
+
+	s.Logger.
+		WithField("component", "apps").
+		WithField("name", layoutID).
+		WithField("cell", cellID).
+		Error("Layout cell not found")
+	return chronograf.Cell{}, chronograf.ErrLayoutCellNotFound
+}
+
+// visualizations are the cell types the UI renders.  Cells without a type
+// are rendered as line graphs.
+var visualizations = []string{
+	"line",
+	"line-stacked",
+	"line-stepplot",
+	"bar",
+	"line-plus-single-stat",
+	"single-stat",
+	"gauge",
+	"table",
+	"alerts",
+	"news",
+	"guide",
+	"note",
+}
+
+// Visualizations returns the recognized cell visualization types
+func (s *BinLayoutsStore) Visualizations(ctx context.Context) []string {
+	return append([]string{}, visualizations...)
+}
+
+// ByVisualization returns the layouts with at least one cell of vizType.
+// ErrUnknownVisualization is returned if vizType is not one of
+// Visualizations.
+func (s *BinLayoutsStore) ByVisualization(ctx context.Context, vizType string) ([]chronograf.Layout, error) {
+	known := false
+	for _, v := range visualizations {
+		known = known || v == vizType
+	}
+	if !known {
+		return nil, chronograf.ErrUnknownVisualization
+	}
+
+	layouts, err := s.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []chronograf.Layout{}
+	for _, layout := range layouts {
+		for _, cell := range layout.Cells {
+			t := cell.Type
+			if t == "" {
+				t = "line"
+			}
+			if t == vizType {
+				res = append(res, layout)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// LayoutFinding describes a layout that is missing required fields
+type LayoutFinding struct {
+	Asset   string            // Asset is the name of the bindata asset of the layout
+	Layout  chronograf.Layout // Layout is the decoded layout
+	Missing []string          // Missing are the JSON names of the empty required fields
+}
+
+// Audit returns the layouts missing an ID, measurement or cells.  Layouts
+// that cannot be decoded are reported as an error.
+func (s *BinLayoutsStore) Audit(ctx context.Context) ([]LayoutFinding, error) {
+	findings := []LayoutFinding{}
+	for _, name := range AssetNames() {
+		octets, err := Asset(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read layout %s: %v", name, err)
+		}
+
+		var layout chronograf.Layout
+		if err = json.Unmarshal(octets, &layout); err != nil {
+			return nil, fmt.Errorf("unable to decode layout %s: %v", name, err)
+		}
+
+		missing := []string{}
+		if layout.ID == "" {
+			missing = append(missing, "id")
+		}
+		if layout.Measurement == "" {
+			missing = append(missing, "measurement")
+		}
+		if len(layout.Cells) == 0 {
+			missing = append(missing, "cells")
+		}
+		if len(missing) > 0 {
+			findings = append(findings, LayoutFinding{
+				Asset:   name,
+				Layout:  layout,
+				Missing: missing,
+			})
+		}
+	}
+	return findings, nil
+}
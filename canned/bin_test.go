@@ -0,0 +1,264 @@
+package canned
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+// TestBinLayoutsStore_Audit guards against shipping layouts missing required fields
+func TestBinLayoutsStore_Audit(t *testing.T) {
+	s := &BinLayoutsStore{}
+	findings, err := s.Audit(context.Background())
+	if err != nil {
+		t.Fatalf("BinLayoutsStore.Audit() error = %v", err)
+	}
+	for _, f := range findings {
+		t.Errorf("layout %s is missing required fields: %v", f.Asset, f.Missing)
+	}
+}
+
+func TestBinLayoutsStore_GetCell(t *testing.T) {
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	tests := []struct {
+		name     string
+		layoutID string
+		cellID   string
+		wantErr  error
+	}{
+		{
+			name:     "cell of canned layout",
+			layoutID: "6dfb4d49-20dc-4157-9018-2b1b1cb75c2d",
+			cellID:   "0246e457-916b-43e3-be99-211c4cbc03e8",
+		},
+		{
+			name:     "unknown layout",
+			layoutID: "biffsgang",
+			cellID:   "0246e457-916b-43e3-be99-211c4cbc03e8",
+			wantErr:  chronograf.ErrLayoutNotFound,
+		},
+		{
+			name:     "unknown cell",
+			layoutID: "6dfb4d49-20dc-4157-9018-2b1b1cb75c2d",
+			cellID:   "biffsgang",
+			wantErr:  chronograf.ErrLayoutCellNotFound,
+		},
+	}
+	for _, tt := range tests {
+		cell, err := s.GetCell(context.Background(), tt.layoutID, tt.cellID)
+		if err != tt.wantErr {
+			t.Errorf("%q. BinLayoutsStore.GetCell() error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && cell.I != tt.cellID {
+			t.Errorf("%q. BinLayoutsStore.GetCell() = %s, want %s", tt.name, cell.I, tt.cellID)
+		}
+	}
+}
+
+func TestBinLayoutsStore_GetMany(t *testing.T) {
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		return []chronograf.Layout{
+			{ID: "cpu", Measurement: "cpu"},
+			{ID: "mem", Measurement: "mem"},
+			{ID: "disk", Measurement: "disk"},
+		}, nil
+	}
+
+	layouts, notFound, err := s.GetMany(context.Background(), []string{"disk", "biffsgang", "cpu", "disk"})
+	if err != nil {
+		t.Fatalf("BinLayoutsStore.GetMany() error = %v", err)
+	}
+	ids := []string{}
+	for _, layout := range layouts {
+		ids = append(ids, layout.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"disk", "cpu"}) {
+		t.Errorf("BinLayoutsStore.GetMany() = %v, want %v", ids, []string{"disk", "cpu"})
+	}
+	if !reflect.DeepEqual(notFound, []string{"biffsgang"}) {
+		t.Errorf("BinLayoutsStore.GetMany() notFound = %v, want %v", notFound, []string{"biffsgang"})
+	}
+
+	layouts[0].Measurement = "biff"
+	if again, _, _ := s.GetMany(context.Background(), []string{"disk"}); again[0].Measurement != "disk" {
+		t.Errorf("BinLayoutsStore.GetMany() returned the cached layout rather than a copy")
+	}
+}
+
+func TestBinLayoutsStore_ByVisualization(t *testing.T) {
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	for _, viz := range s.Visualizations(context.Background()) {
+		layouts, err := s.ByVisualization(context.Background(), viz)
+		if err != nil {
+			t.Fatalf("BinLayoutsStore.ByVisualization(%q) error = %v", viz, err)
+		}
+		for _, layout := range layouts {
+			found := false
+			for _, cell := range layout.Cells {
+				found = found || cell.Type == viz || (cell.Type == "" && viz == "line")
+			}
+			if !found {
+				t.Errorf("BinLayoutsStore.ByVisualization(%q) returned layout %s without such a cell", viz, layout.ID)
+			}
+		}
+	}
+
+	stats, _ := s.ByVisualization(context.Background(), "single-stat")
+	if len(stats) == 0 {
+		t.Errorf("BinLayoutsStore.ByVisualization(%q) should find canned layouts", "single-stat")
+	}
+	if _, err := s.ByVisualization(context.Background(), "hologram"); err != chronograf.ErrUnknownVisualization {
+		t.Errorf("BinLayoutsStore.ByVisualization() error = %v, want %v", err, chronograf.ErrUnknownVisualization)
+	}
+}
+
+func TestBinLayoutsStore_All_Warmup(t *testing.T) {
+	var mu sync.Mutex
+	loads := 0
+	var release chan struct{}
+	fail := true
+
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		mu.Lock()
+		loads++
+		wait := release
+		mu.Unlock()
+		<-wait
+		if fail {
+			return nil, chronograf.ErrLayoutInvalid
+		}
+		return []chronograf.Layout{{ID: "cpu"}}, nil
+	}
+
+	all := func(n int) []error {
+		mu.Lock()
+		release = make(chan struct{})
+		mu.Unlock()
+
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = s.All(context.Background())
+			}(i)
+		}
+		// Wait for every caller to join the flight before it completes
+		for {
+			s.mu.Lock()
+			warming := s.warming != nil
+			s.mu.Unlock()
+			if warming {
+				break
+			}
+			runtime.Gosched()
+		}
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		close(release)
+		mu.Unlock()
+		wg.Wait()
+		return errs
+	}
+
+	for _, err := range all(10) {
+		if err != chronograf.ErrLayoutInvalid {
+			t.Errorf("BinLayoutsStore.All() error = %v, want %v", err, chronograf.ErrLayoutInvalid)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("BinLayoutsStore.All() decoded layouts %d times, want 1", loads)
+	}
+
+	fail = false
+	for _, err := range all(10) {
+		if err != nil {
+			t.Errorf("BinLayoutsStore.All() after failure error = %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("BinLayoutsStore.All() decoded layouts %d times, want 2", loads)
+	}
+
+	layouts, err := s.All(context.Background())
+	if err != nil || len(layouts) != 1 || layouts[0].ID != "cpu" {
+		t.Errorf("BinLayoutsStore.All() = %v, %v", layouts, err)
+	}
+	if loads != 2 {
+		t.Errorf("BinLayoutsStore.All() did not use cached layouts")
+	}
+}
+
+func TestBinLayoutsStore_All_WarmupPanic(t *testing.T) {
+	release := make(chan struct{})
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		<-release
+		panic("corrupt asset")
+	}
+
+	go func() {
+		defer func() { recover() }()
+		s.All(context.Background())
+	}()
+	for {
+		s.mu.Lock()
+		warming := s.warming != nil
+		s.mu.Unlock()
+		if warming {
+			break
+		}
+		runtime.Gosched()
+	}
+
+	// Callers sharing a decoding that panics are released with an error
+	waited := make(chan error)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				waited <- fmt.Errorf("decoded layouts again: %v", r)
+			}
+		}()
+		_, err := s.All(context.Background())
+		waited <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	select {
+	case err := <-waited:
+		if err != errWarmingPanicked {
+			t.Errorf("BinLayoutsStore.All() error = %v, want %v", err, errWarmingPanicked)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("BinLayoutsStore.All() blocked on a decoding that panicked")
+	}
+
+	s.load = func() ([]chronograf.Layout, error) {
+		return []chronograf.Layout{{ID: "cpu"}}, nil
+	}
+	layouts, err := s.All(context.Background())
+	if err != nil || len(layouts) != 1 {
+		t.Errorf("BinLayoutsStore.All() after a panic = %v, %v", layouts, err)
+	}
+}
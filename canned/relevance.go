@@ -0,0 +1,127 @@
+package canned
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// DefaultRelevanceThreshold is the lowest score of layouts suggested for a
+// source; at least half of a layout's measurements must be present.
+const DefaultRelevanceThreshold = 0.5
+
+// ScoredLayout is a layout with its relevance to a source
+type ScoredLayout struct {
+	Layout chronograf.Layout
+	Score  float64 // Score is the fraction of the layout's measurements present in the source
+}
+
+// fromMeasurement matches the FROM clause of a query; the measurement is
+// the last of its dot separated identifiers.
+var fromMeasurement = regexp.MustCompile(`(?i)\bFROM\s+((?:"[^"]*"|[\w:]+)(?:\.(?:"[^"]*"|[\w:]+))*)`)
+
+// LayoutMeasurements returns the sorted measurements queried by the cells
+// of a layout.  A layout without parsable queries has its own measurement.
+func LayoutMeasurements(layout chronograf.Layout) []string {
+	seen := map[string]bool{}
+	for _, cell := range layout.Cells {
+		for _, q := range cell.Queries {
+			for _, m := range fromMeasurement.FindAllStringSubmatch(q.Command, -1) {
+				parts := strings.Split(m[1], ".")
+				seen[strings.Trim(parts[len(parts)-1], `"`)] = true
+			}
+		}
+	}
+	if len(seen) == 0 && layout.Measurement != "" {
+		seen[layout.Measurement] = true
+	}
+
+	res := make([]string, 0, len(seen))
+	for m := range seen {
+		res = append(res, m)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// RelevanceScore is the fraction of the layout's measurements that are
+// among the measurements of a source, from 0 (none present) to 1 (all
+// present).  Layouts without measurements score 0.
+func RelevanceScore(layout chronograf.Layout, measurements map[string]bool) float64 {
+	ms := LayoutMeasurements(layout)
+	if len(ms) == 0 {
+		return 0
+	}
+	present := 0
+	for _, m := range ms {
+		if measurements[m] {
+			present++
+		}
+	}
+	return float64(present) / float64(len(ms))
+}
+
+// ByRelevance returns the layouts scoring at least threshold for the
+// measurements of a source, most relevant first.  Layouts of equal score
+// are ordered by ID.  Layouts scoring 0 are never returned.
+func (s *BinLayoutsStore) ByRelevance(ctx context.Context, measurements []string, threshold float64) ([]ScoredLayout, error) {
+	layouts, err := s.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]bool, len(measurements))
+	for _, m := range measurements {
+		present[m] = true
+	}
+
+	res := []ScoredLayout{}
+	for _, layout := range layouts {
+		score := RelevanceScore(layout, present)
+		if score > 0 && score >= threshold {
+			res = append(res, ScoredLayout{
+				Layout: layout,
+				Score:  score,
+			})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Layout.ID < res[j].Layout.ID
+	})
+	return res, nil
+}
+
+// UncoveredMeasurements returns the sorted measurements of a source that no
+// layout queries, i.e. those without a canned dashboard.  A layout covers
+// every measurement of LayoutMeasurements.
+func (s *BinLayoutsStore) UncoveredMeasurements(ctx context.Context, measurements []string) ([]string, error) {
+	layouts, err := s.cached()
+	if err != nil {
+		return nil, err
+	}
+
+	covered := map[string]bool{}
+	for _, layout := range layouts {
+		for _, m := range LayoutMeasurements(layout) {
+			covered[m] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	res := []string{}
+	for _, m := range measurements {
+		if covered[m] || seen[m] {
+			continue
+		}
+		seen[m] = true
+		res = append(res, m)
+	}
+	sort.Strings(res)
+	return res, nil
+}
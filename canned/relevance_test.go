@@ -0,0 +1,104 @@
+package canned
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func layoutQuerying(id string, queries ...string) chronograf.Layout {
+	cell := chronograf.Cell{}
+	for _, q := range queries {
+		cell.Queries = append(cell.Queries, chronograf.Query{Command: q})
+	}
+	return chronograf.Layout{
+		ID:    id,
+		Cells: []chronograf.Cell{cell},
+	}
+}
+
+func TestLayoutMeasurements(t *testing.T) {
+	layout := layoutQuerying("docker",
+		`SELECT max("n_containers") FROM ":db:".":rp:"."docker"`,
+		`SELECT mean("io_service_bytes_recursive_read") FROM docker_container_blkio WHERE time > :dashboardTime:`,
+		`select mean("usage_percent") from "telegraf".autogen.docker`,
+	)
+	want := []string{"docker", "docker_container_blkio"}
+	if got := LayoutMeasurements(layout); !reflect.DeepEqual(got, want) {
+		t.Errorf("LayoutMeasurements() = %v, want %v", got, want)
+	}
+
+	layout = chronograf.Layout{Measurement: "cpu"}
+	if got := LayoutMeasurements(layout); !reflect.DeepEqual(got, []string{"cpu"}) {
+		t.Errorf("LayoutMeasurements() without queries = %v, want [cpu]", got)
+	}
+}
+
+func TestBinLayoutsStore_ByRelevance(t *testing.T) {
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		return []chronograf.Layout{
+			layoutQuerying("mem", `SELECT mean("used_percent") FROM "mem"`),
+			layoutQuerying("docker",
+				`SELECT max("n_containers") FROM "docker"`,
+				`SELECT mean("read") FROM "docker_container_blkio"`,
+				`SELECT mean("rx") FROM "docker_container_net"`,
+			),
+			layoutQuerying("cpu", `SELECT mean("usage_user") FROM "cpu"`),
+			layoutQuerying("redis", `SELECT mean("clients") FROM "redis"`),
+		}, nil
+	}
+
+	got, err := s.ByRelevance(context.Background(), []string{"cpu", "mem", "docker"}, 0.3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	var scores []float64
+	for _, l := range got {
+		ids = append(ids, l.Layout.ID)
+		scores = append(scores, l.Score)
+	}
+	if want := []string{"cpu", "mem", "docker"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("BinLayoutsStore.ByRelevance() = %v, want %v", ids, want)
+	}
+	if want := []float64{1, 1, 1.0 / 3}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("BinLayoutsStore.ByRelevance() scores = %v, want %v", scores, want)
+	}
+
+	got, err = s.ByRelevance(context.Background(), []string{"cpu", "mem", "docker"}, DefaultRelevanceThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("BinLayoutsStore.ByRelevance() with default threshold returned %d layouts, want 2", len(got))
+	}
+}
+
+func TestBinLayoutsStore_UncoveredMeasurements(t *testing.T) {
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		return []chronograf.Layout{
+			layoutQuerying("docker",
+				`SELECT max("n_containers") FROM "docker"`,
+				`SELECT mean("read") FROM "docker_container_blkio"`,
+			),
+			{ID: "cpu", Measurement: "cpu"},
+		}, nil
+	}
+
+	got, err := s.UncoveredMeasurements(context.Background(), []string{"redis", "cpu", "docker_container_blkio", "apache", "redis"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"apache", "redis"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BinLayoutsStore.UncoveredMeasurements() = %v, want %v", got, want)
+	}
+}
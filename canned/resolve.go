@@ -0,0 +1,69 @@
+package canned
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+// sharedQueries indexes the queries of layouts having a UUID.  These are
+// the definitions that queries of other cells may reference by Ref.
+func sharedQueries(layouts []chronograf.Layout) map[string]chronograf.Query {
+	shared := map[string]chronograf.Query{}
+	for _, layout := range layouts {
+		for _, cell := range layout.Cells {
+			for _, q := range cell.Queries {
+				if q.UUID != "" && q.Ref == "" {
+					shared[q.UUID] = q
+				}
+			}
+		}
+	}
+	return shared
+}
+
+// resolveQueries replaces the queries of layout that reference a shared
+// query with its definition.  The references that could not be resolved
+// are left as they are and returned.
+func resolveQueries(layout *chronograf.Layout, shared map[string]chronograf.Query) (unresolved []string) {
+	for i := range layout.Cells {
+		queries := layout.Cells[i].Queries
+		for j, q := range queries {
+			if q.Ref == "" {
+				continue
+			}
+			def, ok := shared[q.Ref]
+			if !ok {
+				unresolved = append(unresolved, q.Ref)
+				continue
+			}
+			queries[j] = def
+		}
+	}
+	return unresolved
+}
+
+// GetResolved retrieves the Layout with ID like Get, with the references of
+// its queries to shared query definitions resolved inline so the layout is
+// self-contained.  References to unknown definitions are logged and left in
+// the layout.
+func (s *BinLayoutsStore) GetResolved(ctx context.Context, ID string) (chronograf.Layout, error) {
+	layout, err := s.Get(ctx, ID)
+	if err != nil {
+		return chronograf.Layout{}, err
+	}
+
+	// Get succeeded so the layouts are cached
+	layouts, err := s.All(ctx)
+	if err != nil {
+		return chronograf.Layout{}, err
+	}
+	for _, ref := range resolveQueries(&layout, sharedQueries(layouts)) {
+		s.Logger.
+			WithField("component", "apps").
+			WithField("name", ID).
+			WithField("ref", ref).
+			Info("Unable to resolve shared query reference")
+	}
+	return layout, nil
+}
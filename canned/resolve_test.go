@@ -0,0 +1,68 @@
+package canned
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestBinLayoutsStore_GetResolved(t *testing.T) {
+	cpu := chronograf.Query{
+		Command: `SELECT mean("usage_user") FROM "cpu"`,
+		Label:   "% CPU",
+		UUID:    "cpu-usage",
+	}
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		return []chronograf.Layout{
+			{
+				ID:    "system",
+				Cells: []chronograf.Cell{{I: "cpu", Queries: []chronograf.Query{cpu}}},
+			},
+			{
+				ID: "overview",
+				Cells: []chronograf.Cell{
+					{
+						I: "summary",
+						Queries: []chronograf.Query{
+							{Ref: "cpu-usage"},
+							{Ref: "mem-usage"},
+							{Command: `SELECT count("n_cpus") FROM "system"`},
+						},
+					},
+				},
+			},
+		}, nil
+	}
+
+	got, err := s.GetResolved(context.Background(), "overview")
+	if err != nil {
+		t.Fatalf("BinLayoutsStore.GetResolved() error = %v", err)
+	}
+	want := []chronograf.Query{
+		cpu,
+		{Ref: "mem-usage"},
+		{Command: `SELECT count("n_cpus") FROM "system"`},
+	}
+	if !reflect.DeepEqual(got.Cells[0].Queries, want) {
+		t.Errorf("BinLayoutsStore.GetResolved() queries = %v, want %v", got.Cells[0].Queries, want)
+	}
+
+	// Resolving must not change the cached layouts
+	unresolved, err := s.Get(context.Background(), "overview")
+	if err != nil {
+		t.Fatalf("BinLayoutsStore.Get() error = %v", err)
+	}
+	if ref := unresolved.Cells[0].Queries[0].Ref; ref != "cpu-usage" {
+		t.Errorf("BinLayoutsStore.Get() ref = %q, want cpu-usage", ref)
+	}
+
+	if _, err := s.GetResolved(context.Background(), "biffsgang"); err != chronograf.ErrLayoutNotFound {
+		t.Errorf("BinLayoutsStore.GetResolved() error = %v, want %v", err, chronograf.ErrLayoutNotFound)
+	}
+}
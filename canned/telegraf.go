@@ -0,0 +1,115 @@
+package canned
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TelegrafMeasurements maps Telegraf input plugins to the measurements
+// of the canned layouts that visualize their data.  When adding a new
+// canned layout, add its measurement to the plugin producing it.
+var TelegrafMeasurements = map[string][]string{
+	"apache":        {"apache"},
+	"consul":        {"consul_health_checks"},
+	"cpu":           {"cpu"},
+	"disk":          {"disk"},
+	"diskio":        {"diskio"},
+	"docker":        {"docker", "docker_container_blkio", "docker_container_net"},
+	"elasticsearch": {"elasticsearch_indices"},
+	"haproxy":       {"haproxy"},
+	"influxdb": {
+		"influxdb_database",
+		"influxdb_httpd",
+		"influxdb_queryExecutor",
+		"influxdb_write",
+	},
+	"kubernetes": {
+		"kubernetes_node",
+		"kubernetes_pod_container",
+		"kubernetes_pod_network",
+		"kubernetes_system_container",
+	},
+	"mem":        {"mem"},
+	"memcached":  {"memcached"},
+	"mesos":      {"mesos"},
+	"mongodb":    {"mongodb"},
+	"mysql":      {"mysql"},
+	"net":        {"net"},
+	"netstat":    {"netstat"},
+	"nginx":      {"nginx"},
+	"nsq":        {"nsq_channel", "nsq_server", "nsq_topic"},
+	"phpfpm":     {"phpfpm"},
+	"ping":       {"ping"},
+	"postgresql": {"postgresql"},
+	"processes":  {"processes"},
+	"procstat":   {"procstat"},
+	"rabbitmq":   {"rabbitmq_node"},
+	"redis":      {"redis"},
+	"riak":       {"riak"},
+	// consul telemetry is sent to telegraf using the statsd protocol
+	"statsd": {
+		"consul_consul_fsm_register",
+		"consul_memberlist_msg_alive",
+		"consul_raft_state_candidate",
+		"consul_consul_http_GET_v1_health_state__",
+		"consul_raft_state_leader",
+		"consul_serf_events",
+	},
+	"system":  {"system"},
+	"varnish": {"varnish"},
+	"win_perf_counters": {
+		"win_cpu",
+		"win_mem",
+		"win_net",
+		"win_system",
+		"win_websvc",
+	},
+}
+
+// TelegrafInputs returns the sorted names of the input plugins enabled
+// in a Telegraf TOML configuration.  Plugins are enabled by an
+// uncommented [[inputs.name]] table header.
+func TelegrafInputs(r io.Reader) ([]string, error) {
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[[inputs.") {
+			continue
+		}
+		end := strings.Index(line, "]]")
+		if end == -1 {
+			continue
+		}
+		name := strings.TrimSpace(line[len("[[inputs."):end])
+		// sub-tables such as [[inputs.procstat.tagpass]] belong to their plugin
+		if i := strings.Index(name, "."); i != -1 {
+			name = name[:i]
+		}
+		if name != "" {
+			seen[name] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	inputs := make([]string, 0, len(seen))
+	for name := range seen {
+		inputs = append(inputs, name)
+	}
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+// TelegrafLayoutMeasurements returns the canned layout measurements for
+// the Telegraf input plugins.  Plugins without canned layouts are ignored.
+func TelegrafLayoutMeasurements(inputs []string) []string {
+	measurements := []string{}
+	for _, input := range inputs {
+		measurements = append(measurements, TelegrafMeasurements[input]...)
+	}
+	return measurements
+}
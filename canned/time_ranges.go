@@ -0,0 +1,129 @@
+package canned
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/influxdb/influxql"
+)
+
+// LayoutTimeRange is the time range referenced by the cell queries of a
+// layout.  Queries limited only by the dashboard's time range reference
+// none, so a layout without explicit ranges has a zero LayoutTimeRange.
+type LayoutTimeRange struct {
+	ID       string        // ID is the ID of the layout
+	Relative time.Duration // Relative is the widest range back from now, e.g. 30 days for time > now() - 30d
+	Lower    time.Time     // Lower is the earliest absolute time referenced; zero if none
+	Upper    time.Time     // Upper is the latest absolute time referenced; zero if none
+}
+
+// templateVar matches the template variables of cell queries, e.g.
+// :dashboardTime:.  Names start with a letter so clock times such as
+// 22:04:00 are not mistaken for them.
+var templateVar = regexp.MustCompile(`:[A-Za-z_][A-Za-z0-9_]*:`)
+
+// queryTemplate replaces template variables so that queries parse.  The
+// dashboard's time is no explicit range so becomes now().
+func queryTemplate(v string) string {
+	switch v {
+	case ":dashboardTime:", ":upperDashboardTime:":
+		return "now()"
+	case ":interval:":
+		return "1m"
+	}
+	return "''"
+}
+
+// widen widens r by the time conditions of query.  Queries that do
+// not parse reference no range.
+func (r *LayoutTimeRange) widen(query string) {
+	q, err := influxql.ParseQuery(templateVar.ReplaceAllStringFunc(query, queryTemplate))
+	if err != nil {
+		return
+	}
+	for _, stmt := range q.Statements {
+		sel, ok := stmt.(*influxql.SelectStatement)
+		if !ok || sel.Condition == nil {
+			continue
+		}
+		influxql.WalkFunc(sel.Condition, func(n influxql.Node) {
+			cmp, ok := n.(*influxql.BinaryExpr)
+			if !ok {
+				return
+			}
+			if ref, ok := cmp.LHS.(*influxql.VarRef); !ok || ref.Val != "time" {
+				return
+			}
+			switch cmp.Op {
+			case influxql.GT, influxql.GTE, influxql.LT, influxql.LTE, influxql.EQ:
+				r.widenTo(cmp.RHS)
+			}
+		})
+	}
+}
+
+// widenTo widens r to the time of expr, either now() - duration or an
+// absolute time
+func (r *LayoutTimeRange) widenTo(expr influxql.Expr) {
+	switch e := expr.(type) {
+	case *influxql.BinaryExpr:
+		now, ok := e.LHS.(*influxql.Call)
+		d, isDur := e.RHS.(*influxql.DurationLiteral)
+		if ok && isDur && now.Name == "now" && e.Op == influxql.SUB && d.Val > r.Relative {
+			r.Relative = d.Val
+		}
+	case *influxql.StringLiteral:
+		if t, err := e.ToTimeLiteral(); err == nil {
+			r.widenAbsolute(t.Val)
+		}
+	case *influxql.TimeLiteral:
+		r.widenAbsolute(e.Val)
+	case *influxql.IntegerLiteral:
+		r.widenAbsolute(time.Unix(0, e.Val).UTC())
+	}
+}
+
+func (r *LayoutTimeRange) widenAbsolute(t time.Time) {
+	if r.Lower.IsZero() || t.Before(r.Lower) {
+		r.Lower = t
+	}
+	if r.Upper.IsZero() || t.After(r.Upper) {
+		r.Upper = t
+	}
+}
+
+// RelativeString formats the relative range as InfluxQL, e.g. -30d; empty
+// if the layout references no relative range
+func (r LayoutTimeRange) RelativeString() string {
+	if r.Relative == 0 {
+		return ""
+	}
+	return "-" + influxql.FormatDuration(r.Relative)
+}
+
+// layoutTimeRange inspects the queries of every cell of layout
+func layoutTimeRange(layout chronograf.Layout) LayoutTimeRange {
+	r := LayoutTimeRange{ID: layout.ID}
+	for _, cell := range layout.Cells {
+		for _, q := range cell.Queries {
+			r.widen(q.Command)
+		}
+	}
+	return r
+}
+
+// TimeRanges returns the time range referenced by the cell queries of each
+// layout, in the order of All
+func (s *BinLayoutsStore) TimeRanges(ctx context.Context) ([]LayoutTimeRange, error) {
+	layouts, err := s.cached()
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]LayoutTimeRange, len(layouts))
+	for i, layout := range layouts {
+		ranges[i] = layoutTimeRange(layout)
+	}
+	return ranges, nil
+}
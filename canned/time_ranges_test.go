@@ -0,0 +1,73 @@
+package canned
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestBinLayoutsStore_TimeRanges(t *testing.T) {
+	cell := func(queries ...string) chronograf.Cell {
+		c := chronograf.Cell{}
+		for _, q := range queries {
+			c.Queries = append(c.Queries, chronograf.Query{Command: q})
+		}
+		return c
+	}
+	s := &BinLayoutsStore{
+		Logger: &mocks.TestLogger{},
+	}
+	s.load = func() ([]chronograf.Layout, error) {
+		return []chronograf.Layout{
+			{
+				ID: "relative",
+				Cells: []chronograf.Cell{
+					cell(`SELECT mean("usage_user") FROM "cpu" WHERE time > now() - 1h GROUP BY time(:interval:)`),
+					cell(`SELECT max("used") FROM "mem" WHERE time > now() - 30d AND "host" = :host:`, `SELECT count("n") FROM "disk" WHERE time > now() - 7d`),
+				},
+			},
+			{
+				ID: "absolute",
+				Cells: []chronograf.Cell{
+					cell(`SELECT "speed" FROM "delorean" WHERE time >= '1955-11-12T22:04:00Z' AND time < '1985-10-26T01:21:00Z'`),
+				},
+			},
+			{
+				ID: "dashboard",
+				Cells: []chronograf.Cell{
+					cell(`SELECT mean("usage_user") FROM "cpu" WHERE time > :dashboardTime:`),
+					cell(`not a query`),
+					{},
+				},
+			},
+		}, nil
+	}
+
+	ranges, err := s.TimeRanges(context.Background())
+	if err != nil {
+		t.Fatalf("BinLayoutsStore.TimeRanges() error = %v", err)
+	}
+	if len(ranges) != 3 {
+		t.Fatalf("BinLayoutsStore.TimeRanges() returned %d ranges, want 3", len(ranges))
+	}
+
+	if got := ranges[0].RelativeString(); got != "-30d" {
+		t.Errorf("BinLayoutsStore.TimeRanges() relative range of %s = %s, want -30d", ranges[0].ID, got)
+	}
+	if !ranges[0].Lower.IsZero() {
+		t.Errorf("BinLayoutsStore.TimeRanges() absolute range of %s = %v, want none", ranges[0].ID, ranges[0].Lower)
+	}
+
+	lower := time.Date(1955, time.November, 12, 22, 4, 0, 0, time.UTC)
+	upper := time.Date(1985, time.October, 26, 1, 21, 0, 0, time.UTC)
+	if !ranges[1].Lower.Equal(lower) || !ranges[1].Upper.Equal(upper) || ranges[1].Relative != 0 {
+		t.Errorf("BinLayoutsStore.TimeRanges() range of %s = %+v, want %v to %v", ranges[1].ID, ranges[1], lower, upper)
+	}
+
+	if ranges[2] != (LayoutTimeRange{ID: "dashboard"}) {
+		t.Errorf("BinLayoutsStore.TimeRanges() range of %s = %+v, want none", ranges[2].ID, ranges[2])
+	}
+}
@@ -16,6 +16,8 @@ const (
 	ErrSourceNotFound                  = Error("source not found")
 	ErrServerNotFound                  = Error("server not found")
 	ErrLayoutNotFound                  = Error("layout not found")
+	ErrLayoutCellNotFound              = Error("layout cell not found")
+	ErrUnknownVisualization            = Error("unknown cell visualization type")
 	ErrProtoboardNotFound              = Error("protoboard not found")
 	ErrDashboardNotFound               = Error("dashboard not found")
 	ErrUserNotFound                    = Error("user not found")
@@ -45,6 +47,7 @@ const (
 	ErrInvalidCellOptionsColumns       = Error("cell options columns cannot be empty'")
 	ErrOrganizationConfigNotFound      = Error("could not find organization config")
 	ErrInvalidCellQueryType            = Error("invalid cell query type: must be 'flux' or 'influxql'")
+	ErrTemporaryGrantNotFound          = Error("temporary grant not found")
 )
 
 // Error is a domain error encountered while processing chronograf requests
@@ -141,6 +144,33 @@ type Role struct {
 	Permissions  Permissions `json:"permissions,omitempty"`
 	Users        []User      `json:"users,omitempty"`
 	Organization string      `json:"organization,omitempty"`
+	// Labels are key-value pairs organizing the roles of a source.
+	// Sources do not store labels so they are kept by a RoleLabelsStore.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Docs link to the documents authorizing the role, e.g. its change
+	// request.  Sources do not store them so they are kept by a
+	// RoleDocsStore.
+	Docs []RoleDoc `json:"docs,omitempty"`
+	// Delegations are the subsets of the permissions of other roles the
+	// role is granted.  They are resolved whenever permissions are read,
+	// so permissions revoked from a delegating role are revoked from the
+	// role too.  Sources do not store them so they are kept by a
+	// RoleDelegationsStore.
+	Delegations []RoleDelegation `json:"delegations,omitempty"`
+}
+
+// RoleDelegation delegates a subset of the permissions of a role to another
+// role
+type RoleDelegation struct {
+	Role      string     `json:"role"`                // Role is the delegating role
+	Databases []string   `json:"databases,omitempty"` // Databases limit the delegation to these databases; empty delegates every scope
+	Allowed   Allowances `json:"allowed,omitempty"`   // Allowed limit the delegation to these allowances; empty delegates every allowance
+}
+
+// RoleDoc is a document supporting a role
+type RoleDoc struct {
+	URL   string `json:"url"`
+	Label string `json:"label,omitempty"` // Label describes the document, e.g. its ticket number
 }
 
 // RolesStore is the Storage and retrieval of authentication information
@@ -201,6 +231,7 @@ type Query struct {
 	Label    string   `json:"label,omitempty"`    // Label is the Y-Axis label for the data
 	Range    *Range   `json:"range,omitempty"`    // Range is the default Y-Axis range for the data
 	UUID     string   `json:"uuid,omitempty"`     // Indentifier from client to be added to the result
+	Ref      string   `json:"ref,omitempty"`      // Ref is the UUID of a shared query definition the query stands for
 }
 
 // DashboardQuery includes state for the query builder.  This is a transition
@@ -412,9 +443,19 @@ const (
 // Permission is a specific allowance for User or Role bound to a
 // scope of the data source
 type Permission struct {
-	Scope   Scope      `json:"scope"`
-	Name    string     `json:"name,omitempty"`
-	Allowed Allowances `json:"allowed"`
+	Scope          Scope      `json:"scope"`
+	Name           string     `json:"name,omitempty"`
+	Allowed        Allowances `json:"allowed"`
+	Deny           bool       `json:"deny,omitempty"`           // Deny takes the allowances away instead of granting them; denies override grants of the same scope
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`      // ExpiresAt is when a temporary permission is no longer granted; Chronograf keeps it for sources that cannot store it
+	Classification string     `json:"classification,omitempty"` // Classification is a data classification label the server expands into a permission of each database with the label
+	Note           string     `json:"note,omitempty"`           // Note explains why the permission is granted; Chronograf keeps it for sources that cannot store it
+	Alias          string     `json:"alias,omitempty"`          // Alias is a friendly name of a database the server expands into the database's name
+}
+
+// Expired is true if the permission has an expiry at or before now
+func (p *Permission) Expired(now time.Time) bool {
+	return p.ExpiresAt != nil && !p.ExpiresAt.After(now)
 }
 
 // Permissions represent the entire set of permissions a User or Role may have
@@ -426,6 +467,90 @@ type Allowances []string
 // Scope defines the location of access of a permission
 type Scope string
 
+// TemporaryGrant is a permission granted to a role of a source until it
+// expires.  Grants are kept by Chronograf so they are revoked even if the
+// source cannot persist the expiry of permissions.
+type TemporaryGrant struct {
+	ID         string     `json:"id"`
+	SourceID   int        `json:"sourceID"`
+	Role       string     `json:"role"`
+	Permission Permission `json:"permission"` // Permission holds only the allowances the grant added to the role
+	Ephemeral  bool       `json:"ephemeral"`  // Ephemeral roles were created for the grant and are removed when it expires
+	ExpiresAt  time.Time  `json:"expiresAt"`
+}
+
+// TemporaryGrantsStore is the storage of temporary grants awaiting revocation
+type TemporaryGrantsStore interface {
+	// Add creates a new TemporaryGrant with its ID populated
+	Add(context.Context, *TemporaryGrant) (*TemporaryGrant, error)
+	// All lists all TemporaryGrants in the TemporaryGrantsStore
+	All(context.Context) ([]TemporaryGrant, error)
+	// Delete removes a TemporaryGrant from the TemporaryGrantsStore
+	Delete(context.Context, *TemporaryGrant) error
+}
+
+// RoleLabelsStore stores the labels of the roles of sources
+type RoleLabelsStore interface {
+	// All returns the labels of every labeled role of a source by role name
+	All(ctx context.Context, srcID int) (map[string]map[string]string, error)
+	// Get returns the labels of a role of a source; unlabeled roles have none
+	Get(ctx context.Context, srcID int, role string) (map[string]string, error)
+	// Put replaces the labels of a role of a source.  Putting no labels
+	// removes those of the role.
+	Put(ctx context.Context, srcID int, role string, labels map[string]string) error
+}
+
+// RoleDocsStore stores the supporting documents of the roles of sources
+type RoleDocsStore interface {
+	// All returns the documents of every documented role of a source by
+	// role name
+	All(ctx context.Context, srcID int) (map[string][]RoleDoc, error)
+	// Get returns the documents of a role of a source
+	Get(ctx context.Context, srcID int, role string) ([]RoleDoc, error)
+	// Put replaces the documents of a role of a source.  Putting no
+	// documents removes those of the role.
+	Put(ctx context.Context, srcID int, role string, docs []RoleDoc) error
+}
+
+// RoleDelegationsStore stores the delegations of the roles of sources
+type RoleDelegationsStore interface {
+	// All returns the delegations of every delegated role of a source by
+	// role name
+	All(ctx context.Context, srcID int) (map[string][]RoleDelegation, error)
+	// Get returns the delegations of a role of a source
+	Get(ctx context.Context, srcID int, role string) ([]RoleDelegation, error)
+	// Put replaces the delegations of a role of a source.  Putting no
+	// delegations removes those of the role.
+	Put(ctx context.Context, srcID int, role string, delegations []RoleDelegation) error
+}
+
+// RolePermissionsStore stores the permissions of the roles of sources the
+// sources cannot store themselves, e.g. denies
+type RolePermissionsStore interface {
+	// All returns the retained permissions of every role of a source by
+	// role name
+	All(ctx context.Context, srcID int) (map[string]Permissions, error)
+	// Get returns the retained permissions of a role of a source
+	Get(ctx context.Context, srcID int, role string) (Permissions, error)
+	// Put replaces the retained permissions of a role of a source.  Putting
+	// no permissions removes those of the role.
+	Put(ctx context.Context, srcID int, role string, perms Permissions) error
+}
+
+// RoleMembershipsStore stores when the memberships of users in the roles of
+// sources expire
+type RoleMembershipsStore interface {
+	// All returns the expiry of each expiring membership of every role of a
+	// source by role name then user name
+	All(ctx context.Context, srcID int) (map[string]map[string]time.Time, error)
+	// Get returns the expiry of each expiring membership of a role of a
+	// source by user name
+	Get(ctx context.Context, srcID int, role string) (map[string]time.Time, error)
+	// Put replaces the expiring memberships of a role of a source.  Putting
+	// none makes every membership of the role permanent.
+	Put(ctx context.Context, srcID int, role string, expiries map[string]time.Time) error
+}
+
 // User represents an authenticated user.
 type User struct {
 	ID          uint64      `json:"id,string,omitempty"`
@@ -436,6 +561,17 @@ type User struct {
 	Provider    string      `json:"provider,omitempty"`
 	Scheme      string      `json:"scheme,omitempty"`
 	SuperAdmin  bool        `json:"superAdmin,omitempty"`
+	Unresolved  bool        `json:"-"` // Unresolved is true when the user's details could not be retrieved from the data source
+	// ExpiresAt is when the membership of a user in a source role ends.
+	// Sources do not store it so it is kept by a RoleMembershipsStore;
+	// memberships without it are permanent.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// MembershipExpired is true if the role membership of the user ended at or
+// before now
+func (u *User) MembershipExpired(now time.Time) bool {
+	return u.ExpiresAt != nil && !u.ExpiresAt.After(now)
 }
 
 // UserQuery represents the attributes that a user may be retrieved by.
@@ -739,6 +875,8 @@ type Layout struct {
 	Measurement string `json:"measurement"`
 	Autoflow    bool   `json:"autoflow"`
 	Cells       []Cell `json:"cells"`
+	BaseLayout  string `json:"baseLayout,omitempty"` // BaseLayout is the ID of a layout whose cells the layout inherits
+	Versions    string `json:"versions,omitempty"`   // Versions constrains the source versions the layout is meant for, e.g. ">=1.7, <2.0"
 }
 
 // LayoutsStore stores dashboards and associated Cells
@@ -973,10 +1111,22 @@ type KVClient interface {
 	OrganizationConfigStore() OrganizationConfigStore
 	// OrganizationsStore returns the kv's OrganizationsStore type.
 	OrganizationsStore() OrganizationsStore
+	// RoleDelegationsStore returns the kv's RoleDelegationsStore type.
+	RoleDelegationsStore() RoleDelegationsStore
+	// RoleDocsStore returns the kv's RoleDocsStore type.
+	RoleDocsStore() RoleDocsStore
+	// RoleLabelsStore returns the kv's RoleLabelsStore type.
+	RoleLabelsStore() RoleLabelsStore
+	// RoleMembershipsStore returns the kv's RoleMembershipsStore type.
+	RoleMembershipsStore() RoleMembershipsStore
+	// RolePermissionsStore returns the kv's RolePermissionsStore type.
+	RolePermissionsStore() RolePermissionsStore
 	// ServersStore returns the kv's ServersStore type.
 	ServersStore() ServersStore
 	// SourcesStore returns the kv's SourcesStore type.
 	SourcesStore() SourcesStore
+	// TemporaryGrantsStore returns the kv's TemporaryGrantsStore type.
+	TemporaryGrantsStore() TemporaryGrantsStore
 	// UsersStore returns the kv's UsersStore type.
 	UsersStore() UsersStore
 }
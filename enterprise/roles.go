@@ -50,7 +50,17 @@ func (c *RolesStore) Get(ctx context.Context, name string) (*chronograf.Role, er
 	for i, u := range role.Users {
 		user, err := c.Ctrl.User(ctx, u)
 		if err != nil {
-			return nil, err
+			// A user that cannot be retrieved should not prevent the role from loading
+			c.Logger.
+				WithField("component", "roles").
+				WithField("role", name).
+				WithField("user", u).
+				Error("Unable to retrieve role user: ", err)
+			users[i] = chronograf.User{
+				Name:       u,
+				Unresolved: true,
+			}
+			continue
 		}
 		users[i] = chronograf.User{
 			Name:        user.Name,
@@ -1,10 +1,13 @@
 package enterprise
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
 )
 
 func TestRoles_ToChronograf(t *testing.T) {
@@ -30,3 +33,81 @@ func TestRoles_ToChronograf(t *testing.T) {
 		})
 	}
 }
+
+type roleUsersCtrl struct {
+	Ctrl
+	role  *Role
+	users map[string]*User
+}
+
+func (c *roleUsersCtrl) Role(ctx context.Context, name string) (*Role, error) {
+	return c.role, nil
+}
+
+func (c *roleUsersCtrl) User(ctx context.Context, name string) (*User, error) {
+	u, ok := c.users[name]
+	if !ok {
+		return nil, fmt.Errorf("user %s unavailable", name)
+	}
+	return u, nil
+}
+
+func TestRolesStore_Get(t *testing.T) {
+	tests := []struct {
+		name string
+		ctrl Ctrl
+		want *chronograf.Role
+	}{
+		{
+			name: "unavailable users are kept as unresolved",
+			ctrl: &roleUsersCtrl{
+				role: &Role{
+					Name:  "timetravelers",
+					Users: []string{"marty", "doc"},
+				},
+				users: map[string]*User{
+					"marty": {
+						Name: "marty",
+						Permissions: Permissions{
+							"": {"ViewChronograf"},
+						},
+					},
+				},
+			},
+			want: &chronograf.Role{
+				Name:        "timetravelers",
+				Permissions: chronograf.Permissions{},
+				Users: []chronograf.User{
+					{
+						Name: "marty",
+						Permissions: chronograf.Permissions{
+							{
+								Scope:   chronograf.AllScope,
+								Allowed: chronograf.Allowances{"ViewChronograf"},
+							},
+						},
+					},
+					{
+						Name:       "doc",
+						Unresolved: true,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &RolesStore{
+				Ctrl:   tt.ctrl,
+				Logger: log.New(log.DebugLevel),
+			}
+			got, err := c.Get(context.Background(), "timetravelers")
+			if err != nil {
+				t.Fatalf("RolesStore.Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RolesStore.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
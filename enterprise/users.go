@@ -160,10 +160,16 @@ func (c *UserStore) All(ctx context.Context) ([]chronograf.User, error) {
 	return res, nil
 }
 
-// ToEnterprise converts chronograf permission shape to enterprise
+// ToEnterprise converts chronograf permission shape to enterprise.
+// Enterprise has no denies, so denied allowances are removed from the
+// grants of the same scope instead.  Denies scoped to all databases are
+// removed from every grant.
 func ToEnterprise(perms chronograf.Permissions) Permissions {
 	res := Permissions{}
 	for _, perm := range perms {
+		if perm.Deny {
+			continue
+		}
 		if perm.Scope == chronograf.AllScope {
 			// Enterprise uses empty string as the key for all databases
 			res[""] = perm.Allowed
@@ -171,6 +177,32 @@ func ToEnterprise(perms chronograf.Permissions) Permissions {
 			res[perm.Name] = perm.Allowed
 		}
 	}
+	for _, perm := range perms {
+		if !perm.Deny {
+			continue
+		}
+		for db, allowed := range res {
+			if perm.Scope == chronograf.AllScope || (perm.Name == db && db != "") {
+				res[db] = withoutAllowances(allowed, perm.Allowed)
+			}
+		}
+	}
+	return res
+}
+
+func withoutAllowances(allowed, denied chronograf.Allowances) chronograf.Allowances {
+	res := chronograf.Allowances{}
+	for _, a := range allowed {
+		keep := true
+		for _, d := range denied {
+			if a == d {
+				keep = false
+			}
+		}
+		if keep {
+			res = append(res, a)
+		}
+	}
 	return res
 }
 
@@ -737,6 +737,47 @@ func Test_ToEnterprise(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Denies remove allowances",
+			want: enterprise.Permissions{
+				"":          []string{"ViewChronograf"},
+				"telegraf":  []string{"ReadData"},
+				"_internal": []string{},
+			},
+			perms: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ViewChronograf", "KapacitorAPI"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "telegraf",
+					Allowed: chronograf.Allowances{"ReadData", "WriteData", "KapacitorAPI"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "_internal",
+					Allowed: chronograf.Allowances{"ReadData"},
+				},
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"KapacitorAPI"},
+					Deny:    true,
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "telegraf",
+					Allowed: chronograf.Allowances{"WriteData"},
+					Deny:    true,
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "_internal",
+					Allowed: chronograf.Allowances{"ReadData"},
+					Deny:    true,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		if got := enterprise.ToEnterprise(tt.perms); !reflect.DeepEqual(got, tt.want) {
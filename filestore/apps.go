@@ -3,9 +3,12 @@ package filestore
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/influxdata/chronograf"
 )
@@ -16,26 +19,52 @@ const AppExt = ".json"
 // Verify apps (layouts) implements layoutsStore interface.
 var _ chronograf.LayoutsStore = (*Apps)(nil)
 
-// Apps are canned JSON layouts.  Implements LayoutsStore.
+// Apps are canned JSON layouts.  Implements LayoutsStore.  The directory
+// is read on every call so operators can add or edit layouts without
+// restarting.
 type Apps struct {
-	Dir     string                                      // Dir is the directory contained the pre-canned applications.
-	Load    func(string) (chronograf.Layout, error)     // Load loads string name and return a Layout
-	ReadDir func(dirname string) ([]os.FileInfo, error) // ReadDir reads the directory named by dirname and returns a list of directory entries sorted by filename.
-	IDs     chronograf.ID                               // IDs generate unique ids for new application layouts
-	Logger  chronograf.Logger
+	Dir      string                                      // Dir is the directory contained the pre-canned applications.
+	Load     func(string) (chronograf.Layout, error)     // Load loads string name and return a Layout
+	ReadDir  func(dirname string) ([]os.FileInfo, error) // ReadDir reads the directory named by dirname and returns a list of directory entries sorted by filename.
+	Create   func(string, chronograf.Layout) error       // Create will write layout to file.
+	Filename func(string, chronograf.Layout) string      // Filename determines the disk filename for new layouts
+	IDs      chronograf.ID                               // IDs generate unique ids for new application layouts
+	Logger   chronograf.Logger
 }
 
 // NewApps constructs a layout store wrapping a file system directory
 func NewApps(dir string, ids chronograf.ID, logger chronograf.Logger) chronograf.LayoutsStore {
 	return &Apps{
-		Dir:     dir,
-		Load:    loadFile,
-		ReadDir: ioutil.ReadDir,
-		IDs:     ids,
-		Logger:  logger,
+		Dir:      dir,
+		Load:     loadFile,
+		ReadDir:  ioutil.ReadDir,
+		Create:   createLayout,
+		Filename: fileName,
+		IDs:      ids,
+		Logger:   logger,
 	}
 }
 
+func fileName(dir string, layout chronograf.Layout) string {
+	base := fmt.Sprintf("%s%s", layout.ID, AppExt)
+	return path.Join(dir, base)
+}
+
+func createLayout(file string, layout chronograf.Layout) error {
+	h, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	if octets, err := json.MarshalIndent(layout, "    ", "    "); err != nil {
+		return chronograf.ErrLayoutInvalid
+	} else if _, err := h.Write(octets); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func loadFile(name string) (chronograf.Layout, error) {
 	octets, err := ioutil.ReadFile(name)
 	if err != nil {
@@ -62,7 +91,7 @@ func (a *Apps) All(ctx context.Context) ([]chronograf.Layout, error) {
 		if path.Ext(file.Name()) != AppExt {
 			continue
 		}
-		if layout, err := a.Load(path.Join(a.Dir, file.Name())); err != nil {
+		if layout, err := a.load(path.Join(a.Dir, file.Name())); err != nil {
 			continue // We want to load all files we can.
 		} else {
 			layouts = append(layouts, layout)
@@ -71,28 +100,66 @@ func (a *Apps) All(ctx context.Context) ([]chronograf.Layout, error) {
 	return layouts, nil
 }
 
+// load reads a layout file logging the files that are not layouts
+func (a *Apps) load(file string) (chronograf.Layout, error) {
+	layout, err := a.Load(file)
+	if err == chronograf.ErrLayoutNotFound {
+		a.Logger.
+			WithField("component", "apps").
+			WithField("name", file).
+			Error("Unable to read file")
+	} else if err == chronograf.ErrLayoutInvalid {
+		a.Logger.
+			WithField("component", "apps").
+			WithField("name", file).
+			Error("File is not a layout")
+	}
+	return layout, err
+}
+
 // Get returns an app file from the layout directory
 func (a *Apps) Get(ctx context.Context, ID string) (chronograf.Layout, error) {
-	l, file, err := a.idToFile(ID)
+	l, _, err := a.idToFile(ID)
 	if err != nil {
 		return chronograf.Layout{}, err
 	}
+	return l, nil
+}
+
+// Put writes layout to the directory keeping its ID.  The file of the
+// layout with the same ID is replaced; otherwise a file is created for it.
+func (a *Apps) Put(ctx context.Context, layout chronograf.Layout) error {
+	if layout.ID == "" {
+		return chronograf.ErrLayoutInvalid
+	}
+	_, file, err := a.idToFile(layout.ID)
+	if err == chronograf.ErrLayoutNotFound {
+		file = a.Filename(a.Dir, layout)
+	} else if err != nil {
+		return err
+	}
+	if !inDir(a.Dir, file) {
+		return chronograf.ErrLayoutInvalid
+	}
 
+	if err := a.Create(file, layout); err != nil {
+		a.Logger.
+			WithField("component", "apps").
+			WithField("name", file).
+			Error("Unable to write layout: ", err)
+		return err
+	}
+	return nil
+}
+
+// inDir reports whether file is within dir once both are cleaned, so
+// layout IDs such as "../x" cannot write outside the layouts directory
+func inDir(dir, file string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(file))
 	if err != nil {
-		if err == chronograf.ErrLayoutNotFound {
-			a.Logger.
-				WithField("component", "apps").
-				WithField("name", file).
-				Error("Unable to read file")
-		} else if err == chronograf.ErrLayoutInvalid {
-			a.Logger.
-				WithField("component", "apps").
-				WithField("name", file).
-				Error("File is not a layout")
-		}
-		return chronograf.Layout{}, err
+		return false
 	}
-	return l, nil
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 // idToFile takes an id and finds the associated filename
@@ -110,9 +177,9 @@ func (a *Apps) idToFile(ID string) (chronograf.Layout, string, error) {
 			continue
 		}
 		file := path.Join(a.Dir, f.Name())
-		layout, err := a.Load(file)
+		layout, err := a.load(file)
 		if err != nil {
-			return chronograf.Layout{}, "", err
+			continue // Other files may still contain the layout
 		}
 		if layout.ID == ID {
 			return layout, file, nil
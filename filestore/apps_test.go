@@ -104,6 +104,110 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGet_SkipsInvalidFiles(t *testing.T) {
+	t.Parallel()
+	apps, _ := MockApps([]chronograf.Layout{
+		{ID: "1",
+			Application: "howdy",
+		},
+		{ID: "2",
+			Application: "doody",
+		},
+	}, nil)
+	load := apps.Load
+	apps.Load = func(file string) (chronograf.Layout, error) {
+		if file == path.Join(apps.Dir, "1.json") {
+			return chronograf.Layout{}, chronograf.ErrLayoutInvalid
+		}
+		return load(file)
+	}
+
+	layout, err := apps.Get(context.Background(), "2")
+	if err != nil {
+		t.Fatalf("Layouts get error expected: nil; actual: %v", err)
+	}
+	if layout.Application != "doody" {
+		t.Errorf("Layouts should be equal; expected doody; actual %v", layout.Application)
+	}
+
+	layouts, err := apps.All(context.Background())
+	if err != nil {
+		t.Fatalf("apps all error expected: nil; actual: %v", err)
+	}
+	if len(layouts) != 1 || layouts[0].ID != "2" {
+		t.Errorf("apps all should skip invalid files; actual %v", layouts)
+	}
+}
+
+func TestPut(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		Existing []chronograf.Layout
+		Put      chronograf.Layout
+		File     string
+		Files    int
+		Err      error
+	}{
+		{
+			Existing: []chronograf.Layout{
+				{ID: "1",
+					Application: "howdy",
+				},
+			},
+			Put: chronograf.Layout{
+				ID:          "1",
+				Application: "doody",
+			},
+			File:  "dir/1.json",
+			Files: 1,
+		},
+		{
+			Existing: []chronograf.Layout{
+				{ID: "1",
+					Application: "howdy",
+				},
+			},
+			Put: chronograf.Layout{
+				ID:          "mysql",
+				Application: "mysql",
+			},
+			File:  "dir/mysql.json",
+			Files: 2,
+		},
+		{
+			Existing: []chronograf.Layout{},
+			Put: chronograf.Layout{
+				Application: "doody",
+			},
+			Err: chronograf.ErrLayoutInvalid,
+		},
+		{
+			Existing: []chronograf.Layout{},
+			Put: chronograf.Layout{
+				ID:          "../../etc/cron.d/layout",
+				Application: "doody",
+			},
+			Err: chronograf.ErrLayoutInvalid,
+		},
+	}
+	for i, test := range tests {
+		apps, layouts := MockApps(test.Existing, nil)
+		err := apps.Put(context.Background(), test.Put)
+		if err != test.Err {
+			t.Errorf("Test %d: apps put error expected: %v; actual: %v", i, test.Err, err)
+		}
+		if test.Err != nil {
+			continue
+		}
+		if got := (*layouts)[test.File]; !reflect.DeepEqual(got, test.Put) {
+			t.Errorf("Test %d: Layout in %s should be equal; expected %v; actual %v", i, test.File, test.Put, got)
+		}
+		if len(*layouts) != test.Files {
+			t.Errorf("Test %d: apps put should leave %d files; actual %v", i, test.Files, *layouts)
+		}
+	}
+}
+
 type MockFileInfo struct {
 	name string
 }
@@ -180,10 +284,20 @@ func MockApps(existing []chronograf.Layout, expected error) (filestore.Apps, *ma
 		return info, nil
 	}
 
+	create := func(file string, layout chronograf.Layout) error {
+		if expected != nil {
+			return expected
+		}
+		layouts[file] = layout
+		return nil
+	}
+
 	return filestore.Apps{
-		Dir:     dir,
-		Load:    loadLayout,
-		ReadDir: readDir,
+		Dir:      dir,
+		Load:     loadLayout,
+		ReadDir:  readDir,
+		Create:   create,
+		Filename: fileName,
 		IDs: &MockID{
 			id: len(existing),
 		},
@@ -205,9 +205,10 @@ func ToRevoke(username string, perm chronograf.Permission) string {
 	return ToInfluxQL("REVOKE", "FROM", username, perm)
 }
 
-// ToGrant converts the permission into InfluxQL grants
+// ToGrant converts the permission into InfluxQL grants.  InfluxQL cannot
+// deny privileges so denies are never granted.
 func ToGrant(username string, perm chronograf.Permission) string {
-	if len(perm.Allowed) == 0 {
+	if len(perm.Allowed) == 0 || perm.Deny {
 		return ""
 	}
 	return ToInfluxQL("GRANT", "TO", username, perm)
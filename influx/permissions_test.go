@@ -252,6 +252,19 @@ func TestToGrant(t *testing.T) {
 			},
 			want: "",
 		},
+		{
+			name: "deny",
+			args: args{
+				username: "biff",
+				perm: chronograf.Permission{
+					Scope:   chronograf.DBScope,
+					Name:    "gray_sports_almanac",
+					Allowed: chronograf.Allowances{"WRITE"},
+					Deny:    true,
+				},
+			},
+			want: "",
+		},
 	}
 	for _, tt := range tests {
 		if got := ToGrant(tt.args.username, tt.args.perm); got != tt.want {
@@ -217,7 +217,7 @@ type Bucket struct {
 
 var (
 	// ErrKeyNotFound is the error returned when the key requested is not found.
-	ErrKeyNotFound = errors.New("key not found")
+	ErrKeyNotFound = kv.ErrKeyNotFound
 	// ErrTxNotWritable is the error returned when an mutable operation is called during
 	// a non-writable transaction.
 	ErrTxNotWritable = errors.New("transaction is not writable")
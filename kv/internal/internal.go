@@ -909,3 +909,26 @@ func UnmarshalMapping(data []byte, m *chronograf.Mapping) error {
 func UnmarshalMappingPB(data []byte, m *Mapping) error {
 	return proto.Unmarshal(data, m)
 }
+
+// MarshalRoleEntry encodes an entry of a role, such as its labels or
+// documents, to JSON.  Entries of roles have no protobuf message so are
+// stored as JSON.
+func MarshalRoleEntry(entry interface{}) ([]byte, error) {
+	return json.Marshal(entry)
+}
+
+// UnmarshalRoleEntry decodes an entry of a role from JSON.
+func UnmarshalRoleEntry(data []byte, entry interface{}) error {
+	return json.Unmarshal(data, entry)
+}
+
+// MarshalTemporaryGrant encodes a temporary grant to JSON.  Grants have no
+// protobuf message so are stored as JSON.
+func MarshalTemporaryGrant(g *chronograf.TemporaryGrant) ([]byte, error) {
+	return json.Marshal(g)
+}
+
+// UnmarshalTemporaryGrant decodes a temporary grant from JSON.
+func UnmarshalTemporaryGrant(data []byte, g *chronograf.TemporaryGrant) error {
+	return json.Unmarshal(data, g)
+}
@@ -3,6 +3,7 @@ package kv
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 
 	"github.com/influxdata/chronograf"
 	"github.com/influxdata/chronograf/id"
@@ -18,8 +19,14 @@ var (
 	mappingsBucket           = []byte("MappingsV1")
 	organizationConfigBucket = []byte("OrganizationConfigV1")
 	organizationsBucket      = []byte("OrganizationsV1")
+	roleDelegationsBucket    = []byte("RoleDelegationsV1")
+	roleDocsBucket           = []byte("RoleDocsV1")
+	roleLabelsBucket         = []byte("RoleLabelsV1")
+	roleMembershipsBucket    = []byte("RoleMembershipsV1")
+	rolePermissionsBucket    = []byte("RolePermissionsV1")
 	serversBucket            = []byte("Servers")
 	sourcesBucket            = []byte("Sources")
+	temporaryGrantsBucket    = []byte("TemporaryGrantsV1")
 	usersBucket              = []byte("UsersV2")
 )
 
@@ -63,6 +70,10 @@ type Bucket interface {
 	ForEach(fn func(k, v []byte) error) error
 }
 
+// ErrKeyNotFound is returned by the Get of buckets that error when the key
+// does not exist.
+var ErrKeyNotFound = errors.New("key not found")
+
 // Service is the struct that chronograf services are implemented on.
 type Service struct {
 	kv  Store
@@ -115,8 +126,14 @@ func (s *Service) initialize(ctx context.Context, tx Tx) error {
 		mappingsBucket,
 		organizationConfigBucket,
 		organizationsBucket,
+		roleDelegationsBucket,
+		roleDocsBucket,
+		roleLabelsBucket,
+		roleMembershipsBucket,
+		rolePermissionsBucket,
 		serversBucket,
 		sourcesBucket,
+		temporaryGrantsBucket,
 		usersBucket,
 	}
 
@@ -168,6 +185,31 @@ func (s *Service) OrganizationsStore() chronograf.OrganizationsStore {
 	return &organizationsStore{client: s}
 }
 
+// RoleDelegationsStore returns a chronograf.RoleDelegationsStore.
+func (s *Service) RoleDelegationsStore() chronograf.RoleDelegationsStore {
+	return &roleDelegationsStore{entries: roleEntriesStore{client: s, bucket: roleDelegationsBucket}}
+}
+
+// RoleDocsStore returns a chronograf.RoleDocsStore.
+func (s *Service) RoleDocsStore() chronograf.RoleDocsStore {
+	return &roleDocsStore{entries: roleEntriesStore{client: s, bucket: roleDocsBucket}}
+}
+
+// RoleLabelsStore returns a chronograf.RoleLabelsStore.
+func (s *Service) RoleLabelsStore() chronograf.RoleLabelsStore {
+	return &roleLabelsStore{entries: roleEntriesStore{client: s, bucket: roleLabelsBucket}}
+}
+
+// RoleMembershipsStore returns a chronograf.RoleMembershipsStore.
+func (s *Service) RoleMembershipsStore() chronograf.RoleMembershipsStore {
+	return &roleMembershipsStore{entries: roleEntriesStore{client: s, bucket: roleMembershipsBucket}}
+}
+
+// RolePermissionsStore returns a chronograf.RolePermissionsStore.
+func (s *Service) RolePermissionsStore() chronograf.RolePermissionsStore {
+	return &rolePermissionsStore{entries: roleEntriesStore{client: s, bucket: rolePermissionsBucket}}
+}
+
 // ServersStore returns a chronograf.ServersStore.
 func (s *Service) ServersStore() chronograf.ServersStore {
 	return &serversStore{client: s}
@@ -178,6 +220,11 @@ func (s *Service) SourcesStore() chronograf.SourcesStore {
 	return &sourcesStore{client: s}
 }
 
+// TemporaryGrantsStore returns a chronograf.TemporaryGrantsStore.
+func (s *Service) TemporaryGrantsStore() chronograf.TemporaryGrantsStore {
+	return &temporaryGrantsStore{client: s}
+}
+
 // UsersStore returns a chronograf.UsersStore.
 func (s *Service) UsersStore() chronograf.UsersStore {
 	return &usersStore{client: s}
@@ -0,0 +1,286 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/kv/internal"
+)
+
+// roleEntriesStore uses bolt to store and retrieve an entry of each role
+// that its source cannot store, such as the labels or documents of the
+// role.  Entries are kept in their own bucket and keyed by the source ID and
+// role name.
+type roleEntriesStore struct {
+	client *Service
+	bucket []byte
+}
+
+// roleEntriesPrefix is the prefix of the keys of the roles of a source
+func roleEntriesPrefix(srcID int) string {
+	return strconv.Itoa(srcID) + "/"
+}
+
+func roleEntriesKey(srcID int, role string) []byte {
+	return []byte(roleEntriesPrefix(srcID) + role)
+}
+
+// all decodes the entry of every role of the source with decode
+func (s *roleEntriesStore) all(ctx context.Context, srcID int, decode func(role string, v []byte) error) error {
+	prefix := roleEntriesPrefix(srcID)
+	return s.client.kv.View(ctx, func(tx Tx) error {
+		return tx.Bucket(s.bucket).ForEach(func(k, v []byte) error {
+			if !strings.HasPrefix(string(k), prefix) {
+				return nil
+			}
+			return decode(strings.TrimPrefix(string(k), prefix), v)
+		})
+	})
+}
+
+// get decodes the entry of a role of the source into entry.  entry is left
+// untouched if the role has no entry.
+func (s *roleEntriesStore) get(ctx context.Context, srcID int, role string, entry interface{}) error {
+	return s.client.kv.View(ctx, func(tx Tx) error {
+		v, err := tx.Bucket(s.bucket).Get(roleEntriesKey(srcID, role))
+		if err == ErrKeyNotFound || (err == nil && v == nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return internal.UnmarshalRoleEntry(v, entry)
+	})
+}
+
+// put replaces the entry of a role of the source.  Empty entries remove the
+// entry of the role.
+func (s *roleEntriesStore) put(ctx context.Context, srcID int, role string, entry interface{}, empty bool) error {
+	return s.client.kv.Update(ctx, func(tx Tx) error {
+		b := tx.Bucket(s.bucket)
+		key := roleEntriesKey(srcID, role)
+		if empty {
+			if v, err := b.Get(key); v == nil || err != nil {
+				return nil
+			}
+			return b.Delete(key)
+		}
+
+		v, err := internal.MarshalRoleEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(key, v)
+	})
+}
+
+// Ensure roleLabelsStore implements chronograf.RoleLabelsStore.
+var _ chronograf.RoleLabelsStore = &roleLabelsStore{}
+
+// roleLabelsStore stores the labels of roles.
+type roleLabelsStore struct {
+	entries roleEntriesStore
+}
+
+// All returns the labels of every labeled role of the source
+func (s *roleLabelsStore) All(ctx context.Context, srcID int) (map[string]map[string]string, error) {
+	all := map[string]map[string]string{}
+	err := s.entries.all(ctx, srcID, func(role string, v []byte) error {
+		var labels map[string]string
+		if err := internal.UnmarshalRoleEntry(v, &labels); err != nil {
+			return err
+		}
+		all[role] = labels
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// Get returns the labels of a role of the source
+func (s *roleLabelsStore) Get(ctx context.Context, srcID int, role string) (map[string]string, error) {
+	var labels map[string]string
+	if err := s.entries.get(ctx, srcID, role, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// Put replaces the labels of a role of the source
+func (s *roleLabelsStore) Put(ctx context.Context, srcID int, role string, labels map[string]string) error {
+	return s.entries.put(ctx, srcID, role, labels, len(labels) == 0)
+}
+
+// Ensure roleDocsStore implements chronograf.RoleDocsStore.
+var _ chronograf.RoleDocsStore = &roleDocsStore{}
+
+// roleDocsStore stores the supporting documents of roles.
+type roleDocsStore struct {
+	entries roleEntriesStore
+}
+
+// All returns the documents of every documented role of the source
+func (s *roleDocsStore) All(ctx context.Context, srcID int) (map[string][]chronograf.RoleDoc, error) {
+	all := map[string][]chronograf.RoleDoc{}
+	err := s.entries.all(ctx, srcID, func(role string, v []byte) error {
+		var docs []chronograf.RoleDoc
+		if err := internal.UnmarshalRoleEntry(v, &docs); err != nil {
+			return err
+		}
+		all[role] = docs
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// Get returns the documents of a role of the source
+func (s *roleDocsStore) Get(ctx context.Context, srcID int, role string) ([]chronograf.RoleDoc, error) {
+	var docs []chronograf.RoleDoc
+	if err := s.entries.get(ctx, srcID, role, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Put replaces the documents of a role of the source
+func (s *roleDocsStore) Put(ctx context.Context, srcID int, role string, docs []chronograf.RoleDoc) error {
+	return s.entries.put(ctx, srcID, role, docs, len(docs) == 0)
+}
+
+// Ensure roleDelegationsStore implements chronograf.RoleDelegationsStore.
+var _ chronograf.RoleDelegationsStore = &roleDelegationsStore{}
+
+// roleDelegationsStore stores the delegations of roles.
+type roleDelegationsStore struct {
+	entries roleEntriesStore
+}
+
+// All returns the delegations of every delegated role of the source
+func (s *roleDelegationsStore) All(ctx context.Context, srcID int) (map[string][]chronograf.RoleDelegation, error) {
+	all := map[string][]chronograf.RoleDelegation{}
+	err := s.entries.all(ctx, srcID, func(role string, v []byte) error {
+		var delegations []chronograf.RoleDelegation
+		if err := internal.UnmarshalRoleEntry(v, &delegations); err != nil {
+			return err
+		}
+		all[role] = delegations
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// Get returns the delegations of a role of the source
+func (s *roleDelegationsStore) Get(ctx context.Context, srcID int, role string) ([]chronograf.RoleDelegation, error) {
+	var delegations []chronograf.RoleDelegation
+	if err := s.entries.get(ctx, srcID, role, &delegations); err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+// Put replaces the delegations of a role of the source
+func (s *roleDelegationsStore) Put(ctx context.Context, srcID int, role string, delegations []chronograf.RoleDelegation) error {
+	return s.entries.put(ctx, srcID, role, delegations, len(delegations) == 0)
+}
+
+// Ensure roleMembershipsStore implements chronograf.RoleMembershipsStore.
+var _ chronograf.RoleMembershipsStore = &roleMembershipsStore{}
+
+// roleMembershipsStore stores when the memberships of users in roles
+// expire.
+type roleMembershipsStore struct {
+	entries roleEntriesStore
+}
+
+// All returns the expiring memberships of every role of the source
+func (s *roleMembershipsStore) All(ctx context.Context, srcID int) (map[string]map[string]time.Time, error) {
+	all := map[string]map[string]time.Time{}
+	err := s.entries.all(ctx, srcID, func(role string, v []byte) error {
+		var expiries map[string]time.Time
+		if err := internal.UnmarshalRoleEntry(v, &expiries); err != nil {
+			return err
+		}
+		all[role] = expiries
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// Get returns the expiring memberships of a role of the source
+func (s *roleMembershipsStore) Get(ctx context.Context, srcID int, role string) (map[string]time.Time, error) {
+	var expiries map[string]time.Time
+	if err := s.entries.get(ctx, srcID, role, &expiries); err != nil {
+		return nil, err
+	}
+	return expiries, nil
+}
+
+// Put replaces the expiring memberships of a role of the source
+func (s *roleMembershipsStore) Put(ctx context.Context, srcID int, role string, expiries map[string]time.Time) error {
+	return s.entries.put(ctx, srcID, role, expiries, len(expiries) == 0)
+}
+
+// Ensure rolePermissionsStore implements chronograf.RolePermissionsStore.
+var _ chronograf.RolePermissionsStore = &rolePermissionsStore{}
+
+// rolePermissionsStore stores the permissions of roles their sources cannot
+// store.
+type rolePermissionsStore struct {
+	entries roleEntriesStore
+}
+
+// All returns the retained permissions of every role of the source
+func (s *rolePermissionsStore) All(ctx context.Context, srcID int) (map[string]chronograf.Permissions, error) {
+	all := map[string]chronograf.Permissions{}
+	err := s.entries.all(ctx, srcID, func(role string, v []byte) error {
+		var perms chronograf.Permissions
+		if err := internal.UnmarshalRoleEntry(v, &perms); err != nil {
+			return err
+		}
+		all[role] = perms
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// Get returns the retained permissions of a role of the source
+func (s *rolePermissionsStore) Get(ctx context.Context, srcID int, role string) (chronograf.Permissions, error) {
+	var perms chronograf.Permissions
+	if err := s.entries.get(ctx, srcID, role, &perms); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// Put replaces the retained permissions of a role of the source
+func (s *rolePermissionsStore) Put(ctx context.Context, srcID int, role string, perms chronograf.Permissions) error {
+	return s.entries.put(ctx, srcID, role, perms, len(perms) == 0)
+}
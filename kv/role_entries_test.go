@@ -0,0 +1,171 @@
+package kv_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/kv"
+	"github.com/influxdata/chronograf/kv/bolt"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestRoleLabelsStore(t *testing.T) {
+	client, err := NewTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	s := client.RoleLabelsStore()
+	ctx := context.Background()
+
+	if err := s.Put(ctx, 1, "oncall", map[string]string{"team": "sre", "env": "prod"}); err != nil {
+		t.Fatalf("RoleLabelsStore.Put() error = %v", err)
+	}
+	if err := s.Put(ctx, 1, "readers/eu", map[string]string{"team": "data"}); err != nil {
+		t.Fatalf("RoleLabelsStore.Put() error = %v", err)
+	}
+	if err := s.Put(ctx, 11, "oncall", map[string]string{"team": "ops"}); err != nil {
+		t.Fatalf("RoleLabelsStore.Put() error = %v", err)
+	}
+
+	got, err := s.All(ctx, 1)
+	if err != nil {
+		t.Fatalf("RoleLabelsStore.All() error = %v", err)
+	}
+	want := map[string]map[string]string{
+		"oncall":     {"team": "sre", "env": "prod"},
+		"readers/eu": {"team": "data"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("RoleLabelsStore.All():\n-got/+want\ndiff %s", diff)
+	}
+
+	labels, err := s.Get(ctx, 11, "oncall")
+	if err != nil {
+		t.Fatalf("RoleLabelsStore.Get() error = %v", err)
+	}
+	if diff := cmp.Diff(labels, map[string]string{"team": "ops"}); diff != "" {
+		t.Errorf("RoleLabelsStore.Get():\n-got/+want\ndiff %s", diff)
+	}
+
+	if err := s.Put(ctx, 1, "oncall", nil); err != nil {
+		t.Fatalf("RoleLabelsStore.Put() of no labels error = %v", err)
+	}
+	if err := s.Put(ctx, 1, "oncall", nil); err != nil {
+		t.Fatalf("RoleLabelsStore.Put() of no labels to an unlabeled role error = %v", err)
+	}
+	labels, err = s.Get(ctx, 1, "oncall")
+	if err != nil {
+		t.Fatalf("RoleLabelsStore.Get() error = %v", err)
+	}
+	if len(labels) != 0 {
+		t.Errorf("RoleLabelsStore.Get() after removing labels = %v, want none", labels)
+	}
+}
+
+func TestRoleEntriesStores(t *testing.T) {
+	client, err := NewTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	docs := []chronograf.RoleDoc{{URL: "https://tickets.example.com/OPS-1955", Label: "OPS-1955"}}
+	delegations := []chronograf.RoleDelegation{{Role: "dbas", Databases: []string{"telegraf"}, Allowed: chronograf.Allowances{"ReadData"}}}
+	expiries := map[string]time.Time{"kiwi": time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)}
+	perms := chronograf.Permissions{{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"WriteData"}, Deny: true}}
+
+	tests := []struct {
+		name string
+		put  func() error
+		get  func() (interface{}, error)
+		all  func() (interface{}, error)
+		want interface{}
+	}{
+		{
+			name: "RoleDocsStore",
+			put:  func() error { return client.RoleDocsStore().Put(ctx, 1, "oncall", docs) },
+			get:  func() (interface{}, error) { return client.RoleDocsStore().Get(ctx, 1, "oncall") },
+			all:  func() (interface{}, error) { return client.RoleDocsStore().All(ctx, 1) },
+			want: map[string][]chronograf.RoleDoc{"oncall": docs},
+		},
+		{
+			name: "RoleDelegationsStore",
+			put:  func() error { return client.RoleDelegationsStore().Put(ctx, 1, "oncall", delegations) },
+			get:  func() (interface{}, error) { return client.RoleDelegationsStore().Get(ctx, 1, "oncall") },
+			all:  func() (interface{}, error) { return client.RoleDelegationsStore().All(ctx, 1) },
+			want: map[string][]chronograf.RoleDelegation{"oncall": delegations},
+		},
+		{
+			name: "RoleMembershipsStore",
+			put:  func() error { return client.RoleMembershipsStore().Put(ctx, 1, "oncall", expiries) },
+			get:  func() (interface{}, error) { return client.RoleMembershipsStore().Get(ctx, 1, "oncall") },
+			all:  func() (interface{}, error) { return client.RoleMembershipsStore().All(ctx, 1) },
+			want: map[string]map[string]time.Time{"oncall": expiries},
+		},
+		{
+			name: "RolePermissionsStore",
+			put:  func() error { return client.RolePermissionsStore().Put(ctx, 1, "oncall", perms) },
+			get:  func() (interface{}, error) { return client.RolePermissionsStore().Get(ctx, 1, "oncall") },
+			all:  func() (interface{}, error) { return client.RolePermissionsStore().All(ctx, 1) },
+			want: map[string]chronograf.Permissions{"oncall": perms},
+		},
+	}
+	for _, tt := range tests {
+		if err := tt.put(); err != nil {
+			t.Fatalf("%s.Put() error = %v", tt.name, err)
+		}
+	}
+	// Every store keeps the entries of the same role in its own bucket
+	for _, tt := range tests {
+		all, err := tt.all()
+		if err != nil {
+			t.Fatalf("%s.All() error = %v", tt.name, err)
+		}
+		if diff := cmp.Diff(all, tt.want); diff != "" {
+			t.Errorf("%s.All():\n-got/+want\ndiff %s", tt.name, diff)
+		}
+		if _, err := tt.get(); err != nil {
+			t.Errorf("%s.Get() error = %v", tt.name, err)
+		}
+	}
+}
+
+func TestRoleLabelsStore_GetCorrupt(t *testing.T) {
+	f, err := ioutil.TempFile("", "chronograf-bolt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ctx := context.Background()
+	b, err := bolt.NewClient(ctx, bolt.WithPath(f.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := kv.NewService(ctx, b, kv.WithLogger(mocks.NewLogger()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := b.Update(ctx, func(tx kv.Tx) error {
+		return tx.Bucket([]byte("RoleLabelsV1")).Put([]byte("1/oncall"), []byte("{"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries that cannot be decoded are errors rather than no entry
+	if _, err := client.RoleLabelsStore().Get(ctx, 1, "oncall"); err == nil {
+		t.Errorf("RoleLabelsStore.Get() of a corrupt entry error = nil, want an error")
+	}
+	if _, err := client.RoleLabelsStore().All(ctx, 1); err == nil {
+		t.Errorf("RoleLabelsStore.All() of a corrupt entry error = nil, want an error")
+	}
+}
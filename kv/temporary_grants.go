@@ -0,0 +1,74 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/kv/internal"
+)
+
+// Ensure temporaryGrantsStore implements chronograf.TemporaryGrantsStore.
+var _ chronograf.TemporaryGrantsStore = &temporaryGrantsStore{}
+
+// temporaryGrantsStore uses bolt to store and retrieve TemporaryGrants
+type temporaryGrantsStore struct {
+	client *Service
+}
+
+// Add creates a new TemporaryGrant in the temporaryGrantsStore
+func (s *temporaryGrantsStore) Add(ctx context.Context, g *chronograf.TemporaryGrant) (*chronograf.TemporaryGrant, error) {
+	err := s.client.kv.Update(ctx, func(tx Tx) error {
+		b := tx.Bucket(temporaryGrantsBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		g.ID = strconv.FormatUint(seq, 10)
+
+		v, err := internal.MarshalTemporaryGrant(g)
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(g.ID), v)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// All returns all TemporaryGrants awaiting revocation
+func (s *temporaryGrantsStore) All(ctx context.Context) ([]chronograf.TemporaryGrant, error) {
+	var grants []chronograf.TemporaryGrant
+	err := s.client.kv.View(ctx, func(tx Tx) error {
+		return tx.Bucket(temporaryGrantsBucket).ForEach(func(k, v []byte) error {
+			var g chronograf.TemporaryGrant
+			if err := internal.UnmarshalTemporaryGrant(v, &g); err != nil {
+				return err
+			}
+			grants = append(grants, g)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// Delete the TemporaryGrant from the temporaryGrantsStore
+func (s *temporaryGrantsStore) Delete(ctx context.Context, g *chronograf.TemporaryGrant) error {
+	return s.client.kv.Update(ctx, func(tx Tx) error {
+		b := tx.Bucket(temporaryGrantsBucket)
+		if v, err := b.Get([]byte(g.ID)); v == nil || err != nil {
+			return chronograf.ErrTemporaryGrantNotFound
+		}
+		return b.Delete([]byte(g.ID))
+	})
+}
@@ -0,0 +1,77 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/chronograf"
+)
+
+func TestTemporaryGrantsStore(t *testing.T) {
+	client, err := NewTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	s := client.TemporaryGrantsStore()
+	ctx := context.Background()
+	expires := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	grants := []chronograf.TemporaryGrant{
+		{
+			SourceID: 1,
+			Role:     "oncall",
+			Permission: chronograf.Permission{
+				Scope:   chronograf.DBScope,
+				Name:    "telegraf",
+				Allowed: chronograf.Allowances{"WRITE"},
+			},
+			ExpiresAt: expires,
+		},
+		{
+			SourceID: 2,
+			Role:     "incident",
+			Permission: chronograf.Permission{
+				Scope:   chronograf.AllScope,
+				Allowed: chronograf.Allowances{"READ"},
+			},
+			Ephemeral: true,
+			ExpiresAt: expires,
+		},
+	}
+	for i := range grants {
+		g, err := s.Add(ctx, &grants[i])
+		if err != nil {
+			t.Fatalf("TemporaryGrantsStore.Add() error = %v", err)
+		}
+		if g.ID == "" {
+			t.Fatalf("TemporaryGrantsStore.Add() did not set the ID")
+		}
+	}
+
+	got, err := s.All(ctx)
+	if err != nil {
+		t.Fatalf("TemporaryGrantsStore.All() error = %v", err)
+	}
+	if diff := cmp.Diff(got, grants); diff != "" {
+		t.Errorf("TemporaryGrantsStore.All():\n-got/+want\ndiff %s", diff)
+	}
+
+	if err := s.Delete(ctx, &grants[0]); err != nil {
+		t.Fatalf("TemporaryGrantsStore.Delete() error = %v", err)
+	}
+	if err := s.Delete(ctx, &grants[0]); err != chronograf.ErrTemporaryGrantNotFound {
+		t.Errorf("TemporaryGrantsStore.Delete() of deleted grant error = %v, want %v", err, chronograf.ErrTemporaryGrantNotFound)
+	}
+
+	got, err = s.All(ctx)
+	if err != nil {
+		t.Fatalf("TemporaryGrantsStore.All() error = %v", err)
+	}
+	if diff := cmp.Diff(got, grants[1:]); diff != "" {
+		t.Errorf("TemporaryGrantsStore.All() after Delete():\n-got/+want\ndiff %s", diff)
+	}
+}
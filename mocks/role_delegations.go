@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RoleDelegationsStore = &RoleDelegationsStore{}
+
+type RoleDelegationsStore struct {
+	AllF func(ctx context.Context, srcID int) (map[string][]chronograf.RoleDelegation, error)
+	GetF func(ctx context.Context, srcID int, role string) ([]chronograf.RoleDelegation, error)
+	PutF func(ctx context.Context, srcID int, role string, delegations []chronograf.RoleDelegation) error
+}
+
+func (s *RoleDelegationsStore) All(ctx context.Context, srcID int) (map[string][]chronograf.RoleDelegation, error) {
+	return s.AllF(ctx, srcID)
+}
+
+func (s *RoleDelegationsStore) Get(ctx context.Context, srcID int, role string) ([]chronograf.RoleDelegation, error) {
+	return s.GetF(ctx, srcID, role)
+}
+
+func (s *RoleDelegationsStore) Put(ctx context.Context, srcID int, role string, delegations []chronograf.RoleDelegation) error {
+	return s.PutF(ctx, srcID, role, delegations)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RoleDocsStore = &RoleDocsStore{}
+
+type RoleDocsStore struct {
+	AllF func(ctx context.Context, srcID int) (map[string][]chronograf.RoleDoc, error)
+	GetF func(ctx context.Context, srcID int, role string) ([]chronograf.RoleDoc, error)
+	PutF func(ctx context.Context, srcID int, role string, docs []chronograf.RoleDoc) error
+}
+
+func (s *RoleDocsStore) All(ctx context.Context, srcID int) (map[string][]chronograf.RoleDoc, error) {
+	return s.AllF(ctx, srcID)
+}
+
+func (s *RoleDocsStore) Get(ctx context.Context, srcID int, role string) ([]chronograf.RoleDoc, error) {
+	return s.GetF(ctx, srcID, role)
+}
+
+func (s *RoleDocsStore) Put(ctx context.Context, srcID int, role string, docs []chronograf.RoleDoc) error {
+	return s.PutF(ctx, srcID, role, docs)
+}
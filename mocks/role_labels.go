@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RoleLabelsStore = &RoleLabelsStore{}
+
+type RoleLabelsStore struct {
+	AllF func(ctx context.Context, srcID int) (map[string]map[string]string, error)
+	GetF func(ctx context.Context, srcID int, role string) (map[string]string, error)
+	PutF func(ctx context.Context, srcID int, role string, labels map[string]string) error
+}
+
+func (s *RoleLabelsStore) All(ctx context.Context, srcID int) (map[string]map[string]string, error) {
+	return s.AllF(ctx, srcID)
+}
+
+func (s *RoleLabelsStore) Get(ctx context.Context, srcID int, role string) (map[string]string, error) {
+	return s.GetF(ctx, srcID, role)
+}
+
+func (s *RoleLabelsStore) Put(ctx context.Context, srcID int, role string, labels map[string]string) error {
+	return s.PutF(ctx, srcID, role, labels)
+}
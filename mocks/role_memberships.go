@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RoleMembershipsStore = &RoleMembershipsStore{}
+
+type RoleMembershipsStore struct {
+	AllF func(ctx context.Context, srcID int) (map[string]map[string]time.Time, error)
+	GetF func(ctx context.Context, srcID int, role string) (map[string]time.Time, error)
+	PutF func(ctx context.Context, srcID int, role string, expiries map[string]time.Time) error
+}
+
+func (s *RoleMembershipsStore) All(ctx context.Context, srcID int) (map[string]map[string]time.Time, error) {
+	return s.AllF(ctx, srcID)
+}
+
+func (s *RoleMembershipsStore) Get(ctx context.Context, srcID int, role string) (map[string]time.Time, error) {
+	return s.GetF(ctx, srcID, role)
+}
+
+func (s *RoleMembershipsStore) Put(ctx context.Context, srcID int, role string, expiries map[string]time.Time) error {
+	return s.PutF(ctx, srcID, role, expiries)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RolePermissionsStore = &RolePermissionsStore{}
+
+type RolePermissionsStore struct {
+	AllF func(ctx context.Context, srcID int) (map[string]chronograf.Permissions, error)
+	GetF func(ctx context.Context, srcID int, role string) (chronograf.Permissions, error)
+	PutF func(ctx context.Context, srcID int, role string, perms chronograf.Permissions) error
+}
+
+func (s *RolePermissionsStore) All(ctx context.Context, srcID int) (map[string]chronograf.Permissions, error) {
+	return s.AllF(ctx, srcID)
+}
+
+func (s *RolePermissionsStore) Get(ctx context.Context, srcID int, role string) (chronograf.Permissions, error) {
+	return s.GetF(ctx, srcID, role)
+}
+
+func (s *RolePermissionsStore) Put(ctx context.Context, srcID int, role string, perms chronograf.Permissions) error {
+	return s.PutF(ctx, srcID, role, perms)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.TemporaryGrantsStore = &TemporaryGrantsStore{}
+
+type TemporaryGrantsStore struct {
+	AddF    func(context.Context, *chronograf.TemporaryGrant) (*chronograf.TemporaryGrant, error)
+	AllF    func(context.Context) ([]chronograf.TemporaryGrant, error)
+	DeleteF func(context.Context, *chronograf.TemporaryGrant) error
+}
+
+func (s *TemporaryGrantsStore) Add(ctx context.Context, g *chronograf.TemporaryGrant) (*chronograf.TemporaryGrant, error) {
+	return s.AddF(ctx, g)
+}
+
+func (s *TemporaryGrantsStore) All(ctx context.Context) ([]chronograf.TemporaryGrant, error) {
+	return s.AllF(ctx)
+}
+
+func (s *TemporaryGrantsStore) Delete(ctx context.Context, g *chronograf.TemporaryGrant) error {
+	return s.DeleteF(ctx, g)
+}
@@ -0,0 +1,126 @@
+package multistore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// CompatibleLayouts is a LayoutsStore whose All method returns only the
+// layouts meant for the version of a source.  Layouts without version
+// constraints are always included.
+type CompatibleLayouts struct {
+	chronograf.LayoutsStore
+	Version string // Version of the source, e.g. 1.8.3 or 1.8.3-c1.8.3
+}
+
+// All returns the layouts of the store the source version satisfies
+func (s *CompatibleLayouts) All(ctx context.Context) ([]chronograf.Layout, error) {
+	layouts, err := s.LayoutsStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	compatible := []chronograf.Layout{}
+	for _, l := range layouts {
+		if LayoutSupports(l, s.Version) {
+			compatible = append(compatible, l)
+		}
+	}
+	return compatible, nil
+}
+
+// LayoutSupports checks if a source of version satisfies every
+// comma-separated constraint of the layout's Versions.  A constraint is a
+// version optionally preceded by one of =, !=, <, <=, > or >=.  If the
+// source version is unknown every layout is supported; a layout with a
+// constraint that cannot be parsed supports no version.
+func LayoutSupports(layout chronograf.Layout, version string) bool {
+	if strings.TrimSpace(layout.Versions) == "" {
+		return true
+	}
+	v, ok := parseVersion(version)
+	if !ok {
+		return true
+	}
+	for _, constraint := range strings.Split(layout.Versions, ",") {
+		if !satisfies(v, strings.TrimSpace(constraint)) {
+			return false
+		}
+	}
+	return true
+}
+
+// versionOperators are the operators of version constraints, longest first
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+func satisfies(v []int, constraint string) bool {
+	op := ""
+	for _, o := range versionOperators {
+		if strings.HasPrefix(constraint, o) {
+			op = o
+			break
+		}
+	}
+	want, ok := parseVersion(constraint[len(op):])
+	if !ok {
+		return false
+	}
+	cmp := compareVersions(v, want)
+	switch op {
+	case "", "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// parseVersion parses the major, minor and patch numbers of version.  A
+// leading v and any suffix after a - or + are ignored, so the Enterprise
+// version 1.8.3-c1.8.3 is 1.8.3.
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return nil, false
+	}
+	v := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is less than, equal to or greater
+// than b
+func compareVersions(a, b []int) int {
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package multistore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestLayoutSupports(t *testing.T) {
+	tests := []struct {
+		versions string
+		version  string
+		want     bool
+	}{
+		{versions: "", version: "1.8.3", want: true},
+		{versions: ">=1.7", version: "1.8.3", want: true},
+		{versions: ">=1.7", version: "1.6.6", want: false},
+		{versions: ">=1.7, <2.0", version: "2.0.0", want: false},
+		{versions: ">=1.7, <2.0", version: "v1.8.0", want: true},
+		{versions: ">1.8", version: "1.8.0", want: false},
+		{versions: "<=1.8", version: "1.8.0", want: true},
+		{versions: "1.8.3", version: "1.8.3-c1.8.3", want: true},
+		{versions: "=1.8", version: "1.8.1", want: false},
+		{versions: "!=1.8.1", version: "1.8.1", want: false},
+		{versions: ">=1.7", version: "Unknown", want: true},
+		{versions: ">=1.7", version: "", want: true},
+		{versions: "~1.7", version: "1.7.0", want: false},
+		{versions: ">=1.x", version: "1.7.0", want: false},
+	}
+	for _, tt := range tests {
+		layout := chronograf.Layout{Versions: tt.versions}
+		if got := LayoutSupports(layout, tt.version); got != tt.want {
+			t.Errorf("LayoutSupports(%q, %q) = %v, want %v", tt.versions, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestCompatibleLayouts_All(t *testing.T) {
+	s := &CompatibleLayouts{
+		LayoutsStore: &mocks.LayoutsStore{
+			AllF: func(ctx context.Context) ([]chronograf.Layout, error) {
+				return []chronograf.Layout{
+					{ID: "cpu"},
+					{ID: "flux", Versions: ">=1.7"},
+					{ID: "legacy", Versions: "<1.0"},
+				}, nil
+			},
+		},
+		Version: "1.8.3",
+	}
+	layouts, err := s.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []chronograf.Layout{
+		{ID: "cpu"},
+		{ID: "flux", Versions: ">=1.7"},
+	}
+	if !reflect.DeepEqual(layouts, want) {
+		t.Errorf("CompatibleLayouts.All() = %v, want %v", layouts, want)
+	}
+}
@@ -2,6 +2,8 @@ package multistore
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/influxdata/chronograf"
 )
@@ -9,12 +11,20 @@ import (
 // Layouts is a LayoutsStore that contains multiple LayoutsStores
 // The All method will return the set of all Layouts.
 // Each method will be tried against the Stores slice serially.
+// Operations taking longer than their timeout fail with
+// chronograf.ErrUpstreamTimeout; a zero timeout waits for the stores.
 type Layouts struct {
-	Stores []chronograf.LayoutsStore
+	Stores     []chronograf.LayoutsStore
+	GetTimeout time.Duration // GetTimeout limits Get and GetResolved
+	AllTimeout time.Duration // AllTimeout limits All
 }
 
 // All returns the set of all layouts
 func (s *Layouts) All(ctx context.Context) ([]chronograf.Layout, error) {
+	return layoutsWithin(ctx, s.AllTimeout, s.all)
+}
+
+func (s *Layouts) all(ctx context.Context) ([]chronograf.Layout, error) {
 	all := []chronograf.Layout{}
 	layoutSet := map[string]chronograf.Layout{}
 	ok := false
@@ -43,7 +53,14 @@ func (s *Layouts) All(ctx context.Context) ([]chronograf.Layout, error) {
 }
 
 // Get retrieves Layout if `ID` exists.  Searches through each store sequentially until success.
+// The cells of the layout's base layouts are merged into it.
 func (s *Layouts) Get(ctx context.Context, ID string) (chronograf.Layout, error) {
+	return layoutWithin(ctx, s.GetTimeout, func(ctx context.Context) (chronograf.Layout, error) {
+		return s.withBases(ctx, ID, s.get)
+	})
+}
+
+func (s *Layouts) get(ctx context.Context, ID string) (chronograf.Layout, error) {
 	var err error
 	for _, store := range s.Stores {
 		var l chronograf.Layout
@@ -54,3 +71,134 @@ func (s *Layouts) Get(ctx context.Context, ID string) (chronograf.Layout, error)
 	}
 	return chronograf.Layout{}, err
 }
+
+// layoutResolver is a LayoutsStore able to resolve references of layouts
+// to shared query definitions
+type layoutResolver interface {
+	GetResolved(ctx context.Context, ID string) (chronograf.Layout, error)
+}
+
+// GetResolved retrieves Layout if `ID` exists with its shared query
+// references resolved by stores that support them.  Searches through each
+// store sequentially until success.  The cells of the layout's base layouts
+// are merged into it.
+func (s *Layouts) GetResolved(ctx context.Context, ID string) (chronograf.Layout, error) {
+	return layoutWithin(ctx, s.GetTimeout, func(ctx context.Context) (chronograf.Layout, error) {
+		return s.withBases(ctx, ID, s.getResolved)
+	})
+}
+
+func (s *Layouts) getResolved(ctx context.Context, ID string) (chronograf.Layout, error) {
+	var err error
+	for _, store := range s.Stores {
+		var l chronograf.Layout
+		if r, ok := store.(layoutResolver); ok {
+			l, err = r.GetResolved(ctx, ID)
+		} else {
+			l, err = store.Get(ctx, ID)
+		}
+		if err == nil {
+			return l, nil
+		}
+	}
+	return chronograf.Layout{}, err
+}
+
+// withBases gets the layout with ID and merges the cells of its base layout
+// into it, then those of the base's base and so on.  A base layout may be in
+// any of the stores.  The cells of a derived layout replace the cells of its
+// base having the same ID.
+func (s *Layouts) withBases(ctx context.Context, ID string, get func(context.Context, string) (chronograf.Layout, error)) (chronograf.Layout, error) {
+	layout, err := get(ctx, ID)
+	if err != nil {
+		return chronograf.Layout{}, err
+	}
+
+	seen := map[string]bool{layout.ID: true}
+	for baseID := layout.BaseLayout; baseID != ""; {
+		if seen[baseID] {
+			return chronograf.Layout{}, fmt.Errorf("Layout %s inherits from itself through base layout %s", ID, baseID)
+		}
+		seen[baseID] = true
+
+		base, err := get(ctx, baseID)
+		if err != nil {
+			return chronograf.Layout{}, fmt.Errorf("Unable to get base layout %s of layout %s: %v", baseID, ID, err)
+		}
+		layout.Cells = mergeCells(base.Cells, layout.Cells)
+		baseID = base.BaseLayout
+	}
+	return layout, nil
+}
+
+// layoutWithin calls get with a deadline of timeout.  Stores that do not
+// honor the deadline of their context are not waited for; their result is
+// discarded.
+func layoutWithin(ctx context.Context, timeout time.Duration, get func(context.Context) (chronograf.Layout, error)) (chronograf.Layout, error) {
+	if timeout <= 0 {
+		return get(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		layout chronograf.Layout
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		l, err := get(ctx)
+		results <- result{l, err}
+	}()
+	select {
+	case r := <-results:
+		return r.layout, r.err
+	case <-ctx.Done():
+		return chronograf.Layout{}, chronograf.ErrUpstreamTimeout
+	}
+}
+
+// layoutsWithin is like layoutWithin for operations returning many layouts
+func layoutsWithin(ctx context.Context, timeout time.Duration, all func(context.Context) ([]chronograf.Layout, error)) ([]chronograf.Layout, error) {
+	if timeout <= 0 {
+		return all(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		layouts []chronograf.Layout
+		err     error
+	}
+	results := make(chan result, 1)
+	go func() {
+		l, err := all(ctx)
+		results <- result{l, err}
+	}()
+	select {
+	case r := <-results:
+		return r.layouts, r.err
+	case <-ctx.Done():
+		return nil, chronograf.ErrUpstreamTimeout
+	}
+}
+
+// mergeCells returns the cells of a base layout followed by the cells of a
+// layout derived from it.  Cells of the base with the ID of a derived cell
+// are left out.
+func mergeCells(base, derived []chronograf.Cell) []chronograf.Cell {
+	replaced := map[string]bool{}
+	for _, cell := range derived {
+		if cell.I != "" {
+			replaced[cell.I] = true
+		}
+	}
+
+	cells := make([]chronograf.Cell, 0, len(base)+len(derived))
+	for _, cell := range base {
+		if cell.I == "" || !replaced[cell.I] {
+			cells = append(cells, cell)
+		}
+	}
+	return append(cells, derived...)
+}
@@ -0,0 +1,125 @@
+package multistore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestLayouts_Get_BaseLayout(t *testing.T) {
+	custom := map[string]chronograf.Layout{
+		"cpu": {
+			ID:         "cpu",
+			BaseLayout: "header",
+			Cells: []chronograf.Cell{
+				{I: "title", Name: "CPU"},
+				{I: "usage", Name: "Usage"},
+			},
+		},
+		"loop": {
+			ID:         "loop",
+			BaseLayout: "loop",
+		},
+		"orphan": {
+			ID:         "orphan",
+			BaseLayout: "missing",
+		},
+	}
+	canned := map[string]chronograf.Layout{
+		"header": {
+			ID:         "header",
+			BaseLayout: "footer",
+			Cells: []chronograf.Cell{
+				{I: "title", Name: "Title"},
+				{I: "logo", Name: "Logo"},
+			},
+		},
+		"footer": {
+			ID: "footer",
+			Cells: []chronograf.Cell{
+				{I: "links", Name: "Links"},
+			},
+		},
+	}
+	store := func(layouts map[string]chronograf.Layout) chronograf.LayoutsStore {
+		return &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				if l, ok := layouts[id]; ok {
+					return l, nil
+				}
+				return chronograf.Layout{}, chronograf.ErrLayoutNotFound
+			},
+		}
+	}
+	s := &Layouts{
+		Stores: []chronograf.LayoutsStore{store(custom), store(canned)},
+	}
+
+	got, err := s.Get(context.Background(), "cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []chronograf.Cell{
+		{I: "links", Name: "Links"},
+		{I: "logo", Name: "Logo"},
+		{I: "title", Name: "CPU"},
+		{I: "usage", Name: "Usage"},
+	}
+	if !reflect.DeepEqual(got.Cells, want) {
+		t.Errorf("Layouts.Get() cells = %v, want %v", got.Cells, want)
+	}
+
+	for _, id := range []string{"loop", "orphan"} {
+		if _, err := s.Get(context.Background(), id); err == nil {
+			t.Errorf("Layouts.Get(%q) expected error", id)
+		}
+	}
+}
+
+func TestLayouts_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	// The slow store ignores the deadline of its context
+	slow := &mocks.LayoutsStore{
+		GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+			<-release
+			return chronograf.Layout{ID: id}, nil
+		},
+		AllF: func(ctx context.Context) ([]chronograf.Layout, error) {
+			<-release
+			return nil, nil
+		},
+	}
+	s := &Layouts{
+		Stores:     []chronograf.LayoutsStore{slow},
+		GetTimeout: 10 * time.Millisecond,
+		AllTimeout: 10 * time.Millisecond,
+	}
+
+	ctx := context.Background()
+	if _, err := s.Get(ctx, "cpu"); err != chronograf.ErrUpstreamTimeout {
+		t.Errorf("Layouts.Get() error = %v, want %v", err, chronograf.ErrUpstreamTimeout)
+	}
+	if _, err := s.GetResolved(ctx, "cpu"); err != chronograf.ErrUpstreamTimeout {
+		t.Errorf("Layouts.GetResolved() error = %v, want %v", err, chronograf.ErrUpstreamTimeout)
+	}
+	if _, err := s.All(ctx); err != chronograf.ErrUpstreamTimeout {
+		t.Errorf("Layouts.All() error = %v, want %v", err, chronograf.ErrUpstreamTimeout)
+	}
+
+	fast := &Layouts{
+		Stores: []chronograf.LayoutsStore{&mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{ID: id}, nil
+			},
+		}},
+		GetTimeout: time.Second,
+	}
+	if l, err := fast.Get(ctx, "cpu"); err != nil || l.ID != "cpu" {
+		t.Errorf("Layouts.Get() = %v, %v, want cpu", l, err)
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"time"
+
 	"github.com/influxdata/chronograf"
 	"github.com/influxdata/chronograf/canned"
 	"github.com/influxdata/chronograf/filestore"
@@ -19,6 +21,8 @@ type MultiLayoutBuilder struct {
 	Logger     chronograf.Logger
 	UUID       chronograf.ID
 	CannedPath string
+	GetTimeout time.Duration // GetTimeout limits getting a layout; zero waits for the stores
+	AllTimeout time.Duration // AllTimeout limits listing the layouts; zero waits for the stores
 }
 
 // Build will construct a Layouts of canned personalized layouts.
@@ -37,6 +41,8 @@ func (builder *MultiLayoutBuilder) Build() (*multistore.Layouts, error) {
 			apps,
 			binApps,
 		},
+		GetTimeout: builder.GetTimeout,
+		AllTimeout: builder.AllTimeout,
 	}
 
 	return layouts, nil
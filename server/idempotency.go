@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header identifying retries of a request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyKeys remembers the responses of requests with an
+// Idempotency-Key header so retries are answered without repeating the
+// request.  Keys are kept in memory for a TTL.
+type IdempotencyKeys struct {
+	TTL time.Duration
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[idempotencyKey]*idempotentResponse
+}
+
+// NewIdempotencyKeys remembers responses for ttl
+func NewIdempotencyKeys(ttl time.Duration) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		TTL:     ttl,
+		Now:     time.Now,
+		entries: map[idempotencyKey]*idempotentResponse{},
+	}
+}
+
+// idempotencyKey scopes a client key to the request it was first used with
+// and to the user who sent it
+type idempotencyKey struct {
+	key       string
+	method    string
+	path      string
+	principal string // principal is the issuer and subject of the authenticated user; empty without authentication
+}
+
+type idempotentResponse struct {
+	body     [sha256.Size]byte // body is the hash of the request body
+	expires  time.Time
+	done     bool
+	status   int
+	header   http.Header
+	response []byte
+}
+
+// begin returns the remembered response of key.  If the key is new, an
+// in-flight entry is recorded and ok is false.
+func (k *IdempotencyKeys) begin(key idempotencyKey, body [sha256.Size]byte) (res *idempotentResponse, ok bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.Now()
+	for key, res := range k.entries {
+		if res.done && !now.Before(res.expires) {
+			delete(k.entries, key)
+		}
+	}
+
+	if res, ok := k.entries[key]; ok {
+		return res, true
+	}
+	k.entries[key] = &idempotentResponse{body: body}
+	return nil, false
+}
+
+// finish remembers the response of key.  Server errors are not remembered
+// so the request may be retried.
+func (k *IdempotencyKeys) finish(key idempotencyKey, rec *responseRecorder) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if rec.status >= http.StatusInternalServerError {
+		delete(k.entries, key)
+		return
+	}
+	res := k.entries[key]
+	res.done = true
+	res.expires = k.Now().Add(k.TTL)
+	res.status = rec.status
+	res.header = rec.Header()
+	res.response = rec.body.Bytes()
+}
+
+// idempotent replays the remembered response of a request whose
+// Idempotency-Key was already used instead of calling next again.  A key
+// reused with a different body is rejected.
+func (s *Service) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if s.IdempotencyKeys == nil || key == "" {
+			next(w, r)
+			return
+		}
+
+		octets, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			invalidJSON(w, s.Logger)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(octets))
+
+		ik := idempotencyKey{
+			key:    key,
+			method: r.Method,
+			path:   r.URL.Path,
+		}
+		if p, err := getPrincipal(r.Context()); err == nil {
+			ik.principal = p.Issuer + "\x00" + p.Subject
+		}
+		body := sha256.Sum256(octets)
+		res, ok := s.IdempotencyKeys.begin(ik, body)
+		switch {
+		case ok && res.body != body:
+			Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request body", s.Logger)
+			return
+		case ok && !res.done:
+			Error(w, http.StatusConflict, "A request with this Idempotency-Key is in progress", s.Logger)
+			return
+		case ok:
+			for k, v := range res.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(res.status)
+			_, _ = w.Write(res.response)
+			return
+		}
+
+		rec := &responseRecorder{
+			header: http.Header{},
+			status: http.StatusOK,
+		}
+		next(rec, r)
+		s.IdempotencyKeys.finish(ik, rec)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+	}
+}
+
+// responseRecorder captures a response so it can be replayed
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/oauth2"
+)
+
+func TestService_idempotent(t *testing.T) {
+	now := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+	keys := NewIdempotencyKeys(time.Hour)
+	keys.Now = func() time.Time { return now }
+
+	calls := 0
+	h := &Service{
+		Logger:          log.New(log.DebugLevel),
+		IdempotencyKeys: keys,
+	}
+	handler := h.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		location(w, "/chronograf/v1/sources/1/roles/biffsgang")
+		encodeJSON(w, http.StatusCreated, map[string]int{"call": calls}, h.Logger)
+	})
+
+	steps := []struct {
+		name       string
+		key        string
+		subject    string
+		body       string
+		after      time.Duration
+		wantStatus int
+		wantBody   string
+		wantCalls  int
+	}{
+		{
+			name:       "First request",
+			key:        "abc",
+			body:       `{"name":"biffsgang"}`,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"call":1}
+`,
+			wantCalls: 1,
+		},
+		{
+			name:       "Retry replays the response",
+			key:        "abc",
+			body:       `{"name":"biffsgang"}`,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"call":1}
+`,
+			wantCalls: 1,
+		},
+		{
+			name:       "Key reused with another body",
+			key:        "abc",
+			body:       `{"name":"timetravelers"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"Idempotency-Key was used with a different request body"}`,
+			wantCalls:  1,
+		},
+		{
+			name:       "Keys of other users are not replayed",
+			key:        "abc",
+			subject:    "biff",
+			body:       `{"name":"biffsgang"}`,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"call":2}
+`,
+			wantCalls: 2,
+		},
+		{
+			name:       "Requests without a key are not remembered",
+			body:       `{"name":"biffsgang"}`,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"call":3}
+`,
+			wantCalls: 3,
+		},
+		{
+			name:       "Expired keys are forgotten",
+			key:        "abc",
+			body:       `{"name":"biffsgang"}`,
+			after:      time.Hour,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"call":4}
+`,
+			wantCalls: 4,
+		},
+	}
+	for _, tt := range steps {
+		now = now.Add(tt.after)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles", bytes.NewBufferString(tt.body))
+		if tt.key != "" {
+			r.Header.Set(IdempotencyKeyHeader, tt.key)
+		}
+		if tt.subject != "" {
+			r = r.WithContext(context.WithValue(r.Context(), oauth2.PrincipalKey, oauth2.Principal{
+				Subject: tt.subject,
+				Issuer:  "github",
+			}))
+		}
+
+		handler(w, r)
+
+		resp := w.Result()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%q. status = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("%q. body = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+		}
+		if resp.StatusCode == http.StatusCreated && resp.Header.Get("Location") == "" {
+			t.Errorf("%q. Location header was not replayed", tt.name)
+		}
+		if calls != tt.wantCalls {
+			t.Errorf("%q. handler called %d times, want %d", tt.name, calls, tt.wantCalls)
+		}
+	}
+}
@@ -77,6 +77,10 @@ func (s *Service) Influx(w http.ResponseWriter, r *http.Request) {
 		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
 		return
 	}
+	if s.RoleUsage != nil {
+		// Queries run as the source's user so its roles are the ones used
+		s.RoleUsage.Record(id, src.Username, time.Now())
+	}
 
 	uniqueID := req.UUID
 	if uniqueID == "" {
@@ -1,11 +1,20 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bouk/httprouter"
 	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/canned"
+	"github.com/influxdata/chronograf/multistore"
 )
 
 type link struct {
@@ -56,7 +65,8 @@ type getLayoutsResponse struct {
 	Layouts []layoutResponse `json:"layouts"`
 }
 
-// Layouts retrieves all layouts from store
+// Layouts retrieves all layouts from store.  With source=ID only the layouts
+// compatible with the version of the source are retrieved.
 func (s *Service) Layouts(w http.ResponseWriter, r *http.Request) {
 	// Construct a filter sieve for both applications and measurements
 	filtered := map[string]bool{}
@@ -69,7 +79,23 @@ func (s *Service) Layouts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	layouts, err := s.Store.Layouts(ctx).All(ctx)
+	store := s.Store.Layouts(ctx)
+	if id := r.URL.Query().Get("source"); id != "" {
+		version, err := s.sourceVersion(ctx, w, id)
+		if err != nil {
+			return
+		}
+		store = &multistore.CompatibleLayouts{
+			LayoutsStore: store,
+			Version:      version,
+		}
+	}
+
+	layouts, err := store.All(ctx)
+	if err == chronograf.ErrUpstreamTimeout {
+		layoutsTimeout(w, s.Logger)
+		return
+	}
 	if err != nil {
 		Error(w, http.StatusInternalServerError, "Error loading layouts", s.Logger)
 		return
@@ -100,20 +126,197 @@ func (s *Service) Layouts(w http.ResponseWriter, r *http.Request) {
 			res.Layouts = append(res.Layouts, newLayoutResponse(layout))
 		}
 	}
-	encodeJSON(w, http.StatusOK, res, s.Logger)
+	encodeCacheableJSON(w, r, res, s.Logger)
+}
+
+// sourceVersion returns the InfluxDB version recorded for the source with
+// id.  Sources whose version could not be retrieved have the version
+// Unknown, with which all layouts are compatible.
+func (s *Service) sourceVersion(ctx context.Context, w http.ResponseWriter, id string) (string, error) {
+	srcID, err := strconv.Atoi(id)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, "source must be the ID of a source", s.Logger)
+		return "", err
+	}
+	src, err := s.Store.Sources(ctx).Get(ctx, srcID)
+	if err != nil {
+		notFound(w, srcID, s.Logger)
+		return "", err
+	}
+	return src.Version, nil
+}
+
+// layoutResolver is a LayoutsStore able to resolve references of layouts
+// to shared query definitions
+type layoutResolver interface {
+	GetResolved(ctx context.Context, ID string) (chronograf.Layout, error)
 }
 
-// LayoutsID retrieves layout with ID from store
+// LayoutsID retrieves layout with ID from store.  With resolve=true,
+// references to shared query definitions are resolved inline if the store
+// supports it.  Clients accepting application/yaml receive the layout as
+// YAML.  With cellOffset or cellLimit only a page of the cells is returned
+// with the total number of cells; only the first page has the metadata of
+// the layout.
 func (s *Service) LayoutsID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id := httprouter.GetParamFromContext(ctx, "id")
+	page, paged, err := validLayoutCellsQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
 
-	layout, err := s.Store.Layouts(ctx).Get(ctx, id)
+	store := s.Store.Layouts(ctx)
+	get := store.Get
+	if resolver, ok := store.(layoutResolver); ok && r.URL.Query().Get("resolve") == "true" {
+		get = resolver.GetResolved
+	}
+	layout, err := s.getLayout(ctx, get, id)
+	if err == chronograf.ErrUpstreamTimeout {
+		layoutsTimeout(w, s.Logger)
+		return
+	}
 	if err != nil {
 		Error(w, http.StatusNotFound, fmt.Sprintf("ID %s not found", id), s.Logger)
 		return
 	}
 
+	s.LayoutAccess.record(layout.ID, time.Now())
 	res := newLayoutResponse(layout)
+	s.LayoutVersions.record(res)
+	var v interface{} = res
+	if paged {
+		v = layoutCells(res, page, r.URL)
+	}
+	w.Header().Set("Vary", "Accept")
+	if layout.ID != id {
+		// The default layout stands in for a layout that may be created
+		// later, so it must not be cached under the missing layout's URL
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	if wantsYAML(r) {
+		encodeCacheableYAML(w, r, v, s.Logger)
+		return
+	}
+	encodeCacheableJSON(w, r, v, s.Logger)
+}
+
+// getLayout gets layout id with get.  If the layout does not exist the
+// DefaultLayout is returned instead, when one is configured; clients can
+// tell by its ID.
+func (s *Service) getLayout(ctx context.Context, get func(context.Context, string) (chronograf.Layout, error), id string) (chronograf.Layout, error) {
+	layout, err := get(ctx, id)
+	if err != chronograf.ErrLayoutNotFound || s.DefaultLayout == "" || id == s.DefaultLayout {
+		return layout, err
+	}
+	return get(ctx, s.DefaultLayout)
+}
+
+// layoutsTimeout responds that the layout stores did not answer in time
+func layoutsTimeout(w http.ResponseWriter, logger chronograf.Logger) {
+	Error(w, http.StatusGatewayTimeout, "Timeout loading layouts", logger)
+}
+
+// encodeCacheableJSON writes v with a strong ETag of the SHA-256 of the
+// encoded response.  Clients revalidate the response before each use, so
+// changed layouts are seen at once.  Identical layouts have the same ETag
+// across restarts.  If the request's If-None-Match matches the ETag, 304 is
+// returned without a body.  Byte ranges of the encoding may be requested
+// with the Range header.
+func encodeCacheableJSON(w http.ResponseWriter, r *http.Request, v interface{}, logger chronograf.Logger) {
+	body, etag, err := cacheableJSON(v)
+	if err != nil {
+		unknownErrorWithMessage(w, err, logger)
+		return
+	}
+	serveCacheable(w, r, JSONType, body, etag)
+}
+
+// serveCacheable writes the encoded body of a cacheable response of
+// contentType; see encodeCacheableJSON.  A Cache-Control already set by
+// the handler is kept.
+func serveCacheable(w http.ResponseWriter, r *http.Request, contentType string, body []byte, etag string) {
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	w.Header().Set("ETag", etag)
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// ServeContent answers Range requests with 206 Partial Content so
+	// clients on slow links can resume interrupted transfers.  If-Range is
+	// checked against the ETag.
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
+
+// cacheableJSON encodes v and returns the encoding with its strong ETag
+func cacheableJSON(v interface{}) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), strongETag(buf.Bytes()), nil
+}
+
+// strongETag is the strong entity tag of an encoded response
+func strongETag(body []byte) string {
+	return fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+}
+
+// etagMatch uses the weak comparison of If-None-Match to check if etag is
+// one of the entity tags of header
+func etagMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+type telegrafLayoutsResponse struct {
+	Inputs  []string         `json:"inputs"`
+	Layouts []layoutResponse `json:"layouts"`
+}
+
+// TelegrafLayouts retrieves the layouts of the input plugins enabled in the
+// Telegraf configuration of the request body
+func (s *Service) TelegrafLayouts(w http.ResponseWriter, r *http.Request) {
+	inputs, err := canned.TelegrafInputs(r.Body)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Unable to read Telegraf configuration", s.Logger)
+		return
+	}
+
+	filtered := map[string]bool{}
+	for _, m := range canned.TelegrafLayoutMeasurements(inputs) {
+		filtered[m] = true
+	}
+
+	ctx := r.Context()
+	layouts, err := s.Store.Layouts(ctx).All(ctx)
+	if err == chronograf.ErrUpstreamTimeout {
+		layoutsTimeout(w, s.Logger)
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Error loading layouts", s.Logger)
+		return
+	}
+
+	res := telegrafLayoutsResponse{
+		Inputs:  inputs,
+		Layouts: []layoutResponse{},
+	}
+	for _, layout := range layouts {
+		if filtered[layout.Measurement] {
+			res.Layouts = append(res.Layouts, newLayoutResponse(layout))
+		}
+	}
 	encodeJSON(w, http.StatusOK, res, s.Logger)
 }
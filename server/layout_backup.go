@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// LayoutsWriter stores layouts keeping their IDs, e.g. the directory of
+// layouts overlaying those compiled into Chronograf
+type LayoutsWriter interface {
+	Put(ctx context.Context, layout chronograf.Layout) error
+}
+
+// Actions taken for each layout of a restore
+const (
+	restoreWritten = "written"
+	restoreFailed  = "failed"
+)
+
+// layoutsBackup is a portable document of the layouts being served
+type layoutsBackup struct {
+	Layouts    []chronograf.Layout `json:"layouts"`
+	ExportedAt time.Time           `json:"exportedAt"`
+	Links      selfLinks           `json:"links"`
+}
+
+// layoutRestore reports the action taken for one restored layout
+type layoutRestore struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+type layoutsRestoreResponse struct {
+	Written int             `json:"written"`
+	Failed  int             `json:"failed"`
+	Layouts []layoutRestore `json:"layouts"`
+}
+
+// ExportLayouts returns every layout being served, from whichever stores
+// are active, as a document RestoreLayouts accepts.  Layouts are exported
+// as stored, so compiled layouts are a snapshot of this Chronograf.
+func (s *Service) ExportLayouts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	layouts, err := s.Store.Layouts(ctx).All(ctx)
+	if err == chronograf.ErrUpstreamTimeout {
+		layoutsTimeout(w, s.Logger)
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Error loading layouts", s.Logger)
+		return
+	}
+
+	res := layoutsBackup{
+		Layouts:    layouts,
+		ExportedAt: time.Now().UTC(),
+		Links:      selfLinks{Self: "/chronograf/v1/layouts-export"},
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
+
+// safeLayoutID reports whether id can name a layout file by itself, as
+// layouts stores writing files derive their file names from layout IDs
+func safeLayoutID(id string) bool {
+	return !strings.ContainsAny(id, `/\`) &&
+		!strings.Contains(id, "..") &&
+		filepath.Base(id) == id
+}
+
+// RestoreLayouts writes each layout of a layout export to the writable
+// layouts store, where it overlays any compiled layout with the same ID.
+// Exports are either the document of ExportLayouts or an array of layouts.
+// A layout failing to be written does not stop the others.  Layouts are
+// served to every organization, so only super admins may restore them.
+func (s *Service) RestoreLayouts(w http.ResponseWriter, r *http.Request) {
+	if s.LayoutOverlay == nil {
+		Error(w, http.StatusNotFound, "No writable layouts store is available", s.Logger)
+		return
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	var layouts []chronograf.Layout
+	if err := json.Unmarshal(raw, &layouts); err != nil {
+		var backup layoutsBackup
+		if err := json.Unmarshal(raw, &backup); err != nil || backup.Layouts == nil {
+			invalidJSON(w, s.Logger)
+			return
+		}
+		layouts = backup.Layouts
+	}
+
+	var errs validationErrors
+	seen := map[string]bool{}
+	for i, layout := range layouts {
+		field := fmt.Sprintf("layouts[%d].id", i)
+		if layout.ID == "" {
+			errs.add(field, "Layout ID required")
+		} else if !safeLayoutID(layout.ID) {
+			errs.add(field, "Layout ID %s must not contain path separators or ..", layout.ID)
+		} else if seen[layout.ID] {
+			errs.add(field, "Layout %s is restored more than once", layout.ID)
+		}
+		seen[layout.ID] = true
+	}
+	if err := errs.err(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	res := layoutsRestoreResponse{
+		Layouts: make([]layoutRestore, len(layouts)),
+	}
+	for i, layout := range layouts {
+		res.Layouts[i] = layoutRestore{
+			ID:     layout.ID,
+			Action: restoreWritten,
+		}
+		if err := s.LayoutOverlay.Put(ctx, layout); err != nil {
+			res.Layouts[i].Action = restoreFailed
+			res.Layouts[i].Error = err.Error()
+			res.Failed++
+			continue
+		}
+		res.Written++
+	}
+
+	s.Logger.
+		WithField("component", "layouts").
+		WithField("written", res.Written).
+		WithField("failed", res.Failed).
+		Info("Restored layouts")
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
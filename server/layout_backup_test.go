@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+type mockLayoutsWriter map[string]chronograf.Layout
+
+func (m mockLayoutsWriter) Put(ctx context.Context, layout chronograf.Layout) error {
+	if layout.ID == "readonly" {
+		return fmt.Errorf("permission denied")
+	}
+	m[layout.ID] = layout
+	return nil
+}
+
+func TestService_ExportLayouts(t *testing.T) {
+	h := &Service{
+		Store: &mocks.Store{
+			LayoutsStore: &mocks.LayoutsStore{
+				AllF: func(ctx context.Context) ([]chronograf.Layout, error) {
+					return []chronograf.Layout{
+						{ID: "cpu", Application: "system", Measurement: "cpu"},
+					}, nil
+				},
+			},
+		},
+		Logger: log.New(log.DebugLevel),
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://any.url/chronograf/v1/layouts-export", nil)
+	h.ExportLayouts(w, r)
+
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("ExportLayouts() = %v, want %v: %s", resp.StatusCode, http.StatusOK, body)
+	}
+	if want := `{"layouts":[{"id":"cpu","app":"system","measurement":"cpu","autoflow":false,"cells":null}],"exportedAt":`; !strings.HasPrefix(string(body), want) {
+		t.Errorf("ExportLayouts() = %s, want prefix %s", body, want)
+	}
+	if want := `"links":{"self":"/chronograf/v1/layouts-export"}}`; !strings.Contains(string(body), want) {
+		t.Errorf("ExportLayouts() = %s, want %s", body, want)
+	}
+}
+
+func TestService_RestoreLayouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		noOverlay   bool
+		wantStatus  int
+		wantBody    string
+		wantWritten []string
+	}{
+		{
+			name:        "Export document",
+			body:        `{"layouts":[{"id":"cpu","app":"system","measurement":"cpu"},{"id":"readonly","app":"system"}],"exportedAt":"2026-10-15T00:00:00Z","links":{"self":"/chronograf/v1/layouts-export"}}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"written":1,"failed":1,"layouts":[{"id":"cpu","action":"written"},{"id":"readonly","action":"failed","error":"permission denied"}]}` + "\n",
+			wantWritten: []string{"cpu"},
+		},
+		{
+			name:        "Array of layouts",
+			body:        `[{"id":"mem","app":"system","measurement":"mem"}]`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"written":1,"failed":0,"layouts":[{"id":"mem","action":"written"}]}` + "\n",
+			wantWritten: []string{"mem"},
+		},
+		{
+			name:       "Layouts without IDs or restored twice",
+			body:       `[{"app":"system"},{"id":"mem"},{"id":"mem"}]`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"Layout ID required; Layout mem is restored more than once","errors":[{"field":"layouts[0].id","message":"Layout ID required"},{"field":"layouts[2].id","message":"Layout mem is restored more than once"}]}` + "\n",
+		},
+		{
+			name:       "Layout IDs outside the layouts directory",
+			body:       `[{"id":"../../etc/cron.d/layout"},{"id":"a\\b"},{"id":".."}]`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"Layout ID ../../etc/cron.d/layout must not contain path separators or ..; Layout ID a\\b must not contain path separators or ..; Layout ID .. must not contain path separators or ..","errors":[{"field":"layouts[0].id","message":"Layout ID ../../etc/cron.d/layout must not contain path separators or .."},{"field":"layouts[1].id","message":"Layout ID a\\b must not contain path separators or .."},{"field":"layouts[2].id","message":"Layout ID .. must not contain path separators or .."}]}` + "\n",
+		},
+		{
+			name:       "Not an export",
+			body:       `{"roles":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":400,"message":"Unparsable JSON"}`,
+		},
+		{
+			name:       "No writable store",
+			body:       `[]`,
+			noOverlay:  true,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":404,"message":"No writable layouts store is available"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlay := mockLayoutsWriter{}
+			h := &Service{
+				Logger: log.New(log.DebugLevel),
+			}
+			if !tt.noOverlay {
+				h.LayoutOverlay = overlay
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://any.url/chronograf/v1/layouts-import", strings.NewReader(tt.body))
+			h.RestoreLayouts(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("RestoreLayouts() = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("RestoreLayouts() = \n***%v***\n,\nwant\n***%v***", string(body), tt.wantBody)
+			}
+			if len(overlay) != len(tt.wantWritten) {
+				t.Errorf("RestoreLayouts() wrote %v, want %v", overlay, tt.wantWritten)
+			}
+			for _, id := range tt.wantWritten {
+				if _, ok := overlay[id]; !ok {
+					t.Errorf("RestoreLayouts() did not write layout %s", id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/influxdata/chronograf"
+)
+
+// Query parameters paginating the cells of a layout
+const (
+	cellOffsetQuery = "cellOffset"
+	cellLimitQuery  = "cellLimit"
+)
+
+// layoutCellsPage is the position of a page of the cells of a layout
+type layoutCellsPage struct {
+	CellsTotal int    `json:"cellsTotal"` // CellsTotal is the number of cells of the whole layout
+	CellOffset int    `json:"cellOffset"`
+	CellLimit  int    `json:"cellLimit"`
+	Next       string `json:"next,omitempty"` // Next is the URL of the following page, if there are more cells
+}
+
+// pagedLayoutResponse is the first page of a layout.  It has the metadata
+// of the layout so clients can render it before loading the other pages.
+type pagedLayoutResponse struct {
+	layoutResponse
+	layoutCellsPage
+}
+
+// layoutCellsResponse is a page of the cells of a layout after the first
+type layoutCellsResponse struct {
+	ID    string            `json:"id"`
+	Cells []chronograf.Cell `json:"cells"`
+	layoutCellsPage
+	Link link `json:"link"`
+}
+
+// validLayoutCellsQuery reads the cell page of a layout request.  ok is
+// false if the request has neither cellOffset nor cellLimit, so the whole
+// layout is wanted.  A missing cellLimit includes every cell from the
+// offset on.
+func validLayoutCellsQuery(q url.Values) (page layoutCellsPage, ok bool, err error) {
+	offset, limit := q.Get(cellOffsetQuery), q.Get(cellLimitQuery)
+	if offset == "" && limit == "" {
+		return page, false, nil
+	}
+	if offset != "" {
+		if page.CellOffset, err = strconv.Atoi(offset); err != nil || page.CellOffset < 0 {
+			return page, false, fmt.Errorf("cellOffset must be a non-negative integer")
+		}
+	}
+	if limit != "" {
+		if page.CellLimit, err = strconv.Atoi(limit); err != nil || page.CellLimit <= 0 {
+			return page, false, fmt.Errorf("cellLimit must be a positive integer")
+		}
+	}
+	return page, true, nil
+}
+
+// layoutCells returns the page of the cells of res the request at u asked
+// for.  Offsets past the last cell give an empty page.
+func layoutCells(res layoutResponse, page layoutCellsPage, u *url.URL) interface{} {
+	cells := res.Cells
+	page.CellsTotal = len(cells)
+	if page.CellOffset > len(cells) {
+		page.CellOffset = len(cells)
+	}
+	if page.CellLimit == 0 {
+		page.CellLimit = len(cells) - page.CellOffset
+	}
+	end := page.CellOffset + page.CellLimit
+	if end > len(cells) {
+		end = len(cells)
+	}
+	if end < len(cells) {
+		q := u.Query()
+		q.Set(cellOffsetQuery, strconv.Itoa(end))
+		q.Set(cellLimitQuery, strconv.Itoa(page.CellLimit))
+		page.Next = fmt.Sprintf("%s?%s", u.Path, q.Encode())
+	}
+
+	cells = append([]chronograf.Cell{}, cells[page.CellOffset:end]...)
+	if page.CellOffset == 0 {
+		res.Cells = cells
+		return pagedLayoutResponse{
+			layoutResponse:  res,
+			layoutCellsPage: page,
+		}
+	}
+	return layoutCellsResponse{
+		ID:              res.ID,
+		Cells:           cells,
+		layoutCellsPage: page,
+		Link:            res.Link,
+	}
+}
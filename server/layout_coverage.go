@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// measurementsPage is how many measurements are listed per query when all
+// the measurements of a database are needed
+const measurementsPage = 1000
+
+type uncoveredMeasurementsResponse struct {
+	Databases []string  `json:"databases"` // Databases are those whose measurements were checked
+	Checked   int       `json:"checked"`   // Checked is the number of distinct measurements checked
+	Uncovered []string  `json:"uncovered"` // Uncovered are the measurements no canned layout queries
+	Links     selfLinks `json:"links"`
+}
+
+// databaseMeasurements lists every measurement of a database of the
+// connected source
+func (s *Service) databaseMeasurements(ctx context.Context, db string) ([]string, error) {
+	names := []string{}
+	for offset := 0; ; offset += measurementsPage {
+		ms, err := s.Databases.GetMeasurements(ctx, db, measurementsPage, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range ms {
+			names = append(names, m.Name)
+		}
+		if len(ms) < measurementsPage {
+			return names, nil
+		}
+	}
+}
+
+// SourceUncoveredMeasurements lists the measurements of a source that no
+// layout compiled into Chronograf queries, so dashboards can be authored
+// for them.  It is the inverse of suggesting layouts for a source.  The db
+// query parameter checks a single database; otherwise every database of
+// the source is checked.
+func (s *Service) SourceUncoveredMeasurements(w http.ResponseWriter, r *http.Request) {
+	if s.CannedLayouts == nil {
+		Error(w, http.StatusNotFound, "Canned layouts are not available", s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+
+	dbs, err := s.sourceDatabases(ctx, w, srcID)
+	if err != nil {
+		return
+	}
+	if db := r.URL.Query().Get("db"); db != "" {
+		found := false
+		for _, name := range dbs {
+			found = found || name == db
+		}
+		if !found {
+			Error(w, http.StatusNotFound, fmt.Sprintf("Database %s not found", db), s.Logger)
+			return
+		}
+		dbs = []string{db}
+	}
+
+	measurements := []string{}
+	seen := map[string]bool{}
+	for _, db := range dbs {
+		ms, err := s.databaseMeasurements(ctx, db)
+		if err != nil {
+			Error(w, http.StatusBadRequest, fmt.Sprintf("Unable to get measurements of database %s: %v", db, err), s.Logger)
+			return
+		}
+		for _, m := range ms {
+			if !seen[m] {
+				seen[m] = true
+				measurements = append(measurements, m)
+			}
+		}
+	}
+
+	uncovered, err := s.CannedLayouts.UncoveredMeasurements(ctx, measurements)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Error loading layouts", s.Logger)
+		return
+	}
+	res := uncoveredMeasurementsResponse{
+		Databases: dbs,
+		Checked:   len(measurements),
+		Uncovered: uncovered,
+		Links:     selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/layouts/uncovered", srcID)},
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/canned"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceUncoveredMeasurements(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Every database",
+			wantStatus: http.StatusOK,
+			wantBody: `{"databases":["telegraf","app"],"checked":3,"uncovered":["checkout_latency","fluxcapacitor"],"links":{"self":"/chronograf/v1/sources/1/layouts/uncovered"}}
+`,
+		},
+		{
+			name:       "One database",
+			query:      "?db=telegraf",
+			wantStatus: http.StatusOK,
+			wantBody: `{"databases":["telegraf"],"checked":2,"uncovered":["fluxcapacitor"],"links":{"self":"/chronograf/v1/sources/1/layouts/uncovered"}}
+`,
+		},
+		{
+			name:       "Unknown database",
+			query:      "?db=delorean",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":404,"message":"Database delorean not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			measurements := map[string][]chronograf.Measurement{
+				"telegraf": {{Name: "cpu"}, {Name: "fluxcapacitor"}},
+				"app":      {{Name: "checkout_latency"}, {Name: "cpu"}},
+			}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{ID: 1}, nil
+						},
+					},
+				},
+				Databases: &mocks.Databases{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					AllDBF: func(ctx context.Context) ([]chronograf.Database, error) {
+						return []chronograf.Database{{Name: "telegraf"}, {Name: "app"}}, nil
+					},
+					GetMeasurementsF: func(ctx context.Context, db string, limit, offset int) ([]chronograf.Measurement, error) {
+						if offset > 0 {
+							return nil, nil
+						}
+						return measurements[db], nil
+					},
+				},
+				CannedLayouts: &canned.BinLayoutsStore{Logger: &mocks.TestLogger{}},
+				Logger:        log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/layouts/uncovered"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceUncoveredMeasurements(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceUncoveredMeasurements() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceUncoveredMeasurements() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
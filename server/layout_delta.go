@@ -0,0 +1,214 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// maxLayoutVersions bounds the layout versions remembered by the server
+const maxLayoutVersions = 1024
+
+// LayoutVersions remembers the layout responses served by ETag so changes
+// to a layout can be sent as a JSON patch from the version a client holds.
+// The oldest versions are forgotten once more than Max are remembered.
+type LayoutVersions struct {
+	Max int
+
+	mu       sync.Mutex
+	versions map[string][]byte
+	order    []string
+}
+
+// NewLayoutVersions creates a store remembering up to max layout versions
+func NewLayoutVersions(max int) *LayoutVersions {
+	return &LayoutVersions{
+		Max:      max,
+		versions: map[string][]byte{},
+	}
+}
+
+// record remembers the encoding of a layout response by its ETag.  record
+// does nothing if versions are not tracked.
+func (l *LayoutVersions) record(res layoutResponse) {
+	if l == nil {
+		return
+	}
+	body, etag, err := cacheableJSON(res)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.versions[etag]; ok {
+		return
+	}
+	l.versions[etag] = body
+	l.order = append(l.order, etag)
+	for len(l.order) > l.Max {
+		delete(l.versions, l.order[0])
+		l.order = l.order[1:]
+	}
+}
+
+// version returns the encoded layout response with the ETag
+func (l *LayoutVersions) version(etag string) ([]byte, bool) {
+	if l == nil {
+		return nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	body, ok := l.versions[etag]
+	return body, ok
+}
+
+// patchOperation is an operation of a JSON patch (RFC 6902)
+type patchOperation struct {
+	Op    string
+	Path  string
+	Value interface{}
+}
+
+// MarshalJSON encodes the value of all operations but remove, even if null
+func (p patchOperation) MarshalJSON() ([]byte, error) {
+	if p.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{p.Op, p.Path})
+	}
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{p.Op, p.Path, p.Value})
+}
+
+// jsonPatch returns the operations changing the JSON document from into to.
+// Objects are compared by member and arrays of the same length by element;
+// arrays changing length are replaced whole.
+func jsonPatch(path string, from, to interface{}) []patchOperation {
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, ok := f[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		ops := []patchOperation{}
+		for _, k := range keys {
+			p := path + "/" + escapePointer(k)
+			fv, inFrom := f[k]
+			tv, inTo := t[k]
+			switch {
+			case !inTo:
+				ops = append(ops, patchOperation{Op: "remove", Path: p})
+			case !inFrom:
+				ops = append(ops, patchOperation{Op: "add", Path: p, Value: tv})
+			default:
+				ops = append(ops, jsonPatch(p, fv, tv)...)
+			}
+		}
+		return ops
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok || len(t) != len(f) {
+			break
+		}
+		ops := []patchOperation{}
+		for i := range f {
+			ops = append(ops, jsonPatch(fmt.Sprintf("%s/%d", path, i), f[i], t[i])...)
+		}
+		return ops
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return []patchOperation{}
+	}
+	return []patchOperation{{Op: "replace", Path: path, Value: to}}
+}
+
+// escapePointer escapes a member name as a JSON pointer (RFC 6901) token
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+// LayoutsIDDelta retrieves the changes to a layout since the version with
+// the ETag of the If-None-Match header.  If the layout is unchanged 304 is
+// returned.  If the version is known the changes are a JSON patch, otherwise
+// the whole layout is returned.
+func (s *Service) LayoutsIDDelta(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := httprouter.GetParamFromContext(ctx, "id")
+
+	layout, err := s.getLayout(ctx, s.Store.Layouts(ctx).Get, id)
+	if err == chronograf.ErrUpstreamTimeout {
+		layoutsTimeout(w, s.Logger)
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusNotFound, fmt.Sprintf("ID %s not found", id), s.Logger)
+		return
+	}
+
+	res := newLayoutResponse(layout)
+	s.LayoutVersions.record(res)
+	body, etag, err := cacheableJSON(res)
+	if err != nil {
+		unknownErrorWithMessage(w, err, s.Logger)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Vary", "If-None-Match")
+	w.Header().Set("ETag", etag)
+	match := strings.TrimPrefix(strings.TrimSpace(r.Header.Get("If-None-Match")), "W/")
+	if match == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	old, ok := s.LayoutVersions.version(match)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(body); err != nil {
+			s.Logger.Error("Unable to write response: ", err)
+		}
+		return
+	}
+
+	var from, to interface{}
+	if err := json.Unmarshal(old, &from); err != nil {
+		unknownErrorWithMessage(w, err, s.Logger)
+		return
+	}
+	if err := json.Unmarshal(body, &to); err != nil {
+		unknownErrorWithMessage(w, err, s.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json-patch+json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(jsonPatch("", from, to)); err != nil {
+		s.Logger.Error("Unable to write response: ", err)
+	}
+}
@@ -3,15 +3,19 @@ package server_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 
+	"github.com/bouk/httprouter"
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/chronograf"
 	"github.com/influxdata/chronograf/mocks"
 	"github.com/influxdata/chronograf/server"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_Layouts(t *testing.T) {
@@ -184,3 +188,617 @@ func Test_Layouts(t *testing.T) {
 		})
 	}
 }
+
+func Test_Layouts_SourceVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{
+			name:       "Layouts compatible with the source",
+			source:     "1",
+			wantStatus: http.StatusOK,
+			wantIDs:    []string{"cpu", "flux"},
+		},
+		{
+			name:       "Source with unknown version",
+			source:     "2",
+			wantStatus: http.StatusOK,
+			wantIDs:    []string{"cpu", "flux", "legacy"},
+		},
+		{
+			name:       "Missing source",
+			source:     "3",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid source",
+			source:     "influxdb",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := server.Service{
+				Store: &mocks.Store{
+					LayoutsStore: &mocks.LayoutsStore{
+						AllF: func(ctx context.Context) ([]chronograf.Layout, error) {
+							return []chronograf.Layout{
+								{ID: "cpu"},
+								{ID: "flux", Versions: ">=1.7"},
+								{ID: "legacy", Versions: "<1.0"},
+							}, nil
+						},
+					},
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							switch ID {
+							case 1:
+								return chronograf.Source{ID: 1, Version: "1.8.3"}, nil
+							case 2:
+								return chronograf.Source{ID: 2, Version: "Unknown"}, nil
+							}
+							return chronograf.Source{}, chronograf.ErrSourceNotFound
+						},
+					},
+				},
+				Logger: &mocks.TestLogger{},
+			}
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/chronograf/v1/layouts?source="+tt.source, nil)
+			svc.Layouts(rr, req)
+
+			resp := rr.Result()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Layouts() = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Layouts []chronograf.Layout `json:"layouts"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			ids := []string{}
+			for _, l := range body.Layouts {
+				ids = append(ids, l.ID)
+			}
+			if !cmp.Equal(ids, tt.wantIDs) {
+				t.Errorf("Layouts() = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func Test_TelegrafLayouts(t *testing.T) {
+	allLayouts := []chronograf.Layout{
+		{
+			ID:          "cpu",
+			Application: "system",
+			Measurement: "cpu",
+		},
+		{
+			ID:          "docker",
+			Application: "docker",
+			Measurement: "docker",
+		},
+		{
+			ID:          "docker_blkio",
+			Application: "docker",
+			Measurement: "docker_container_blkio",
+		},
+		{
+			ID:          "redis",
+			Application: "redis",
+			Measurement: "redis",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		config      string
+		wantInputs  []string
+		wantLayouts []string
+	}{
+		{
+			name: "enabled plugins",
+			config: `
+[agent]
+  interval = "10s"
+
+[[inputs.cpu]]
+  percpu = true
+
+# [[inputs.redis]]
+#   servers = ["tcp://localhost:6379"]
+
+[[inputs.docker]]
+  endpoint = "unix:///var/run/docker.sock"
+[[inputs.docker.tagdrop]]
+  container_name = ["telegraf"]
+
+[[inputs.exec]]
+  commands = ["/tmp/test.sh"]
+`,
+			wantInputs:  []string{"cpu", "docker", "exec"},
+			wantLayouts: []string{"cpu", "docker", "docker_blkio"},
+		},
+		{
+			name:        "no plugins",
+			config:      `[agent]`,
+			wantInputs:  []string{},
+			wantLayouts: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			svc := server.Service{
+				Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+					AllF: func(ctx context.Context) ([]chronograf.Layout, error) {
+						return allLayouts, nil
+					},
+				},
+				},
+				Logger: &mocks.TestLogger{},
+			}
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/chronograf/v1/layouts/telegraf", strings.NewReader(test.config))
+			svc.TelegrafLayouts(rr, req)
+
+			respFrame := struct {
+				Inputs  []string `json:"inputs"`
+				Layouts []struct {
+					ID string `json:"id"`
+				} `json:"layouts"`
+			}{}
+			if err := json.NewDecoder(rr.Result().Body).Decode(&respFrame); err != nil {
+				t.Fatalf("%q - Error unmarshaling JSON: err: %s", test.name, err.Error())
+			}
+
+			if !cmp.Equal(test.wantInputs, respFrame.Inputs) {
+				t.Errorf("%q - Expected inputs to be equal: diff:\n\t%s", test.name, cmp.Diff(test.wantInputs, respFrame.Inputs))
+			}
+			ids := []string{}
+			for _, l := range respFrame.Layouts {
+				ids = append(ids, l.ID)
+			}
+			if !cmp.Equal(test.wantLayouts, ids) {
+				t.Errorf("%q - Expected layouts to be equal: diff:\n\t%s", test.name, cmp.Diff(test.wantLayouts, ids))
+			}
+		})
+	}
+}
+
+func Test_LayoutsID_Cache(t *testing.T) {
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{
+					ID:          id,
+					Application: "influxdb",
+					Measurement: "influxdb",
+				}, nil
+			},
+		},
+		},
+		Logger: &mocks.TestLogger{},
+	}
+
+	get := func(ifNoneMatch string) *http.Response {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chronograf/v1/layouts/influxdb", nil)
+		req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+			{
+				Key:   "id",
+				Value: "influxdb",
+			},
+		}))
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		svc.LayoutsID(rr, req)
+		return rr.Result()
+	}
+
+	first := get("")
+	etag := first.Header.Get("ETag")
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("LayoutsID() status = %d, want %d", first.StatusCode, http.StatusOK)
+	}
+	if !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("LayoutsID() ETag = %q, want a strong ETag", etag)
+	}
+	if cc := first.Header.Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("LayoutsID() Cache-Control = %q", cc)
+	}
+	if again := get("").Header.Get("ETag"); again != etag {
+		t.Errorf("LayoutsID() ETag = %q, want stable ETag %q", again, etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{
+			name:        "matching ETag",
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "weak form of matching ETag in list",
+			ifNoneMatch: `"stale", W/` + etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "any ETag",
+			ifNoneMatch: "*",
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "stale ETag",
+			ifNoneMatch: `"stale"`,
+			wantStatus:  http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		resp := get(tt.ifNoneMatch)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%q. LayoutsID() status = %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if got := resp.Header.Get("ETag"); got != etag {
+			t.Errorf("%q. LayoutsID() ETag = %q, want %q", tt.name, got, etag)
+		}
+	}
+}
+
+func Test_LayoutsID_Default(t *testing.T) {
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				if id != "kiosk" {
+					return chronograf.Layout{}, chronograf.ErrLayoutNotFound
+				}
+				return chronograf.Layout{
+					ID:          id,
+					Application: "influxdb",
+					Measurement: "influxdb",
+				}, nil
+			},
+		},
+		},
+		Logger: &mocks.TestLogger{},
+	}
+
+	get := func(id string) *http.Response {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chronograf/v1/layouts/"+id, nil)
+		req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+			{
+				Key:   "id",
+				Value: id,
+			},
+		}))
+		svc.LayoutsID(rr, req)
+		return rr.Result()
+	}
+
+	if resp := get("missing"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("LayoutsID() without a default layout status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	svc.DefaultLayout = "kiosk"
+	resp := get("missing")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("LayoutsID() with a default layout status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var layout struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&layout); err != nil {
+		t.Fatal(err)
+	}
+	if layout.ID != "kiosk" {
+		t.Errorf("LayoutsID() served layout %q, want the default layout kiosk", layout.ID)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("LayoutsID() of the default layout Cache-Control = %q, want no-store", cc)
+	}
+
+	if cc := get("kiosk").Header.Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("LayoutsID() of an existing layout Cache-Control = %q, want private, no-cache", cc)
+	}
+}
+
+func Test_LayoutsID_Timeout(t *testing.T) {
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{}, chronograf.ErrUpstreamTimeout
+			},
+		},
+		},
+		Logger: &mocks.TestLogger{},
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/chronograf/v1/layouts/influxdb", nil)
+	req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+		{
+			Key:   "id",
+			Value: "influxdb",
+		},
+	}))
+	svc.LayoutsID(rr, req)
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Errorf("LayoutsID() of a timed out store status = %d, want %d", rr.Code, http.StatusGatewayTimeout)
+	}
+}
+
+func Test_LayoutsID_CellPages(t *testing.T) {
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{
+					ID:          id,
+					Application: "influxdb",
+					Measurement: "influxdb",
+					Cells: []chronograf.Cell{
+						{I: "1", Name: "writes", Axes: map[string]chronograf.Axis{}},
+						{I: "2", Name: "queries", Axes: map[string]chronograf.Axis{}},
+						{I: "3", Name: "series", Axes: map[string]chronograf.Axis{}},
+					},
+				}, nil
+			},
+		},
+		},
+		Logger: &mocks.TestLogger{},
+	}
+
+	type page struct {
+		ID          string            `json:"id"`
+		Application string            `json:"app"`
+		Cells       []chronograf.Cell `json:"cells"`
+		CellsTotal  int               `json:"cellsTotal"`
+		CellOffset  int               `json:"cellOffset"`
+		CellLimit   int               `json:"cellLimit"`
+		Next        string            `json:"next"`
+	}
+	get := func(query string) (int, page) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chronograf/v1/layouts/influxdb"+query, nil)
+		req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+			{
+				Key:   "id",
+				Value: "influxdb",
+			},
+		}))
+		svc.LayoutsID(rr, req)
+		var p page
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rr.Code, p
+	}
+
+	code, first := get("?cellLimit=2")
+	if code != http.StatusOK {
+		t.Fatalf("LayoutsID() first page status = %d, want %d", code, http.StatusOK)
+	}
+	if first.Application != "influxdb" || len(first.Cells) != 2 || first.CellsTotal != 3 {
+		t.Errorf("LayoutsID() first page = %+v, want the metadata and 2 of 3 cells", first)
+	}
+	if first.Next != "/chronograf/v1/layouts/influxdb?cellLimit=2&cellOffset=2" {
+		t.Errorf("LayoutsID() first page next = %q", first.Next)
+	}
+
+	_, last := get("?cellLimit=2&cellOffset=2")
+	if last.Application != "" || last.ID != "influxdb" {
+		t.Errorf("LayoutsID() later page has metadata %+v, want only the ID", last)
+	}
+	if len(last.Cells) != 1 || last.Cells[0].I != "3" || last.CellOffset != 2 || last.Next != "" {
+		t.Errorf("LayoutsID() last page = %+v, want cell 3 without a next page", last)
+	}
+
+	if code, _ := get("?cellLimit=0"); code != http.StatusUnprocessableEntity {
+		t.Errorf("LayoutsID() with cellLimit=0 status = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+}
+
+func Test_LayoutsIDDelta(t *testing.T) {
+	measurement := "influxdb"
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{
+					ID:          id,
+					Application: "influxdb",
+					Measurement: measurement,
+				}, nil
+			},
+		},
+		},
+		Logger:         &mocks.TestLogger{},
+		LayoutVersions: server.NewLayoutVersions(10),
+	}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chronograf/v1/layouts/influxdb/delta", nil)
+		req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+			{
+				Key:   "id",
+				Value: "influxdb",
+			},
+		}))
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		svc.LayoutsIDDelta(rr, req)
+		return rr
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("LayoutsIDDelta() without ETag = %d %s, want whole layout", first.Code, first.Header().Get("Content-Type"))
+	}
+	if got := get(etag); got.Code != http.StatusNotModified {
+		t.Errorf("LayoutsIDDelta() of current ETag status = %d, want %d", got.Code, http.StatusNotModified)
+	}
+
+	measurement = "cpu"
+	patch := get(etag)
+	if patch.Code != http.StatusOK || patch.Header().Get("Content-Type") != "application/json-patch+json" {
+		t.Fatalf("LayoutsIDDelta() of old ETag = %d %s, want JSON patch", patch.Code, patch.Header().Get("Content-Type"))
+	}
+	want := `[{"op":"replace","path":"/measurement","value":"cpu"}]
+`
+	if got := patch.Body.String(); got != want {
+		t.Errorf("LayoutsIDDelta() patch = %s, want %s", got, want)
+	}
+	if got := patch.Header().Get("ETag"); got == etag {
+		t.Errorf("LayoutsIDDelta() ETag of changed layout = %s, want new ETag", got)
+	}
+
+	if got := get(`"unknown"`); got.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("LayoutsIDDelta() of unknown ETag Content-Type = %s, want whole layout", got.Header().Get("Content-Type"))
+	}
+}
+
+func Test_LayoutsID_Range(t *testing.T) {
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{
+					ID:          id,
+					Application: "influxdb",
+					Measurement: "influxdb",
+				}, nil
+			},
+		},
+		},
+		Logger: &mocks.TestLogger{},
+	}
+
+	get := func(header map[string]string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chronograf/v1/layouts/influxdb", nil)
+		req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+			{
+				Key:   "id",
+				Value: "influxdb",
+			},
+		}))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		svc.LayoutsID(rr, req)
+		return rr
+	}
+
+	full := get(nil)
+	body := full.Body.String()
+	etag := full.Header().Get("ETag")
+
+	partial := get(map[string]string{"Range": "bytes=10-"})
+	if partial.Code != http.StatusPartialContent {
+		t.Fatalf("LayoutsID() with Range status = %d, want %d", partial.Code, http.StatusPartialContent)
+	}
+	if got, want := partial.Header().Get("Content-Range"), fmt.Sprintf("bytes 10-%d/%d", len(body)-1, len(body)); got != want {
+		t.Errorf("LayoutsID() Content-Range = %q, want %q", got, want)
+	}
+	if got := partial.Body.String(); got != body[10:] {
+		t.Errorf("LayoutsID() partial body = %q, want %q", got, body[10:])
+	}
+
+	resumed := get(map[string]string{"Range": "bytes=10-", "If-Range": etag})
+	if resumed.Code != http.StatusPartialContent {
+		t.Errorf("LayoutsID() with matching If-Range status = %d, want %d", resumed.Code, http.StatusPartialContent)
+	}
+	changed := get(map[string]string{"Range": "bytes=10-", "If-Range": `"stale"`})
+	if changed.Code != http.StatusOK || changed.Body.String() != body {
+		t.Errorf("LayoutsID() with stale If-Range status = %d, want whole layout", changed.Code)
+	}
+}
+
+func Test_LayoutsID_YAML(t *testing.T) {
+	svc := server.Service{
+		Store: &mocks.Store{LayoutsStore: &mocks.LayoutsStore{
+			GetF: func(ctx context.Context, id string) (chronograf.Layout, error) {
+				return chronograf.Layout{
+					ID:          id,
+					Application: "influxdb",
+					Measurement: "influxdb",
+					Cells: []chronograf.Cell{
+						{
+							I:    "writes",
+							Name: "Write Points",
+							W:    4,
+							H:    4,
+							Queries: []chronograf.Query{
+								{
+									Command: "SELECT non_negative_derivative(max(\"pointReq\")) FROM \"write\"",
+									Label:   "points/s",
+								},
+							},
+						},
+					},
+				}, nil
+			},
+		},
+		},
+		Logger: &mocks.TestLogger{},
+	}
+
+	get := func(accept string) (*http.Response, []byte) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chronograf/v1/layouts/influxdb", nil)
+		req = req.WithContext(httprouter.WithParams(req.Context(), httprouter.Params{
+			{
+				Key:   "id",
+				Value: "influxdb",
+			},
+		}))
+		req.Header.Set("Accept", accept)
+		svc.LayoutsID(rr, req)
+		return rr.Result(), rr.Body.Bytes()
+	}
+
+	resp, body := get("application/yaml")
+	if ct := resp.Header.Get("Content-Type"); ct != server.YAMLType {
+		t.Fatalf("LayoutsID() Content-Type = %q, want %q", ct, server.YAMLType)
+	}
+	if vary := resp.Header.Get("Vary"); vary != "Accept" {
+		t.Errorf("LayoutsID() Vary = %q, want Accept", vary)
+	}
+	converted, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		t.Fatalf("LayoutsID() YAML is invalid: %v\n%s", err, body)
+	}
+
+	resp, jsonBody := get("application/json")
+	if ct := resp.Header.Get("Content-Type"); ct != server.JSONType {
+		t.Fatalf("LayoutsID() Content-Type = %q, want %q", ct, server.JSONType)
+	}
+	var fromYAML, fromJSON interface{}
+	if err := json.Unmarshal(converted, &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(jsonBody, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fromJSON, fromYAML); diff != "" {
+		t.Errorf("LayoutsID() YAML converted to JSON differs from JSON (-json +yaml):\n%s", diff)
+	}
+}
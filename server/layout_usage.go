@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// layoutAccessBuffer is the number of layout accesses waiting to be
+// recorded before further accesses are dropped
+const layoutAccessBuffer = 1024
+
+// LayoutAccessSink receives the accesses of layouts, e.g. to find rarely
+// used layouts
+type LayoutAccessSink interface {
+	LayoutAccessed(id string, at time.Time)
+}
+
+// LayoutAccess records layout accesses into a sink in the background so
+// serving a layout never waits on the sink.  Accesses are dropped while
+// the sink falls behind.
+type LayoutAccess struct {
+	Sink     LayoutAccessSink
+	accesses chan layoutAccessed
+}
+
+type layoutAccessed struct {
+	id string
+	at time.Time
+}
+
+// NewLayoutAccess records layout accesses into sink until ctx is done
+func NewLayoutAccess(ctx context.Context, sink LayoutAccessSink) *LayoutAccess {
+	a := &LayoutAccess{
+		Sink:     sink,
+		accesses: make(chan layoutAccessed, layoutAccessBuffer),
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case access := <-a.accesses:
+				a.Sink.LayoutAccessed(access.id, access.at)
+			}
+		}
+	}()
+	return a
+}
+
+// record queues an access of the layout with id without blocking
+func (a *LayoutAccess) record(id string, at time.Time) {
+	if a == nil {
+		return
+	}
+	select {
+	case a.accesses <- layoutAccessed{id, at}:
+	default:
+	}
+}
+
+// LayoutAccessCounts is a LayoutAccessSink counting the accesses of each
+// layout in memory.  Counts start over when the server restarts.
+type LayoutAccessCounts struct {
+	mu     sync.Mutex
+	counts map[string]*layoutAccessCount
+}
+
+// NewLayoutAccessCounts creates empty layout access counts
+func NewLayoutAccessCounts() *LayoutAccessCounts {
+	return &LayoutAccessCounts{
+		counts: map[string]*layoutAccessCount{},
+	}
+}
+
+type layoutAccessCount struct {
+	ID           string     `json:"id"`
+	Count        int        `json:"count"`
+	LastAccessed *time.Time `json:"lastAccessed"` // LastAccessed is nil if the layout was never accessed
+}
+
+// LayoutAccessed counts an access of the layout with id at time at
+func (c *LayoutAccessCounts) LayoutAccessed(id string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count, ok := c.counts[id]
+	if !ok {
+		count = &layoutAccessCount{ID: id}
+		c.counts[id] = count
+	}
+	count.Count++
+	if count.LastAccessed == nil || at.After(*count.LastAccessed) {
+		count.LastAccessed = &at
+	}
+}
+
+// count returns the accesses of the layout with id
+func (c *LayoutAccessCounts) count(id string) layoutAccessCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if count, ok := c.counts[id]; ok {
+		return *count
+	}
+	return layoutAccessCount{ID: id}
+}
+
+// LayoutUsage lists the number of accesses of every layout, least accessed
+// first, so rarely used layouts can be found.  Layouts that were never
+// accessed have a count of zero.
+func (s *Service) LayoutUsage(w http.ResponseWriter, r *http.Request) {
+	var counts *LayoutAccessCounts
+	if s.LayoutAccess != nil {
+		counts, _ = s.LayoutAccess.Sink.(*LayoutAccessCounts)
+	}
+	if counts == nil {
+		Error(w, http.StatusNotFound, "Layout access counts are not tracked", s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	layouts, err := s.Store.Layouts(ctx).All(ctx)
+	if err == chronograf.ErrUpstreamTimeout {
+		layoutsTimeout(w, s.Logger)
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Error loading layouts", s.Logger)
+		return
+	}
+
+	res := struct {
+		Layouts []layoutAccessCount `json:"layouts"`
+	}{
+		Layouts: []layoutAccessCount{},
+	}
+	seen := map[string]bool{}
+	for _, layout := range layouts {
+		if seen[layout.ID] {
+			continue
+		}
+		seen[layout.ID] = true
+		res.Layouts = append(res.Layouts, counts.count(layout.ID))
+	}
+	sort.Slice(res.Layouts, func(i, j int) bool {
+		if res.Layouts[i].Count != res.Layouts[j].Count {
+			return res.Layouts[i].Count < res.Layouts[j].Count
+		}
+		return res.Layouts[i].ID < res.Layouts[j].ID
+	})
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
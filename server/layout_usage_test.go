@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+type layoutAccessFunc func(id string, at time.Time)
+
+func (f layoutAccessFunc) LayoutAccessed(id string, at time.Time) {
+	f(id, at)
+}
+
+func TestLayoutAccess_record(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accessed := make(chan string, 1)
+	a := NewLayoutAccess(ctx, layoutAccessFunc(func(id string, at time.Time) {
+		accessed <- id
+	}))
+	a.record("cpu", time.Now())
+	select {
+	case id := <-accessed:
+		if id != "cpu" {
+			t.Errorf("LayoutAccess.record() sank %s, want cpu", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("LayoutAccess.record() was never sunk")
+	}
+
+	// Tracking is disabled without a LayoutAccess
+	var disabled *LayoutAccess
+	disabled.record("cpu", time.Now())
+}
+
+func TestService_LayoutUsage(t *testing.T) {
+	counts := NewLayoutAccessCounts()
+	counts.LayoutAccessed("cpu", time.Date(1985, time.October, 26, 1, 21, 0, 0, time.UTC))
+	counts.LayoutAccessed("cpu", time.Date(1955, time.November, 5, 6, 0, 0, 0, time.UTC))
+	counts.LayoutAccessed("mem", time.Date(2015, time.October, 21, 16, 29, 0, 0, time.UTC))
+	counts.LayoutAccessed("retired", time.Date(2015, time.October, 21, 16, 29, 0, 0, time.UTC))
+
+	tests := []struct {
+		name       string
+		access     *LayoutAccess
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Least accessed layouts first",
+			access:     &LayoutAccess{Sink: counts},
+			wantStatus: http.StatusOK,
+			wantBody: `{"layouts":[{"id":"disk","count":0,"lastAccessed":null},{"id":"mem","count":1,"lastAccessed":"2015-10-21T16:29:00Z"},{"id":"cpu","count":2,"lastAccessed":"1985-10-26T01:21:00Z"}]}
+`,
+		},
+		{
+			name:       "Tracking disabled",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":404,"message":"Layout access counts are not tracked"}`,
+		},
+	}
+	for _, tt := range tests {
+		s := &Service{
+			Store: &mocks.Store{
+				LayoutsStore: &mocks.LayoutsStore{
+					AllF: func(ctx context.Context) ([]chronograf.Layout, error) {
+						return []chronograf.Layout{{ID: "cpu"}, {ID: "mem"}, {ID: "disk"}, {ID: "cpu"}}, nil
+					},
+				},
+			},
+			LayoutAccess: tt.access,
+			Logger:       log.New(log.DebugLevel),
+		}
+		w := httptest.NewRecorder()
+		s.LayoutUsage(w, httptest.NewRequest("GET", "http://server.local/chronograf/v1/layouts-usage", nil))
+
+		resp := w.Result()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%q. LayoutUsage() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("%q. LayoutUsage() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+	"sigs.k8s.io/yaml"
+)
+
+// YAMLType is the mimetype of YAML responses
+const YAMLType = "application/yaml"
+
+// wantsYAML checks if the client accepts YAML responses
+func wantsYAML(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(accept)
+		if err == nil && (mediaType == YAMLType || mediaType == "application/x-yaml") {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCacheableYAML writes v as YAML like encodeCacheableJSON.  Fields are
+// named by their JSON tags, so converting the YAML to JSON gives the JSON
+// response.
+func encodeCacheableYAML(w http.ResponseWriter, r *http.Request, v interface{}, logger chronograf.Logger) {
+	body, err := yaml.Marshal(v)
+	if err != nil {
+		unknownErrorWithMessage(w, err, logger)
+		return
+	}
+	serveCacheable(w, r, YAMLType, body, strongETag(body))
+}
@@ -179,6 +179,7 @@ func NewMux(opts MuxOpts, service Service) http.Handler {
 	router.PATCH("/chronograf/v1/sources/:id", EnsureEditor(service.UpdateSource))
 	router.DELETE("/chronograf/v1/sources/:id", EnsureEditor(service.RemoveSource))
 	router.GET("/chronograf/v1/sources/:id/health", EnsureViewer(service.SourceHealth))
+	router.GET("/chronograf/v1/sources/:id/layouts/uncovered", EnsureViewer(service.SourceUncoveredMeasurements))
 
 	// Flux
 	router.GET("/chronograf/v1/flux", EnsureViewer(service.Flux))
@@ -214,6 +215,13 @@ func NewMux(opts MuxOpts, service Service) http.Handler {
 
 	// All possible permissions for users in this source
 	router.GET("/chronograf/v1/sources/:id/permissions", EnsureViewer(service.Permissions))
+	router.GET("/chronograf/v1/sources/:id/permissions/roles", EnsureViewer(service.SearchSourceRolePermissions))
+	router.GET("/chronograf/v1/sources/:id/permissions/coverage", EnsureViewer(service.SourceRoleCoverage))
+	router.GET("/chronograf/v1/sources/:id/permissions/gaps", EnsureViewer(service.SourcePermissionGaps))
+	router.GET("/chronograf/v1/sources/:id/permissions/distinct", EnsureViewer(service.SourceDistinctPermissions))
+	router.GET("/chronograf/v1/sources/:id/permissions/dangling", EnsureViewer(service.SourceDanglingPermissions))
+	router.GET("/chronograf/v1/sources/:id/permissions/vocabulary", EnsureViewer(service.SourcePermissionVocabulary))
+	router.POST("/chronograf/v1/sources/:id/permissions/simulate", EnsureViewer(prettyJSON(service.SimulateAuthorization)))
 
 	// Users associated with the data source
 	router.GET("/chronograf/v1/sources/:id/users", EnsureAdmin(service.SourceUsers))
@@ -222,14 +230,46 @@ func NewMux(opts MuxOpts, service Service) http.Handler {
 	router.GET("/chronograf/v1/sources/:id/users/:uid", EnsureAdmin(service.SourceUserID))
 	router.DELETE("/chronograf/v1/sources/:id/users/:uid", EnsureAdmin(service.RemoveSourceUser))
 	router.PATCH("/chronograf/v1/sources/:id/users/:uid", EnsureAdmin(service.UpdateSourceUser))
+	router.POST("/chronograf/v1/sources/:id/users/:uid/roles/reconcile", EnsureAdmin(service.ReconcileSourceUserRoles))
+	router.GET("/chronograf/v1/sources/:id/users/:uid/permissions", EnsureAdmin(service.SourceUserEffectivePermissions))
 
 	// Roles associated with the data source
-	router.GET("/chronograf/v1/sources/:id/roles", EnsureViewer(service.SourceRoles))
-	router.POST("/chronograf/v1/sources/:id/roles", EnsureEditor(service.NewSourceRole))
-
-	router.GET("/chronograf/v1/sources/:id/roles/:rid", EnsureViewer(service.SourceRoleID))
-	router.DELETE("/chronograf/v1/sources/:id/roles/:rid", EnsureEditor(service.RemoveSourceRole))
-	router.PATCH("/chronograf/v1/sources/:id/roles/:rid", EnsureEditor(service.UpdateSourceRole))
+	router.GET("/chronograf/v1/sources/:id/roles", EnsureViewer(prettyJSON(service.SourceRoles)))
+	router.POST("/chronograf/v1/sources/:id/roles", EnsureEditor(prettyJSON(service.idempotent(service.NewSourceRole))))
+	router.POST("/chronograf/v1/sources/:id/roles-import", EnsureEditor(service.ImportSourceRoles))
+	router.POST("/chronograf/v1/sources/:id/roles-reconcile", EnsureEditor(prettyJSON(service.ReconcileSourceRoles)))
+	router.POST("/chronograf/v1/sources/:id/roles-copy", EnsureEditor(prettyJSON(service.CopySourceRoles)))
+	router.POST("/chronograf/v1/sources/:id/roles-label", EnsureEditor(prettyJSON(service.LabelSourceRoles)))
+	router.POST("/chronograf/v1/sources/:id/roles-rename-scope", EnsureEditor(prettyJSON(service.RenameSourceRoleScope)))
+	router.GET("/chronograf/v1/sources/:id/roles-duplicates", EnsureViewer(prettyJSON(service.DuplicateSourceRoles)))
+	router.POST("/chronograf/v1/sources/:id/roles-duplicates", EnsureEditor(prettyJSON(service.DuplicateSourceRoles)))
+	router.GET("/chronograf/v1/sources/:id/roles-opa", EnsureViewer(prettyJSON(service.SourceRolesOPA)))
+	router.GET("/chronograf/v1/sources/:id/roles-users", EnsureViewer(service.SourceRoleUsers))
+	router.GET("/chronograf/v1/sources/:id/roles-diff", EnsureViewer(prettyJSON(service.DiffSourceRoles)))
+	router.GET("/chronograf/v1/sources/:id/roles-events", EnsureViewer(service.SourceRoleEvents))
+	router.POST("/chronograf/v1/sources/:id/roles-inheritance", EnsureViewer(prettyJSON(service.CheckSourceRoleInheritance)))
+	router.POST("/chronograf/v1/sources/:id/roles-from-queries", EnsureViewer(prettyJSON(service.SuggestSourceRoleFromQueries)))
+
+	router.GET("/chronograf/v1/sources/:id/roles/:rid", EnsureViewer(prettyJSON(service.SourceRoleID)))
+	router.DELETE("/chronograf/v1/sources/:id/roles/:rid", EnsureEditor(prettyJSON(service.RemoveSourceRole)))
+	router.PATCH("/chronograf/v1/sources/:id/roles/:rid", EnsureEditor(prettyJSON(service.idempotent(service.UpdateSourceRole))))
+	router.GET("/chronograf/v1/sources/:id/roles/:rid/preview-remove", EnsureViewer(prettyJSON(service.PreviewRemoveSourceRole)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/preview-update", EnsureViewer(prettyJSON(service.PreviewSourceRoleUpdate)))
+	router.GET("/chronograf/v1/sources/:id/roles/:rid/availability", EnsureViewer(prettyJSON(service.CheckSourceRoleName)))
+	router.GET("/chronograf/v1/sources/:id/roles/:rid/lint", EnsureViewer(prettyJSON(service.LintSourceRole)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/lint", EnsureViewer(prettyJSON(service.LintSourceRole)))
+	router.GET("/chronograf/v1/sources/:id/roles/:rid/duties", EnsureViewer(prettyJSON(service.CheckSourceRoleDuties)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/duties", EnsureViewer(prettyJSON(service.CheckSourceRoleDuties)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/least-privilege", EnsureViewer(prettyJSON(service.SuggestSourceRolePermissions)))
+	router.GET("/chronograf/v1/sources/:id/roles/:rid/template", EnsureViewer(prettyJSON(service.ExportSourceRoleTemplate)))
+	router.GET("/chronograf/v1/sources/:id/roles/:rid/summary", EnsureViewer(prettyJSON(service.SummarizeSourceRolePermissions)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/approve", EnsureAdmin(prettyJSON(service.ApproveSourceRole)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/reject", EnsureAdmin(prettyJSON(service.RejectSourceRole)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/tokens", EnsureAdmin(prettyJSON(service.NewSourceRoleToken)))
+	router.DELETE("/chronograf/v1/sources/:id/roles/:rid/tokens/:tid", EnsureAdmin(prettyJSON(service.RemoveSourceRoleToken)))
+	router.POST("/chronograf/v1/sources/:id/roles/:rid/grants", EnsureAdmin(prettyJSON(service.NewTemporaryGrant)))
+	router.POST("/chronograf/v1/role-tokens/introspect", EnsureViewer(prettyJSON(service.IntrospectRoleToken)))
+	router.POST("/chronograf/v1/permissions/validate", EnsureViewer(prettyJSON(service.ValidatePermissions)))
 
 	// Services are resources that chronograf proxies to
 	router.GET("/chronograf/v1/sources/:id/services", EnsureViewer(service.Services))
@@ -270,6 +310,11 @@ func NewMux(opts MuxOpts, service Service) http.Handler {
 	// Layouts
 	router.GET("/chronograf/v1/layouts", EnsureViewer(service.Layouts))
 	router.GET("/chronograf/v1/layouts/:id", EnsureViewer(service.LayoutsID))
+	router.GET("/chronograf/v1/layouts/:id/delta", EnsureViewer(service.LayoutsIDDelta))
+	router.GET("/chronograf/v1/layouts-usage", EnsureAdmin(service.LayoutUsage))
+	router.GET("/chronograf/v1/layouts-export", EnsureViewer(service.ExportLayouts))
+	router.POST("/chronograf/v1/layouts-import", EnsureSuperAdmin(service.RestoreLayouts))
+	router.POST("/chronograf/v1/layouts/telegraf", EnsureViewer(service.TelegrafLayouts))
 
 	// Protoboards
 	router.GET("/chronograf/v1/protoboards", EnsureViewer(service.Protoboards))
@@ -457,6 +502,24 @@ func Error(w http.ResponseWriter, code int, msg string, logger chronograf.Logger
 }
 
 func invalidData(w http.ResponseWriter, err error, logger chronograf.Logger) {
+	if errs, ok := err.(validationErrors); ok {
+		logger.
+			WithField("component", "server").
+			WithField("http_status ", http.StatusUnprocessableEntity).
+			Error("Error message ", errs.describe())
+		e := struct {
+			ErrorMessage
+			Errors validationErrors `json:"errors"`
+		}{
+			ErrorMessage: ErrorMessage{
+				Code:    http.StatusUnprocessableEntity,
+				Message: errs.Error(),
+			},
+			Errors: errs,
+		}
+		encodeJSON(w, http.StatusUnprocessableEntity, e, logger)
+		return
+	}
 	Error(w, http.StatusUnprocessableEntity, fmt.Sprintf("%v", err), logger)
 }
 
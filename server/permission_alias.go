@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// NewScopeAliases parses alias:database pairs into the database each alias
+// names
+func NewScopeAliases(pairs []string) (map[string]string, error) {
+	aliases := map[string]string{}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("Scope alias %q must be alias:database", pair)
+		}
+		if db, ok := aliases[parts[0]]; ok && db != parts[1] {
+			return nil, fmt.Errorf("Scope alias %s names both %s and %s", parts[0], db, parts[1])
+		}
+		aliases[parts[0]] = parts[1]
+	}
+	return aliases, nil
+}
+
+// expandScopeAliases replaces the alias of each aliased permission with the
+// database it names.  Aliases are expanded before permissions are validated
+// so the databases they name are checked like any other.
+func (s *Service) expandScopeAliases(perms chronograf.Permissions) error {
+	var errs validationErrors
+	for i := range perms {
+		perm := &perms[i]
+		if perm.Alias == "" {
+			continue
+		}
+
+		field := fmt.Sprintf("permissions[%d].alias", i)
+		if perm.Scope != chronograf.DBScope || perm.Name != "" {
+			errs.add(field, "Aliased permission must be database scoped without a name")
+			continue
+		}
+		db, ok := s.ScopeAliases[perm.Alias]
+		if !ok {
+			errs.add(field, "Unknown scope alias %s", perm.Alias)
+			continue
+		}
+		perm.Name = db
+		perm.Alias = ""
+	}
+	return errs.err()
+}
+
+// withScopeAliases collapses the databases of the role's permissions back
+// to their aliases for clients asking with aliases=true.  A database with
+// several aliases is collapsed to the least of them.
+func (s *Service) withScopeAliases(r *http.Request, rr *sourceRoleResponse) {
+	if len(s.ScopeAliases) == 0 {
+		return
+	}
+	if collapse, _ := strconv.ParseBool(r.URL.Query().Get("aliases")); !collapse {
+		return
+	}
+
+	aliases := map[string]string{}
+	for alias, db := range s.ScopeAliases {
+		if least, ok := aliases[db]; !ok || alias < least {
+			aliases[db] = alias
+		}
+	}
+
+	// The permissions may be shared with the stored role so are copied
+	perms := make(chronograf.Permissions, len(rr.Permissions))
+	for i, perm := range rr.Permissions {
+		if alias, ok := aliases[perm.Name]; ok && perm.Scope == chronograf.DBScope {
+			perm.Alias = alias
+			perm.Name = ""
+		}
+		perms[i] = perm
+	}
+	rr.Permissions = perms
+}
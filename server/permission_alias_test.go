@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestNewScopeAliases(t *testing.T) {
+	got, err := NewScopeAliases([]string{"prod-metrics:telegraf_prod", "billing:payroll", "billing:payroll"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"prod-metrics": "telegraf_prod",
+		"billing":      "payroll",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewScopeAliases() = %v, want %v", got, want)
+	}
+
+	for _, pairs := range [][]string{{"billing"}, {":payroll"}, {"billing:"}, {"billing:payroll", "billing:hr"}} {
+		if _, err := NewScopeAliases(pairs); err == nil {
+			t.Errorf("NewScopeAliases(%q) expected error", pairs)
+		}
+	}
+}
+
+func TestService_NewSourceRole_ScopeAlias(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantPerms chronograf.Permissions
+		wantBody  string
+	}{
+		{
+			name: "Expands alias",
+			body: `{"name": "operators", "permissions": [{"scope": "database", "alias": "prod-metrics", "allowed": ["READ"]}]}`,
+			wantPerms: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "telegraf_prod",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			name: "Unknown alias",
+			body: `{"name": "operators", "permissions": [{"scope": "database", "alias": "staging-metrics", "allowed": ["READ"]}]}`,
+			wantBody: `{"code":422,"message":"Unknown scope alias staging-metrics","errors":[{"field":"permissions[0].alias","message":"Unknown scope alias staging-metrics"}]}
+`,
+		},
+		{
+			name: "Alias of a forbidden database",
+			body: `{"name": "operators", "permissions": [{"scope": "database", "alias": "internal", "allowed": ["READ"]}]}`,
+			wantBody: `{"code":422,"message":"Database _internal may not be granted to roles","errors":[{"field":"permissions[0].name","message":"Database _internal may not be granted to roles"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added chronograf.Permissions
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return nil, fmt.Errorf("role %s not found", name)
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								added = role.Permissions
+								return role, nil
+							},
+						}, nil
+					},
+				},
+				Logger:          log.New(log.DebugLevel),
+				ForbiddenScopes: []string{"_internal"},
+				ScopeAliases: map[string]string{
+					"prod-metrics": "telegraf_prod",
+					"internal":     "_internal",
+				},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.NewSourceRole(w, r)
+
+			if tt.wantBody != "" {
+				body, _ := ioutil.ReadAll(w.Result().Body)
+				if string(body) != tt.wantBody {
+					t.Errorf("NewSourceRole() = %s, want %s", body, tt.wantBody)
+				}
+				return
+			}
+			if !reflect.DeepEqual(added, tt.wantPerms) {
+				t.Errorf("NewSourceRole() added permissions %v, want %v", added, tt.wantPerms)
+			}
+		})
+	}
+}
+
+func TestService_withScopeAliases(t *testing.T) {
+	h := &Service{
+		ScopeAliases: map[string]string{
+			"prod-metrics": "telegraf_prod",
+			"metrics":      "telegraf_prod",
+		},
+	}
+	role := chronograf.Role{
+		Name: "operators",
+		Permissions: chronograf.Permissions{
+			{
+				Scope:   chronograf.DBScope,
+				Name:    "telegraf_prod",
+				Allowed: chronograf.Allowances{"READ"},
+			},
+			{
+				Scope:   chronograf.DBScope,
+				Name:    "telegraf_dev",
+				Allowed: chronograf.Allowances{"WRITE"},
+			},
+		},
+	}
+
+	rr := newSourceRoleResponse(1, &role)
+	h.withScopeAliases(httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/operators?aliases=true", nil), &rr)
+	want := chronograf.Permissions{
+		{
+			Scope:   chronograf.DBScope,
+			Alias:   "metrics",
+			Allowed: chronograf.Allowances{"READ"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf_dev",
+			Allowed: chronograf.Allowances{"WRITE"},
+		},
+	}
+	if !reflect.DeepEqual(rr.Permissions, want) {
+		t.Errorf("withScopeAliases() = %v, want %v", rr.Permissions, want)
+	}
+	if role.Permissions[0].Name != "telegraf_prod" {
+		t.Errorf("withScopeAliases() changed the permissions of the role")
+	}
+
+	rr = newSourceRoleResponse(1, &role)
+	h.withScopeAliases(httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/operators", nil), &rr)
+	if !reflect.DeepEqual(rr.Permissions, role.Permissions) {
+		t.Errorf("withScopeAliases() without aliases=true = %v, want %v", rr.Permissions, role.Permissions)
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// EffectivePermissionsCache keeps the effective permissions of the users
+// of sources for a TTL.  Entries are dropped when a role that contains, or
+// comes to contain, the user changes through Chronograf.  Roles changed
+// elsewhere are seen once the entry expires.
+type EffectivePermissionsCache struct {
+	TTL time.Duration
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[effectivePermissionsKey]effectivePermissionsEntry
+	gens    map[int]uint64 // gens counts the invalidations of each source
+}
+
+// NewEffectivePermissionsCache keeps effective permissions for ttl
+func NewEffectivePermissionsCache(ttl time.Duration) *EffectivePermissionsCache {
+	return &EffectivePermissionsCache{
+		TTL:     ttl,
+		Now:     time.Now,
+		entries: map[effectivePermissionsKey]effectivePermissionsEntry{},
+		gens:    map[int]uint64{},
+	}
+}
+
+type effectivePermissionsKey struct {
+	source int
+	user   string
+}
+
+type effectivePermissionsEntry struct {
+	perms      chronograf.Permissions
+	roles      []string
+	delegators []string // delegators are the roles delegating permissions to roles
+	expires    time.Time
+}
+
+// get returns the cached permissions of user.  On a miss gen is passed to
+// put so permissions computed while the roles changed are not cached.
+func (c *EffectivePermissionsCache) get(srcID int, user string) (e effectivePermissionsEntry, ok bool, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := effectivePermissionsKey{srcID, user}
+	e, ok = c.entries[key]
+	if ok && !c.Now().Before(e.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	return e, ok, c.gens[srcID]
+}
+
+// put caches the permissions of user computed from the roles of the
+// source as of gen.  The entry expires after the TTL or when the first
+// permission it was computed from expires.
+func (c *EffectivePermissionsCache) put(srcID int, user string, gen uint64, e effectivePermissionsEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gens[srcID] != gen {
+		return
+	}
+	now := c.Now()
+	if ttl := now.Add(c.TTL); e.expires.IsZero() || ttl.Before(e.expires) {
+		e.expires = ttl
+	}
+	// Expired entries of other users are dropped as entries are written so
+	// the cache does not grow with every user ever looked up
+	for key, old := range c.entries {
+		if !now.Before(old.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[effectivePermissionsKey{srcID, user}] = e
+}
+
+// invalidate drops the entries of the users of the source that were
+// members of role or of a role it delegates to, and of users, its members
+// after the change
+func (c *EffectivePermissionsCache) invalidate(srcID int, role string, users []chronograf.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gens[srcID]++
+	for key, e := range c.entries {
+		if key.source != srcID {
+			continue
+		}
+		for _, name := range append(e.roles, e.delegators...) {
+			if name == role {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+	for _, u := range users {
+		delete(c.entries, effectivePermissionsKey{srcID, u.Name})
+	}
+}
+
+// firstExpiry returns when the first unexpired permission of the roles of
+// user expires; zero if none expire
+func firstExpiry(roles []chronograf.Role, user string, now time.Time) time.Time {
+	var first time.Time
+	for i := range roles {
+		if !hasRoleUser(&roles[i], user) {
+			continue
+		}
+		for _, perm := range roles[i].Permissions {
+			if perm.ExpiresAt == nil || perm.Expired(now) {
+				continue
+			}
+			if first.IsZero() || perm.ExpiresAt.Before(first) {
+				first = *perm.ExpiresAt
+			}
+		}
+	}
+	return first
+}
+
+// userEffectivePermissions returns the effective permissions of user and
+// the roles granting them, computed from all roles of the source unless
+// cached
+func (s *Service) userEffectivePermissions(ctx context.Context, srcID int, store chronograf.RolesStore, user string) (chronograf.Permissions, []string, error) {
+	cache := s.EffectivePermissions
+	if cache == nil {
+		roles, err := store.All(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		perms, names := effectivePermissions(roles, user, time.Now())
+		return perms, names, nil
+	}
+
+	e, ok, gen := cache.get(srcID, user)
+	if ok {
+		return e.perms, e.roles, nil
+	}
+	roles, err := store.All(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := cache.Now()
+	e.perms, e.roles, e.delegators = delegatedEffectivePermissions(roles, user, now)
+	e.expires = firstExpiry(roles, user, now)
+	cache.put(srcID, user, gen, e)
+	return e.perms, e.roles, nil
+}
+
+var _ chronograf.RolesStore = &invalidatingRolesStore{}
+
+// invalidatingRolesStore drops the cached effective permissions of the
+// users affected by each change made to the roles of a source
+type invalidatingRolesStore struct {
+	chronograf.RolesStore
+	srcID int
+	cache *EffectivePermissionsCache
+}
+
+// Add creates a new role and drops the cached permissions of its users
+func (s *invalidatingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.invalidate(s.srcID, role.Name, role.Users)
+	return res, nil
+}
+
+// Update changes a role and drops the cached permissions of its previous
+// and new users
+func (s *invalidatingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	if err := s.RolesStore.Update(ctx, role); err != nil {
+		return err
+	}
+	s.cache.invalidate(s.srcID, role.Name, role.Users)
+	return nil
+}
+
+// Delete removes a role and drops the cached permissions of its users
+func (s *invalidatingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	if err := s.RolesStore.Delete(ctx, role); err != nil {
+		return err
+	}
+	s.cache.invalidate(s.srcID, role.Name, nil)
+	return nil
+}
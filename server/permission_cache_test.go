@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_userEffectivePermissions_Cache(t *testing.T) {
+	now := time.Date(1985, time.October, 26, 1, 21, 0, 0, time.UTC)
+	soon := now.Add(time.Minute)
+	loads := 0
+	roles := []chronograf.Role{
+		{
+			Name:  "timetravelers",
+			Users: []chronograf.User{{Name: "marty"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ"}},
+			},
+		},
+		{
+			Name:  "biffsgang",
+			Users: []chronograf.User{{Name: "biff"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "almanac", Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &soon},
+			},
+		},
+	}
+	cache := NewEffectivePermissionsCache(time.Hour)
+	cache.Now = func() time.Time { return now }
+	s := &Service{
+		TimeSeriesClient: &mocks.TimeSeries{
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+						loads++
+						return roles, nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						return nil
+					},
+				}, nil
+			},
+		},
+		EffectivePermissions: cache,
+		Logger:               log.New(log.DebugLevel),
+	}
+	ctx := context.Background()
+	ts, _ := s.TimeSeriesClient.New(chronograf.Source{ID: 1}, nil)
+	store, _ := s.hasRoles(ctx, 1, ts)
+
+	lookup := func(user string, wantLoads int) chronograf.Permissions {
+		t.Helper()
+		perms, _, err := s.userEffectivePermissions(ctx, 1, store, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loads != wantLoads {
+			t.Errorf("userEffectivePermissions(%q) loaded roles %d times, want %d", user, loads, wantLoads)
+		}
+		return perms
+	}
+
+	lookup("marty", 1)
+	lookup("marty", 1)
+
+	// Adding marty to another role drops marty's permissions
+	roles[1].Users = append(roles[1].Users, chronograf.User{Name: "marty"})
+	if err := store.Update(ctx, &roles[1]); err != nil {
+		t.Fatal(err)
+	}
+	perms := lookup("marty", 2)
+	want := chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ"}},
+		{Scope: chronograf.DBScope, Name: "almanac", Allowed: chronograf.Allowances{"READ"}},
+	}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("userEffectivePermissions() = %v, want %v", perms, want)
+	}
+
+	// The entry expires with the first permission it was computed from
+	now = soon
+	perms = lookup("marty", 3)
+	want = chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ"}},
+	}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("userEffectivePermissions() after expiry = %v, want %v", perms, want)
+	}
+
+	// Permissions computed before a change of the roles are not cached
+	_, _, gen := cache.get(1, "biff")
+	cache.invalidate(1, "timetravelers", nil)
+	cache.put(1, "biff", gen, effectivePermissionsEntry{})
+	if _, ok, _ := cache.get(1, "biff"); ok {
+		t.Errorf("EffectivePermissionsCache.put() cached permissions computed before an invalidation")
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// NewClassifications parses label:database pairs into the databases of each
+// data classification label
+func NewClassifications(pairs []string) (map[string][]string, error) {
+	classifications := map[string][]string{}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("Database classification %q must be label:database", pair)
+		}
+		classifications[parts[0]] = append(classifications[parts[0]], parts[1])
+	}
+	return classifications, nil
+}
+
+// expandClassifications replaces each permission referencing a data
+// classification with a permission of every database with the label.  The
+// databases are those configured when the role is created or updated;
+// roles are not changed as databases are later classified.
+func (s *Service) expandClassifications(perms *chronograf.Permissions) error {
+	var errs validationErrors
+	expanded := make(chronograf.Permissions, 0, len(*perms))
+	for i, perm := range *perms {
+		if perm.Classification == "" {
+			expanded = append(expanded, perm)
+			continue
+		}
+
+		dbs, ok := s.Classifications[perm.Classification]
+		if !ok {
+			errs.add(fmt.Sprintf("permissions[%d].classification", i), "Unknown data classification %s", perm.Classification)
+			continue
+		}
+		for _, db := range dbs {
+			expanded = append(expanded, chronograf.Permission{
+				Scope:     chronograf.DBScope,
+				Name:      db,
+				Allowed:   append(chronograf.Allowances{}, perm.Allowed...),
+				Deny:      perm.Deny,
+				ExpiresAt: perm.ExpiresAt,
+				Note:      perm.Note,
+			})
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	*perms = expanded
+	return nil
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestNewClassifications(t *testing.T) {
+	got, err := NewClassifications([]string{"restricted:payroll", "public:telegraf", "restricted:hr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"restricted": {"payroll", "hr"},
+		"public":     {"telegraf"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewClassifications() = %v, want %v", got, want)
+	}
+
+	for _, pair := range []string{"restricted", ":payroll", "restricted:"} {
+		if _, err := NewClassifications([]string{pair}); err == nil {
+			t.Errorf("NewClassifications(%q) expected error", pair)
+		}
+	}
+}
+
+func TestService_NewSourceRole_Classification(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantPerms chronograf.Permissions
+		wantBody  string
+	}{
+		{
+			name: "Expands classification",
+			body: `{"name": "auditors", "permissions": [{"scope": "database", "classification": "restricted", "allowed": ["READ"]}, {"scope": "database", "name": "telegraf", "allowed": ["WRITE"]}]}`,
+			wantPerms: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "payroll",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "hr",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "telegraf",
+					Allowed: chronograf.Allowances{"WRITE"},
+				},
+			},
+		},
+		{
+			name: "Unknown classification",
+			body: `{"name": "auditors", "permissions": [{"scope": "database", "classification": "secret", "allowed": ["READ"]}]}`,
+			wantBody: `{"code":422,"message":"Unknown data classification secret","errors":[{"field":"permissions[0].classification","message":"Unknown data classification secret"}]}
+`,
+		},
+		{
+			name: "Unknown classification with a percent sign",
+			body: `{"name": "auditors", "permissions": [{"scope": "database", "classification": "100%d", "allowed": ["READ"]}]}`,
+			wantBody: `{"code":422,"message":"Unknown data classification 100%d","errors":[{"field":"permissions[0].classification","message":"Unknown data classification 100%d"}]}
+`,
+		},
+		{
+			name: "Classification with a database name",
+			body: `{"name": "auditors", "permissions": [{"scope": "database", "name": "hr", "classification": "restricted", "allowed": ["READ"]}]}`,
+			wantBody: `{"code":422,"message":"Classified permission must be database scoped without a name","errors":[{"field":"permissions[0].classification","message":"Classified permission must be database scoped without a name"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added chronograf.Permissions
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return nil, fmt.Errorf("role %s not found", name)
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								added = role.Permissions
+								return role, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+				Classifications: map[string][]string{
+					"restricted": {"payroll", "hr"},
+				},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.NewSourceRole(w, r)
+
+			if tt.wantBody != "" {
+				body, _ := ioutil.ReadAll(w.Result().Body)
+				if string(body) != tt.wantBody {
+					t.Errorf("NewSourceRole() = %s, want %s", body, tt.wantBody)
+				}
+				return
+			}
+			if !reflect.DeepEqual(added, tt.wantPerms) {
+				t.Errorf("NewSourceRole() added permissions %v, want %v", added, tt.wantPerms)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// permissionContradiction is a permission at odds with an earlier permission
+// of the same role
+type permissionContradiction struct {
+	index   int // index is the position of the later permission
+	message string
+}
+
+func scopeString(perm chronograf.Permission) string {
+	if perm.Scope == chronograf.AllScope {
+		return "all databases"
+	}
+	return "database " + perm.Name
+}
+
+// overlaps is true if the permissions apply to a database in common
+func overlaps(a, b chronograf.Permission) bool {
+	if a.Scope == chronograf.AllScope || b.Scope == chronograf.AllScope {
+		return true
+	}
+	return a.Scope == b.Scope && a.Name == b.Name
+}
+
+func allowanceSet(allowed chronograf.Allowances) map[string]bool {
+	set := map[string]bool{}
+	for _, a := range allowed {
+		set[a] = true
+	}
+	return set
+}
+
+// sharedAllowances returns the allowances of a also in b, in the order of a
+func sharedAllowances(a, b chronograf.Permission) chronograf.Allowances {
+	allowed := allowanceSet(b.Allowed)
+	shared := chronograf.Allowances{}
+	for _, x := range a.Allowed {
+		if allowed[x] {
+			shared = append(shared, x)
+			allowed[x] = false
+		}
+	}
+	return shared
+}
+
+// permissionContradictions finds the permissions whose effect depends on how
+// they are combined with another: a grant and a deny of the same allowance
+// on overlapping scopes, or grants of the same scope listing different
+// allowances.  Classified permissions are skipped as their databases are
+// not known until they are expanded.
+func permissionContradictions(perms chronograf.Permissions) []permissionContradiction {
+	res := []permissionContradiction{}
+	for j, later := range perms {
+		if later.Classification != "" {
+			continue
+		}
+		for i, earlier := range perms[:j] {
+			if earlier.Classification != "" || !overlaps(earlier, later) {
+				continue
+			}
+
+			if earlier.Deny != later.Deny {
+				shared := sharedAllowances(earlier, later)
+				if len(shared) == 0 {
+					continue
+				}
+				grant, deny := earlier, later
+				if earlier.Deny {
+					grant, deny = later, earlier
+				}
+				res = append(res, permissionContradiction{j, fmt.Sprintf(
+					"Permissions %d and %d grant %s on %s and deny them on %s",
+					i, j, strings.Join(shared, ", "), scopeString(grant), scopeString(deny),
+				)})
+				continue
+			}
+
+			if sameScope(earlier, later) &&
+				!sameAllowances(allowanceSet(earlier.Allowed), allowanceSet(later.Allowed)) {
+				res = append(res, permissionContradiction{j, fmt.Sprintf(
+					"Permissions %d and %d grant different allowances on %s",
+					i, j, scopeString(later),
+				)})
+			}
+		}
+	}
+	return res
+}
+
+// contradictionWarnings returns the contradictions of perms as warnings of a
+// role response.  Under a strict policy contradictions fail validation
+// instead, so there is nothing to warn of.
+func (s *Service) contradictionWarnings(perms chronograf.Permissions) []string {
+	if s.StrictPermissions {
+		return nil
+	}
+	var warnings []string
+	for _, c := range permissionContradictions(perms) {
+		warnings = append(warnings, c.message)
+	}
+	return warnings
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+)
+
+func TestService_contradictionWarnings(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+			Deny:    true,
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"WriteData", "ReadData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "chronograf",
+			Allowed: chronograf.Allowances{"WriteData"},
+		},
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ViewChronograf"},
+			Deny:    true,
+		},
+	}
+
+	s := &Service{}
+	want := []string{"Permissions 0 and 1 grant ReadData, WriteData on database telegraf and deny them on database telegraf"}
+	if got := s.contradictionWarnings(perms); !reflect.DeepEqual(got, want) {
+		t.Errorf("Service.contradictionWarnings() = %v, want %v", got, want)
+	}
+
+	s.StrictPermissions = true
+	if got := s.contradictionWarnings(perms); got != nil {
+		t.Errorf("Service.contradictionWarnings() with strict permissions = %v, want nil", got)
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+type danglingPermissionsResponse struct {
+	Databases []string               `json:"databases"` // Databases are the current databases of the source
+	Roles     []danglingRoleResponse `json:"roles"`     // Roles are the roles having dangling or unmatched permissions
+	Links     selfLinks              `json:"links"`
+}
+
+type danglingRoleResponse struct {
+	Name      string                 `json:"name"`
+	Dangling  chronograf.Permissions `json:"dangling"`  // Dangling are the permissions of databases the source does not have, e.g. dropped databases
+	Unmatched chronograf.Permissions `json:"unmatched"` // Unmatched are the permissions of all databases while the source has none
+}
+
+// sourceDatabases lists the names of the current databases of the source
+// with srcID.  The response is written if the databases are unavailable.
+func (s *Service) sourceDatabases(ctx context.Context, w http.ResponseWriter, srcID int) ([]string, error) {
+	src, err := s.Store.Sources(ctx).Get(ctx, srcID)
+	if err != nil {
+		notFound(w, srcID, s.Logger)
+		return nil, err
+	}
+	if err = s.Databases.Connect(ctx, &src); err != nil {
+		msg := fmt.Sprintf("Unable to connect to source %d: %v", srcID, err)
+		Error(w, http.StatusBadRequest, msg, s.Logger)
+		return nil, err
+	}
+	dbs, err := s.Databases.AllDB(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return nil, err
+	}
+	names := make([]string, len(dbs))
+	for i, db := range dbs {
+		names[i] = db.Name
+	}
+	return names, nil
+}
+
+// SourceDanglingPermissions checks the permissions of every role of a source
+// against the source's current databases.  Permissions of databases the
+// source does not have are dangling.  Permissions of all databases are
+// never dangling; they are listed as unmatched while the source has no
+// databases for them to grant.
+func (s *Service) SourceDanglingPermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	dbs, err := s.sourceDatabases(ctx, w, srcID)
+	if err != nil {
+		return
+	}
+	exists := map[string]bool{}
+	for _, db := range dbs {
+		exists[db] = true
+	}
+
+	res := danglingPermissionsResponse{
+		Databases: sortedKeys(exists),
+		Roles:     []danglingRoleResponse{},
+		Links:     selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/permissions/dangling", srcID)},
+	}
+	for _, role := range roles {
+		rr := danglingRoleResponse{
+			Name:      role.Name,
+			Dangling:  chronograf.Permissions{},
+			Unmatched: chronograf.Permissions{},
+		}
+		for _, perm := range role.Permissions {
+			switch {
+			case perm.Scope == chronograf.DBScope && !exists[perm.Name]:
+				rr.Dangling = append(rr.Dangling, perm)
+			case perm.Scope == chronograf.AllScope && len(exists) == 0:
+				rr.Unmatched = append(rr.Unmatched, perm)
+			}
+		}
+		if len(rr.Dangling) > 0 || len(rr.Unmatched) > 0 {
+			res.Roles = append(res.Roles, rr)
+		}
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceDanglingPermissions(t *testing.T) {
+	roles := []chronograf.Role{
+		{
+			Name: "timetravelers",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "almanac",
+					Allowed: chronograf.Allowances{"WRITE"},
+				},
+			},
+		},
+		{
+			Name: "admins",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		databases  []chronograf.Database
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Permissions of dropped databases dangle",
+			databases:  []chronograf.Database{{Name: "delorean"}, {Name: "_internal"}},
+			wantStatus: http.StatusOK,
+			wantBody: `{"databases":["_internal","delorean"],"roles":[{"name":"timetravelers","dangling":[{"scope":"database","name":"almanac","allowed":["WRITE"]}],"unmatched":[]}],"links":{"self":"/chronograf/v1/sources/1/permissions/dangling"}}
+`,
+		},
+		{
+			name:       "Permissions of all databases match nothing without databases",
+			databases:  []chronograf.Database{},
+			wantStatus: http.StatusOK,
+			wantBody: `{"databases":[],"roles":[{"name":"timetravelers","dangling":[{"scope":"database","name":"delorean","allowed":["READ"]},{"scope":"database","name":"almanac","allowed":["WRITE"]}],"unmatched":[]},{"name":"admins","dangling":[],"unmatched":[{"scope":"all","allowed":["READ"]}]}],"links":{"self":"/chronograf/v1/sources/1/permissions/dangling"}}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return roles, nil
+							},
+						}, nil
+					},
+				},
+				Databases: &mocks.Databases{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					AllDBF: func(ctx context.Context) ([]chronograf.Database, error) {
+						return tt.databases, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/permissions/dangling", nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceDanglingPermissions(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceDanglingPermissions() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceDanglingPermissions() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
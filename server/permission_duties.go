@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// Duty is a responsibility granted by any of its allowances, e.g.
+// administering schemas by CreateDatabase or DropDatabase
+type Duty struct {
+	Name    string                `json:"name"`
+	Allowed chronograf.Allowances `json:"allowed"`
+}
+
+// DutyRule is a set of duties no single role may hold more than one of
+type DutyRule struct {
+	Name   string `json:"name"`
+	Duties []Duty `json:"duties"`
+}
+
+// SeparationOfDuties are the rules of mutually exclusive duties the
+// permissions of source roles are validated against.  New rules are added
+// to its file; no code changes are needed.
+type SeparationOfDuties []DutyRule
+
+// NewSeparationOfDuties reads the rules of a JSON file.  A rule forbidding
+// roles that both administer schemas and delete data is
+// {"name": "schema-or-delete", "duties": [{"name": "schema-admin", "allowed":
+// ["CreateDatabase", "DropDatabase"]}, {"name": "data-delete", "allowed": ["DropData"]}]}
+func NewSeparationOfDuties(path string) (SeparationOfDuties, error) {
+	octets, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules SeparationOfDuties
+	if err := json.Unmarshal(octets, &rules); err != nil {
+		return nil, fmt.Errorf("Separation of duties rules %s are not valid JSON: %v", path, err)
+	}
+
+	var errs validationErrors
+	names := map[string]bool{}
+	for i, rule := range rules {
+		field := fmt.Sprintf("[%d]", i)
+		if rule.Name == "" {
+			errs.add(field+".name", "Rule requires a name")
+		} else if names[rule.Name] {
+			errs.add(field+".name", "Rule %s is defined more than once", rule.Name)
+		}
+		names[rule.Name] = true
+		if len(rule.Duties) < 2 {
+			errs.add(field+".duties", "Rule requires at least two duties")
+		}
+		for j, duty := range rule.Duties {
+			if duty.Name == "" {
+				errs.add(fmt.Sprintf("%s.duties[%d].name", field, j), "Duty requires a name")
+			}
+			if len(duty.Allowed) == 0 {
+				errs.add(fmt.Sprintf("%s.duties[%d].allowed", field, j), "Duty requires at least one allowance")
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("Separation of duties rules %s are invalid: %s", path, errs.describe())
+	}
+	return rules, nil
+}
+
+// dutyGrant is a permission granting allowances of a duty
+type dutyGrant struct {
+	Duty    string                `json:"duty"`
+	Field   string                `json:"field"` // Field is the granting permission, e.g. permissions[2]
+	Allowed chronograf.Allowances `json:"allowed"`
+}
+
+// dutyConflict is a rule broken by permissions granting more than one of
+// its duties
+type dutyConflict struct {
+	Rule   string      `json:"rule"`
+	Grants []dutyGrant `json:"grants"`
+}
+
+func (c dutyConflict) String() string {
+	grants := make([]string, len(c.Grants))
+	for i, g := range c.Grants {
+		grants[i] = fmt.Sprintf("%s by %s (%s)", g.Duty, g.Field, strings.Join(g.Allowed, ", "))
+	}
+	return fmt.Sprintf("Separation of duties rule %s forbids granting %s together", c.Rule, strings.Join(grants, " and "))
+}
+
+// conflicts returns the rules broken by perms.  Only allowances the
+// permissions grant count; those the role also denies do not.
+func (rules SeparationOfDuties) conflicts(perms chronograf.Permissions) []dutyConflict {
+	conflicts := []dutyConflict{}
+	for _, rule := range rules {
+		c := dutyConflict{Rule: rule.Name}
+		held := 0
+		for _, duty := range rule.Duties {
+			holds := false
+			for i, perm := range perms {
+				if perm.Deny {
+					continue
+				}
+				var allowed chronograf.Allowances
+				for _, a := range perm.Allowed {
+					if hasAllowance(duty.Allowed, a) && grants(perms, perm.Scope, perm.Name, a) {
+						allowed = append(allowed, a)
+					}
+				}
+				if len(allowed) > 0 {
+					holds = true
+					c.Grants = append(c.Grants, dutyGrant{
+						Duty:    duty.Name,
+						Field:   fmt.Sprintf("permissions[%d]", i),
+						Allowed: allowed,
+					})
+				}
+			}
+			if holds {
+				held++
+			}
+		}
+		if held > 1 {
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts
+}
+
+// ValidPermissions names the permissions breaking each rule
+func (rules SeparationOfDuties) ValidPermissions(perms chronograf.Permissions) error {
+	var errs validationErrors
+	for _, c := range rules.conflicts(perms) {
+		errs.add("", "%s", c)
+	}
+	return errs.err()
+}
+
+// CheckSourceRoleDuties checks a role against the separation of duties
+// rules.  GET checks the role as stored in the source; POST checks the role
+// of the request body before it is created or updated.
+func (s *Service) CheckSourceRoleDuties(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+
+	var role *chronograf.Role
+	if r.Method == http.MethodPost {
+		var req sourceRoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			invalidJSON(w, s.Logger)
+			return
+		}
+		if req.Name == "" {
+			req.Name = rid
+		}
+		role = &req.Role
+	}
+
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	if role == nil {
+		role, err = roles.Get(ctx, rid)
+		if err != nil {
+			Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+			return
+		}
+	}
+
+	res := struct {
+		Name      string         `json:"name"`
+		Conflicts []dutyConflict `json:"conflicts"`
+	}{
+		Name:      role.Name,
+		Conflicts: s.SeparationOfDuties.conflicts(role.Permissions),
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+var testDuties = SeparationOfDuties{
+	{
+		Name: "schema-or-delete",
+		Duties: []Duty{
+			{Name: "schema-admin", Allowed: chronograf.Allowances{"CreateDatabase", "DropDatabase"}},
+			{Name: "data-delete", Allowed: chronograf.Allowances{"DropData"}},
+		},
+	},
+}
+
+func TestNewSeparationOfDuties(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr bool
+	}{
+		{
+			name:  "Rules",
+			rules: `[{"name": "schema-or-delete", "duties": [{"name": "schema-admin", "allowed": ["CreateDatabase"]}, {"name": "data-delete", "allowed": ["DropData"]}]}]`,
+		},
+		{
+			name:    "Invalid JSON",
+			rules:   `[{"name":`,
+			wantErr: true,
+		},
+		{
+			name:    "Rule with one duty",
+			rules:   `[{"name": "lonely", "duties": [{"name": "data-delete", "allowed": ["DropData"]}]}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Duty without allowances",
+			rules:   `[{"name": "empty", "duties": [{"name": "a", "allowed": ["DropData"]}, {"name": "b", "allowed": []}]}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "duties")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			if _, err := f.WriteString(tt.rules); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			if _, err := NewSeparationOfDuties(f.Name()); (err != nil) != tt.wantErr {
+				t.Errorf("NewSeparationOfDuties() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeparationOfDuties_ValidPermissions(t *testing.T) {
+	tests := []struct {
+		name  string
+		perms chronograf.Permissions
+		want  string
+	}{
+		{
+			name: "One duty",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"CreateDatabase", "ReadData"}},
+			},
+		},
+		{
+			name: "Conflicting duties",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"CreateDatabase"}},
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData", "DropData"}},
+			},
+			want: "Separation of duties rule schema-or-delete forbids granting schema-admin by permissions[0] (CreateDatabase) and data-delete by permissions[1] (DropData) together",
+		},
+		{
+			name: "Denied duty",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"CreateDatabase"}},
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"DropData"}},
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"DropData"}, Deny: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if err := testDuties.ValidPermissions(tt.perms); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("ValidPermissions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_CheckSourceRoleDuties(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		wantBody string
+	}{
+		{
+			name:   "Stored role",
+			method: "GET",
+			wantBody: `{"name":"biffsgang","conflicts":[]}
+`,
+		},
+		{
+			name:   "Inline role",
+			method: "POST",
+			body:   `{"permissions": [{"scope": "all", "allowed": ["DropDatabase", "DropData"]}]}`,
+			wantBody: `{"name":"biffsgang","conflicts":[{"rule":"schema-or-delete","grants":[{"duty":"schema-admin","field":"permissions[0]","allowed":["DropDatabase"]},{"duty":"data-delete","field":"permissions[0]","allowed":["DropData"]}]}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{Name: name}, nil
+							},
+						}, nil
+					},
+				},
+				SeparationOfDuties: testDuties,
+				Logger:             log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "http://server.local/chronograf/v1/sources/1/roles/biffsgang/duties", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: "biffsgang",
+					},
+				}))
+			h.CheckSourceRoleDuties(w, r)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("CheckSourceRoleDuties() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
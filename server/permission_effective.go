@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+type effectivePermissionsResponse struct {
+	User        string                 `json:"user"`
+	Permissions chronograf.Permissions `json:"permissions"` // Permissions are the union of the permissions of Roles
+	Roles       []string               `json:"roles"`       // Roles are the roles containing the user
+	Links       selfLinks              `json:"links"`
+}
+
+// effectivePermissions returns the union of the unexpired permissions of
+// the roles containing user, including those delegated to them, and the
+// names of those roles
+func effectivePermissions(roles []chronograf.Role, user string, now time.Time) (chronograf.Permissions, []string) {
+	perms, names, _ := delegatedEffectivePermissions(roles, user, now)
+	return perms, names
+}
+
+// delegatedEffectivePermissions is effectivePermissions also returning the
+// names of the roles delegating permissions to the roles of user.  As
+// sources enforce them, the denies of a role only take allowances away
+// from the grants of that role, so each role is resolved on its own before
+// the roles are combined.
+func delegatedEffectivePermissions(roles []chronograf.Role, user string, now time.Time) (chronograf.Permissions, []string, []string) {
+	perms := chronograf.Permissions{}
+	names := []string{}
+	delegators := []string{}
+	for i := range roles {
+		if !hasActiveRoleUser(&roles[i], user, now) {
+			continue
+		}
+		names = append(names, roles[i].Name)
+		kept, _ := unexpiredPermissions(roles[i].Permissions, now)
+		delegated, from := delegatedPermissions(roles, &roles[i], now)
+		perms = append(perms, roleGrants(append(kept, delegated...))...)
+		delegators = append(delegators, from...)
+	}
+	// A user is granted everything any of their roles allow, so overlapping
+	// permissions are always combined into the union of their allowances.
+	return mergePermissionsWith(perms, MostPermissive, nil), names, delegators
+}
+
+// roleGrants returns what the permissions of one role grant: its grants
+// without the allowances its denies take away, and without grants left
+// with no allowances
+func roleGrants(perms chronograf.Permissions) chronograf.Permissions {
+	res := chronograf.Permissions{}
+	for _, perm := range mergePermissionsWith(perms, MostPermissive, nil) {
+		if !perm.Deny && len(perm.Allowed) > 0 {
+			res = append(res, perm)
+		}
+	}
+	return res
+}
+
+// SourceUserEffectivePermissions retrieves the permissions a user receives
+// from all of the roles of a source.  The permissions are cached if
+// EffectivePermissions is set.
+func (s *Service) SourceUserEffectivePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	uid := httprouter.GetParamFromContext(ctx, "uid")
+	perms, names, err := s.userEffectivePermissions(ctx, srcID, store, uid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	res := effectivePermissionsResponse{
+		User:        uid,
+		Permissions: perms,
+		Roles:       names,
+		Links:       selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/users/%s/permissions", srcID, uid)},
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceUserEffectivePermissions(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	roles := []chronograf.Role{
+		{
+			Name:  "readers",
+			Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "pics",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			Name:  "writers",
+			Users: []chronograf.User{{Name: "marty"}},
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "pics",
+					Allowed: chronograf.Allowances{"WRITE"},
+				},
+				{
+					Scope:     chronograf.AllScope,
+					Allowed:   chronograf.Allowances{"ALL"},
+					ExpiresAt: &expired,
+				},
+			},
+		},
+		{
+			Name:  "biffsgang",
+			Users: []chronograf.User{{Name: "biff"}},
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ALL"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		user     string
+		wantBody string
+	}{
+		{
+			name: "Union of roles",
+			user: "marty",
+			wantBody: `{"user":"marty","permissions":[{"scope":"database","name":"pics","allowed":["READ","WRITE"]}],"roles":["readers","writers"],"links":{"self":"/chronograf/v1/sources/1/users/marty/permissions"}}
+`,
+		},
+		{
+			name: "User without roles",
+			user: "jennifer",
+			wantBody: `{"user":"jennifer","permissions":[],"roles":[],"links":{"self":"/chronograf/v1/sources/1/users/jennifer/permissions"}}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return roles, nil
+							},
+						}, nil
+					},
+				},
+				Logger:              log.New(log.DebugLevel),
+				PermissionConflicts: LeastPermissive,
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/users/"+tt.user+"/permissions", nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "uid",
+						Value: tt.user,
+					},
+				}))
+			h.SourceUserEffectivePermissions(w, r)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("SourceUserEffectivePermissions() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_effectivePermissions_Denies(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	roles := []chronograf.Role{
+		{
+			Name:  "contractors",
+			Users: []chronograf.User{{Name: "kiwi"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData", "WriteData"}},
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"WriteData"}, Deny: true},
+			},
+		},
+		{
+			Name:  "collectors",
+			Users: []chronograf.User{{Name: "kiwi"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"WriteData"}},
+			},
+		},
+	}
+
+	// The deny of contractors does not take away the grant of collectors
+	perms, _ := effectivePermissions(roles, "kiwi", now)
+	want := chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData", "WriteData"}},
+	}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("effectivePermissions() = %v, want %v", perms, want)
+	}
+
+	roles[1].Users = nil
+	perms, _ = effectivePermissions(roles, "kiwi", now)
+	want = chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData"}},
+	}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("effectivePermissions() without collectors = %v, want %v", perms, want)
+	}
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/influxdata/chronograf"
+)
+
+const (
+	// MostPermissive merges conflicting permissions into the union of their allowances
+	MostPermissive = "most-permissive"
+	// LeastPermissive merges conflicting permissions into the allowances they have in common
+	LeastPermissive = "least-permissive"
+)
+
+// permissionConflicts returns the strategy used to merge permissions
+func (s *Service) permissionConflicts() string {
+	if s.PermissionConflicts == LeastPermissive {
+		return LeastPermissive
+	}
+	return MostPermissive
+}
+
+type permissionKey struct {
+	scope chronograf.Scope
+	name  string
+	deny  bool
+}
+
+// mergePermissions combines the permissions of the same scope and database
+// into one permission.  Permissions with different allowances conflict and
+// are resolved by the Service's PermissionConflicts strategy.  Denies are
+// applied last, taking their allowances away from the merged grants.  The
+// merged grants are in the order each scope first appears followed by the
+// merged denies.
+func (s *Service) mergePermissions(perms chronograf.Permissions) chronograf.Permissions {
+	return mergePermissionsWith(perms, s.permissionConflicts(), s.Logger)
+}
+
+// mergePermissionsWith merges permissions resolving conflicts by strategy.
+// Conflicts are logged unless logger is nil.  Denies of the same scope are
+// always combined into the union of their allowances.
+func mergePermissionsWith(perms chronograf.Permissions, strategy string, logger chronograf.Logger) chronograf.Permissions {
+	keys := []permissionKey{}
+	merged := map[permissionKey]map[string]bool{}
+	for _, perm := range perms {
+		key := permissionKey{perm.Scope, perm.Name, perm.Deny}
+		allowed := map[string]bool{}
+		for _, a := range perm.Allowed {
+			allowed[a] = true
+		}
+
+		prev, ok := merged[key]
+		if !ok {
+			keys = append(keys, key)
+			merged[key] = allowed
+			continue
+		}
+		if sameAllowances(prev, allowed) {
+			continue
+		}
+
+		if logger != nil && !perm.Deny {
+			logger.
+				WithField("component", "permissions").
+				WithField("strategy", strategy).
+				WithField("scope", perm.Scope).
+				WithField("name", perm.Name).
+				Info("Resolved conflicting permissions")
+		}
+		if strategy == MostPermissive || perm.Deny {
+			for a := range allowed {
+				prev[a] = true
+			}
+			continue
+		}
+		for a := range prev {
+			if !allowed[a] {
+				delete(prev, a)
+			}
+		}
+	}
+
+	grants, denies := []permissionKey{}, []permissionKey{}
+	for _, key := range keys {
+		if key.deny {
+			denies = append(denies, key)
+		} else {
+			grants = append(grants, key)
+		}
+	}
+	for _, deny := range denies {
+		for _, grant := range grants {
+			if deny.scope == chronograf.AllScope || (deny.scope == grant.scope && deny.name == grant.name) {
+				for a := range merged[deny] {
+					delete(merged[grant], a)
+				}
+			}
+		}
+	}
+
+	keys = append(grants, denies...)
+	res := make(chronograf.Permissions, len(keys))
+	for i, key := range keys {
+		allowed := make(chronograf.Allowances, 0, len(merged[key]))
+		for a := range merged[key] {
+			allowed = append(allowed, a)
+		}
+		sort.Strings(allowed)
+		res[i] = chronograf.Permission{
+			Scope:   key.scope,
+			Name:    key.name,
+			Allowed: allowed,
+			Deny:    key.deny,
+		}
+	}
+	return res
+}
+
+func sameAllowances(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+)
+
+func TestService_mergePermissions(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"WRITE", "READ"},
+		},
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"READ"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"READ", "DELETE"},
+		},
+	}
+	tests := []struct {
+		name     string
+		strategy string
+		want     chronograf.Permissions
+	}{
+		{
+			name: "Defaults to most permissive",
+			want: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"DELETE", "READ", "WRITE"},
+				},
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			name:     "Least permissive keeps common allowances",
+			strategy: LeastPermissive,
+			want: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		s := &Service{
+			Logger:              log.New(log.DebugLevel),
+			PermissionConflicts: tt.strategy,
+		}
+		if got := s.mergePermissions(perms); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Service.mergePermissions() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestService_mergePermissions_Deny(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"READ", "WRITE"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"WRITE"},
+			Deny:    true,
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"READ", "WRITE"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"DELETE"},
+			Deny:    true,
+		},
+	}
+	want := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"READ", "WRITE"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"READ"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "delorean",
+			Allowed: chronograf.Allowances{"DELETE", "WRITE"},
+			Deny:    true,
+		},
+	}
+	s := &Service{
+		Logger:              log.New(log.DebugLevel),
+		PermissionConflicts: LeastPermissive,
+	}
+	if got := s.mergePermissions(perms); !reflect.DeepEqual(got, want) {
+		t.Errorf("Service.mergePermissions() = %v, want %v", got, want)
+	}
+
+	// Sources cannot narrow the grant of all databases to deny one
+	if !grants(perms, chronograf.DBScope, "delorean", "WRITE") {
+		t.Errorf("grants() of WRITE granted on all databases = false, want true")
+	}
+	if !grants(perms, chronograf.DBScope, "delorean", "READ") {
+		t.Errorf("grants() of READ = false, want true")
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/influxdata/chronograf"
+)
+
+// NewPermissionPresets reads the named permission bundles of a JSON file
+// mapping each preset name to its permissions, e.g.
+// {"db-reader": [{"scope": "database", "name": "telegraf", "allowed": ["ReadData"]}]}
+func NewPermissionPresets(path string) (map[string]chronograf.Permissions, error) {
+	octets, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	presets := map[string]chronograf.Permissions{}
+	if err := json.Unmarshal(octets, &presets); err != nil {
+		return nil, fmt.Errorf("Permission presets %s are not valid JSON: %v", path, err)
+	}
+
+	var errs validationErrors
+	for _, name := range sortedPresets(presets) {
+		perms := presets[name]
+		if len(perms) == 0 {
+			errs.add(name, "Permission preset has no permissions")
+			continue
+		}
+		errs.merge(name, validPermissions(&perms, permissionPolicy{}))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("Permission presets %s are invalid: %s", path, errs.describe())
+	}
+	return presets, nil
+}
+
+func sortedPresets(presets map[string]chronograf.Permissions) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandPresets adds the permissions of each preset referenced by a role
+// request to its explicit permissions.  Presets are expanded when the role is
+// created or updated; roles are not changed as presets are later redefined.
+func (s *Service) expandPresets(req *sourceRoleRequest) error {
+	if len(req.Presets) == 0 {
+		return nil
+	}
+	var errs validationErrors
+	perms := append(chronograf.Permissions{}, req.Permissions...)
+	for i, name := range req.Presets {
+		preset, ok := s.PermissionPresets[name]
+		if !ok {
+			errs.add(fmt.Sprintf("presets[%d]", i), "Unknown permission preset %s", name)
+			continue
+		}
+		perms = append(perms, preset...)
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	req.Permissions = perms
+	req.Presets = nil
+	return nil
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestNewPermissionPresets(t *testing.T) {
+	tests := []struct {
+		name    string
+		presets string
+		want    map[string]chronograf.Permissions
+		wantErr bool
+	}{
+		{
+			name:    "Presets",
+			presets: `{"db-reader": [{"scope": "database", "name": "telegraf", "allowed": ["READ"]}]}`,
+			want: map[string]chronograf.Permissions{
+				"db-reader": {
+					{
+						Scope:   chronograf.DBScope,
+						Name:    "telegraf",
+						Allowed: chronograf.Allowances{"READ"},
+					},
+				},
+			},
+		},
+		{
+			name:    "Invalid JSON",
+			presets: `{"db-reader":`,
+			wantErr: true,
+		},
+		{
+			name:    "Preset without permissions",
+			presets: `{"db-reader": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid permission",
+			presets: `{"db-reader": [{"scope": "database", "allowed": ["READ"]}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "presets")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			if _, err := f.WriteString(tt.presets); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			got, err := NewPermissionPresets(f.Name())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPermissionPresets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewPermissionPresets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewPermissionPresets("/nonexistent/presets.json"); err == nil {
+		t.Errorf("NewPermissionPresets() of a missing file expected error")
+	}
+}
+
+func TestService_NewSourceRole_Presets(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantPerms chronograf.Permissions
+		wantBody  string
+	}{
+		{
+			name: "Combines presets with permissions",
+			body: `{"name": "operators", "presets": ["db-reader"], "permissions": [{"scope": "all", "allowed": ["ViewChronograf"]}]}`,
+			wantPerms: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ViewChronograf"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "telegraf",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			name: "Unknown preset",
+			body: `{"name": "operators", "presets": ["db-reader", "db-admin"]}`,
+			wantBody: `{"code":422,"message":"Unknown permission preset db-admin","errors":[{"field":"presets[1]","message":"Unknown permission preset db-admin"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added chronograf.Permissions
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return nil, fmt.Errorf("role %s not found", name)
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								added = role.Permissions
+								return role, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+				PermissionPresets: map[string]chronograf.Permissions{
+					"db-reader": {
+						{
+							Scope:   chronograf.DBScope,
+							Name:    "telegraf",
+							Allowed: chronograf.Allowances{"READ"},
+						},
+					},
+				},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.NewSourceRole(w, r)
+
+			if tt.wantBody != "" {
+				body, _ := ioutil.ReadAll(w.Result().Body)
+				if string(body) != tt.wantBody {
+					t.Errorf("NewSourceRole() = %s, want %s", body, tt.wantBody)
+				}
+				return
+			}
+			if !reflect.DeepEqual(added, tt.wantPerms) {
+				t.Errorf("NewSourceRole() added permissions %v, want %v", added, tt.wantPerms)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+type simulateAuthorizationRequest struct {
+	User     string            `json:"user"`
+	Action   string            `json:"action"`             // Action is the allowance the user attempts, e.g. READ or WriteData
+	Database string            `json:"database,omitempty"` // Database the action is on; empty for actions on all databases
+	Roles    []chronograf.Role `json:"roles"`              // Roles are proposed roles replacing the source's roles of the same name
+}
+
+func (r *simulateAuthorizationRequest) Valid() error {
+	var errs validationErrors
+	if r.User == "" {
+		errs.add("user", "User required")
+	}
+	if r.Action == "" {
+		errs.add("action", "Action required")
+	}
+	for i, role := range r.Roles {
+		if role.Name == "" {
+			errs.add(fmt.Sprintf("roles[%d].name", i), "Name is required for a role")
+		}
+	}
+	return errs.err()
+}
+
+// decidingRule is the permission of a role that decided an authorization
+type decidingRule struct {
+	Role       string                `json:"role"`
+	Permission chronograf.Permission `json:"permission"`
+}
+
+type simulateAuthorizationResponse struct {
+	User     string        `json:"user"`
+	Action   string        `json:"action"`
+	Database string        `json:"database,omitempty"`
+	Allowed  bool          `json:"allowed"`
+	Rule     *decidingRule `json:"rule,omitempty"` // Rule is absent if no role of the user has the action
+	Reason   string        `json:"reason"`
+	Roles    []string      `json:"roles"` // Roles are the roles containing the user
+}
+
+// simulateAuthorization decides if user may perform the action on database
+// with roles.  Each role of the user is decided as the source enforces its
+// unexpired permissions, so a deny takes allowances away from the grants of
+// its own role only and the user is allowed if any role allows the action.
+func simulateAuthorization(roles []chronograf.Role, user, action, database string, now time.Time) simulateAuthorizationResponse {
+	res := simulateAuthorizationResponse{
+		User:     user,
+		Action:   action,
+		Database: database,
+		Roles:    []string{},
+	}
+
+	scope := chronograf.AllScope
+	if database != "" {
+		scope = chronograf.DBScope
+	}
+	var denied *decidingRule
+	for i := range roles {
+		if !hasRoleUser(&roles[i], user) {
+			continue
+		}
+		res.Roles = append(res.Roles, roles[i].Name)
+		kept, _ := unexpiredPermissions(roles[i].Permissions, now)
+		allowed, rule := decide(kept, scope, database, action)
+		if rule < 0 {
+			continue
+		}
+		deciding := &decidingRule{
+			Role:       roles[i].Name,
+			Permission: kept[rule],
+		}
+		if allowed && !res.Allowed {
+			res.Allowed, res.Rule = true, deciding
+		} else if !allowed && denied == nil {
+			denied = deciding
+		}
+	}
+
+	switch {
+	case res.Allowed:
+		res.Reason = fmt.Sprintf("Granted by role %s", res.Rule.Role)
+	case denied != nil:
+		res.Rule = denied
+		res.Reason = fmt.Sprintf("Denied by role %s", denied.Role)
+	default:
+		res.Reason = fmt.Sprintf("No role of %s grants %s", user, action)
+	}
+	return res
+}
+
+// SimulateAuthorization decides whether a user would be allowed an action
+// on a database if proposed roles replaced the source's roles of the same
+// name, and returns the deciding rule.  Proposed roles are validated and
+// expanded as if they were posted.  Nothing is changed.
+func (s *Service) SimulateAuthorization(w http.ResponseWriter, r *http.Request) {
+	var req simulateAuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	var errs validationErrors
+	proposed := map[string]chronograf.Role{}
+	for i, role := range req.Roles {
+		field := fmt.Sprintf("roles[%d].permissions", i)
+		if err := s.expandScopeAliases(role.Permissions); err != nil {
+			errs.merge(field, err)
+			continue
+		}
+		if err := validPermissions(&role.Permissions, s.permissionPolicy()); err != nil {
+			errs.merge(field, err)
+			continue
+		}
+		if err := s.expandClassifications(&role.Permissions); err != nil {
+			errs.merge(field, err)
+			continue
+		}
+		proposed[role.Name] = role
+	}
+	if err := errs.err(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+	current, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	roles := make([]chronograf.Role, 0, len(current)+len(proposed))
+	for _, role := range current {
+		if _, ok := proposed[role.Name]; !ok {
+			roles = append(roles, role)
+		}
+	}
+	for _, role := range req.Roles {
+		if p, ok := proposed[role.Name]; ok {
+			// The last of proposed roles of the same name is used
+			roles = append(roles, p)
+			delete(proposed, role.Name)
+		}
+	}
+
+	res := simulateAuthorization(roles, req.User, req.Action, req.Database, time.Now())
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
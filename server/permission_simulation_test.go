@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SimulateAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Granted by a permission of all databases",
+			body:       `{"user": "marty", "action": "READ", "database": "delorean"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"marty","action":"READ","database":"delorean","allowed":true,"rule":{"role":"timetravelers","permission":{"scope":"all","allowed":["READ"]}},"reason":"Granted by role timetravelers","roles":["timetravelers"]}
+`,
+		},
+		{
+			name:       "Denied by a proposed role",
+			body:       `{"user": "marty", "action": "READ", "database": "delorean", "roles": [{"name": "timetravelers", "users": [{"name": "marty"}], "permissions": [{"scope": "database", "name": "delorean", "allowed": ["READ", "WRITE"]}, {"scope": "all", "allowed": ["READ"], "deny": true}]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"marty","action":"READ","database":"delorean","allowed":false,"rule":{"role":"timetravelers","permission":{"scope":"all","allowed":["READ"],"deny":true}},"reason":"Denied by role timetravelers","roles":["timetravelers"]}
+`,
+		},
+		{
+			name:       "Denies of a role do not override the grants of others",
+			body:       `{"user": "marty", "action": "READ", "database": "delorean", "roles": [{"name": "grounded", "users": [{"name": "marty"}], "permissions": [{"scope": "database", "name": "delorean", "allowed": ["READ"], "deny": true}]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"marty","action":"READ","database":"delorean","allowed":true,"rule":{"role":"timetravelers","permission":{"scope":"all","allowed":["READ"]}},"reason":"Granted by role timetravelers","roles":["timetravelers","grounded"]}
+`,
+		},
+		{
+			name:       "ALL implies READ",
+			body:       `{"user": "biff", "action": "READ", "database": "delorean", "roles": [{"name": "biffsgang", "users": [{"name": "biff"}], "permissions": [{"scope": "all", "allowed": ["ALL"]}]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"biff","action":"READ","database":"delorean","allowed":true,"rule":{"role":"biffsgang","permission":{"scope":"all","allowed":["ALL"]}},"reason":"Granted by role biffsgang","roles":["biffsgang"]}
+`,
+		},
+		{
+			name:       "Deny of a database does not narrow a grant of all databases",
+			body:       `{"user": "jennifer", "action": "ReadData", "database": "payroll"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"jennifer","action":"ReadData","database":"payroll","allowed":true,"rule":{"role":"analysts","permission":{"scope":"all","allowed":["ReadData"]}},"reason":"Granted by role analysts","roles":["analysts"]}
+`,
+		},
+		{
+			name:       "Proposed role replaces the role of the source",
+			body:       `{"user": "marty", "action": "WRITE", "database": "delorean", "roles": [{"name": "timetravelers", "users": [{"name": "doc"}]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"marty","action":"WRITE","database":"delorean","allowed":false,"reason":"No role of marty grants WRITE","roles":[]}
+`,
+		},
+		{
+			name:       "Action on all databases",
+			body:       `{"user": "marty", "action": "WRITE"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"marty","action":"WRITE","allowed":false,"reason":"No role of marty grants WRITE","roles":["timetravelers"]}
+`,
+		},
+		{
+			name:       "Invalid proposed role",
+			body:       `{"user": "marty", "action": "READ", "roles": [{"name": "grounded", "permissions": [{"scope": "database", "allowed": ["READ"]}]}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Database scoped permission requires a name","errors":[{"field":"roles[0].permissions[0].name","message":"Database scoped permission requires a name"}]}
+`,
+		},
+		{
+			name:       "Missing action",
+			body:       `{"user": "marty"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Action required","errors":[{"field":"action","message":"Action required"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name:  "timetravelers",
+										Users: []chronograf.User{{Name: "marty"}},
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"READ"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"READ", "WRITE"},
+											},
+										},
+									},
+									{
+										Name:  "biffsgang",
+										Users: []chronograf.User{{Name: "biff"}},
+									},
+									{
+										Name:  "analysts",
+										Users: []chronograf.User{{Name: "jennifer"}},
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"ReadData"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "payroll",
+												Allowed: chronograf.Allowances{"ReadData"},
+												Deny:    true,
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/permissions/simulate", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SimulateAuthorization(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SimulateAuthorization() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SimulateAuthorization() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/influxdata/chronograf"
+)
+
+// permissionUsage is a distinct scope and allowance used by roles
+type permissionUsage struct {
+	Scope     chronograf.Scope `json:"scope"`
+	Allowance string           `json:"allowance"`
+	Roles     int              `json:"roles"` // Roles is the number of roles granting the allowance in the scope
+}
+
+// distinctPermissions counts the roles using each scope and allowance.  The
+// usages are sorted by scope then allowance.
+func distinctPermissions(roles []chronograf.Role) []permissionUsage {
+	type pair struct {
+		scope     chronograf.Scope
+		allowance string
+	}
+	counts := map[pair]int{}
+	for _, role := range roles {
+		used := map[pair]bool{}
+		for _, perm := range role.Permissions {
+			if perm.Deny {
+				continue
+			}
+			for _, a := range perm.Allowed {
+				used[pair{perm.Scope, a}] = true
+			}
+		}
+		for p := range used {
+			counts[p]++
+		}
+	}
+
+	res := make([]permissionUsage, 0, len(counts))
+	for p, n := range counts {
+		res = append(res, permissionUsage{
+			Scope:     p.scope,
+			Allowance: p.allowance,
+			Roles:     n,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Scope != res[j].Scope {
+			return res[i].Scope < res[j].Scope
+		}
+		return res[i].Allowance < res[j].Allowance
+	})
+	return res
+}
+
+// SourceDistinctPermissions lists the distinct scope and allowance pairs
+// used across all roles of a source with the number of roles using each
+func (s *Service) SourceDistinctPermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := struct {
+		Permissions []permissionUsage `json:"permissions"`
+		Links       selfLinks         `json:"links"`
+	}{
+		Permissions: distinctPermissions(roles),
+		Links:       selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/permissions/distinct", srcID)},
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+)
+
+func Test_distinctPermissions(t *testing.T) {
+	roles := []chronograf.Role{
+		{
+			Name: "timetravelers",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"WRITE", "READ"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "hillvalley",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			Name: "admins",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"READ"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			Name: "nobody",
+		},
+	}
+	want := []permissionUsage{
+		{
+			Scope:     chronograf.AllScope,
+			Allowance: "READ",
+			Roles:     1,
+		},
+		{
+			Scope:     chronograf.DBScope,
+			Allowance: "READ",
+			Roles:     2,
+		},
+		{
+			Scope:     chronograf.DBScope,
+			Allowance: "WRITE",
+			Roles:     1,
+		},
+	}
+	if got := distinctPermissions(roles); !reflect.DeepEqual(got, want) {
+		t.Errorf("distinctPermissions() = %v, want %v", got, want)
+	}
+	if got := distinctPermissions(nil); !reflect.DeepEqual(got, []permissionUsage{}) {
+		t.Errorf("distinctPermissions(nil) = %v, want empty", got)
+	}
+}
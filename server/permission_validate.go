@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+type permissionsValidation struct {
+	Valid  bool             `json:"valid"`
+	Errors validationErrors `json:"errors"` // Errors are the problems found with the set, e.g. at permissions[0].name
+}
+
+// sourcePermissions is the payload of the permissions of a source, as
+// listed by Permissions
+type sourcePermissions struct {
+	Permissions *chronograf.Permissions `json:"permissions"`
+}
+
+// validatePermissionSet validates perms as the permissions of a role being
+// created would be validated, reporting each invalid entry
+func (s *Service) validatePermissionSet(perms chronograf.Permissions, policy permissionPolicy) permissionsValidation {
+	var errs validationErrors
+	if err := s.expandScopeAliases(perms); err != nil {
+		errs.merge("", err)
+	} else {
+		errs.merge("permissions", validPermissions(&perms, policy))
+	}
+	if errs == nil {
+		errs = validationErrors{}
+	}
+	return permissionsValidation{
+		Valid:  len(errs) == 0,
+		Errors: errs,
+	}
+}
+
+// ValidatePermissions validates each of an array of permission sets
+// independently, as the permissions of a role being created would be
+// validated, e.g. to lint generated permissions before building roles.
+// Each set has a result in the order of the request.  A single source
+// permissions payload, {"permissions": [...]}, is validated as one set and
+// has a single result.
+func (s *Service) ValidatePermissions(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+
+	policy := s.permissionPolicy()
+	if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		var req sourcePermissions
+		if err := json.Unmarshal(raw, &req); err != nil || req.Permissions == nil {
+			invalidJSON(w, s.Logger)
+			return
+		}
+		encodeJSON(w, http.StatusOK, s.validatePermissionSet(*req.Permissions, policy), s.Logger)
+		return
+	}
+
+	var sets []chronograf.Permissions
+	if err := json.Unmarshal(raw, &sets); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	res := struct {
+		Results []permissionsValidation `json:"results"`
+	}{
+		Results: make([]permissionsValidation, len(sets)),
+	}
+	for i, perms := range sets {
+		res.Results[i] = s.validatePermissionSet(perms, policy)
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
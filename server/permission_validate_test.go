@@ -0,0 +1,80 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/chronograf/log"
+)
+
+func TestService_ValidatePermissions(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Each set is validated independently",
+			body: `[
+				[{"scope": "database", "name": "telegraf", "allowed": ["READ"]}],
+				[{"scope": "database", "allowed": ["READ"]}, {"scope": "cluster", "allowed": ["READ"]}],
+				[{"scope": "database", "name": "_internal", "allowed": ["READ"]}],
+				[{"scope": "database", "alias": "prod-metrics", "allowed": ["READ"]}]
+			]`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"results":[{"valid":true,"errors":[]},{"valid":false,"errors":[{"field":"permissions[0].name","message":"Database scoped permission requires a name"},{"field":"permissions[1].scope","message":"Invalid permission scope"}]},{"valid":false,"errors":[{"field":"permissions[0].name","message":"Database _internal may not be granted to roles"}]},{"valid":true,"errors":[]}]}
+`,
+		},
+		{
+			name: "Source permissions payload",
+			body: `{"permissions": [
+				{"scope": "all", "allowed": ["READ"]},
+				{"scope": "database", "allowed": ["WRITE"]},
+				{"scope": "database", "name": "_internal", "allowed": ["READ"]}
+			]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"valid":false,"errors":[{"field":"permissions[1].name","message":"Database scoped permission requires a name"},{"field":"permissions[2].name","message":"Database _internal may not be granted to roles"}]}
+`,
+		},
+		{
+			name:       "Source permissions payload without permissions",
+			body:       `{"links": {}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":400,"message":"Unparsable JSON"}`,
+		},
+		{
+			name:       "Not an array of sets",
+			body:       `{"scope": "all"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":400,"message":"Unparsable JSON"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Logger:          log.New(log.DebugLevel),
+				ForbiddenScopes: []string{"_internal"},
+				ScopeAliases: map[string]string{
+					"prod-metrics": "telegraf_prod",
+				},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/permissions/validate", strings.NewReader(tt.body))
+			h.ValidatePermissions(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. ValidatePermissions() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. ValidatePermissions() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
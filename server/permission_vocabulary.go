@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+// permissionScope is a scope a source supports with the allowances that may
+// be granted within it
+type permissionScope struct {
+	Scope   chronograf.Scope      `json:"scope"`
+	Allowed chronograf.Allowances `json:"allowed"`
+}
+
+type permissionVocabularyResponse struct {
+	Roles  bool              `json:"roles"` // Roles is true if the source supports roles
+	Scopes []permissionScope `json:"scopes"`
+	Links  map[string]string `json:"links"`
+}
+
+// permissionVocabulary lists the scopes of perms with their allowances in
+// the order the source returns them.  Scopes the source lists more than once
+// are merged.
+func permissionVocabulary(perms chronograf.Permissions) []permissionScope {
+	res := []permissionScope{}
+	index := map[chronograf.Scope]int{}
+	for _, perm := range perms {
+		i, ok := index[perm.Scope]
+		if !ok {
+			i = len(res)
+			index[perm.Scope] = i
+			res = append(res, permissionScope{
+				Scope:   perm.Scope,
+				Allowed: chronograf.Allowances{},
+			})
+		}
+		for _, a := range perm.Allowed {
+			if !hasAllowance(res[i].Allowed, a) {
+				res[i].Allowed = append(res[i].Allowed, a)
+			}
+		}
+	}
+	return res
+}
+
+func hasAllowance(allowed chronograf.Allowances, allowance string) bool {
+	for _, a := range allowed {
+		if a == allowance {
+			return true
+		}
+	}
+	return false
+}
+
+// SourcePermissionVocabulary returns the scopes and allowances the source
+// supports and whether it supports roles, so role editors offer only valid
+// options.  OSS sources support READ and WRITE on databases and ALL on all
+// databases, but not roles; Enterprise sources support roles and their
+// finer grained allowances.
+func (s *Service) SourcePermissionVocabulary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	_, roles := s.hasRoles(ctx, srcID, ts)
+	httpAPISrcs := "/chronograf/v1/sources"
+	res := permissionVocabularyResponse{
+		Roles:  roles,
+		Scopes: permissionVocabulary(ts.Permissions(ctx)),
+		Links: map[string]string{
+			"self":   fmt.Sprintf("%s/%d/permissions/vocabulary", httpAPISrcs, srcID),
+			"source": fmt.Sprintf("%s/%d", httpAPISrcs, srcID),
+		},
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
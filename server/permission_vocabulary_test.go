@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourcePermissionVocabulary(t *testing.T) {
+	tests := []struct {
+		name       string
+		perms      chronograf.Permissions
+		roles      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "OSS source",
+			perms: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ALL"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Allowed: chronograf.Allowances{"WRITE", "READ"},
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":false,"scopes":[{"scope":"all","allowed":["ALL"]},{"scope":"database","allowed":["WRITE","READ"]}],"links":{"self":"/chronograf/v1/sources/1/permissions/vocabulary","source":"/chronograf/v1/sources/1"}}
+`,
+		},
+		{
+			name: "Enterprise source listing a scope twice",
+			perms: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ViewChronograf", "ReadData"},
+				},
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ReadData", "Monitor"},
+				},
+			},
+			roles:      true,
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":true,"scopes":[{"scope":"all","allowed":["ViewChronograf","ReadData","Monitor"]}],"links":{"self":"/chronograf/v1/sources/1/permissions/vocabulary","source":"/chronograf/v1/sources/1"}}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					PermissionsF: func(ctx context.Context) chronograf.Permissions {
+						return tt.perms
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						if !tt.roles {
+							return nil, fmt.Errorf("roles not supported")
+						}
+						return &mocks.RolesStore{}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/permissions/vocabulary", nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourcePermissionVocabulary(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourcePermissionVocabulary() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourcePermissionVocabulary() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"path"
+	"regexp"
+	"unicode/utf8"
 
 	"github.com/influxdata/chronograf"
 )
@@ -54,17 +57,197 @@ func (s *Service) Permissions(w http.ResponseWriter, r *http.Request) {
 	encodeJSON(w, http.StatusOK, res, s.Logger)
 }
 
-func validPermissions(perms *chronograf.Permissions) error {
+// maxPermissionNote is the longest note of a permission
+const maxPermissionNote = 1024
+
+// PermissionValidator enforces an operator's own policy on the permissions
+// of source roles, e.g. a team's naming or least privilege rules.
+type PermissionValidator interface {
+	// ValidPermissions returns why perms violate the policy, or nil
+	ValidPermissions(perms chronograf.Permissions) error
+}
+
+// PermissionValidatorFunc is an adapter to use a function as a PermissionValidator
+type PermissionValidatorFunc func(chronograf.Permissions) error
+
+// ValidPermissions calls f(perms)
+func (f PermissionValidatorFunc) ValidPermissions(perms chronograf.Permissions) error {
+	return f(perms)
+}
+
+// permissionPolicy are the rules of the operator permissions are validated
+// against in addition to their syntax
+type permissionPolicy struct {
+	forbidden  []string              // forbidden are database patterns (path.Match syntax) that may not be granted
+	validators []PermissionValidator // validators are run in order after the built-in checks
+	strict     bool                  // strict fails validation of contradicting permissions rather than warning of them
+	duties     SeparationOfDuties    // duties are the separation of duties rules permissions may not break
+	scopeNames *regexp.Regexp        // scopeNames is the naming convention of the databases of permissions; nil allows any name
+}
+
+// NewScopeNamePattern compiles the naming convention the databases of the
+// permissions of source roles must follow, e.g. ^(sre|web)_ for databases
+// prefixed by their team
+func NewScopeNamePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid scope name pattern: %v", err)
+	}
+	return re, nil
+}
+
+// permissionPolicy returns the policy for the permissions of source roles
+func (s *Service) permissionPolicy() permissionPolicy {
+	return permissionPolicy{
+		forbidden:  s.ForbiddenScopes,
+		validators: s.PermissionValidators,
+		strict:     s.StrictPermissions,
+		duties:     s.SeparationOfDuties,
+		scopeNames: s.ScopeNamePattern,
+	}
+}
+
+// validPermissions checks the syntax of perms then the operator's policy.
+// Grants and denies are validated alike, except only grants may not be
+// scoped to a forbidden database.  The naming convention of the policy
+// applies to database names whether or not the databases exist.  Sources
+// cannot take allowances of one database away from grants of all
+// databases, so such denies are rejected.  The problems found by every
+// validator of the policy are returned together.
+func validPermissions(perms *chronograf.Permissions, policy permissionPolicy) error {
 	if perms == nil {
 		return nil
 	}
-	for _, perm := range *perms {
+	var errs validationErrors
+	for i, perm := range *perms {
 		if perm.Scope != chronograf.AllScope && perm.Scope != chronograf.DBScope {
-			return fmt.Errorf("Invalid permission scope")
+			errs.add(fmt.Sprintf("[%d].scope", i), "Invalid permission scope")
+		}
+		if perm.Scope == chronograf.DBScope && perm.Name == "" && perm.Classification == "" {
+			errs.add(fmt.Sprintf("[%d].name", i), "Database scoped permission requires a name")
+		}
+		if perm.Classification != "" && (perm.Scope != chronograf.DBScope || perm.Name != "") {
+			errs.add(fmt.Sprintf("[%d].classification", i), "Classified permission must be database scoped without a name")
+		}
+		if perm.Scope == chronograf.DBScope && !perm.Deny && forbiddenDatabase(perm.Name, policy.forbidden) {
+			errs.add(fmt.Sprintf("[%d].name", i), "Database %s may not be granted to roles", perm.Name)
+		}
+		if perm.Scope == chronograf.DBScope && perm.Name != "" && policy.scopeNames != nil && !policy.scopeNames.MatchString(perm.Name) {
+			errs.add(fmt.Sprintf("[%d].name", i), "Database %s does not follow the naming convention %s", perm.Name, policy.scopeNames)
+		}
+		if perm.Deny && perm.Scope == chronograf.DBScope && grantsAllDatabases(*perms, perm.Allowed) {
+			errs.add(fmt.Sprintf("[%d].deny", i), "Deny of database %s cannot be enforced under a grant of all databases", perm.Name)
+		}
+		if utf8.RuneCountInString(perm.Note) > maxPermissionNote {
+			errs.add(fmt.Sprintf("[%d].note", i), "Note must be at most %d characters", maxPermissionNote)
+		}
+	}
+	if policy.strict {
+		for _, c := range permissionContradictions(*perms) {
+			errs.add(fmt.Sprintf("[%d]", c.index), c.message)
+		}
+	}
+	errs.merge("", policy.duties.ValidPermissions(*perms))
+	for _, v := range policy.validators {
+		errs.merge("", v.ValidPermissions(*perms))
+	}
+	return errs.err()
+}
+
+// grantsAllDatabases checks if a grant of perms scoped to all databases
+// has any of the allowances
+func grantsAllDatabases(perms chronograf.Permissions, allowances chronograf.Allowances) bool {
+	for _, perm := range perms {
+		if perm.Deny || perm.Scope != chronograf.AllScope {
+			continue
+		}
+		for _, a := range allowances {
+			if hasAllowance(perm.Allowed, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func forbiddenDatabase(name string, forbidden []string) bool {
+	for _, pattern := range forbidden {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// grants checks if perms allow the allowance within the scope of a
+// database name as sources enforce them.  Permissions scoped to all
+// databases grant the allowance to every database.  A deny of the allowance
+// overrides the grants it applies to.
+func grants(perms chronograf.Permissions, scope chronograf.Scope, name, allowance string) bool {
+	granted, _ := decide(perms, scope, name, allowance)
+	return granted
+}
+
+// decide is grants returning the index of the deciding permission as well:
+// the first grant still allowing the allowance, otherwise the first deny
+// taking it away, or -1 if no permission grants it.  Permissions are the
+// permissions of one user or role and are decided as sources enforce them:
+// ALL implies READ and WRITE as InfluxQL grants it, and a deny takes its
+// allowances away from the grants of its database, or of every database if
+// it is scoped to all databases, as Enterprise does.  A deny of a database
+// does not narrow grants of all databases.
+func decide(perms chronograf.Permissions, scope chronograf.Scope, name, allowance string) (granted bool, rule int) {
+	rule = -1
+	for i, grant := range perms {
+		if grant.Deny || !appliesTo(grant, scope, name) || !allows(grant.Allowed, allowance) {
+			continue
 		}
-		if perm.Scope == chronograf.DBScope && perm.Name == "" {
-			return fmt.Errorf("Database scoped permission requires a name")
+		allowed, denied := grant.Allowed, -1
+		for j, deny := range perms {
+			if !deny.Deny || !narrows(deny, grant) {
+				continue
+			}
+			allowed = withoutAllowances(allowed, deny.Allowed)
+			if denied < 0 && !allows(allowed, allowance) {
+				denied = j
+			}
+		}
+		if denied < 0 {
+			return true, i
+		}
+		if rule < 0 {
+			rule = denied
+		}
+	}
+	return false, rule
+}
+
+// appliesTo is true if perm applies within the scope of a database name
+func appliesTo(perm chronograf.Permission, scope chronograf.Scope, name string) bool {
+	return perm.Scope == chronograf.AllScope || (perm.Scope == scope && perm.Name == name)
+}
+
+// narrows is true if sources take the allowances of deny away from grant
+func narrows(deny, grant chronograf.Permission) bool {
+	return deny.Scope == chronograf.AllScope ||
+		(grant.Scope == chronograf.DBScope && deny.Scope == chronograf.DBScope && deny.Name == grant.Name)
+}
+
+// allows checks if allowed has the allowance, or ALL for READ and WRITE
+func allows(allowed chronograf.Allowances, allowance string) bool {
+	if hasAllowance(allowed, allowance) {
+		return true
+	}
+	return (allowance == "READ" || allowance == "WRITE") && hasAllowance(allowed, "ALL")
+}
+
+// withoutAllowances returns the allowances of allowed not denied
+func withoutAllowances(allowed, denied chronograf.Allowances) chronograf.Allowances {
+	res := chronograf.Allowances{}
+	for _, a := range allowed {
+		if !hasAllowance(denied, a) {
+			res = append(res, a)
 		}
 	}
-	return nil
+	return res
 }
@@ -3,9 +3,12 @@ package server
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/bouk/httprouter"
@@ -112,3 +115,292 @@ func TestService_Permissions(t *testing.T) {
 		}
 	}
 }
+
+func Test_validPermissions_Note(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ViewChronograf"},
+			Note:    "Operators need to view dashboards",
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData"},
+			Note:    strings.Repeat("a", maxPermissionNote+1),
+		},
+	}
+	want := validationErrors{
+		{Field: "[1].note", Message: "Note must be at most 1024 characters"},
+	}
+	if err := validPermissions(&perms, permissionPolicy{}); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+
+	perms[1].Note = strings.Repeat("a", maxPermissionNote)
+	if err := validPermissions(&perms, permissionPolicy{}); err != nil {
+		t.Errorf("validPermissions() = %v, want nil", err)
+	}
+
+	// Notes are limited in characters rather than bytes
+	perms[1].Note = strings.Repeat("é", maxPermissionNote)
+	if err := validPermissions(&perms, permissionPolicy{}); err != nil {
+		t.Errorf("validPermissions() = %v, want nil", err)
+	}
+}
+
+func Test_validPermissions_Forbidden(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ViewChronograf"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "_internal",
+			Allowed: chronograf.Allowances{"ReadData"},
+		},
+	}
+	if err := validPermissions(&perms, permissionPolicy{}); err != nil {
+		t.Errorf("validPermissions() = %v, want nil", err)
+	}
+
+	want := validationErrors{
+		{Field: "[2].name", Message: "Database _internal may not be granted to roles"},
+	}
+	if err := validPermissions(&perms, permissionPolicy{forbidden: []string{"_*", "monitor"}}); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+
+	perms[2].Name = "a%db"
+	want = validationErrors{
+		{Field: "[2].name", Message: "Database a%db may not be granted to roles"},
+	}
+	if err := validPermissions(&perms, permissionPolicy{forbidden: []string{"a*"}}); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+}
+
+func Test_validPermissions_Denies(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ReadData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "payroll",
+			Allowed: chronograf.Allowances{"WriteData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "payroll",
+			Allowed: chronograf.Allowances{"WriteData"},
+			Deny:    true,
+		},
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"DropDatabase"},
+			Deny:    true,
+		},
+	}
+	if err := validPermissions(&perms, permissionPolicy{}); err != nil {
+		t.Errorf("validPermissions() = %v, want nil", err)
+	}
+
+	perms[2].Allowed = chronograf.Allowances{"ReadData"}
+	want := validationErrors{
+		{Field: "[2].deny", Message: "Deny of database payroll cannot be enforced under a grant of all databases"},
+	}
+	if err := validPermissions(&perms, permissionPolicy{}); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+}
+
+func Test_validPermissions_ScopeNames(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ViewChronograf"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "sre_telegraf",
+			Allowed: chronograf.Allowances{"ReadData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "payroll",
+			Allowed: chronograf.Allowances{"ReadData"},
+			Deny:    true,
+		},
+		{
+			Scope:          chronograf.DBScope,
+			Classification: "pii",
+			Allowed:        chronograf.Allowances{"ReadData"},
+		},
+	}
+	if err := validPermissions(&perms, permissionPolicy{}); err != nil {
+		t.Errorf("validPermissions() = %v, want nil", err)
+	}
+
+	scopeNames, err := NewScopeNamePattern("^(sre|web)_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := validationErrors{
+		{Field: "[2].name", Message: "Database telegraf does not follow the naming convention ^(sre|web)_"},
+		{Field: "[3].name", Message: "Database payroll does not follow the naming convention ^(sre|web)_"},
+	}
+	if err := validPermissions(&perms, permissionPolicy{scopeNames: scopeNames}); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+
+	if _, err := NewScopeNamePattern("^(sre"); err == nil {
+		t.Errorf("NewScopeNamePattern() = nil, want error")
+	}
+}
+
+func Test_validPermissions_Validators(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ALL"},
+		},
+		{
+			Scope: "cluster",
+		},
+	}
+	policy := permissionPolicy{
+		validators: []PermissionValidator{
+			PermissionValidatorFunc(func(perms chronograf.Permissions) error {
+				for _, perm := range perms {
+					if perm.Scope == chronograf.AllScope {
+						return fmt.Errorf("Permissions on all databases require a change ticket")
+					}
+				}
+				return nil
+			}),
+			PermissionValidatorFunc(func(perms chronograf.Permissions) error {
+				return nil
+			}),
+			PermissionValidatorFunc(func(perms chronograf.Permissions) error {
+				return fmt.Errorf("Roles may have at most 1 permission")
+			}),
+		},
+	}
+	want := validationErrors{
+		{Field: "[1].scope", Message: "Invalid permission scope"},
+		{Field: "", Message: "Permissions on all databases require a change ticket"},
+		{Field: "", Message: "Roles may have at most 1 permission"},
+	}
+	if err := validPermissions(&perms, policy); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+}
+
+func Test_validPermissions_Strict(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "_internal",
+			Allowed: chronograf.Allowances{"WriteData", "ManageShard"},
+			Deny:    true,
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+		},
+	}
+	// Denying a database what all databases are granted is never enforceable
+	unenforceable := fieldError{Field: "[2].deny", Message: "Deny of database _internal cannot be enforced under a grant of all databases"}
+	if err := validPermissions(&perms, permissionPolicy{}); !reflect.DeepEqual(err, validationErrors{unenforceable}) {
+		t.Errorf("validPermissions() = %v, want %v", err, unenforceable)
+	}
+
+	want := validationErrors{
+		unenforceable,
+		{Field: "[2]", Message: "Permissions 0 and 2 grant WriteData on all databases and deny them on database _internal"},
+		{Field: "[3]", Message: "Permissions 1 and 3 grant different allowances on database telegraf"},
+	}
+	if err := validPermissions(&perms, permissionPolicy{strict: true}); !reflect.DeepEqual(err, want) {
+		t.Errorf("validPermissions() = %v, want %v", err, want)
+	}
+}
+
+func Test_grants(t *testing.T) {
+	tests := []struct {
+		name      string
+		perms     chronograf.Permissions
+		database  string
+		allowance string
+		want      bool
+	}{
+		{
+			name:      "ALL implies READ",
+			perms:     chronograf.Permissions{{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ALL"}}},
+			database:  "telegraf",
+			allowance: "READ",
+			want:      true,
+		},
+		{
+			name:      "ALL does not imply other allowances",
+			perms:     chronograf.Permissions{{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ALL"}}},
+			database:  "telegraf",
+			allowance: "DropDatabase",
+		},
+		{
+			name: "Deny of a database narrows grants of the database",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData"}},
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData"}, Deny: true},
+			},
+			database:  "payroll",
+			allowance: "ReadData",
+		},
+		{
+			name: "Deny of a database does not narrow grants of all databases",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ReadData"}},
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData"}, Deny: true},
+			},
+			database:  "payroll",
+			allowance: "ReadData",
+			want:      true,
+		},
+		{
+			name: "Deny of all databases narrows every grant",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ReadData"}, Deny: true},
+			},
+			database:  "payroll",
+			allowance: "ReadData",
+		},
+	}
+	for _, tt := range tests {
+		if got := grants(tt.perms, chronograf.DBScope, tt.database, tt.allowance); got != tt.want {
+			t.Errorf("%q. grants() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
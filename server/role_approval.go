@@ -0,0 +1,173 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+const (
+	// RolePending is the status of a role change awaiting approval
+	RolePending = "pending"
+	// RoleApproved is the status of a role change applied to the source
+	RoleApproved = "approved"
+	// RoleRejected is the status of a role change that was discarded
+	RoleRejected = "rejected"
+)
+
+// RoleApprovals holds the role changes of sources awaiting approval.
+// Pending changes are kept in memory and are lost when the server restarts.
+type RoleApprovals struct {
+	mu      sync.Mutex
+	changes map[roleChangeKey]roleChange
+}
+
+// NewRoleApprovals creates an empty set of pending role changes
+func NewRoleApprovals() *RoleApprovals {
+	return &RoleApprovals{
+		changes: map[roleChangeKey]roleChange{},
+	}
+}
+
+type roleChangeKey struct {
+	source int
+	role   string
+}
+
+// roleChange is a proposed role creation or update of a source
+type roleChange struct {
+	role   chronograf.Role
+	create bool
+}
+
+// pending returns the pending change of a role if there is one
+func (a *RoleApprovals) pending(srcID int, name string) (roleChange, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c, ok := a.changes[roleChangeKey{srcID, name}]
+	return c, ok
+}
+
+// propose records a change of a role replacing any earlier pending change.
+// An update to a role pending creation amends the creation.
+func (a *RoleApprovals) propose(srcID int, c roleChange) roleChange {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := roleChangeKey{srcID, c.role.Name}
+	if prev, ok := a.changes[key]; ok && prev.create && !c.create {
+		if c.role.Permissions != nil {
+			prev.role.Permissions = c.role.Permissions
+		}
+		if c.role.Users != nil {
+			prev.role.Users = c.role.Users
+		}
+		c = prev
+	}
+	a.changes[key] = c
+	return c
+}
+
+// take removes and returns the pending change of a role
+func (a *RoleApprovals) take(srcID int, name string) (roleChange, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := roleChangeKey{srcID, name}
+	c, ok := a.changes[key]
+	delete(a.changes, key)
+	return c, ok
+}
+
+// proposeSourceRole records a role change for approval instead of applying
+// it to the source.  The response is the role as it would be once approved.
+func (s *Service) proposeSourceRole(w http.ResponseWriter, r *http.Request, srcID int, current *chronograf.Role, c roleChange) {
+	c = s.RoleApprovals.propose(srcID, c)
+
+	proposed := c.role
+	if current != nil && !c.create {
+		proposed = updatedRole(current, proposed)
+	}
+
+	rr := newSourceRoleResponse(srcID, &proposed)
+	rr.Status = RolePending
+	location(w, rr.Links.Self)
+	s.encodeSourceRole(w, r, http.StatusAccepted, rr)
+}
+
+// ApproveSourceRole applies the pending change of a role to the source
+func (s *Service) ApproveSourceRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	c, ok := s.pendingRoleChange(w, srcID, rid)
+	if !ok {
+		return
+	}
+
+	if c.create {
+		_, err = roles.Add(ctx, &c.role)
+	} else {
+		err = roles.Update(ctx, &c.role)
+	}
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	s.RoleApprovals.take(srcID, rid)
+
+	role, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	rr := newSourceRoleResponse(srcID, role)
+	rr.Status = RoleApproved
+	s.encodeSourceRole(w, r, http.StatusOK, rr)
+}
+
+// RejectSourceRole discards the pending change of a role
+func (s *Service) RejectSourceRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	c, ok := s.pendingRoleChange(w, srcID, rid)
+	if !ok {
+		return
+	}
+	s.RoleApprovals.take(srcID, rid)
+
+	rr := newSourceRoleResponse(srcID, &c.role)
+	rr.Status = RoleRejected
+	s.encodeSourceRole(w, r, http.StatusOK, rr)
+}
+
+// pendingRoleChange writes an error if the role has no change awaiting approval
+func (s *Service) pendingRoleChange(w http.ResponseWriter, srcID int, name string) (roleChange, bool) {
+	if s.RoleApprovals == nil {
+		Error(w, http.StatusNotFound, "Role approval is not enabled", s.Logger)
+		return roleChange{}, false
+	}
+	c, ok := s.RoleApprovals.pending(srcID, name)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Role %s has no pending change", name), s.Logger)
+		return roleChange{}, false
+	}
+	return c, true
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_RoleApproval(t *testing.T) {
+	// backend is the role state of the source
+	backend := map[string]chronograf.Role{
+		"biffsgang": {
+			Name: "biffsgang",
+			Users: []chronograf.User{
+				{
+					Name: "match",
+				},
+			},
+		},
+	}
+	h := &Service{
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					return chronograf.Source{
+						ID: 1,
+					}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+						role, ok := backend[name]
+						if !ok {
+							return nil, fmt.Errorf("role %s not found", name)
+						}
+						return &role, nil
+					},
+					AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+						backend[role.Name] = *role
+						return role, nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						prev := backend[role.Name]
+						if role.Permissions != nil {
+							prev.Permissions = role.Permissions
+						}
+						if role.Users != nil {
+							prev.Users = role.Users
+						}
+						backend[role.Name] = prev
+						return nil
+					},
+				}, nil
+			},
+		},
+		Logger:        log.New(log.DebugLevel),
+		RoleApprovals: NewRoleApprovals(),
+	}
+
+	steps := []struct {
+		name       string
+		handler    http.HandlerFunc
+		method     string
+		role       string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantRoles  []string
+	}{
+		{
+			name:       "Creating a role is pending",
+			handler:    h.NewSourceRole,
+			method:     "POST",
+			body:       `{"name":"timetravelers","users":[{"name":"marty"}]}`,
+			wantStatus: http.StatusAccepted,
+			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"}],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"status":"pending"}
+`,
+			wantRoles: []string{"biffsgang"},
+		},
+		{
+			name:       "Approving the creation adds the role",
+			handler:    h.ApproveSourceRole,
+			method:     "POST",
+			role:       "timetravelers",
+			wantStatus: http.StatusOK,
+			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"}],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"status":"approved"}
+`,
+			wantRoles: []string{"biffsgang", "timetravelers"},
+		},
+		{
+			name:       "Updating a role is pending",
+			handler:    h.UpdateSourceRole,
+			method:     "PATCH",
+			role:       "biffsgang",
+			body:       `{"users":[{"name":"skinhead"}]}`,
+			wantStatus: http.StatusAccepted,
+			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"}],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"},"status":"pending"}
+`,
+			wantRoles: []string{"biffsgang", "timetravelers"},
+		},
+		{
+			name:       "Rejecting the update discards it",
+			handler:    h.RejectSourceRole,
+			method:     "POST",
+			role:       "biffsgang",
+			wantStatus: http.StatusOK,
+			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"}],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"},"status":"rejected"}
+`,
+			wantRoles: []string{"biffsgang", "timetravelers"},
+		},
+		{
+			name:       "Approving without a pending change",
+			handler:    h.ApproveSourceRole,
+			method:     "POST",
+			role:       "biffsgang",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":404,"message":"Role biffsgang has no pending change"}`,
+			wantRoles:  []string{"biffsgang", "timetravelers"},
+		},
+	}
+	for _, tt := range steps {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tt.method, "http://server.local/chronograf/v1/sources/1/roles", bytes.NewBufferString(tt.body))
+		r = r.WithContext(httprouter.WithParams(
+			context.Background(),
+			httprouter.Params{
+				{
+					Key:   "id",
+					Value: "1",
+				},
+				{
+					Key:   "rid",
+					Value: tt.role,
+				},
+			}))
+
+		tt.handler(w, r)
+
+		resp := w.Result()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%q. status = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("%q. body = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+		}
+
+		roles := []string{}
+		for _, name := range []string{"biffsgang", "timetravelers"} {
+			if _, ok := backend[name]; ok {
+				roles = append(roles, name)
+			}
+		}
+		if !reflect.DeepEqual(roles, tt.wantRoles) {
+			t.Errorf("%q. source roles = %v, want %v", tt.name, roles, tt.wantRoles)
+		}
+	}
+	if users := backend["biffsgang"].Users; len(users) != 1 || users[0].Name != "match" {
+		t.Errorf("rejected update was applied to the source: %v", users)
+	}
+}
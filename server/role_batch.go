@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+// maxBatchRoles limits the roles fetched by a single batch request
+const maxBatchRoles = 100
+
+// sourceRolesByID retrieves the roles named by the rid query parameters in
+// the order requested.  Roles that do not exist are listed in notFound
+// rather than failing the request.
+func (s *Service) sourceRolesByID(w http.ResponseWriter, r *http.Request, q sourceRolesQuery, ids []string) {
+	if len(ids) > maxBatchRoles {
+		Error(w, http.StatusUnprocessableEntity, fmt.Sprintf("At most %d roles may be fetched at once", maxBatchRoles), s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rr := []sourceRoleResponse{}
+	notFound := []string{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var role *chronograf.Role
+		if q.IncludeSystem || !s.isProtectedRole(id) {
+			role, err = store.Get(ctx, id)
+		}
+		if role == nil || err != nil {
+			notFound = append(notFound, id)
+			continue
+		}
+		rr = append(rr, newSourceRoleResponse(srcID, role))
+	}
+
+	res := struct {
+		Roles    interface{} `json:"roles"`
+		NotFound []string    `json:"notFound"`
+	}{
+		Roles:    s.sourceRolesListing(r, rr),
+		NotFound: notFound,
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceRoles_ByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantBody string
+	}{
+		{
+			name:  "Found and missing roles",
+			query: "?rid=biffsgang&rid=timetravelers&rid=biffsgang",
+			wantBody: `{"roles":[{"users":[],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}],"notFound":["timetravelers"]}
+`,
+		},
+		{
+			name:  "Protected role",
+			query: "?rid=_admin",
+			wantBody: `{"roles":[],"notFound":["_admin"]}
+`,
+		},
+		{
+			name:  "Protected role included",
+			query: "?rid=_admin&includeSystem=true",
+			wantBody: `{"roles":[{"users":[],"name":"_admin","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/_admin"}}],"notFound":[]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								if name == "timetravelers" {
+									return nil, fmt.Errorf("role %s not found", name)
+								}
+								return &chronograf.Role{Name: name}, nil
+							},
+						}, nil
+					},
+				},
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.SourceRoles(w, r)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("SourceRoles() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
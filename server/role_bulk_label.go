@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+// roleLabelRequest is the label applied to every matching role
+type roleLabelRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (r *roleLabelRequest) Valid() error {
+	var errs validationErrors
+	validLabels(map[string]string{r.Key: r.Value}, &errs)
+	return errs.err()
+}
+
+type roleLabelResponse struct {
+	Key       string        `json:"key"`
+	Value     string        `json:"value"`
+	Affected  int           `json:"affected"`  // Affected is the number of roles the label was applied to
+	Updated   []string      `json:"updated"`   // Updated are the roles the label was applied to
+	Unchanged []string      `json:"unchanged"` // Unchanged are the matching roles that already had the label
+	Failed    []roleFailure `json:"failed"`
+}
+
+// LabelSourceRoles applies a label to every role of a source matching the
+// filters of the query, which are those of listing roles, e.g.
+// prefix=app- or database=telegraf.  Roles already having the label are
+// left alone, so applying a label again changes nothing.  A label of the
+// same key with another value is replaced.
+func (s *Service) LabelSourceRoles(w http.ResponseWriter, r *http.Request) {
+	if s.RoleLabels == nil {
+		Error(w, http.StatusNotFound, "Role labels are not enabled", s.Logger)
+		return
+	}
+
+	q, err := validSourceRolesQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+
+	var req roleLabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := roleLabelResponse{
+		Key:       req.Key,
+		Value:     req.Value,
+		Updated:   []string{},
+		Unchanged: []string{},
+		Failed:    []roleFailure{},
+	}
+	for _, role := range roles {
+		if !q.IncludeSystem && s.isProtectedRole(role.Name) {
+			continue
+		}
+		// The database filter trims the permissions of the role it is
+		// given, so a copy is matched
+		match := role
+		if !q.matches(&match) || !q.scopeToDatabase(&match) {
+			continue
+		}
+		if q.ModifiedSince != nil && !s.modifiedSince(srcID, role.Name, *q.ModifiedSince) {
+			continue
+		}
+		if v, ok := role.Labels[req.Key]; ok && v == req.Value {
+			res.Unchanged = append(res.Unchanged, role.Name)
+			continue
+		}
+
+		labels := make(map[string]string, len(role.Labels)+1)
+		for k, v := range role.Labels {
+			labels[k] = v
+		}
+		labels[req.Key] = req.Value
+		var errs validationErrors
+		if validLabels(labels, &errs); len(errs) > 0 {
+			res.Failed = append(res.Failed, roleFailure{
+				Role:    role.Name,
+				Message: errs.Error(),
+			})
+			continue
+		}
+		if err := store.Update(ctx, &chronograf.Role{Name: role.Name, Labels: labels}); err != nil {
+			res.Failed = append(res.Failed, roleFailure{
+				Role:    role.Name,
+				Message: fmt.Sprintf("Unable to label role: %v", err),
+			})
+			continue
+		}
+		res.Updated = append(res.Updated, role.Name)
+	}
+	res.Affected = len(res.Updated)
+
+	status := http.StatusOK
+	if len(res.Failed) > 0 {
+		status = http.StatusBadRequest
+	}
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("label", req.Key).
+		WithField("updated", len(res.Updated)).
+		WithField("failed", len(res.Failed)).
+		Info("Labeled roles")
+	encodeJSON(w, status, res, s.Logger)
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_LabelSourceRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		noLabels   bool
+		wantStatus int
+		wantBody   string
+		wantPuts   []string
+	}{
+		{
+			name:       "Matching roles are labeled",
+			query:      "?prefix=app-",
+			body:       `{"key": "team", "value": "ops"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"key":"team","value":"ops","affected":1,"updated":["app-db"],"unchanged":["app-web"],"failed":[]}
+`,
+			wantPuts: []string{"app-db env=prod,team=ops"},
+		},
+		{
+			name:       "Roles with permissions of a database are labeled",
+			query:      "?database=delorean",
+			body:       `{"key": "team", "value": "time"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"key":"team","value":"time","affected":1,"updated":["biffsgang"],"unchanged":[],"failed":[]}
+`,
+			wantPuts: []string{"biffsgang team=time"},
+		},
+		{
+			name:       "Invalid label",
+			body:       `{"key": "", "value": "ops"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Label key \"\" must be 1 to 63 characters","errors":[{"field":"labels","message":"Label key \"\" must be 1 to 63 characters"}]}
+`,
+			wantPuts: []string{},
+		},
+		{
+			name:       "Labels disabled",
+			body:       `{"key": "team", "value": "ops"}`,
+			noLabels:   true,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":404,"message":"Role labels are not enabled"}`,
+			wantPuts:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puts := []string{}
+			labels := map[string]map[string]string{
+				"app-web": {"team": "ops"},
+				"app-db":  {"env": "prod"},
+			}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{Name: "app-web"},
+									{Name: "app-db"},
+									{
+										Name: "biffsgang",
+										Permissions: chronograf.Permissions{
+											{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ"}},
+										},
+									},
+								}, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								if role.Users != nil || role.Permissions != nil {
+									return fmt.Errorf("labeling %s changed its users or permissions", role.Name)
+								}
+								return nil
+							},
+						}, nil
+					},
+				},
+				RoleLabels: &mocks.RoleLabelsStore{
+					AllF: func(ctx context.Context, srcID int) (map[string]map[string]string, error) {
+						return labels, nil
+					},
+					PutF: func(ctx context.Context, srcID int, role string, l map[string]string) error {
+						pairs := []string{}
+						for k, v := range l {
+							pairs = append(pairs, k+"="+v)
+						}
+						sort.Strings(pairs)
+						puts = append(puts, role+" "+strings.Join(pairs, ","))
+						return nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+			if tt.noLabels {
+				h.RoleLabels = nil
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-label"+tt.query, strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.LabelSourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. LabelSourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. LabelSourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if !reflect.DeepEqual(puts, tt.wantPuts) {
+				t.Errorf("%q. LabelSourceRoles() puts = %v, want %v", tt.name, puts, tt.wantPuts)
+			}
+		})
+	}
+}
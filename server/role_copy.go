@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// How roles copied to a source that already has them are handled
+const (
+	copySkip      = "skip"      // copySkip leaves the target's role as it is
+	copyOverwrite = "overwrite" // copyOverwrite replaces the target's role with the copy
+	copyFail      = "fail"      // copyFail copies nothing if any role exists in the target
+)
+
+type copyRolesRequest struct {
+	Target     int    `json:"target"`     // Target is the ID of the source the roles are copied to
+	Users      bool   `json:"users"`      // Users copies the users of the roles as well as their permissions
+	OnConflict string `json:"onConflict"` // OnConflict is how roles the target already has are handled; defaults to skip
+}
+
+func (r *copyRolesRequest) Valid() error {
+	var errs validationErrors
+	if r.Target == 0 {
+		errs.add("target", "Target source required")
+	}
+	switch r.OnConflict {
+	case "":
+		r.OnConflict = copySkip
+	case copySkip, copyOverwrite, copyFail:
+	default:
+		errs.add("onConflict", "onConflict must be one of %s", strings.Join([]string{copySkip, copyOverwrite, copyFail}, ", "))
+	}
+	return errs.err()
+}
+
+type copyRolesResponse struct {
+	Copied      []string `json:"copied"`      // Copied are the roles created in the target
+	Overwritten []string `json:"overwritten"` // Overwritten are the roles of the target replaced by their copy
+	Skipped     []string `json:"skipped"`     // Skipped are the roles the target already had
+	Pending     bool     `json:"pending"`     // Pending is true if the copies await approval
+}
+
+// CopySourceRoles copies the roles of a source to a target source.  Each
+// role is validated and created as if it were posted to the target.
+// Protected system roles are not copied.
+func (s *Service) CopySourceRoles(w http.ResponseWriter, r *http.Request) {
+	var req copyRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	from, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	targetTS, err := s.connectSource(ctx, w, req.Target)
+	if err != nil {
+		return
+	}
+	to, ok := s.hasRoles(ctx, req.Target, targetTS)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", req.Target), s.Logger)
+		return
+	}
+
+	all, err := from.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	// Validate every copy and find the conflicts before changing the target
+	copies := []sourceRoleRequest{}
+	conflicts := map[string]string{}
+	var errs validationErrors
+	for _, role := range all {
+		if s.isProtectedRole(role.Name) {
+			continue
+		}
+		rr := s.newSourceRoleRequest()
+		rr.Role = chronograf.Role{
+			Name:        role.Name,
+			Permissions: role.Permissions,
+		}
+		if req.Users {
+			rr.Users = role.Users
+		}
+		if err := rr.ValidCreate(); err != nil {
+			errs.merge(fmt.Sprintf("roles.%s", role.Name), err)
+			continue
+		}
+		if existing, ok := s.existingRoleName(ctx, to, rr.Name); ok {
+			conflicts[rr.Name] = existing
+		}
+		copies = append(copies, rr)
+	}
+	if err := errs.err(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+	if req.OnConflict == copyFail && len(conflicts) > 0 {
+		names := make([]string, 0, len(conflicts))
+		for _, rr := range copies {
+			if existing, ok := conflicts[rr.Name]; ok {
+				names = append(names, existing)
+			}
+		}
+		msg := fmt.Sprintf("Source %d already has roles %s", req.Target, strings.Join(names, ", "))
+		Error(w, http.StatusConflict, msg, s.Logger)
+		return
+	}
+
+	res := copyRolesResponse{
+		Copied:      []string{},
+		Overwritten: []string{},
+		Skipped:     []string{},
+		Pending:     s.RoleApprovals != nil,
+	}
+	for i := range copies {
+		role := &copies[i].Role
+		existing, exists := conflicts[role.Name]
+		switch {
+		case exists && req.OnConflict == copySkip:
+			res.Skipped = append(res.Skipped, role.Name)
+			continue
+		case exists:
+			// Overwrite the target's role even if its name differs by case
+			role.Name = existing
+			res.Overwritten = append(res.Overwritten, role.Name)
+		default:
+			res.Copied = append(res.Copied, role.Name)
+		}
+
+		if s.RoleApprovals != nil {
+			s.RoleApprovals.propose(req.Target, roleChange{role: *role, create: !exists})
+			continue
+		}
+		if exists {
+			err = to.Update(ctx, role)
+		} else {
+			_, err = to.Add(ctx, role)
+		}
+		if err != nil {
+			msg := fmt.Sprintf("Unable to copy role %s to source %d: %v", role.Name, req.Target, err)
+			Error(w, http.StatusBadRequest, msg, s.Logger)
+			return
+		}
+	}
+
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("target", req.Target).
+		WithField("copied", len(res.Copied)).
+		WithField("overwritten", len(res.Overwritten)).
+		WithField("skipped", len(res.Skipped)).
+		Info("Copied roles")
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
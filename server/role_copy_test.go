@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_CopySourceRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantOps    []string
+	}{
+		{
+			name:       "Roles the target has are skipped",
+			body:       `{"target": 2}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"copied":["timetravelers"],"overwritten":[],"skipped":["biffsgang"],"pending":false}
+`,
+			wantOps: []string{"add timetravelers users=0"},
+		},
+		{
+			name:       "Roles the target has are overwritten with their users",
+			body:       `{"target": 2, "users": true, "onConflict": "overwrite"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"copied":["timetravelers"],"overwritten":["biffsgang"],"skipped":[],"pending":false}
+`,
+			wantOps: []string{"update biffsgang users=1", "add timetravelers users=2"},
+		},
+		{
+			name:       "Nothing is copied if the target has any of the roles",
+			body:       `{"target": 2, "onConflict": "fail"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"code":409,"message":"Source 2 already has roles biffsgang"}`,
+		},
+		{
+			name:       "Unknown conflict policy",
+			body:       `{"target": 2, "onConflict": "merge"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"onConflict must be one of skip, overwrite, fail","errors":[{"field":"onConflict","message":"onConflict must be one of skip, overwrite, fail"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := []string{}
+			connected := 0
+			source := &mocks.RolesStore{
+				AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+					return []chronograf.Role{
+						{
+							Name:  "biffsgang",
+							Users: []chronograf.User{{Name: "biff"}},
+						},
+						{
+							Name:  "timetravelers",
+							Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+							Permissions: chronograf.Permissions{
+								{
+									Scope:   chronograf.DBScope,
+									Name:    "delorean",
+									Allowed: chronograf.Allowances{"READ"},
+								},
+							},
+						},
+						{
+							Name: "_admin",
+						},
+					}, nil
+				},
+			}
+			target := &mocks.RolesStore{
+				GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+					if name != "biffsgang" {
+						return nil, fmt.Errorf("role %s not found", name)
+					}
+					return &chronograf.Role{Name: name}, nil
+				},
+				AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+					ops = append(ops, fmt.Sprintf("add %s users=%d", role.Name, len(role.Users)))
+					return role, nil
+				},
+				UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+					ops = append(ops, fmt.Sprintf("update %s users=%d", role.Name, len(role.Users)))
+					return nil
+				},
+			}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						connected = src.ID
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						if connected == 2 {
+							return target, nil
+						}
+						return source, nil
+					},
+				},
+				ProtectedRoles: []string{"_*"},
+				Logger:         log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-copy", bytes.NewBufferString(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.CopySourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. CopySourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. CopySourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if tt.wantOps == nil {
+				tt.wantOps = []string{}
+			}
+			if !reflect.DeepEqual(ops, tt.wantOps) {
+				t.Errorf("%q. CopySourceRoles() operations = %v, want %v", tt.name, ops, tt.wantOps)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+type coverageMatrixResponse struct {
+	Databases []string          `json:"databases"` // Databases are the columns of the matrix
+	Roles     []roleCoverageRow `json:"roles"`     // Roles are the rows of the matrix
+	Links     selfLinks         `json:"links"`
+}
+
+type roleCoverageRow struct {
+	Name  string                  `json:"name"`
+	All   chronograf.Allowances   `json:"all"`   // All are the allowances granted on every database
+	Cells []chronograf.Allowances `json:"cells"` // Cells are the combined allowances of each database column
+}
+
+// SourceRoleCoverage builds a matrix of the allowances each role has on each
+// database.  Allowances scoped to all databases are part of every cell.
+// Conflicting permissions of the same scope are merged using the Service's
+// PermissionConflicts strategy.  Expired permissions are not coverage.
+// Only databases referenced by a role are columns unless the allDatabases
+// query parameter is true, in which case every database of the source is.
+func (s *Service) SourceRoleCoverage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	allDatabases := r.URL.Query().Get("allDatabases") == "true"
+
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	columns := map[string]bool{}
+	for _, role := range roles {
+		for _, perm := range role.Permissions {
+			if perm.Scope == chronograf.DBScope {
+				columns[perm.Name] = true
+			}
+		}
+	}
+
+	if allDatabases {
+		dbs, err := s.sourceDatabases(ctx, w, srcID)
+		if err != nil {
+			return
+		}
+		for _, db := range dbs {
+			columns[db] = true
+		}
+	}
+
+	now := time.Now()
+	res := coverageMatrixResponse{
+		Databases: sortedKeys(columns),
+		Roles:     make([]roleCoverageRow, len(roles)),
+		Links:     selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/permissions/coverage", srcID)},
+	}
+	for i, role := range roles {
+		res.Roles[i] = s.roleCoverageRow(role, res.Databases, now)
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
+
+// roleCoverageRow merges the unexpired permissions of a role on each
+// database.  Conflicting permissions of the same scope are merged with the
+// PermissionConflicts strategy, while the allowances granted on all
+// databases are added to those of every database.  Denies of all databases
+// take allowances away from every database.
+func (s *Service) roleCoverageRow(role chronograf.Role, databases []string, now time.Time) roleCoverageRow {
+	kept, _ := unexpiredPermissions(role.Permissions, now)
+	all, denies := chronograf.Permissions{}, chronograf.Permissions{}
+	for _, perm := range kept {
+		if perm.Scope != chronograf.AllScope {
+			continue
+		}
+		all = append(all, perm)
+		if perm.Deny {
+			denies = append(denies, perm)
+		}
+	}
+
+	row := roleCoverageRow{
+		Name:  role.Name,
+		All:   chronograf.Allowances{},
+		Cells: make([]chronograf.Allowances, len(databases)),
+	}
+	if merged := s.mergePermissions(all); len(merged) > 0 && !merged[0].Deny {
+		row.All = merged[0].Allowed
+	}
+
+	for i, db := range databases {
+		perms := chronograf.Permissions{}
+		for _, perm := range denies {
+			perm.Scope, perm.Name = chronograf.DBScope, db
+			perms = append(perms, perm)
+		}
+		for _, perm := range kept {
+			if perm.Scope == chronograf.DBScope && perm.Name == db {
+				perms = append(perms, perm)
+			}
+		}
+
+		cell := append(chronograf.Allowances{}, row.All...)
+		if merged := s.mergePermissions(perms); len(merged) > 0 && !merged[0].Deny {
+			for _, a := range merged[0].Allowed {
+				if !hasAllowance(cell, a) {
+					cell = append(cell, a)
+				}
+			}
+		}
+		sort.Strings(cell)
+		row.Cells[i] = cell
+	}
+	return row
+}
+
+// permissionGapsQuery is a database and the allowances it requires
+type permissionGapsQuery struct {
+	Database string
+	Require  []string // Require are the allowances someone must have on Database
+}
+
+// validPermissionGapsQuery reads the database and the required allowances,
+// given as repeated or comma separated require parameters.  Allowances
+// unknown to the source, as listed in vocabulary, are rejected; an empty
+// vocabulary accepts any allowance.
+func validPermissionGapsQuery(q url.Values, vocabulary []permissionScope) (*permissionGapsQuery, error) {
+	gq := &permissionGapsQuery{
+		Database: q.Get("database"),
+		Require:  []string{},
+	}
+	if gq.Database == "" {
+		return nil, fmt.Errorf("database is required")
+	}
+	seen := map[string]bool{}
+	for _, v := range q["require"] {
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" && !seen[a] {
+				seen[a] = true
+				gq.Require = append(gq.Require, a)
+			}
+		}
+	}
+	if len(gq.Require) == 0 {
+		return nil, fmt.Errorf("require must list at least one allowance")
+	}
+	if len(vocabulary) == 0 {
+		return gq, nil
+	}
+	for _, a := range gq.Require {
+		known := false
+		for _, scope := range vocabulary {
+			known = known || hasAllowance(scope.Allowed, a)
+		}
+		if !known {
+			return nil, fmt.Errorf("Allowance %s is not supported by the source", a)
+		}
+	}
+	return gq, nil
+}
+
+// permissionCoverage is the roles granting a required allowance
+type permissionCoverage struct {
+	Allowance  string   `json:"allowance"`
+	Roles      []string `json:"roles"`      // Roles grant the allowance and have at least one member
+	Unassigned []string `json:"unassigned"` // Unassigned roles grant the allowance but have no member
+}
+
+type permissionGapsResponse struct {
+	Database string               `json:"database"`
+	Covered  []permissionCoverage `json:"covered"` // Covered are the allowances some user has through a role
+	Gaps     []permissionCoverage `json:"gaps"`    // Gaps are the allowances no user has through a role
+	Links    selfLinks            `json:"links"`
+}
+
+// permissionGaps checks which of the required allowances on a database at
+// least one member of roles has as of now.  Expired permissions and
+// memberships grant nothing, and a deny of an allowance overrides its grants
+// within a role.
+func permissionGaps(roles []chronograf.Role, q *permissionGapsQuery, now time.Time) ([]permissionCoverage, []permissionCoverage) {
+	covered := []permissionCoverage{}
+	gaps := []permissionCoverage{}
+	for _, a := range q.Require {
+		c := permissionCoverage{
+			Allowance:  a,
+			Roles:      []string{},
+			Unassigned: []string{},
+		}
+		for _, role := range roles {
+			perms, _ := unexpiredPermissions(role.Permissions, now)
+			if !grants(perms, chronograf.DBScope, q.Database, a) {
+				continue
+			}
+			if users, _ := unexpiredUsers(role.Users, now); len(users) > 0 {
+				c.Roles = append(c.Roles, role.Name)
+			} else {
+				c.Unassigned = append(c.Unassigned, role.Name)
+			}
+		}
+		sort.Strings(c.Roles)
+		sort.Strings(c.Unassigned)
+		if len(c.Roles) > 0 {
+			covered = append(covered, c)
+		} else {
+			gaps = append(gaps, c)
+		}
+	}
+	return covered, gaps
+}
+
+// SourcePermissionGaps reports which allowances of a checklist no user has
+// on a database through the roles of a source, e.g. a database left without
+// an administrator.  The database and require query parameters give the
+// database and its checklist.  Protected system roles count as they grant
+// permissions like any other role.
+func (s *Service) SourcePermissionGaps(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	q, err := validPermissionGapsQuery(r.URL.Query(), permissionVocabulary(ts.Permissions(ctx)))
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := permissionGapsResponse{
+		Database: q.Database,
+		Links:    selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/permissions/gaps", srcID)},
+	}
+	res.Covered, res.Gaps = permissionGaps(roles, q, time.Now())
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
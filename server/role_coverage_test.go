@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceRoleCoverage(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Databases referenced by roles",
+			wantStatus: http.StatusOK,
+			wantBody: `{"databases":["delorean","hillvalley"],"roles":[{"name":"timetravelers","all":[],"cells":[["READ","WRITE"],["READ"]]},{"name":"admins","all":["READ"],"cells":[["READ"],["READ"]]}],"links":{"self":"/chronograf/v1/sources/1/permissions/coverage"}}
+`,
+		},
+		{
+			name:       "Every database of the source",
+			query:      "?allDatabases=true",
+			wantStatus: http.StatusOK,
+			wantBody: `{"databases":["_internal","delorean","hillvalley"],"roles":[{"name":"timetravelers","all":[],"cells":[[],["READ","WRITE"],["READ"]]},{"name":"admins","all":["READ"],"cells":[["READ"],["READ"],["READ"]]}],"links":{"self":"/chronograf/v1/sources/1/permissions/coverage"}}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "timetravelers",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "hillvalley",
+												Allowed: chronograf.Allowances{"READ"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"WRITE", "READ"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"READ"},
+											},
+										},
+									},
+									{
+										Name: "admins",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"READ"},
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				Databases: &mocks.Databases{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					AllDBF: func(ctx context.Context) ([]chronograf.Database, error) {
+						return []chronograf.Database{
+							{Name: "_internal"},
+							{Name: "delorean"},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/permissions/coverage"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceRoleCoverage(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceRoleCoverage() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceRoleCoverage() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestService_SourcePermissionGaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Allowances without an assigned role",
+			query:      "?database=delorean&require=READ,WRITE&require=ALL",
+			wantStatus: http.StatusOK,
+			wantBody: `{"database":"delorean","covered":[{"allowance":"READ","roles":["admins","timetravelers"],"unassigned":["dbas"]},{"allowance":"WRITE","roles":["timetravelers"],"unassigned":["dbas"]}],"gaps":[{"allowance":"ALL","roles":[],"unassigned":["dbas"]}],"links":{"self":"/chronograf/v1/sources/1/permissions/gaps"}}
+`,
+		},
+		{
+			name:       "Deny overrides grant",
+			query:      "?database=hillvalley&require=WRITE",
+			wantStatus: http.StatusOK,
+			wantBody: `{"database":"hillvalley","covered":[],"gaps":[{"allowance":"WRITE","roles":[],"unassigned":[]}],"links":{"self":"/chronograf/v1/sources/1/permissions/gaps"}}
+`,
+		},
+		{
+			name:       "Missing database",
+			query:      "?require=READ",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"database is required"}`,
+		},
+		{
+			name:       "Missing checklist",
+			query:      "?database=delorean",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"require must list at least one allowance"}`,
+		},
+		{
+			name:       "Unknown allowance",
+			query:      "?database=delorean&require=ReadData",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"Allowance ReadData is not supported by the source"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					PermissionsF: func(ctx context.Context) chronograf.Permissions {
+						return chronograf.Permissions{
+							{
+								Scope:   chronograf.AllScope,
+								Allowed: chronograf.Allowances{"ALL"},
+							},
+							{
+								Scope:   chronograf.DBScope,
+								Allowed: chronograf.Allowances{"READ", "WRITE"},
+							},
+						}
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "timetravelers",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"WRITE", "READ"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "hillvalley",
+												Allowed: chronograf.Allowances{"WRITE"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "hillvalley",
+												Allowed: chronograf.Allowances{"WRITE"},
+												Deny:    true,
+											},
+										},
+										Users: []chronograf.User{{Name: "marty"}},
+									},
+									{
+										Name: "admins",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"READ"},
+											},
+										},
+										Users: []chronograf.User{{Name: "docbrown"}},
+									},
+									{
+										Name: "dbas",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"ALL"},
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/permissions/gaps"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourcePermissionGaps(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourcePermissionGaps() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourcePermissionGaps() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestService_roleCoverageRow(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	role := chronograf.Role{
+		Name: "timetravelers",
+		Permissions: chronograf.Permissions{
+			{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+			{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"WRITE"}},
+			{Scope: chronograf.DBScope, Name: "hillvalley", Allowed: chronograf.Allowances{"WRITE"}, ExpiresAt: &past},
+		},
+	}
+	s := &Service{
+		PermissionConflicts: LeastPermissive,
+		Logger:              log.New(log.DebugLevel),
+	}
+
+	// Permissions of different scopes add up rather than conflict
+	got := s.roleCoverageRow(role, []string{"delorean", "hillvalley"}, now)
+	want := roleCoverageRow{
+		Name:  "timetravelers",
+		All:   chronograf.Allowances{"READ"},
+		Cells: []chronograf.Allowances{{"READ", "WRITE"}, {"READ"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("roleCoverageRow() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// encodeRoleCursor returns an opaque cursor resuming a role listing after
+// the role with name
+func encodeRoleCursor(name string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(name))
+}
+
+// decodeRoleCursor returns the name of the last role listed before cursor
+func decodeRoleCursor(cursor string) (string, bool) {
+	name, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(name) == 0 {
+		return "", false
+	}
+	return string(name), true
+}
+
+// paged checks if the query asks for a page of the roles
+func (q *sourceRolesQuery) paged() bool {
+	return q.Limit > 0 || q.After != ""
+}
+
+// page returns the roles sorted by name following the query's cursor, at
+// most Limit of them.  The cursor of the next page is empty if no roles
+// follow the page.  Roles created or removed between pages do not shift the
+// roles of later pages.
+func (q *sourceRolesQuery) page(rr []sourceRoleResponse) ([]sourceRoleResponse, string) {
+	sort.SliceStable(rr, func(i, j int) bool {
+		return rr[i].Name < rr[j].Name
+	})
+	start := sort.Search(len(rr), func(i int) bool {
+		return rr[i].Name > q.After
+	})
+	rr = rr[start:]
+	if q.Limit == 0 || len(rr) <= q.Limit {
+		return rr, ""
+	}
+	rr = rr[:q.Limit]
+	return rr, encodeRoleCursor(rr[len(rr)-1].Name)
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// maxRoleDelegations is the most delegations a role may have
+const maxRoleDelegations = 32
+
+// validDelegations checks the delegations of the role named name.  A role
+// cannot delegate to itself.
+func validDelegations(name string, delegations []chronograf.RoleDelegation, errs *validationErrors) {
+	if len(delegations) > maxRoleDelegations {
+		errs.add("delegations", "Role may have at most %d delegations", maxRoleDelegations)
+	}
+	for i, d := range delegations {
+		if d.Role == "" {
+			errs.add(fmt.Sprintf("delegations[%d].role", i), "Delegating role name required")
+		} else if d.Role == name {
+			errs.add(fmt.Sprintf("delegations[%d].role", i), "Role cannot delegate to itself")
+		}
+		for j, db := range d.Databases {
+			if db == "" {
+				errs.add(fmt.Sprintf("delegations[%d].databases[%d]", i, j), "Database name required")
+			}
+		}
+		for j, a := range d.Allowed {
+			if a == "" {
+				errs.add(fmt.Sprintf("delegations[%d].allowed[%d]", i, j), "Allowance required")
+			}
+		}
+	}
+}
+
+// delegatedSubset returns the grants of perms within the subset of a
+// delegation.  Permissions of all databases are narrowed to each database
+// of the delegation.  Denies are never delegated, as they would take away
+// what the delegate is granted itself; perms are the grants the delegating
+// role is left with after its own denies.
+func delegatedSubset(perms chronograf.Permissions, d chronograf.RoleDelegation) chronograf.Permissions {
+	res := chronograf.Permissions{}
+	for _, perm := range perms {
+		if perm.Deny {
+			continue
+		}
+		if len(d.Allowed) > 0 {
+			allowed := chronograf.Allowances{}
+			for _, a := range perm.Allowed {
+				if hasAllowance(d.Allowed, a) {
+					allowed = append(allowed, a)
+				}
+			}
+			if len(allowed) == 0 {
+				continue
+			}
+			perm.Allowed = allowed
+		}
+
+		switch {
+		case len(d.Databases) == 0:
+			res = append(res, perm)
+		case perm.Scope == chronograf.AllScope:
+			for _, db := range d.Databases {
+				narrowed := perm
+				narrowed.Scope, narrowed.Name = chronograf.DBScope, db
+				res = append(res, narrowed)
+			}
+		case perm.Scope == chronograf.DBScope && hasDatabase(d.Databases, perm.Name):
+			res = append(res, perm)
+		}
+	}
+	return res
+}
+
+func hasDatabase(dbs []string, name string) bool {
+	for _, db := range dbs {
+		if db == name {
+			return true
+		}
+	}
+	return false
+}
+
+// delegatedPermissions resolves the unexpired permissions other roles
+// delegate to role as of now, with the names of the delegating roles.  A
+// delegating role passes on what it was delegated itself; loops of
+// delegation are followed once around.  Delegations of roles the source no
+// longer has grant nothing.
+func delegatedPermissions(roles []chronograf.Role, role *chronograf.Role, now time.Time) (chronograf.Permissions, []string) {
+	byName := make(map[string]*chronograf.Role, len(roles))
+	for i := range roles {
+		byName[roles[i].Name] = &roles[i]
+	}
+
+	delegators := []string{}
+	seen := map[string]bool{}
+	onPath := map[string]bool{role.Name: true}
+	var resolve func(r *chronograf.Role) chronograf.Permissions
+	resolve = func(r *chronograf.Role) chronograf.Permissions {
+		perms := chronograf.Permissions{}
+		for _, d := range r.Delegations {
+			from, ok := byName[d.Role]
+			if !ok || onPath[d.Role] {
+				continue
+			}
+			if !seen[d.Role] {
+				seen[d.Role] = true
+				delegators = append(delegators, d.Role)
+			}
+
+			onPath[d.Role] = true
+			kept, _ := unexpiredPermissions(from.Permissions, now)
+			granted := roleGrants(append(kept, resolve(from)...))
+			delete(onPath, d.Role)
+
+			perms = append(perms, delegatedSubset(granted, d)...)
+		}
+		return perms
+	}
+	return resolve(role), delegators
+}
+
+var _ chronograf.RolesStore = &delegatingRolesStore{}
+
+// delegatingRolesStore keeps the delegations of the roles of a source
+// alongside the underlying RolesStore
+type delegatingRolesStore struct {
+	chronograf.RolesStore
+	srcID       int
+	delegations chronograf.RoleDelegationsStore
+	Logger      chronograf.Logger
+}
+
+// All returns the roles of the source with their delegations
+func (s *delegatingRolesStore) All(ctx context.Context) ([]chronograf.Role, error) {
+	roles, err := s.RolesStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delegations, err := s.delegations.All(ctx, s.srcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].Delegations = delegations[roles[i].Name]
+	}
+	return roles, nil
+}
+
+// Get returns the role with its delegations
+func (s *delegatingRolesStore) Get(ctx context.Context, name string) (*chronograf.Role, error) {
+	role, err := s.RolesStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role.Delegations, err = s.delegations.Get(ctx, s.srcID, role.Name); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Add creates the role then stores its delegations
+func (s *delegatingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if len(role.Delegations) > 0 {
+		if err := s.delegations.Put(ctx, s.srcID, role.Name, role.Delegations); err != nil {
+			return nil, err
+		}
+	}
+	res.Delegations = role.Delegations
+	return res, nil
+}
+
+// Update changes the role and replaces its delegations.  Updates without
+// delegations keep them; an empty list revokes them.
+func (s *delegatingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	return updateRoleEntry(ctx, s.RolesStore, role, role.Delegations != nil, func() error {
+		return s.delegations.Put(ctx, s.srcID, role.Name, role.Delegations)
+	})
+}
+
+// Delete removes the role and its delegations.  Delegations from the role
+// to others are kept but grant nothing unless a role of the same name is
+// created again.
+func (s *delegatingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	return deleteRoleEntry(ctx, s.RolesStore, role, s.Logger, "the delegations", func() error {
+		return s.delegations.Put(ctx, s.srcID, role.Name, nil)
+	})
+}
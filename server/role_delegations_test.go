@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_sourceRoleRequest_Delegations(t *testing.T) {
+	tests := []struct {
+		name        string
+		delegations []chronograf.RoleDelegation
+		wantErr     string
+	}{
+		{
+			name: "Valid delegations",
+			delegations: []chronograf.RoleDelegation{
+				{Role: "dbas", Databases: []string{"telegraf"}, Allowed: chronograf.Allowances{"READ"}},
+				{Role: "admins"},
+			},
+		},
+		{
+			name:        "Delegating role required",
+			delegations: []chronograf.RoleDelegation{{Databases: []string{"telegraf"}}},
+			wantErr:     "Delegating role name required",
+		},
+		{
+			name:        "Delegating to itself",
+			delegations: []chronograf.RoleDelegation{{Role: "oncall"}},
+			wantErr:     "Role cannot delegate to itself",
+		},
+		{
+			name:        "Empty database",
+			delegations: []chronograf.RoleDelegation{{Role: "dbas", Databases: []string{""}}},
+			wantErr:     "Database name required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sourceRoleRequest{
+				Role: chronograf.Role{
+					Name:        "oncall",
+					Delegations: tt.delegations,
+				},
+			}
+			err := r.ValidCreate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidCreate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidCreate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_delegatedPermissions(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	roles := []chronograf.Role{
+		{
+			Name: "admins",
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ", "WRITE"}},
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ALL"}, ExpiresAt: &past},
+			},
+			Delegations: []chronograf.RoleDelegation{{Role: "leads"}},
+		},
+		{
+			Name: "leads",
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ALL"}},
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"READ"}, Deny: true},
+			},
+			Delegations: []chronograf.RoleDelegation{{Role: "admins", Databases: []string{"payroll"}}},
+		},
+		{
+			Name:  "oncall",
+			Users: []chronograf.User{{Name: "kiwi"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"READ"}},
+			},
+			Delegations: []chronograf.RoleDelegation{
+				{Role: "leads", Databases: []string{"telegraf", "payroll"}, Allowed: chronograf.Allowances{"READ", "ALL"}},
+				{Role: "retired"},
+			},
+		},
+	}
+
+	got, delegators := delegatedPermissions(roles, &roles[2], now)
+	// leads pass on the READ of payroll delegated by admins less their
+	// own deny of it, and never the deny itself
+	want := chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ALL"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("delegatedPermissions() = %v, want %v", got, want)
+	}
+	if want := []string{"leads", "admins"}; !reflect.DeepEqual(delegators, want) {
+		t.Errorf("delegatedPermissions() delegators = %v, want %v", delegators, want)
+	}
+
+	// Revoking a permission from the delegating role revokes it from the
+	// delegates, while the deny of the delegating role does not take away
+	// what the delegate is granted itself
+	roles[1].Permissions = roles[1].Permissions[1:]
+	perms, _ := effectivePermissions(roles, "kiwi", now)
+	want = chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"READ"}},
+	}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("effectivePermissions() after revoking = %v, want %v", perms, want)
+	}
+}
+
+func Test_delegatingRolesStore_Cache(t *testing.T) {
+	roles := []chronograf.Role{
+		{
+			Name: "dbas",
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"READ"}},
+			},
+		},
+		{
+			Name:  "oncall",
+			Users: []chronograf.User{{Name: "kiwi"}},
+		},
+	}
+	delegations := map[string][]chronograf.RoleDelegation{
+		"oncall": {{Role: "dbas"}},
+	}
+	s := &Service{
+		TimeSeriesClient: &mocks.TimeSeries{
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+						return append([]chronograf.Role{}, roles...), nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						if role.Permissions != nil {
+							roles[0].Permissions = role.Permissions
+						}
+						return nil
+					},
+				}, nil
+			},
+		},
+		RoleDelegations: &mocks.RoleDelegationsStore{
+			AllF: func(ctx context.Context, srcID int) (map[string][]chronograf.RoleDelegation, error) {
+				return delegations, nil
+			},
+		},
+		EffectivePermissions: NewEffectivePermissionsCache(time.Hour),
+		Logger:               log.New(log.DebugLevel),
+	}
+	ctx := context.Background()
+	ts, _ := s.TimeSeriesClient.New(chronograf.Source{ID: 1}, nil)
+	store, _ := s.hasRoles(ctx, 1, ts)
+
+	perms, _, err := s.userEffectivePermissions(ctx, 1, store, "kiwi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 {
+		t.Fatalf("userEffectivePermissions() = %v, want the delegated permission", perms)
+	}
+
+	// Revoking from the delegating role drops the cached permissions of the
+	// users of its delegates
+	if err := store.Update(ctx, &chronograf.Role{Name: "dbas", Permissions: chronograf.Permissions{}}); err != nil {
+		t.Fatal(err)
+	}
+	perms, _, err = s.userEffectivePermissions(ctx, 1, store, "kiwi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 0 {
+		t.Errorf("userEffectivePermissions() after revoking = %v, want none", perms)
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/chronograf"
+)
+
+// roleDrift is how a role both sources have differs.  Users and permissions
+// are added going from the source's role to the other source's role.
+type roleDrift struct {
+	Role        string                    `json:"role"`
+	Users       roleUsersDiff             `json:"users"`
+	Permissions roleUpdatePermissionsDiff `json:"permissions"`
+}
+
+type sourceRolesDiffResponse struct {
+	Source        int         `json:"source"`
+	Against       int         `json:"against"`
+	OnlyInSource  []string    `json:"onlyInSource"`  // OnlyInSource are the roles the other source does not have
+	OnlyInAgainst []string    `json:"onlyInAgainst"` // OnlyInAgainst are the roles only the other source has
+	Differing     []roleDrift `json:"differing"`     // Differing are the roles both sources have with different users or permissions
+}
+
+// indexRoles maps the names of roles to the roles, leaving out protected
+// system roles
+func (s *Service) indexRoles(roles []chronograf.Role) map[string]*chronograf.Role {
+	index := map[string]*chronograf.Role{}
+	for i := range roles {
+		if !s.isProtectedRole(roles[i].Name) {
+			index[roles[i].Name] = &roles[i]
+		}
+	}
+	return index
+}
+
+// diffSourceRoles compares the roles of a source to those of another
+func (s *Service) diffSourceRoles(roles, against []chronograf.Role) sourceRolesDiffResponse {
+	res := sourceRolesDiffResponse{
+		OnlyInSource:  []string{},
+		OnlyInAgainst: []string{},
+		Differing:     []roleDrift{},
+	}
+
+	a, b := s.indexRoles(roles), s.indexRoles(against)
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		other, ok := b[name]
+		if !ok {
+			res.OnlyInSource = append(res.OnlyInSource, name)
+			continue
+		}
+		if !roleDrifted(a[name], other) {
+			continue
+		}
+		res.Differing = append(res.Differing, roleDrift{
+			Role:        name,
+			Users:       diffRoleUsers(a[name], other),
+			Permissions: diffRolePermissions(a[name].Permissions, other.Permissions),
+		})
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			res.OnlyInAgainst = append(res.OnlyInAgainst, name)
+		}
+	}
+	sort.Strings(res.OnlyInAgainst)
+	return res
+}
+
+// DiffSourceRoles compares the roles of a source to the roles of the source
+// given by the against query parameter, e.g. to find drift between twin
+// clusters.  Protected system roles are not compared.
+func (s *Service) DiffSourceRoles(w http.ResponseWriter, r *http.Request) {
+	againstID, err := strconv.Atoi(r.URL.Query().Get("against"))
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, "against must be the ID of a source", s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	againstTS, err := s.connectSource(ctx, w, againstID)
+	if err != nil {
+		return
+	}
+	againstRoles, ok := s.hasRoles(ctx, againstID, againstTS)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", againstID), s.Logger)
+		return
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	allAgainst, err := againstRoles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := s.diffSourceRoles(all, allAgainst)
+	res.Source = srcID
+	res.Against = againstID
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_DiffSourceRoles(t *testing.T) {
+	roles := map[int][]chronograf.Role{
+		1: {
+			{
+				Name:  "timetravelers",
+				Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+				Permissions: chronograf.Permissions{
+					{
+						Scope:   chronograf.DBScope,
+						Name:    "delorean",
+						Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+					},
+				},
+			},
+			{
+				Name: "biffsgang",
+			},
+			{
+				Name:  "hillvalley",
+				Users: []chronograf.User{{Name: "goldie"}},
+			},
+			{
+				Name: "_admin",
+			},
+		},
+		2: {
+			{
+				Name:  "timetravelers",
+				Users: []chronograf.User{{Name: "doc"}, {Name: "jennifer"}},
+				Permissions: chronograf.Permissions{
+					{
+						Scope:   chronograf.DBScope,
+						Name:    "delorean",
+						Allowed: chronograf.Allowances{"WriteData"},
+					},
+				},
+			},
+			{
+				Name:  "hillvalley",
+				Users: []chronograf.User{{Name: "goldie"}},
+			},
+			{
+				Name: "libyans",
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Roles differ",
+			query:      "?against=2",
+			wantStatus: http.StatusOK,
+			wantBody: `{"source":1,"against":2,"onlyInSource":["biffsgang"],"onlyInAgainst":["libyans"],"differing":[{"role":"timetravelers","users":{"added":["jennifer"],"removed":["marty"]},"permissions":{"added":[{"scope":"database","name":"delorean","allowed":["WriteData"]}],"removed":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}]}}]}
+`,
+		},
+		{
+			name:       "Missing against",
+			query:      "",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"against must be the ID of a source"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var connected int
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						connected = src.ID
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						all := roles[connected]
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return all, nil
+							},
+						}, nil
+					},
+				},
+				ProtectedRoles: []string{"_*"},
+				Logger:         log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles-diff"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.DiffSourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. DiffSourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. DiffSourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
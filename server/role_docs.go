@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/chronograf"
+)
+
+// Limits of the supporting documents of a role
+const (
+	maxRoleDocs     = 32  // maxRoleDocs is the most documents a role may link to
+	maxRoleDocLabel = 255 // maxRoleDocLabel is the longest document label
+)
+
+// validDocs checks that the documents of a role link to absolute http or
+// https URLs
+func validDocs(docs []chronograf.RoleDoc, errs *validationErrors) {
+	if len(docs) > maxRoleDocs {
+		errs.add("docs", "Role may link to at most %d documents", maxRoleDocs)
+	}
+	for i, doc := range docs {
+		u, err := url.Parse(doc.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add(fmt.Sprintf("docs[%d].url", i), "Document URL must be an absolute http or https URL")
+		}
+		if len(doc.Label) > maxRoleDocLabel {
+			errs.add(fmt.Sprintf("docs[%d].label", i), "Document label must be at most %d characters", maxRoleDocLabel)
+		}
+	}
+}
+
+var _ chronograf.RolesStore = &documentingRolesStore{}
+
+// documentingRolesStore keeps the supporting documents of the roles of a
+// source alongside the underlying RolesStore
+type documentingRolesStore struct {
+	chronograf.RolesStore
+	srcID  int
+	docs   chronograf.RoleDocsStore
+	Logger chronograf.Logger
+}
+
+// All returns the roles of the source with their documents
+func (s *documentingRolesStore) All(ctx context.Context) ([]chronograf.Role, error) {
+	roles, err := s.RolesStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.docs.All(ctx, s.srcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].Docs = docs[roles[i].Name]
+	}
+	return roles, nil
+}
+
+// Get returns the role with its documents
+func (s *documentingRolesStore) Get(ctx context.Context, name string) (*chronograf.Role, error) {
+	role, err := s.RolesStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role.Docs, err = s.docs.Get(ctx, s.srcID, role.Name); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Add creates the role then stores its documents
+func (s *documentingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if len(role.Docs) > 0 {
+		if err := s.docs.Put(ctx, s.srcID, role.Name, role.Docs); err != nil {
+			return nil, err
+		}
+	}
+	res.Docs = role.Docs
+	return res, nil
+}
+
+// Update changes the role and replaces its documents.  Updates without
+// documents keep them; an empty list removes them.
+func (s *documentingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	return updateRoleEntry(ctx, s.RolesStore, role, role.Docs != nil, func() error {
+		return s.docs.Put(ctx, s.srcID, role.Name, role.Docs)
+	})
+}
+
+// Delete removes the role and its documents
+func (s *documentingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	return deleteRoleEntry(ctx, s.RolesStore, role, s.Logger, "the documents", func() error {
+		return s.docs.Put(ctx, s.srcID, role.Name, nil)
+	})
+}
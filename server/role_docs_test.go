@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_sourceRoleRequest_Docs(t *testing.T) {
+	tests := []struct {
+		name    string
+		docs    []chronograf.RoleDoc
+		wantErr string
+	}{
+		{
+			name: "Valid documents",
+			docs: []chronograf.RoleDoc{
+				{URL: "https://tickets.example.com/OPS-1955", Label: "OPS-1955"},
+				{URL: "http://wiki.example.com/policies/access?rev=3"},
+			},
+		},
+		{
+			name:    "Relative URL",
+			docs:    []chronograf.RoleDoc{{URL: "/policies/access"}},
+			wantErr: "Document URL must be an absolute http or https URL",
+		},
+		{
+			name:    "Other scheme",
+			docs:    []chronograf.RoleDoc{{URL: "javascript:alert(1)"}},
+			wantErr: "Document URL must be an absolute http or https URL",
+		},
+		{
+			name:    "Long label",
+			docs:    []chronograf.RoleDoc{{URL: "https://tickets.example.com/OPS-1955", Label: strings.Repeat("l", 256)}},
+			wantErr: "Document label must be at most 255 characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sourceRoleRequest{
+				Role: chronograf.Role{
+					Name: "oncall",
+					Docs: tt.docs,
+				},
+			}
+			err := r.ValidCreate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidCreate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidCreate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_documentingRolesStore(t *testing.T) {
+	docs := map[string][]chronograf.RoleDoc{}
+	store := &documentingRolesStore{
+		RolesStore: &mocks.RolesStore{
+			AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+				return &chronograf.Role{Name: role.Name}, nil
+			},
+			GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+				return &chronograf.Role{Name: name}, nil
+			},
+			UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+			DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+		},
+		srcID: 1,
+		docs: &mocks.RoleDocsStore{
+			GetF: func(ctx context.Context, srcID int, role string) ([]chronograf.RoleDoc, error) {
+				return docs[role], nil
+			},
+			PutF: func(ctx context.Context, srcID int, role string, d []chronograf.RoleDoc) error {
+				if len(d) == 0 {
+					delete(docs, role)
+					return nil
+				}
+				docs[role] = d
+				return nil
+			},
+		},
+	}
+	ctx := context.Background()
+
+	want := []chronograf.RoleDoc{{URL: "https://tickets.example.com/OPS-1955", Label: "OPS-1955"}}
+	if _, err := store.Add(ctx, &chronograf.Role{Name: "oncall", Docs: want}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(ctx, &chronograf.Role{Name: "oncall"}); err != nil {
+		t.Fatal(err)
+	}
+	role, err := store.Get(ctx, "oncall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(role.Docs, want) {
+		t.Errorf("documentingRolesStore.Update() without documents changed them to %v, want %v", role.Docs, want)
+	}
+
+	if err := store.Delete(ctx, &chronograf.Role{Name: "oncall"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := docs["oncall"]; ok {
+		t.Errorf("documentingRolesStore.Delete() did not remove the documents")
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// duplicateRoles are roles granting the same permissions.  Canonical is the
+// role the users of the duplicates are moved to when they are merged.
+type duplicateRoles struct {
+	Canonical   string                 `json:"canonical"`
+	Duplicates  []string               `json:"duplicates"`
+	Permissions chronograf.Permissions `json:"permissions"`
+}
+
+type duplicateRolesResponse struct {
+	Groups []duplicateRoles `json:"groups"`
+	Merged bool             `json:"merged"` // Merged is true if the duplicates were merged rather than only reported
+	Failed []roleFailure    `json:"failed"`
+}
+
+// permissionFingerprint is the same for roles granting the same
+// permissions however they are written.  Permissions of the same scope are
+// merged and denies applied; notes are ignored.  Temporary permissions are
+// kept apart with their expiry so they are not mistaken for lasting grants.
+func permissionFingerprint(perms chronograf.Permissions, now time.Time) string {
+	perms, _ = unexpiredPermissions(perms, now)
+	lasting := chronograf.Permissions{}
+	temporary := chronograf.Permissions{}
+	for _, perm := range perms {
+		perm.Note = ""
+		if perm.ExpiresAt != nil {
+			temporary = append(temporary, perm)
+		} else {
+			lasting = append(lasting, perm)
+		}
+	}
+	keys := permissionKeys(mergePermissionsWith(lasting, MostPermissive, nil))
+	keys = append(keys, permissionKeys(temporary)...)
+	return strings.Join(keys, "\x01")
+}
+
+// findDuplicateRoles groups roles with the same permission fingerprint.
+// Roles granting nothing are not duplicates of each other.  The canonical
+// role of a group is a protected role if there is one, otherwise the role
+// with the most users, then the first by name.
+func findDuplicateRoles(roles []chronograf.Role, protected func(string) bool, now time.Time) []duplicateRoles {
+	byPrint := map[string][]chronograf.Role{}
+	prints := []string{}
+	for _, role := range roles {
+		if len(role.Permissions) == 0 {
+			continue
+		}
+		fp := permissionFingerprint(role.Permissions, now)
+		if fp == "" {
+			continue
+		}
+		if _, ok := byPrint[fp]; !ok {
+			prints = append(prints, fp)
+		}
+		byPrint[fp] = append(byPrint[fp], role)
+	}
+
+	groups := []duplicateRoles{}
+	for _, fp := range prints {
+		group := byPrint[fp]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			pi, pj := protected(group[i].Name), protected(group[j].Name)
+			if pi != pj {
+				return pi
+			}
+			if len(group[i].Users) != len(group[j].Users) {
+				return len(group[i].Users) > len(group[j].Users)
+			}
+			return group[i].Name < group[j].Name
+		})
+		dups := duplicateRoles{
+			Canonical:   group[0].Name,
+			Duplicates:  []string{},
+			Permissions: group[0].Permissions,
+		}
+		for _, role := range group[1:] {
+			// Protected roles are never deleted, so are not merged away
+			if protected(role.Name) {
+				continue
+			}
+			dups.Duplicates = append(dups.Duplicates, role.Name)
+		}
+		if len(dups.Duplicates) > 0 {
+			groups = append(groups, dups)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Canonical < groups[j].Canonical })
+	return groups
+}
+
+// mergedRoleUsers are the users of the canonical role followed by the users
+// of its duplicates, each once
+func mergedRoleUsers(group duplicateRoles, byName map[string]chronograf.Role) []chronograf.User {
+	users := []chronograf.User{}
+	seen := map[string]bool{}
+	for _, name := range append([]string{group.Canonical}, group.Duplicates...) {
+		for _, u := range byName[name].Users {
+			if seen[u.Name] {
+				continue
+			}
+			seen[u.Name] = true
+			users = append(users, chronograf.User{Name: u.Name, ExpiresAt: u.ExpiresAt})
+		}
+	}
+	return users
+}
+
+// DuplicateSourceRoles reports the roles of a source granting the same
+// permissions under different names.  POST with confirm=true merges each
+// group: the users of the duplicates are added to the canonical role, then
+// the duplicates are deleted.  Without confirm=true nothing is changed.  A
+// group whose canonical role cannot be updated is left alone.
+func (s *Service) DuplicateSourceRoles(w http.ResponseWriter, r *http.Request) {
+	merge := false
+	if r.Method == http.MethodPost {
+		if c := r.URL.Query().Get("confirm"); c != "" {
+			b, err := strconv.ParseBool(c)
+			if err != nil {
+				Error(w, http.StatusUnprocessableEntity, "confirm must be a boolean", s.Logger)
+				return
+			}
+			merge = b
+		}
+	}
+	if merge && s.RoleApprovals != nil {
+		Error(w, http.StatusConflict, "Duplicate roles cannot be merged while role changes require approval", s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := duplicateRolesResponse{
+		Groups: findDuplicateRoles(all, s.isProtectedRole, time.Now()),
+		Merged: merge,
+		Failed: []roleFailure{},
+	}
+	if !merge {
+		encodeJSON(w, http.StatusOK, res, s.Logger)
+		return
+	}
+
+	byName := map[string]chronograf.Role{}
+	for _, role := range all {
+		byName[role.Name] = role
+	}
+	deleted := 0
+	for _, group := range res.Groups {
+		canonical := chronograf.Role{
+			Name:  group.Canonical,
+			Users: mergedRoleUsers(group, byName),
+		}
+		if err := roles.Update(ctx, &canonical); err != nil {
+			res.Failed = append(res.Failed, roleFailure{
+				Role:    group.Canonical,
+				Message: fmt.Sprintf("Unable to add users of duplicate roles: %v", err),
+			})
+			continue
+		}
+		for _, name := range group.Duplicates {
+			if err := roles.Delete(ctx, &chronograf.Role{Name: name}); err != nil {
+				res.Failed = append(res.Failed, roleFailure{
+					Role:    name,
+					Message: fmt.Sprintf("Unable to delete duplicate role: %v", err),
+				})
+				continue
+			}
+			deleted++
+		}
+	}
+
+	status := http.StatusOK
+	if len(res.Failed) > 0 {
+		status = http.StatusBadRequest
+	}
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("groups", len(res.Groups)).
+		WithField("deleted", deleted).
+		WithField("failed", len(res.Failed)).
+		Info("Merged duplicate roles")
+	encodeJSON(w, status, res, s.Logger)
+}
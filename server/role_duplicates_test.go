@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_DuplicateSourceRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		failDelete string
+		wantStatus int
+		wantBody   string
+		wantOps    []string
+	}{
+		{
+			name:       "Duplicates are reported",
+			method:     "GET",
+			wantStatus: http.StatusOK,
+			wantBody: `{"groups":[{"canonical":"viewers","duplicates":["readers","observers"],"permissions":[{"scope":"database","name":"delorean","allowed":["WRITE"]},{"scope":"database","name":"delorean","allowed":["READ"],"note":"dashboards"}]}],"merged":false,"failed":[]}
+`,
+			wantOps: []string{},
+		},
+		{
+			name:       "Nothing is merged without confirmation",
+			method:     "POST",
+			wantStatus: http.StatusOK,
+			wantBody: `{"groups":[{"canonical":"viewers","duplicates":["readers","observers"],"permissions":[{"scope":"database","name":"delorean","allowed":["WRITE"]},{"scope":"database","name":"delorean","allowed":["READ"],"note":"dashboards"}]}],"merged":false,"failed":[]}
+`,
+			wantOps: []string{},
+		},
+		{
+			name:       "Duplicates are merged",
+			method:     "POST",
+			query:      "?confirm=true",
+			failDelete: "observers",
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{"groups":[{"canonical":"viewers","duplicates":["readers","observers"],"permissions":[{"scope":"database","name":"delorean","allowed":["WRITE"]},{"scope":"database","name":"delorean","allowed":["READ"],"note":"dashboards"}]}],"merged":true,"failed":[{"role":"observers","message":"Unable to delete duplicate role: observers is locked"}]}
+`,
+			wantOps: []string{"update viewers doc,marty", "delete readers"},
+		},
+		{
+			name:       "Invalid confirmation",
+			method:     "POST",
+			query:      "?confirm=yes",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"confirm must be a boolean"}`,
+			wantOps:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := []string{}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name:  "readers",
+										Users: []chronograf.User{{Name: "marty"}},
+										Permissions: chronograf.Permissions{
+											{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ", "WRITE"}},
+										},
+									},
+									{
+										Name:  "viewers",
+										Users: []chronograf.User{{Name: "doc"}, {Name: "marty"}},
+										Permissions: chronograf.Permissions{
+											{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"WRITE"}},
+											{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ"}, Note: "dashboards"},
+										},
+									},
+									{
+										Name: "observers",
+										Permissions: chronograf.Permissions{
+											{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"WRITE", "READ"}},
+										},
+									},
+									{
+										Name: "biffsgang",
+										Permissions: chronograf.Permissions{
+											{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"READ"}},
+										},
+									},
+									{Name: "empty"},
+									{Name: "vacant"},
+								}, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								op := "update " + role.Name + " "
+								for i, u := range role.Users {
+									if i > 0 {
+										op += ","
+									}
+									op += u.Name
+								}
+								ops = append(ops, op)
+								return nil
+							},
+							DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+								if role.Name == tt.failDelete {
+									return fmt.Errorf("%s is locked", role.Name)
+								}
+								ops = append(ops, "delete "+role.Name)
+								return nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "http://server.local/chronograf/v1/sources/1/roles-duplicates"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.DuplicateSourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. DuplicateSourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. DuplicateSourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if !reflect.DeepEqual(ops, tt.wantOps) {
+				t.Errorf("%q. DuplicateSourceRoles() ops = %v, want %v", tt.name, ops, tt.wantOps)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+const (
+	// CamelCaseNaming is the default field naming of role responses
+	CamelCaseNaming = "camelCase"
+	// SnakeCaseNaming flattens role responses into snake_case fields
+	SnakeCaseNaming = "snake_case"
+)
+
+// jsonProfiles returns the space-separated profiles of the JSON media types
+// accepted by the client
+func jsonProfiles(r *http.Request) []string {
+	profiles := []string{}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accept)
+		if err != nil || mediaType != JSONType {
+			continue
+		}
+		profiles = append(profiles, strings.Fields(params["profile"])...)
+	}
+	return profiles
+}
+
+// roleNaming returns the field naming of role responses.  Clients choose a
+// naming with the profile parameter of the Accept header, e.g.
+// "Accept: application/json; profile=snake_case".  Otherwise, the
+// Service's RoleFieldNaming is used.
+func (s *Service) roleNaming(r *http.Request) string {
+	for _, profile := range jsonProfiles(r) {
+		switch profile {
+		case CamelCaseNaming, SnakeCaseNaming:
+			return profile
+		}
+	}
+	if s.RoleFieldNaming == SnakeCaseNaming {
+		return SnakeCaseNaming
+	}
+	return CamelCaseNaming
+}
+
+// prettyJSON indents the JSON responses of next for clients asking with
+// pretty=true, e.g. operators reading role responses with curl.  Responses
+// are compact otherwise.
+func prettyJSON(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty")); !pretty {
+			next(w, r)
+			return
+		}
+
+		rec := &responseRecorder{
+			header: http.Header{},
+			status: http.StatusOK,
+		}
+		next(rec, r)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		body := rec.body.Bytes()
+		mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+		if mediaType == JSONType || mediaType == HALType {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, bytes.TrimSpace(body), "", "  "); err == nil {
+				body = append(indented.Bytes(), '\n')
+			}
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(body)
+	}
+}
+
+// encodeSourceRole writes a single role using the naming requested by the
+// client, or as HAL if the client accepts application/hal+json
+func (s *Service) encodeSourceRole(w http.ResponseWriter, r *http.Request, status int, rr sourceRoleResponse) {
+	s.withRoleUsage(&rr)
+	s.withUpdatedAt(&rr)
+	s.withScopeAliases(r, &rr)
+	if wantsHAL(r) {
+		encodeHAL(w, status, newHALRoleResponse(rr), s.Logger)
+		return
+	}
+	encodeJSON(w, status, s.roleRepresentation(r, rr), s.Logger)
+}
+
+// roleRepresentation converts a role to the naming and permission format
+// requested by the client
+func (s *Service) roleRepresentation(r *http.Request, rr sourceRoleResponse) interface{} {
+	legacy := wantsLegacyPermissions(r)
+	if s.roleNaming(r) == SnakeCaseNaming {
+		snake := newSnakeRoleResponse(rr)
+		if legacy {
+			return legacySnakeRoleResponse{snake, legacyPermissions(rr.Permissions)}
+		}
+		return snake
+	}
+	if legacy {
+		return legacyRoleResponse{rr, legacyPermissions(rr.Permissions)}
+	}
+	return rr
+}
+
+// encodeSourceRoles writes a listing of roles using the naming requested by
+// the client.  Clients accepting text/vnd.graphviz receive a DOT graph.  A
+// cursor is included if the listing is a page followed by more roles.
+func (s *Service) encodeSourceRoles(w http.ResponseWriter, r *http.Request, status int, rr []sourceRoleResponse, cursor string) {
+	if wantsGraphviz(r) {
+		s.encodeRoleGraph(w, status, rr)
+		return
+	}
+	if wantsHAL(r) {
+		for i := range rr {
+			s.withRoleUsage(&rr[i])
+			s.withUpdatedAt(&rr[i])
+			s.withScopeAliases(r, &rr[i])
+		}
+		s.encodeHALRoles(w, r, status, rr, cursor)
+		return
+	}
+	res := struct {
+		Roles  interface{} `json:"roles"`
+		Cursor string      `json:"cursor,omitempty"` // Cursor resumes the listing after this page
+	}{s.sourceRolesListing(r, rr), cursor}
+	encodeJSON(w, status, res, s.Logger)
+}
+
+// sourceRolesListing adds usage to the roles and converts them to the
+// naming and permission format requested by the client
+func (s *Service) sourceRolesListing(r *http.Request, rr []sourceRoleResponse) []interface{} {
+	roles := make([]interface{}, len(rr))
+	for i := range rr {
+		s.withRoleUsage(&rr[i])
+		s.withUpdatedAt(&rr[i])
+		s.withScopeAliases(r, &rr[i])
+		roles[i] = s.roleRepresentation(r, rr[i])
+	}
+	return roles
+}
+
+// snakeRoleResponse is the snake_case representation of sourceRoleResponse
+type snakeRoleResponse struct {
+	Name        string                      `json:"name"`
+	Users       []snakeRoleUser             `json:"users"`
+	UserCount   int                         `json:"user_count"`
+	Permissions chronograf.Permissions      `json:"permissions"`
+	SelfLink    string                      `json:"self_link"`
+	Status      string                      `json:"status,omitempty"`
+	LastUsed    *time.Time                  `json:"last_used,omitempty"`
+	UsageCount  *int                        `json:"usage_count,omitempty"`
+	UpdatedAt   *time.Time                  `json:"updated_at,omitempty"`
+	Warnings    []string                    `json:"warnings,omitempty"`
+	Source      *roleSource                 `json:"source,omitempty"`
+	Labels      map[string]string           `json:"labels,omitempty"`
+	Docs        []chronograf.RoleDoc        `json:"docs,omitempty"`
+	Delegations []chronograf.RoleDelegation `json:"delegations,omitempty"`
+	RiskScore   *int                        `json:"risk_score,omitempty"`
+	RiskFactors []roleRiskFactor            `json:"risk_factors,omitempty"`
+}
+
+type snakeRoleUser struct {
+	Name      string     `json:"name"`
+	SelfLink  string     `json:"self_link"`
+	Resolved  *bool      `json:"resolved,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   *bool      `json:"expired,omitempty"`
+}
+
+func newSnakeRoleResponse(rr sourceRoleResponse) snakeRoleResponse {
+	users := make([]snakeRoleUser, len(rr.Users))
+	for i, u := range rr.Users {
+		users[i] = snakeRoleUser{
+			Name:     u.Name,
+			SelfLink: u.Links.Self,
+		}
+		if u.unresolved {
+			resolved := false
+			users[i].Resolved = &resolved
+		}
+		if u.expiresAt != nil {
+			expired := u.expired
+			users[i].ExpiresAt = u.expiresAt
+			users[i].Expired = &expired
+		}
+	}
+	return snakeRoleResponse{
+		Name:        rr.Name,
+		Users:       users,
+		UserCount:   len(users),
+		Permissions: rr.Permissions,
+		SelfLink:    rr.Links.Self,
+		Status:      rr.Status,
+		LastUsed:    rr.LastUsed,
+		UsageCount:  rr.UsageCount,
+		UpdatedAt:   rr.UpdatedAt,
+		Warnings:    rr.Warnings,
+		Source:      rr.Source,
+		Labels:      rr.Labels,
+		Docs:        rr.Docs,
+		Delegations: rr.Delegations,
+		RiskScore:   rr.RiskScore,
+		RiskFactors: rr.RiskFactors,
+	}
+}
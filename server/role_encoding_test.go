@@ -0,0 +1,160 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+)
+
+func TestService_encodeSourceRoles(t *testing.T) {
+	role := &chronograf.Role{
+		Name: "timetravelers",
+		Permissions: chronograf.Permissions{
+			{
+				Scope:   chronograf.AllScope,
+				Allowed: chronograf.Allowances{"ReadData"},
+			},
+		},
+		Users: []chronograf.User{
+			{
+				Name: "marty",
+			},
+		},
+	}
+	tests := []struct {
+		name            string
+		roleFieldNaming string
+		accept          string
+		want            string
+	}{
+		{
+			name: "default naming",
+			want: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"}],"name":"timetravelers","permissions":[{"scope":"all","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}]}
+`,
+		},
+		{
+			name:   "snake_case requested by profile",
+			accept: "application/json; profile=snake_case",
+			want: `{"roles":[{"name":"timetravelers","users":[{"name":"marty","self_link":"/chronograf/v1/sources/1/users/marty"}],"user_count":1,"permissions":[{"scope":"all","allowed":["ReadData"]}],"self_link":"/chronograf/v1/sources/1/roles/timetravelers"}]}
+`,
+		},
+		{
+			name:            "snake_case service default",
+			roleFieldNaming: SnakeCaseNaming,
+			accept:          "text/html, application/json",
+			want: `{"roles":[{"name":"timetravelers","users":[{"name":"marty","self_link":"/chronograf/v1/sources/1/users/marty"}],"user_count":1,"permissions":[{"scope":"all","allowed":["ReadData"]}],"self_link":"/chronograf/v1/sources/1/roles/timetravelers"}]}
+`,
+		},
+		{
+			name:            "camelCase profile overrides service default",
+			roleFieldNaming: SnakeCaseNaming,
+			accept:          "application/json;profile=camelCase",
+			want: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"}],"name":"timetravelers","permissions":[{"scope":"all","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}]}
+`,
+		},
+		{
+			name:   "legacy permissions requested by profile",
+			accept: "application/json; profile=legacy-permissions",
+			want: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"}],"name":"timetravelers","links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"permissions":["ReadData:*"]}]}
+`,
+		},
+		{
+			name:   "legacy permissions with snake_case",
+			accept: `application/json; profile="snake_case legacy-permissions"`,
+			want: `{"roles":[{"name":"timetravelers","users":[{"name":"marty","self_link":"/chronograf/v1/sources/1/users/marty"}],"user_count":1,"self_link":"/chronograf/v1/sources/1/roles/timetravelers","permissions":["ReadData:*"]}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{
+				Logger:          log.New(log.DebugLevel),
+				RoleFieldNaming: tt.roleFieldNaming,
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			s.encodeSourceRoles(w, r, 200, []sourceRoleResponse{newSourceRoleResponse(1, role)}, "")
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.want {
+				t.Errorf("encodeSourceRoles() = \n***%v***\n,\nwant\n***%v***", string(body), tt.want)
+			}
+		})
+	}
+}
+
+func Test_prettyJSON(t *testing.T) {
+	handler := prettyJSON(func(w http.ResponseWriter, r *http.Request) {
+		encodeJSON(w, http.StatusCreated, map[string]interface{}{"name": "timetravelers", "users": []string{"marty"}}, log.New(log.DebugLevel))
+	})
+	tests := []struct {
+		name     string
+		query    string
+		wantBody string
+	}{
+		{
+			name: "Compact by default",
+			wantBody: `{"name":"timetravelers","users":["marty"]}
+`,
+		},
+		{
+			name:  "Indented when pretty",
+			query: "?pretty=true",
+			wantBody: `{
+  "name": "timetravelers",
+  "users": [
+    "marty"
+  ]
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/timetravelers"+tt.query, nil))
+		resp := w.Result()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("%q. prettyJSON() status = %d, want %d", tt.name, resp.StatusCode, http.StatusCreated)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != JSONType {
+			t.Errorf("%q. prettyJSON() Content-Type = %s, want %s", tt.name, ct, JSONType)
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("%q. prettyJSON() = \n%s\nwant\n%s", tt.name, body, tt.wantBody)
+		}
+	}
+}
+
+func Test_legacyPermissions(t *testing.T) {
+	perms := chronograf.Permissions{
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "telegraf",
+			Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+		},
+		{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{"ViewChronograf"},
+		},
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "_internal",
+			Allowed: chronograf.Allowances{"WriteData"},
+			Deny:    true,
+		},
+	}
+	want := []string{"ReadData:telegraf", "WriteData:telegraf", "ViewChronograf:*", "!WriteData:_internal"}
+	if got := legacyPermissions(perms); !reflect.DeepEqual(got, want) {
+		t.Errorf("legacyPermissions() = %v, want %v", got, want)
+	}
+}
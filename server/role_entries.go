@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+// updateRoleEntry changes the role in store and then, if the role has the
+// entry Chronograf keeps for it, replaces the entry with put.  Updates
+// without the entry keep the one of the role.
+func updateRoleEntry(ctx context.Context, store chronograf.RolesStore, role *chronograf.Role, has bool, put func() error) error {
+	if err := store.Update(ctx, role); err != nil {
+		return err
+	}
+	if !has {
+		return nil
+	}
+	return put()
+}
+
+// deleteRoleEntry removes the role from store and then the entry Chronograf
+// keeps for it with remove.  The role is gone once store deletes it, so
+// failing to remove the entry is logged rather than failing the delete.
+func deleteRoleEntry(ctx context.Context, store chronograf.RolesStore, role *chronograf.Role, logger chronograf.Logger, entry string, remove func() error) error {
+	if err := store.Delete(ctx, role); err != nil {
+		return err
+	}
+	if err := remove(); err != nil {
+		logger.
+			WithField("component", "roles").
+			WithField("role", role.Name).
+			Error("Unable to remove "+entry+" of deleted role: ", err)
+	}
+	return nil
+}
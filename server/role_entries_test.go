@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_deleteRoleEntry(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteErr   error
+		removeErr   error
+		wantRemoved bool
+		wantErr     bool
+	}{
+		{
+			name:        "removes the entry of the deleted role",
+			wantRemoved: true,
+		},
+		{
+			name:        "failing to remove the entry of the deleted role is not an error",
+			removeErr:   errors.New("bucket unavailable"),
+			wantRemoved: true,
+		},
+		{
+			name:      "keeps the entry of roles that fail to delete",
+			deleteErr: errors.New("role is in use"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		store := &mocks.RolesStore{
+			DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+				return tt.deleteErr
+			},
+		}
+		removed := false
+		err := deleteRoleEntry(context.Background(), store, &chronograf.Role{Name: "oncall"}, log.New(log.DebugLevel), "the labels", func() error {
+			removed = true
+			return tt.removeErr
+		})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. deleteRoleEntry() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if removed != tt.wantRemoved {
+			t.Errorf("%q. deleteRoleEntry() removed = %v, want %v", tt.name, removed, tt.wantRemoved)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// Types of role change events
+const (
+	RoleCreated = "create"
+	RoleUpdated = "update"
+	RoleDeleted = "delete"
+)
+
+// roleEventsBuffer is how many events a subscriber may fall behind before
+// it is dropped
+const roleEventsBuffer = 64
+
+// roleEventsHeartbeat is how often an idle event stream sends a comment to
+// keep the connection open
+var roleEventsHeartbeat = 30 * time.Second
+
+// roleEvent is a change made to a role of a source through Chronograf, or
+// the membership of a user in a role about to expire
+type roleEvent struct {
+	Type      string     `json:"type"`
+	Source    int        `json:"source"`
+	Role      string     `json:"role"`
+	Time      time.Time  `json:"time"`
+	User      string     `json:"user,omitempty"`      // User is the member whose membership is expiring
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // ExpiresAt is when the expiring membership ends
+}
+
+// RoleEvents passes the changes made to roles on to the subscribers of
+// each source.  Events are not kept; a subscriber receives the events
+// published while it is subscribed.
+type RoleEvents struct {
+	mu   sync.Mutex
+	subs map[chan roleEvent]int // subs are the channels of subscribers and the source of each
+}
+
+// NewRoleEvents creates role events without subscribers
+func NewRoleEvents() *RoleEvents {
+	return &RoleEvents{
+		subs: map[chan roleEvent]int{},
+	}
+}
+
+// subscribe receives the events of the roles of srcID until cancel is
+// called.  The channel is closed if the subscriber falls too far behind,
+// so events are never silently skipped.
+func (e *RoleEvents) subscribe(srcID int) (events <-chan roleEvent, cancel func()) {
+	ch := make(chan roleEvent, roleEventsBuffer)
+	e.mu.Lock()
+	e.subs[ch] = srcID
+	e.mu.Unlock()
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.subs[ch]; ok {
+			delete(e.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends ev to the subscribers of its source without waiting on
+// them.  Subscribers whose buffer is full are dropped.
+func (e *RoleEvents) publish(ev roleEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch, srcID := range e.subs {
+		if srcID != ev.Source {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+			delete(e.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+var _ chronograf.RolesStore = &publishingRolesStore{}
+
+// publishingRolesStore publishes an event for each change made to the roles
+// of a source through the underlying RolesStore
+type publishingRolesStore struct {
+	chronograf.RolesStore
+	srcID  int
+	events *RoleEvents
+}
+
+func (s *publishingRolesStore) publish(typ, role string) {
+	s.events.publish(roleEvent{
+		Type:   typ,
+		Source: s.srcID,
+		Role:   role,
+		Time:   time.Now().UTC(),
+	})
+}
+
+// Add creates a new role and publishes its creation
+func (s *publishingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(RoleCreated, role.Name)
+	return res, nil
+}
+
+// Update changes a role and publishes the change
+func (s *publishingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	if err := s.RolesStore.Update(ctx, role); err != nil {
+		return err
+	}
+	s.publish(RoleUpdated, role.Name)
+	return nil
+}
+
+// Delete removes a role and publishes its removal
+func (s *publishingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	if err := s.RolesStore.Delete(ctx, role); err != nil {
+		return err
+	}
+	s.publish(RoleDeleted, role.Name)
+	return nil
+}
+
+// SourceRoleEvents streams the changes made to the roles of a source as
+// server-sent events until the client disconnects.  The event type is
+// the type of change.  If the client falls too far behind the stream ends
+// and the client should fetch the roles again.
+func (s *Service) SourceRoleEvents(w http.ResponseWriter, r *http.Request) {
+	if s.RoleEvents == nil {
+		Error(w, http.StatusNotFound, "Role events are not enabled", s.Logger)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		Error(w, http.StatusInternalServerError, "Streaming is not supported", s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	if _, ok := s.hasRoles(ctx, srcID, ts); !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	events, cancel := s.RoleEvents.subscribe(srcID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(roleEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+		}
+		flusher.Flush()
+	}
+}
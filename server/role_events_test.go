@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+// endRoleEvents closes the channels of every subscriber, ending their
+// streams once the events already published are sent
+func endRoleEvents(e *RoleEvents) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		delete(e.subs, ch)
+		close(ch)
+	}
+}
+
+func TestRoleEvents_publish(t *testing.T) {
+	e := NewRoleEvents()
+	pics, cancel := e.subscribe(1)
+	defer cancel()
+	other, cancelOther := e.subscribe(2)
+	defer cancelOther()
+
+	for i := 0; i < roleEventsBuffer+1; i++ {
+		e.publish(roleEvent{Type: RoleUpdated, Source: 1, Role: "biffsgang"})
+	}
+	n := 0
+	for range pics {
+		n++
+	}
+	if n != roleEventsBuffer {
+		t.Errorf("RoleEvents.publish() sent %d events to a subscriber falling behind, want %d then the stream to end", n, roleEventsBuffer)
+	}
+	select {
+	case ev := <-other:
+		t.Errorf("RoleEvents.publish() sent %v to a subscriber of another source", ev)
+	default:
+	}
+}
+
+func TestService_SourceRoleEvents(t *testing.T) {
+	h := &Service{
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					return chronograf.Source{
+						ID: ID,
+					}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+						return role, nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						return nil
+					},
+					DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+						return nil
+					},
+				}, nil
+			},
+		},
+		RoleEvents: NewRoleEvents(),
+		Logger:     log.New(log.DebugLevel),
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles-events", nil)
+	r = r.WithContext(httprouter.WithParams(
+		context.Background(),
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: "1",
+			},
+		}))
+
+	done := make(chan struct{})
+	go func() {
+		h.SourceRoleEvents(w, r)
+		close(done)
+	}()
+	for {
+		h.RoleEvents.mu.Lock()
+		subscribed := len(h.RoleEvents.subs) > 0
+		h.RoleEvents.mu.Unlock()
+		if subscribed {
+			break
+		}
+		runtime.Gosched()
+	}
+
+	ctx := context.Background()
+	for srcID := 1; srcID <= 2; srcID++ {
+		ts, _ := h.TimeSeriesClient.New(chronograf.Source{ID: srcID}, nil)
+		roles, _ := h.hasRoles(ctx, srcID, ts)
+		role := &chronograf.Role{Name: "biffsgang"}
+		roles.Add(ctx, role)
+		roles.Update(ctx, role)
+		roles.Delete(ctx, role)
+	}
+	endRoleEvents(h.RoleEvents)
+	<-done
+
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("SourceRoleEvents() Content-Type = %s, want text/event-stream", got)
+	}
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	got := regexp.MustCompile(`,"time":"[^"]*"`).ReplaceAllString(string(body), "")
+	want := `event: create
+data: {"type":"create","source":1,"role":"biffsgang"}
+
+event: update
+data: {"type":"update","source":1,"role":"biffsgang"}
+
+event: delete
+data: {"type":"delete","source":1,"role":"biffsgang"}
+
+`
+	if got != want {
+		t.Errorf("SourceRoleEvents() = \n***%v***\n,\nwant\n***%v***", got, want)
+	}
+}
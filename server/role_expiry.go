@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// unexpiredPermissions returns the permissions that have not expired by now.
+// expired is true if any permission was dropped.
+func unexpiredPermissions(perms chronograf.Permissions, now time.Time) (kept chronograf.Permissions, expired bool) {
+	kept = make(chronograf.Permissions, 0, len(perms))
+	for i := range perms {
+		if perms[i].Expired(now) {
+			expired = true
+			continue
+		}
+		kept = append(kept, perms[i])
+	}
+	return kept, expired
+}
+
+// sweepExpiredPermissions revokes expired role permissions, role memberships
+// and temporary grants of every source each interval until ctx is done.  The first sweep
+// is immediate so permissions, memberships and grants that expired while
+// Chronograf was down are revoked.
+func sweepExpiredPermissions(ctx context.Context, s *Service, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	s.SweepExpiredPermissions(ctx, time.Now())
+	s.RevokeTemporaryGrants(ctx, time.Now())
+	for {
+		select {
+		case <-tick.C:
+			s.SweepExpiredPermissions(ctx, time.Now())
+			s.RevokeTemporaryGrants(ctx, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SweepExpiredPermissions removes the permissions and users whose
+// membership expired by now from the roles of all role capable sources, and
+// announces the memberships about to expire.  Expiries are kept by
+// Chronograf rather than the sources, so only sources with expiring
+// permissions or memberships are connected to.  Sources that cannot be
+// reached are logged and skipped.
+func (s *Service) SweepExpiredPermissions(ctx context.Context, now time.Time) {
+	ctx = serverContext(ctx)
+	srcs, err := s.Store.Sources(ctx).All(ctx)
+	if err != nil {
+		s.Logger.
+			WithField("component", "roles").
+			Error("Unable to list sources to sweep expired permissions: ", err)
+		return
+	}
+
+	for _, src := range srcs {
+		log := s.Logger.
+			WithField("component", "roles").
+			WithField("source", src.ID)
+
+		// Sources whose expiries cannot be read are swept regardless
+		expiring, err := s.hasExpiries(ctx, src.ID)
+		if err != nil {
+			log.Error("Unable to read expiries of source roles: ", err)
+		} else if !expiring {
+			continue
+		}
+
+		ts, err := s.TimeSeries(src)
+		if err != nil {
+			log.Error("Unable to connect to source to sweep expired permissions: ", err)
+			continue
+		}
+		if err = ts.Connect(ctx, &src); err != nil {
+			log.Error("Unable to connect to source to sweep expired permissions: ", err)
+			continue
+		}
+
+		roles, ok := s.hasRoles(ctx, src.ID, ts)
+		if !ok {
+			continue
+		}
+		all, err := roles.All(ctx)
+		if err != nil {
+			log.Error("Unable to list roles to sweep expired permissions: ", err)
+			continue
+		}
+
+		for _, role := range all {
+			s.announceExpiringMemberships(src.ID, &role, now)
+
+			update := chronograf.Role{Name: role.Name}
+			kept, expired := unexpiredPermissions(role.Permissions, now)
+			if expired {
+				update.Permissions = kept
+			}
+			// Updates without users keep them, so users are only given
+			// when memberships expired
+			users, left := unexpiredUsers(role.Users, now)
+			if left {
+				update.Users = users
+			}
+			if !expired && !left {
+				continue
+			}
+			if err := roles.Update(ctx, &update); err != nil {
+				log.WithField("role", role.Name).Error("Unable to revoke expired permissions: ", err)
+				continue
+			}
+			log.WithField("role", role.Name).Info("Revoked expired permissions")
+		}
+	}
+}
+
+// hasExpiries checks if the roles of the source have expiring permissions
+// or memberships
+func (s *Service) hasExpiries(ctx context.Context, srcID int) (bool, error) {
+	if s.RoleMemberships != nil {
+		memberships, err := s.RoleMemberships.All(ctx, srcID)
+		if err != nil {
+			return false, err
+		}
+		for _, expiries := range memberships {
+			if len(expiries) > 0 {
+				return true, nil
+			}
+		}
+	}
+	if s.RolePermissions != nil {
+		retained, err := s.RolePermissions.All(ctx, srcID)
+		if err != nil {
+			return false, err
+		}
+		for _, perms := range retained {
+			for _, perm := range perms {
+				if perm.ExpiresAt != nil {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
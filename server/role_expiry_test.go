@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_unexpiredPermissions(t *testing.T) {
+	now := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		perms       chronograf.Permissions
+		want        chronograf.Permissions
+		wantExpired bool
+	}{
+		{
+			name:  "no permissions",
+			perms: nil,
+			want:  chronograf.Permissions{},
+		},
+		{
+			name: "permissions without expiry are kept",
+			perms: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"ReadData"},
+				},
+			},
+			want: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"ReadData"},
+				},
+			},
+		},
+		{
+			name: "expired permissions are dropped",
+			perms: chronograf.Permissions{
+				{
+					Scope:     chronograf.DBScope,
+					Name:      "delorean",
+					Allowed:   chronograf.Allowances{"ReadData"},
+					ExpiresAt: &past,
+				},
+				{
+					Scope:     chronograf.DBScope,
+					Name:      "hillvalley",
+					Allowed:   chronograf.Allowances{"WriteData"},
+					ExpiresAt: &future,
+				},
+				{
+					Scope:     chronograf.AllScope,
+					Allowed:   chronograf.Allowances{"ViewChronograf"},
+					ExpiresAt: &now,
+				},
+			},
+			want: chronograf.Permissions{
+				{
+					Scope:     chronograf.DBScope,
+					Name:      "hillvalley",
+					Allowed:   chronograf.Allowances{"WriteData"},
+					ExpiresAt: &future,
+				},
+			},
+			wantExpired: true,
+		},
+	}
+	for _, tt := range tests {
+		got, expired := unexpiredPermissions(tt.perms, now)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. unexpiredPermissions() = %v, want %v", tt.name, got, tt.want)
+		}
+		if expired != tt.wantExpired {
+			t.Errorf("%q. unexpiredPermissions() expired = %v, want %v", tt.name, expired, tt.wantExpired)
+		}
+	}
+}
+
+func TestService_SweepExpiredPermissions(t *testing.T) {
+	now := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	updates := []chronograf.Role{}
+	connected := []int{}
+	s := &Service{
+		Logger: log.New(log.DebugLevel),
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				AllF: func(ctx context.Context) ([]chronograf.Source, error) {
+					return []chronograf.Source{{ID: 1}, {ID: 2}}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				connected = append(connected, src.ID)
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+						return []chronograf.Role{
+							{
+								Name: "biffsgang",
+								Permissions: chronograf.Permissions{
+									{
+										Scope:   chronograf.DBScope,
+										Name:    "grays_sports_almanac",
+										Allowed: chronograf.Allowances{"ReadData"},
+									},
+								},
+							},
+							{
+								Name: "timetravelers",
+								Permissions: chronograf.Permissions{
+									{
+										Scope:   chronograf.DBScope,
+										Name:    "delorean",
+										Allowed: chronograf.Allowances{"ReadData"},
+									},
+								},
+								Users: []chronograf.User{
+									{Name: "marty"},
+									{Name: "doc"},
+								},
+							},
+						}, nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						updates = append(updates, *role)
+						return nil
+					},
+				}, nil
+			},
+		},
+		// Only the roles of source 1 have expiries
+		RolePermissions: &mocks.RolePermissionsStore{
+			AllF: func(ctx context.Context, srcID int) (map[string]chronograf.Permissions, error) {
+				if srcID != 1 {
+					return nil, nil
+				}
+				return map[string]chronograf.Permissions{
+					"biffsgang": {
+						{
+							Scope:     chronograf.DBScope,
+							Name:      "grays_sports_almanac",
+							Allowed:   chronograf.Allowances{"ReadData"},
+							ExpiresAt: &past,
+						},
+					},
+				}, nil
+			},
+			PutF: func(ctx context.Context, srcID int, role string, perms chronograf.Permissions) error {
+				return nil
+			},
+		},
+		RoleMemberships: &mocks.RoleMembershipsStore{
+			AllF: func(ctx context.Context, srcID int) (map[string]map[string]time.Time, error) {
+				if srcID != 1 {
+					return nil, nil
+				}
+				return map[string]map[string]time.Time{
+					"timetravelers": {"doc": past},
+				}, nil
+			},
+			PutF: func(ctx context.Context, srcID int, role string, expiries map[string]time.Time) error {
+				return nil
+			},
+		},
+	}
+
+	s.SweepExpiredPermissions(context.Background(), now)
+
+	want := []chronograf.Role{
+		{
+			Name:        "biffsgang",
+			Permissions: chronograf.Permissions{},
+		},
+		{
+			Name:  "timetravelers",
+			Users: []chronograf.User{{Name: "marty"}},
+		},
+	}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("Service.SweepExpiredPermissions() updates = %v, want %v", updates, want)
+	}
+	if !reflect.DeepEqual(connected, []int{1}) {
+		t.Errorf("Service.SweepExpiredPermissions() connected to sources %v, want only source 1", connected)
+	}
+}
+
+func Test_sweepExpiredPermissions(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	updates := []chronograf.Role{}
+	s := &Service{
+		Logger: log.New(log.DebugLevel),
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				AllF: func(ctx context.Context) ([]chronograf.Source, error) {
+					return []chronograf.Source{{ID: 1}}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+						return []chronograf.Role{
+							{
+								Name: "biffsgang",
+								Permissions: chronograf.Permissions{
+									{
+										Scope:   chronograf.DBScope,
+										Name:    "grays_sports_almanac",
+										Allowed: chronograf.Allowances{"ReadData"},
+									},
+								},
+							},
+						}, nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						updates = append(updates, *role)
+						return nil
+					},
+				}, nil
+			},
+		},
+		RolePermissions: &mocks.RolePermissionsStore{
+			AllF: func(ctx context.Context, srcID int) (map[string]chronograf.Permissions, error) {
+				return map[string]chronograf.Permissions{
+					"biffsgang": {
+						{
+							Scope:     chronograf.DBScope,
+							Name:      "grays_sports_almanac",
+							Allowed:   chronograf.Allowances{"ReadData"},
+							ExpiresAt: &past,
+						},
+					},
+				}, nil
+			},
+			PutF: func(ctx context.Context, srcID int, role string, perms chronograf.Permissions) error {
+				return nil
+			},
+		},
+	}
+
+	// Permissions that expired while Chronograf was down are revoked
+	// without waiting for the first interval
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sweepExpiredPermissions(ctx, s, time.Hour)
+
+	want := []chronograf.Role{
+		{
+			Name:        "biffsgang",
+			Permissions: chronograf.Permissions{},
+		},
+	}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("sweepExpiredPermissions() updates = %v, want %v", updates, want)
+	}
+}
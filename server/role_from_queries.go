@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/influxdb/influxql"
+)
+
+// loggedQuery is a query run by a user, as found in a query log
+type loggedQuery struct {
+	Query string `json:"query"`
+	DB    string `json:"db,omitempty"` // DB is the database the query ran against when it does not name one
+}
+
+type roleFromQueriesRequest struct {
+	Name    string        `json:"name,omitempty"` // Name of the suggested role; defaults to suggested
+	User    string        `json:"user,omitempty"` // User who ran the queries; added to the suggested role
+	Queries []loggedQuery `json:"queries"`
+}
+
+func (r *roleFromQueriesRequest) Valid() error {
+	var errs validationErrors
+	if len(r.Queries) == 0 {
+		errs.add("queries", "At least one query is required")
+	}
+	for i, q := range r.Queries {
+		if q.Query == "" {
+			errs.add(fmt.Sprintf("queries[%d].query", i), "Query is required")
+		}
+	}
+	return errs.err()
+}
+
+// unparsedQuery is a logged query no permissions could be derived from
+type unparsedQuery struct {
+	Field   string `json:"field"`
+	Query   string `json:"query"`
+	Message string `json:"message"`
+}
+
+type roleFromQueriesResponse struct {
+	Role     sourceRoleResponse `json:"role"`
+	Unparsed []unparsedQuery    `json:"unparsed"`
+}
+
+// statementPermissions returns the Enterprise permissions required to run
+// stmt against db, the database of the query.  Statements reading or
+// changing data require allowances of their database; statements
+// administering the source require allowances of all databases.
+func statementPermissions(stmt influxql.Statement, db string) (chronograf.Permissions, error) {
+	on := func(database, allowance string) (chronograf.Permissions, error) {
+		if database == "" {
+			database = db
+		}
+		if database == "" {
+			return nil, fmt.Errorf("%s requires a database; set db of the query", allowance)
+		}
+		return chronograf.Permissions{{
+			Scope:   chronograf.DBScope,
+			Name:    database,
+			Allowed: chronograf.Allowances{allowance},
+		}}, nil
+	}
+	all := func(allowance string) (chronograf.Permissions, error) {
+		return chronograf.Permissions{{
+			Scope:   chronograf.AllScope,
+			Allowed: chronograf.Allowances{allowance},
+		}}, nil
+	}
+
+	switch s := stmt.(type) {
+	case *influxql.SelectStatement:
+		perms := chronograf.Permissions{}
+		for _, source := range s.Sources {
+			m, ok := source.(*influxql.Measurement)
+			if !ok {
+				return nil, fmt.Errorf("Source %s is not a measurement", source)
+			}
+			p, err := on(m.Database, "ReadData")
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, p...)
+		}
+		if s.Target != nil {
+			p, err := on(s.Target.Measurement.Database, "WriteData")
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, p...)
+		}
+		return perms, nil
+	case *influxql.ShowMeasurementsStatement, *influxql.ShowSeriesStatement, *influxql.ShowTagKeysStatement,
+		*influxql.ShowTagValuesStatement, *influxql.ShowFieldKeysStatement, *influxql.ShowRetentionPoliciesStatement:
+		return on(defaultDatabase(stmt), "ReadData")
+	case *influxql.ShowDatabasesStatement:
+		// Every user may list the databases they can access
+		return chronograf.Permissions{}, nil
+	case *influxql.DeleteStatement, *influxql.DropSeriesStatement, *influxql.DropMeasurementStatement:
+		// Only DELETE names its database
+		return on(defaultDatabase(stmt), "DropData")
+	case *influxql.CreateDatabaseStatement:
+		return all("CreateDatabase")
+	case *influxql.DropDatabaseStatement:
+		return on(s.Name, "DropDatabase")
+	case *influxql.CreateRetentionPolicyStatement, *influxql.AlterRetentionPolicyStatement:
+		return on(defaultDatabase(stmt), "CreateDatabase")
+	case *influxql.DropRetentionPolicyStatement:
+		return on(s.Database, "DropDatabase")
+	case *influxql.CreateContinuousQueryStatement, *influxql.DropContinuousQueryStatement:
+		return on(defaultDatabase(stmt), "ManageContinuousQuery")
+	case *influxql.ShowContinuousQueriesStatement:
+		return all("ManageContinuousQuery")
+	case *influxql.ShowQueriesStatement, *influxql.KillQueryStatement:
+		return all("ManageQuery")
+	case *influxql.CreateSubscriptionStatement, *influxql.DropSubscriptionStatement, *influxql.ShowSubscriptionsStatement:
+		return all("ManageSubscription")
+	case *influxql.ShowShardsStatement, *influxql.ShowShardGroupsStatement, *influxql.DropShardStatement:
+		return all("ManageShard")
+	case *influxql.ShowStatsStatement, *influxql.ShowDiagnosticsStatement:
+		return all("Monitor")
+	case *influxql.ShowUsersStatement, *influxql.ShowGrantsForUserStatement:
+		return all("ViewAdmin")
+	case *influxql.CreateUserStatement, *influxql.DropUserStatement, *influxql.SetPasswordUserStatement,
+		*influxql.GrantStatement, *influxql.GrantAdminStatement, *influxql.RevokeStatement, *influxql.RevokeAdminStatement:
+		return all("CreateUserAndRole")
+	}
+	return nil, fmt.Errorf("Statement %T is not supported", stmt)
+}
+
+// defaultDatabase returns the database stmt names; empty if it names none
+func defaultDatabase(stmt influxql.Statement) string {
+	if s, ok := stmt.(influxql.HasDefaultDatabase); ok {
+		return s.DefaultDatabase()
+	}
+	return ""
+}
+
+// queryPermissions returns the permissions required to run every
+// statement of q
+func queryPermissions(q loggedQuery) (chronograf.Permissions, error) {
+	query, err := influxql.ParseQuery(q.Query)
+	if err != nil {
+		return nil, err
+	}
+	perms := chronograf.Permissions{}
+	for _, stmt := range query.Statements {
+		p, err := statementPermissions(stmt, q.DB)
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, p...)
+	}
+	return perms, nil
+}
+
+// roleFromQueries derives the permissions required to run every query.
+// Queries that cannot be parsed, or require permissions that cannot be
+// derived, are returned rather than skipped.
+func roleFromQueries(queries []loggedQuery) (chronograf.Permissions, []unparsedQuery) {
+	perms := chronograf.Permissions{}
+	unparsed := []unparsedQuery{}
+	for i, q := range queries {
+		p, err := queryPermissions(q)
+		if err != nil {
+			unparsed = append(unparsed, unparsedQuery{
+				Field:   fmt.Sprintf("queries[%d]", i),
+				Query:   q.Query,
+				Message: err.Error(),
+			})
+			continue
+		}
+		perms = append(perms, p...)
+	}
+	// Queries requiring the same allowance of a database need it once
+	return mergePermissionsWith(perms, MostPermissive, nil), unparsed
+}
+
+// SuggestSourceRoleFromQueries proposes a role granting exactly the
+// permissions needed to run the queries of a query log.  Queries no
+// permissions could be derived from are reported.  Nothing is created.
+func (s *Service) SuggestSourceRoleFromQueries(w http.ResponseWriter, r *http.Request) {
+	var req roleFromQueriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	if _, ok := s.hasRoles(ctx, srcID, ts); !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	role := chronograf.Role{
+		Name: req.Name,
+	}
+	if role.Name == "" {
+		role.Name = "suggested"
+	}
+	if req.User != "" {
+		role.Users = []chronograf.User{{Name: req.User}}
+	}
+
+	var res roleFromQueriesResponse
+	role.Permissions, res.Unparsed = roleFromQueries(req.Queries)
+	res.Role = newSourceRoleResponse(srcID, &role)
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SuggestSourceRoleFromQueries(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Permissions of the queries",
+			body: `{"user": "marty", "queries": [
+				{"query": "SELECT mean(\"usage_user\") FROM \"cpu\" WHERE time > now() - 1h", "db": "telegraf"},
+				{"query": "SELECT * INTO \"almanac\".\"autogen\".\"scores\" FROM \"telegraf\".\"autogen\".\"scores\""},
+				{"query": "SHOW MEASUREMENTS; DROP SERIES FROM \"cpu\"", "db": "telegraf"},
+				{"query": "SHOW QUERIES"}
+			]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"role":{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"}],"name":"suggested","permissions":[{"scope":"database","name":"telegraf","allowed":["DropData","ReadData"]},{"scope":"database","name":"almanac","allowed":["WriteData"]},{"scope":"all","allowed":["ManageQuery"]}],"links":{"self":"/chronograf/v1/sources/1/roles/suggested"}},"unparsed":[]}
+`,
+		},
+		{
+			name: "Unparseable queries are reported",
+			body: `{"name": "docs", "queries": [
+				{"query": "SELECT FROM", "db": "telegraf"},
+				{"query": "SELECT * FROM \"cpu\""},
+				{"query": "SHOW TAG KEYS", "db": "telegraf"}
+			]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"role":{"users":[],"name":"docs","permissions":[{"scope":"database","name":"telegraf","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/docs"}},"unparsed":[{"field":"queries[0]","query":"SELECT FROM","message":"found FROM, expected identifier, string, number, bool at line 1, char 8"},{"field":"queries[1]","query":"SELECT * FROM \"cpu\"","message":"ReadData requires a database; set db of the query"}]}
+`,
+		},
+		{
+			name:       "No queries",
+			body:       `{"queries": []}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"At least one query is required","errors":[{"field":"queries","message":"At least one query is required"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-from-queries", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SuggestSourceRoleFromQueries(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SuggestSourceRoleFromQueries() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SuggestSourceRoleFromQueries() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
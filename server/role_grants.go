@@ -0,0 +1,344 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// temporaryGrantRequest grants permissions to a role for a while.  With
+// Ephemeral, the role is created for the grant and removed when it expires.
+type temporaryGrantRequest struct {
+	Permissions chronograf.Permissions `json:"permissions"`
+	Duration    string                 `json:"duration"`  // Duration is how long the permissions are granted, e.g. 90m
+	Ephemeral   bool                   `json:"ephemeral"` // Ephemeral creates the role for the grant
+	Users       []chronograf.User      `json:"users"`     // Users are the members of an ephemeral role
+
+	duration time.Duration
+	policy   permissionPolicy
+}
+
+func (r *temporaryGrantRequest) Valid() error {
+	var errs validationErrors
+	if len(r.Permissions) == 0 {
+		errs.add("permissions", "Permissions to grant required")
+	}
+	for i, perm := range r.Permissions {
+		if perm.Deny {
+			errs.add(fmt.Sprintf("permissions[%d].deny", i), "Temporary permissions must be grants")
+		}
+		if len(perm.Allowed) == 0 {
+			errs.add(fmt.Sprintf("permissions[%d].allowed", i), "Allowances to grant required")
+		}
+	}
+	errs.merge("permissions", validPermissions(&r.Permissions, r.policy))
+
+	d, err := time.ParseDuration(r.Duration)
+	if err != nil || d <= 0 {
+		errs.add("duration", "Duration must be a positive duration, e.g. 90m")
+	}
+	r.duration = d
+
+	if len(r.Users) > 0 && !r.Ephemeral {
+		errs.add("users", "Users may only be given to ephemeral roles")
+	}
+	for i, user := range r.Users {
+		if user.Name == "" {
+			errs.add(fmt.Sprintf("users[%d].name", i), "Username required")
+		}
+	}
+	return errs.err()
+}
+
+type temporaryGrantsResponse struct {
+	Role   sourceRoleResponse          `json:"role"`
+	Grants []chronograf.TemporaryGrant `json:"grants"`
+}
+
+// sameScope is true if both permissions are grants of the same database
+func sameScope(a, b chronograf.Permission) bool {
+	return !a.Deny && !b.Deny && a.Scope == b.Scope && a.Name == b.Name
+}
+
+// addAllowances returns a copy of perms granting the allowances of perm, and
+// perm with only the allowances perms did not already grant in its scope
+func addAllowances(perms chronograf.Permissions, perm chronograf.Permission) (chronograf.Permissions, chronograf.Permission) {
+	res := append(chronograf.Permissions{}, perms...)
+	for i := range res {
+		if !sameScope(res[i], perm) {
+			continue
+		}
+		had := map[string]bool{}
+		for _, a := range res[i].Allowed {
+			had[a] = true
+		}
+		added := chronograf.Allowances{}
+		for _, a := range perm.Allowed {
+			if !had[a] {
+				had[a] = true
+				added = append(added, a)
+			}
+		}
+		res[i].Allowed = append(append(chronograf.Allowances{}, res[i].Allowed...), added...)
+		perm.Allowed = added
+		return res, perm
+	}
+	return append(res, perm), perm
+}
+
+// removeAllowances returns a copy of perms no longer granting the
+// allowances of perm.  Permissions left without allowances are dropped.
+func removeAllowances(perms chronograf.Permissions, perm chronograf.Permission) chronograf.Permissions {
+	revoked := map[string]bool{}
+	for _, a := range perm.Allowed {
+		revoked[a] = true
+	}
+	res := make(chronograf.Permissions, 0, len(perms))
+	for _, p := range perms {
+		if !sameScope(p, perm) {
+			res = append(res, p)
+			continue
+		}
+		kept := chronograf.Allowances{}
+		for _, a := range p.Allowed {
+			if !revoked[a] {
+				kept = append(kept, a)
+			}
+		}
+		if len(kept) > 0 {
+			p.Allowed = kept
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// NewTemporaryGrant grants permissions to a role of a source until the
+// duration passes, when the permission sweeper revokes them.  Grants are
+// kept by Chronograf so pending revocations survive restarts.
+func (s *Service) NewTemporaryGrant(w http.ResponseWriter, r *http.Request) {
+	req := temporaryGrantRequest{policy: s.permissionPolicy()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := s.expandScopeAliases(req.Permissions); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+	if err := s.expandClassifications(&req.Permissions); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	if s.TemporaryGrants == nil {
+		Error(w, http.StatusNotFound, "Temporary grants are not enabled", s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	if s.isProtectedRole(rid) {
+		protectedRole(w, rid, s.Logger)
+		return
+	}
+
+	var current *chronograf.Role
+	grant := chronograf.Role{Name: rid}
+	grants := []chronograf.TemporaryGrant{}
+	expiresAt := time.Now().Add(req.duration).UTC()
+	if req.Ephemeral {
+		if existing, ok := s.existingRoleName(ctx, roles, rid); ok {
+			Error(w, http.StatusBadRequest, fmt.Sprintf("Source %d already has role %s", srcID, existing), s.Logger)
+			return
+		}
+		grant.Users = req.Users
+	} else {
+		if current, err = roles.Get(ctx, rid); err != nil {
+			Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+			return
+		}
+		grant.Permissions = current.Permissions
+	}
+	for _, perm := range req.Permissions {
+		grant.Permissions, perm = addAllowances(grant.Permissions, perm)
+		if len(perm.Allowed) > 0 {
+			grants = append(grants, chronograf.TemporaryGrant{Permission: perm})
+		}
+	}
+	if len(grants) == 0 {
+		Error(w, http.StatusConflict, fmt.Sprintf("Role %s already has the permissions", rid), s.Logger)
+		return
+	}
+
+	if req.Ephemeral {
+		_, err = roles.Add(ctx, &grant)
+	} else {
+		err = roles.Update(ctx, &chronograf.Role{Name: rid, Permissions: grant.Permissions})
+	}
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	for i := range grants {
+		grants[i].SourceID = srcID
+		grants[i].Role = rid
+		grants[i].Ephemeral = req.Ephemeral
+		grants[i].ExpiresAt = expiresAt
+		if _, err := s.TemporaryGrants.Add(ctx, &grants[i]); err != nil {
+			// Without its record the grant would never be revoked
+			s.undoTemporaryGrant(ctx, roles, current, rid)
+			for _, g := range grants[:i] {
+				_ = s.TemporaryGrants.Delete(ctx, &g)
+			}
+			Error(w, http.StatusInternalServerError, fmt.Sprintf("Unable to record temporary grant: %v", err), s.Logger)
+			return
+		}
+	}
+
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("role", rid).
+		WithField("ephemeral", req.Ephemeral).
+		Info("Granted temporary permissions until ", expiresAt.Format(time.RFC3339))
+
+	res := temporaryGrantsResponse{
+		Role:   newSourceRoleResponse(srcID, &grant),
+		Grants: grants,
+	}
+	encodeJSON(w, http.StatusCreated, res, s.Logger)
+}
+
+// undoTemporaryGrant restores the role as it was before a grant.  An
+// ephemeral role, having no prior state, is removed.
+func (s *Service) undoTemporaryGrant(ctx context.Context, roles chronograf.RolesStore, current *chronograf.Role, rid string) {
+	var err error
+	if current == nil {
+		err = roles.Delete(ctx, &chronograf.Role{Name: rid})
+	} else {
+		err = roles.Update(ctx, &chronograf.Role{Name: rid, Permissions: current.Permissions})
+	}
+	if err != nil {
+		s.Logger.
+			WithField("component", "roles").
+			WithField("role", rid).
+			Error("Unable to undo unrecorded temporary grant: ", err)
+	}
+}
+
+// RevokeTemporaryGrants revokes the temporary grants that expired by now.
+// Grants whose source cannot be reached are kept to be retried.
+func (s *Service) RevokeTemporaryGrants(ctx context.Context, now time.Time) {
+	if s.TemporaryGrants == nil {
+		return
+	}
+	ctx = serverContext(ctx)
+	grants, err := s.TemporaryGrants.All(ctx)
+	if err != nil {
+		s.Logger.
+			WithField("component", "roles").
+			Error("Unable to list temporary grants to revoke: ", err)
+		return
+	}
+
+	stores := map[int]chronograf.RolesStore{}
+	for i := range grants {
+		g := &grants[i]
+		if g.ExpiresAt.After(now) {
+			continue
+		}
+		log := s.Logger.
+			WithField("component", "roles").
+			WithField("source", g.SourceID).
+			WithField("role", g.Role)
+
+		roles, ok := stores[g.SourceID]
+		if !ok {
+			if roles, err = s.sourceRoles(ctx, g.SourceID); err != nil {
+				log.Error("Unable to connect to source to revoke temporary grant: ", err)
+				continue
+			}
+			stores[g.SourceID] = roles
+		}
+
+		if err := revokeTemporaryGrant(ctx, roles, g); err != nil {
+			log.Error("Unable to revoke temporary grant: ", err)
+			continue
+		}
+		if err := s.TemporaryGrants.Delete(ctx, g); err != nil {
+			log.Error("Unable to remove revoked temporary grant: ", err)
+			continue
+		}
+		log.Info("Revoked temporary permission ", permissionString(g.Permission))
+	}
+}
+
+// sourceRoles connects to the roles of the source with the id
+func (s *Service) sourceRoles(ctx context.Context, srcID int) (chronograf.RolesStore, error) {
+	src, err := s.Store.Sources(ctx).Get(ctx, srcID)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := s.TimeSeries(src)
+	if err != nil {
+		return nil, err
+	}
+	if err = ts.Connect(ctx, &src); err != nil {
+		return nil, err
+	}
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		return nil, fmt.Errorf("Source %d does not have role capability", srcID)
+	}
+	return roles, nil
+}
+
+// revokeTemporaryGrant takes the grant away from its role.  A role that no
+// longer exists has nothing left to revoke.
+func revokeTemporaryGrant(ctx context.Context, roles chronograf.RolesStore, g *chronograf.TemporaryGrant) error {
+	role, err := roles.Get(ctx, g.Role)
+	if err != nil {
+		if all, aerr := roles.All(ctx); aerr == nil && !hasRoleNamed(all, g.Role) {
+			return nil
+		}
+		return err
+	}
+	if g.Ephemeral {
+		return roles.Delete(ctx, role)
+	}
+	return roles.Update(ctx, &chronograf.Role{
+		Name:        role.Name,
+		Permissions: removeAllowances(role.Permissions, g.Permission),
+	})
+}
+
+func hasRoleNamed(roles []chronograf.Role, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
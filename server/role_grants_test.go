@@ -0,0 +1,345 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_NewTemporaryGrant(t *testing.T) {
+	tests := []struct {
+		name       string
+		rid        string
+		body       string
+		wantStatus int
+		wantRole   *chronograf.Role // wantRole is the role as added or updated
+		wantGrants []chronograf.TemporaryGrant
+	}{
+		{
+			name:       "Allowances are added to the role",
+			rid:        "timetravelers",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["ReadData", "WriteData"]}, {"scope": "all", "allowed": ["ViewChronograf"]}], "duration": "90m"}`,
+			wantStatus: http.StatusCreated,
+			wantRole: &chronograf.Role{
+				Name: "timetravelers",
+				Permissions: chronograf.Permissions{
+					{
+						Scope:   chronograf.DBScope,
+						Name:    "delorean",
+						Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+					},
+					{
+						Scope:   chronograf.AllScope,
+						Allowed: chronograf.Allowances{"ViewChronograf"},
+					},
+				},
+			},
+			wantGrants: []chronograf.TemporaryGrant{
+				{
+					SourceID: 1,
+					Role:     "timetravelers",
+					Permission: chronograf.Permission{
+						Scope:   chronograf.DBScope,
+						Name:    "delorean",
+						Allowed: chronograf.Allowances{"WriteData"},
+					},
+				},
+				{
+					SourceID: 1,
+					Role:     "timetravelers",
+					Permission: chronograf.Permission{
+						Scope:   chronograf.AllScope,
+						Allowed: chronograf.Allowances{"ViewChronograf"},
+					},
+				},
+			},
+		},
+		{
+			name:       "Ephemeral role is created",
+			rid:        "incident",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["WriteData"]}], "duration": "1h", "ephemeral": true, "users": [{"name": "doc"}]}`,
+			wantStatus: http.StatusCreated,
+			wantRole: &chronograf.Role{
+				Name:  "incident",
+				Users: []chronograf.User{{Name: "doc"}},
+				Permissions: chronograf.Permissions{
+					{
+						Scope:   chronograf.DBScope,
+						Name:    "delorean",
+						Allowed: chronograf.Allowances{"WriteData"},
+					},
+				},
+			},
+			wantGrants: []chronograf.TemporaryGrant{
+				{
+					SourceID: 1,
+					Role:     "incident",
+					Permission: chronograf.Permission{
+						Scope:   chronograf.DBScope,
+						Name:    "delorean",
+						Allowed: chronograf.Allowances{"WriteData"},
+					},
+					Ephemeral: true,
+				},
+			},
+		},
+		{
+			name:       "Ephemeral role already exists",
+			rid:        "timetravelers",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["WriteData"]}], "duration": "1h", "ephemeral": true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Role already has the permissions",
+			rid:        "timetravelers",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["ReadData"]}], "duration": "1h"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Invalid duration",
+			rid:        "timetravelers",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["WriteData"]}], "duration": "-1h"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "Users of a role that is not ephemeral",
+			rid:        "timetravelers",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["WriteData"]}], "duration": "1h", "users": [{"name": "biff"}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var role *chronograf.Role
+			grants := []chronograf.TemporaryGrant{}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								if name != "timetravelers" {
+									return nil, errors.New("role not found")
+								}
+								return &chronograf.Role{
+									Name: name,
+									Permissions: chronograf.Permissions{
+										{
+											Scope:   chronograf.DBScope,
+											Name:    "delorean",
+											Allowed: chronograf.Allowances{"ReadData"},
+										},
+									},
+								}, nil
+							},
+							AddF: func(ctx context.Context, r *chronograf.Role) (*chronograf.Role, error) {
+								role = r
+								return r, nil
+							},
+							UpdateF: func(ctx context.Context, r *chronograf.Role) error {
+								role = r
+								return nil
+							},
+						}, nil
+					},
+				},
+				TemporaryGrants: &mocks.TemporaryGrantsStore{
+					AddF: func(ctx context.Context, g *chronograf.TemporaryGrant) (*chronograf.TemporaryGrant, error) {
+						grant := *g
+						grant.ExpiresAt = time.Time{}
+						grants = append(grants, grant)
+						return g, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles/"+tt.rid+"/grants", bytes.NewBufferString(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: tt.rid,
+					},
+				}))
+
+			h.NewTemporaryGrant(w, r)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. NewTemporaryGrant() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(role, tt.wantRole) {
+				t.Errorf("%q. NewTemporaryGrant() role = %v, want %v", tt.name, role, tt.wantRole)
+			}
+			if tt.wantGrants == nil {
+				tt.wantGrants = []chronograf.TemporaryGrant{}
+			}
+			if !reflect.DeepEqual(grants, tt.wantGrants) {
+				t.Errorf("%q. NewTemporaryGrant() grants = %v, want %v", tt.name, grants, tt.wantGrants)
+			}
+		})
+	}
+}
+
+func TestService_RevokeTemporaryGrants(t *testing.T) {
+	now := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+
+	updates := []chronograf.Role{}
+	deletedRoles := []string{}
+	deletedGrants := []string{}
+	s := &Service{
+		Logger: log.New(log.DebugLevel),
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					if ID != 1 {
+						return chronograf.Source{}, errors.New("source not found")
+					}
+					return chronograf.Source{ID: ID}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+						return &chronograf.Role{
+							Name: name,
+							Permissions: chronograf.Permissions{
+								{
+									Scope:   chronograf.DBScope,
+									Name:    "delorean",
+									Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+								},
+								{
+									Scope:   chronograf.AllScope,
+									Allowed: chronograf.Allowances{"ViewChronograf"},
+								},
+							},
+						}, nil
+					},
+					UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+						updates = append(updates, *role)
+						return nil
+					},
+					DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+						deletedRoles = append(deletedRoles, role.Name)
+						return nil
+					},
+				}, nil
+			},
+		},
+		TemporaryGrants: &mocks.TemporaryGrantsStore{
+			AllF: func(ctx context.Context) ([]chronograf.TemporaryGrant, error) {
+				return []chronograf.TemporaryGrant{
+					{
+						ID:       "1",
+						SourceID: 1,
+						Role:     "timetravelers",
+						Permission: chronograf.Permission{
+							Scope:   chronograf.DBScope,
+							Name:    "delorean",
+							Allowed: chronograf.Allowances{"WriteData"},
+						},
+						ExpiresAt: now.Add(-time.Minute),
+					},
+					{
+						ID:       "2",
+						SourceID: 1,
+						Role:     "incident",
+						Permission: chronograf.Permission{
+							Scope:   chronograf.AllScope,
+							Allowed: chronograf.Allowances{"ViewChronograf"},
+						},
+						Ephemeral: true,
+						ExpiresAt: now,
+					},
+					{
+						ID:       "3",
+						SourceID: 1,
+						Role:     "timetravelers",
+						Permission: chronograf.Permission{
+							Scope:   chronograf.AllScope,
+							Allowed: chronograf.Allowances{"ViewChronograf"},
+						},
+						ExpiresAt: now.Add(time.Minute),
+					},
+					{
+						ID:       "4",
+						SourceID: 2,
+						Role:     "timetravelers",
+						Permission: chronograf.Permission{
+							Scope:   chronograf.AllScope,
+							Allowed: chronograf.Allowances{"ViewChronograf"},
+						},
+						ExpiresAt: now.Add(-time.Hour),
+					},
+				}, nil
+			},
+			DeleteF: func(ctx context.Context, g *chronograf.TemporaryGrant) error {
+				deletedGrants = append(deletedGrants, g.ID)
+				return nil
+			},
+		},
+	}
+
+	s.RevokeTemporaryGrants(context.Background(), now)
+
+	wantUpdates := []chronograf.Role{
+		{
+			Name: "timetravelers",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "delorean",
+					Allowed: chronograf.Allowances{"ReadData"},
+				},
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ViewChronograf"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(updates, wantUpdates) {
+		t.Errorf("Service.RevokeTemporaryGrants() updates = %v, want %v", updates, wantUpdates)
+	}
+	if want := []string{"incident"}; !reflect.DeepEqual(deletedRoles, want) {
+		t.Errorf("Service.RevokeTemporaryGrants() deleted roles = %v, want %v", deletedRoles, want)
+	}
+	// Grant 3 has not expired and the source of grant 4 cannot be reached
+	if want := []string{"1", "2"}; !reflect.DeepEqual(deletedGrants, want) {
+		t.Errorf("Service.RevokeTemporaryGrants() deleted grants = %v, want %v", deletedGrants, want)
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// GraphvizType is the mimetype of GraphViz DOT responses
+const GraphvizType = "text/vnd.graphviz"
+
+// wantsGraphviz checks if the client accepts GraphViz DOT responses
+func wantsGraphviz(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(accept)
+		if err == nil && mediaType == GraphvizType {
+			return true
+		}
+	}
+	return false
+}
+
+// roleGraph writes the roles as a GraphViz digraph with an edge from each
+// user to their roles.  Role nodes are labeled with their permissions.
+// Roles, users and permissions are sorted so the same roles always produce
+// the same graph.
+func roleGraph(rr []sourceRoleResponse) []byte {
+	roles := make([]sourceRoleResponse, len(rr))
+	copy(roles, rr)
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph roles {\n\trankdir=LR;\n")
+
+	users := map[string]bool{}
+	edges := []string{}
+	for _, role := range roles {
+		label := append([]string{role.Name}, permissionSummaries(role.Permissions)...)
+		fmt.Fprintf(&buf, "\t%s [shape=box, label=%s];\n", dotID("role:"+role.Name), dotID(strings.Join(label, "\n")))
+		for _, u := range role.Users {
+			users[u.Name] = true
+			edges = append(edges, fmt.Sprintf("\t%s -> %s;\n", dotID("user:"+u.Name), dotID("role:"+role.Name)))
+		}
+	}
+
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%s [shape=ellipse, label=%s];\n", dotID("user:"+name), dotID(name))
+	}
+
+	sort.Strings(edges)
+	for _, edge := range edges {
+		buf.WriteString(edge)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// permissionSummaries describes each permission as its scope and sorted
+// allowances, e.g. "telegraf: READ, WRITE" or "deny telegraf: WRITE"
+func permissionSummaries(perms chronograf.Permissions) []string {
+	res := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		scope := "all databases"
+		if perm.Scope != chronograf.AllScope {
+			scope = perm.Name
+		}
+		allowed := append([]string{}, perm.Allowed...)
+		sort.Strings(allowed)
+		if perm.Deny {
+			scope = "deny " + scope
+		}
+		res = append(res, fmt.Sprintf("%s: %s", scope, strings.Join(allowed, ", ")))
+	}
+	sort.Strings(res)
+	return res
+}
+
+// dotID quotes s as a DOT identifier.  Newlines become DOT line breaks.
+func dotID(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+// encodeRoleGraph writes the roles as a GraphViz DOT document
+func (s *Service) encodeRoleGraph(w http.ResponseWriter, status int, rr []sourceRoleResponse) {
+	w.Header().Set("Content-Type", GraphvizType)
+	w.WriteHeader(status)
+	if _, err := w.Write(roleGraph(rr)); err != nil {
+		s.Logger.Error("Unable to write response: ", err)
+	}
+}
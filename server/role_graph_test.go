@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/influxdata/chronograf"
+)
+
+func Test_roleGraph(t *testing.T) {
+	rr := []sourceRoleResponse{
+		newSourceRoleResponse(1, &chronograf.Role{
+			Name:  "writers",
+			Users: []chronograf.User{{Name: "marty"}},
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "pics",
+					Allowed: chronograf.Allowances{"WRITE", "READ"},
+				},
+			},
+		}),
+		newSourceRoleResponse(1, &chronograf.Role{
+			Name:  `doc's "lab"`,
+			Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"ALL"},
+				},
+			},
+		}),
+	}
+	want := `digraph roles {
+	rankdir=LR;
+	"role:doc's \"lab\"" [shape=box, label="doc's \"lab\"\nall databases: ALL"];
+	"role:writers" [shape=box, label="writers\npics: READ, WRITE"];
+	"user:doc" [shape=ellipse, label="doc"];
+	"user:marty" [shape=ellipse, label="marty"];
+	"user:doc" -> "role:doc's \"lab\"";
+	"user:marty" -> "role:doc's \"lab\"";
+	"user:marty" -> "role:writers";
+}
+`
+	if got := string(roleGraph(rr)); got != want {
+		t.Errorf("roleGraph() = \n%s\nwant\n%s", got, want)
+	}
+	// Reordering the roles must not change the graph
+	rr[0], rr[1] = rr[1], rr[0]
+	if got := string(roleGraph(rr)); got != want {
+		t.Errorf("roleGraph() of reordered roles = \n%s\nwant\n%s", got, want)
+	}
+}
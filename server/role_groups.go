@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// groupRolesRequest maps the identity provider groups of a user to roles
+type groupRolesRequest struct {
+	Mapping map[string][]string `json:"mapping"` // Mapping is the roles of each group
+	Groups  []string            `json:"groups"`  // Groups the user is a member of
+}
+
+func (r *groupRolesRequest) Valid() error {
+	var errs validationErrors
+	if len(r.Mapping) == 0 {
+		errs.add("mapping", "Mapping of groups to roles required")
+	}
+	for group, roles := range r.Mapping {
+		for i, role := range roles {
+			if role == "" {
+				errs.add(fmt.Sprintf("mapping.%s[%d]", group, i), "Role name required")
+			}
+		}
+	}
+	return errs.err()
+}
+
+// managed returns every role of the mapping
+func (r *groupRolesRequest) managed() map[string]bool {
+	roles := map[string]bool{}
+	for _, names := range r.Mapping {
+		for _, name := range names {
+			roles[name] = true
+		}
+	}
+	return roles
+}
+
+// wanted returns the roles mapped from the user's groups
+func (r *groupRolesRequest) wanted() map[string]bool {
+	roles := map[string]bool{}
+	for _, group := range r.Groups {
+		for _, name := range r.Mapping[group] {
+			roles[name] = true
+		}
+	}
+	return roles
+}
+
+type groupRolesResponse struct {
+	User    string   `json:"user"`
+	Added   []string `json:"added"`   // Added are the roles the user joined
+	Removed []string `json:"removed"` // Removed are the roles the user left
+	Pending bool     `json:"pending"` // Pending is true if the changes await approval
+}
+
+// ReconcileSourceUserRoles makes the user a member of the roles mapped from
+// the user's groups and removes the user from the other roles of the
+// mapping.  Roles that are not part of the mapping are left untouched.
+func (s *Service) ReconcileSourceUserRoles(w http.ResponseWriter, r *http.Request) {
+	var req groupRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	uid := httprouter.GetParamFromContext(ctx, "uid")
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	managed, wanted := req.managed(), req.wanted()
+	var errs validationErrors
+	existing := map[string]bool{}
+	for _, role := range all {
+		existing[role.Name] = true
+	}
+	for _, name := range sortedKeys(managed) {
+		if !existing[name] {
+			errs.add("mapping", "Source %d does not have role %s", srcID, name)
+		}
+		if s.isProtectedRole(name) {
+			errs.add("mapping", "Role %s is a protected system role", name)
+		}
+	}
+	if err := errs.err(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	res := groupRolesResponse{
+		User:    uid,
+		Added:   []string{},
+		Removed: []string{},
+		Pending: s.RoleApprovals != nil,
+	}
+	for i := range all {
+		role := &all[i]
+		if !managed[role.Name] {
+			continue
+		}
+
+		member := hasRoleUser(role, uid)
+		var users []chronograf.User
+		switch {
+		case wanted[role.Name] && !member:
+			users = append(role.Users, chronograf.User{Name: uid})
+			res.Added = append(res.Added, role.Name)
+		case !wanted[role.Name] && member:
+			users = []chronograf.User{}
+			for _, u := range role.Users {
+				if u.Name != uid {
+					users = append(users, u)
+				}
+			}
+			res.Removed = append(res.Removed, role.Name)
+		default:
+			continue
+		}
+
+		update := chronograf.Role{
+			Name:  role.Name,
+			Users: users,
+		}
+		if s.RoleApprovals != nil {
+			s.RoleApprovals.propose(srcID, roleChange{role: update})
+			continue
+		}
+		if err := roles.Update(ctx, &update); err != nil {
+			msg := fmt.Sprintf("Unable to update role %s: %v", role.Name, err)
+			Error(w, http.StatusBadRequest, msg, s.Logger)
+			return
+		}
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_ReconcileSourceUserRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantUpdates []chronograf.Role
+	}{
+		{
+			name:       "User joins mapped roles and leaves unmapped ones",
+			body:       `{"mapping":{"engineers":["writers"],"analysts":["readers"],"ops":["admins"]},"groups":["engineers","analysts"]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":"marty","added":["writers"],"removed":["admins"],"pending":false}
+`,
+			wantUpdates: []chronograf.Role{
+				{
+					Name:  "writers",
+					Users: []chronograf.User{{Name: "doc"}, {Name: "marty"}},
+				},
+				{
+					Name:  "admins",
+					Users: []chronograf.User{{Name: "biff"}},
+				},
+			},
+		},
+		{
+			name:       "Mapped roles must exist",
+			body:       `{"mapping":{"engineers":["writers","time-travelers"]},"groups":["engineers"]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Source 1 does not have role time-travelers","errors":[{"field":"mapping","message":"Source 1 does not have role time-travelers"}]}
+`,
+		},
+		{
+			name:       "Mapping is required",
+			body:       `{"groups":["engineers"]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Mapping of groups to roles required","errors":[{"field":"mapping","message":"Mapping of groups to roles required"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := []chronograf.Role{}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name:  "writers",
+										Users: []chronograf.User{{Name: "doc"}},
+									},
+									{
+										Name:  "readers",
+										Users: []chronograf.User{{Name: "marty"}},
+									},
+									{
+										Name:  "admins",
+										Users: []chronograf.User{{Name: "biff"}, {Name: "marty"}},
+									},
+									{
+										Name:  "unmanaged",
+										Users: []chronograf.User{{Name: "marty"}},
+									},
+								}, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								updates = append(updates, *role)
+								return nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/users/marty/roles/reconcile", bytes.NewBufferString(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "uid",
+						Value: "marty",
+					},
+				}))
+
+			h.ReconcileSourceUserRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. ReconcileSourceUserRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. ReconcileSourceUserRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if tt.wantUpdates == nil {
+				tt.wantUpdates = []chronograf.Role{}
+			}
+			if !reflect.DeepEqual(updates, tt.wantUpdates) {
+				t.Errorf("%q. ReconcileSourceUserRoles() updates = %v, want %v", tt.name, updates, tt.wantUpdates)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// HALType is the mimetype of HAL (Hypertext Application Language) responses
+const HALType = "application/hal+json"
+
+// wantsHAL checks if the client accepts HAL responses
+func wantsHAL(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(accept)
+		if err == nil && mediaType == HALType {
+			return true
+		}
+	}
+	return false
+}
+
+type halLink struct {
+	Href string `json:"href"`
+}
+
+// halRoleResponse is the HAL representation of sourceRoleResponse.  Users
+// are embedded and the role links to its source and the source's users.
+type halRoleResponse struct {
+	Links       map[string]halLink          `json:"_links"`
+	Name        string                      `json:"name"`
+	Permissions chronograf.Permissions      `json:"permissions"`
+	Status      string                      `json:"status,omitempty"`
+	LastUsed    *time.Time                  `json:"lastUsed,omitempty"`
+	UsageCount  *int                        `json:"usageCount,omitempty"`
+	UpdatedAt   *time.Time                  `json:"updatedAt,omitempty"`
+	Warnings    []string                    `json:"warnings,omitempty"`
+	Labels      map[string]string           `json:"labels,omitempty"`
+	Docs        []chronograf.RoleDoc        `json:"docs,omitempty"`
+	Delegations []chronograf.RoleDelegation `json:"delegations,omitempty"`
+	RiskScore   *int                        `json:"riskScore,omitempty"`
+	RiskFactors []roleRiskFactor            `json:"riskFactors,omitempty"`
+	Embedded    struct {
+		Users  []halRoleUser `json:"users"`
+		Source *roleSource   `json:"source,omitempty"`
+	} `json:"_embedded"`
+}
+
+type halRoleUser struct {
+	Links     map[string]halLink `json:"_links"`
+	Name      string             `json:"name"`
+	Resolved  *bool              `json:"resolved,omitempty"`
+	ExpiresAt *time.Time         `json:"expiresAt,omitempty"`
+	Expired   *bool              `json:"expired,omitempty"`
+}
+
+func newHALRoleResponse(rr sourceRoleResponse) halRoleResponse {
+	src := fmt.Sprintf("/chronograf/v1/sources/%d", rr.srcID)
+	res := halRoleResponse{
+		Links: map[string]halLink{
+			"self":   {rr.Links.Self},
+			"users":  {src + "/users"},
+			"source": {src},
+		},
+		Name:        rr.Name,
+		Permissions: rr.Permissions,
+		Status:      rr.Status,
+		LastUsed:    rr.LastUsed,
+		UsageCount:  rr.UsageCount,
+		UpdatedAt:   rr.UpdatedAt,
+		Warnings:    rr.Warnings,
+		Labels:      rr.Labels,
+		Docs:        rr.Docs,
+		Delegations: rr.Delegations,
+		RiskScore:   rr.RiskScore,
+		RiskFactors: rr.RiskFactors,
+	}
+	res.Embedded.Source = rr.Source
+	res.Embedded.Users = make([]halRoleUser, len(rr.Users))
+	for i, u := range rr.Users {
+		res.Embedded.Users[i] = halRoleUser{
+			Links: map[string]halLink{
+				"self": {u.Links.Self},
+			},
+			Name: u.Name,
+		}
+		if u.unresolved {
+			resolved := false
+			res.Embedded.Users[i].Resolved = &resolved
+		}
+		if u.expiresAt != nil {
+			expired := u.expired
+			res.Embedded.Users[i].ExpiresAt = u.expiresAt
+			res.Embedded.Users[i].Expired = &expired
+		}
+	}
+	return res
+}
+
+// encodeHALRoles writes a listing of roles as a HAL collection linking to
+// the next page if there is one
+func (s *Service) encodeHALRoles(w http.ResponseWriter, r *http.Request, status int, rr []sourceRoleResponse, cursor string) {
+	res := struct {
+		Links    map[string]halLink `json:"_links"`
+		Embedded struct {
+			Roles []halRoleResponse `json:"roles"`
+		} `json:"_embedded"`
+	}{
+		Links: map[string]halLink{
+			"self": {fmt.Sprintf("/chronograf/v1/sources/%s/roles", httprouter.GetParamFromContext(r.Context(), "id"))},
+		},
+	}
+	if cursor != "" {
+		query := r.URL.Query()
+		query.Set("cursor", cursor)
+		res.Links["next"] = halLink{res.Links["self"].Href + "?" + query.Encode()}
+	}
+	res.Embedded.Roles = make([]halRoleResponse, len(rr))
+	for i := range rr {
+		res.Embedded.Roles[i] = newHALRoleResponse(rr[i])
+	}
+	encodeHAL(w, status, res, s.Logger)
+}
+
+// encodeHAL writes v as a HAL response
+func encodeHAL(w http.ResponseWriter, status int, v interface{}, logger chronograf.Logger) {
+	w.Header().Set("Content-Type", HALType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		unknownErrorWithMessage(w, err, logger)
+	}
+}
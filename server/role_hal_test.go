@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceRoles_HAL(t *testing.T) {
+	role := chronograf.Role{
+		Name:  "biffsgang",
+		Users: []chronograf.User{{Name: "biff"}},
+		Permissions: chronograf.Permissions{
+			{
+				Scope:   chronograf.AllScope,
+				Allowed: chronograf.Allowances{"ALL"},
+			},
+		},
+	}
+	h := &Service{
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					return chronograf.Source{
+						ID: 1,
+					}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+						return &role, nil
+					},
+					AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+						return []chronograf.Role{role}, nil
+					},
+				}, nil
+			},
+		},
+		Logger: log.New(log.DebugLevel),
+	}
+	params := httprouter.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+		{
+			Key:   "rid",
+			Value: "biffsgang",
+		},
+	}
+	halRole := `{"_links":{"self":{"href":"/chronograf/v1/sources/1/roles/biffsgang"},"source":{"href":"/chronograf/v1/sources/1"},"users":{"href":"/chronograf/v1/sources/1/users"}},"name":"biffsgang","permissions":[{"scope":"all","allowed":["ALL"]}],"_embedded":{"users":[{"_links":{"self":{"href":"/chronograf/v1/sources/1/users/biff"}},"name":"biff"}]}}`
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/biffsgang", nil)
+	r.Header.Set("Accept", "application/hal+json")
+	h.SourceRoleID(w, r.WithContext(httprouter.WithParams(context.Background(), params)))
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	if got := w.Header().Get("Content-Type"); got != HALType {
+		t.Errorf("SourceRoleID() Content-Type = %s, want %s", got, HALType)
+	}
+	if string(body) != halRole+"\n" {
+		t.Errorf("SourceRoleID() = %s, want %s", body, halRole)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles", nil)
+	r.Header.Set("Accept", "application/hal+json, application/json;q=0.9")
+	h.SourceRoles(w, r.WithContext(httprouter.WithParams(context.Background(), params)))
+	body, _ = ioutil.ReadAll(w.Result().Body)
+	want := `{"_links":{"self":{"href":"/chronograf/v1/sources/1/roles"}},"_embedded":{"roles":[` + halRole + `]}}
+`
+	if string(body) != want {
+		t.Errorf("SourceRoles() = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+// Actions taken for each role of an import
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importPending = "pending"
+	importFailed  = "failed"
+)
+
+// importProgress reports the action taken for one imported role
+type importProgress struct {
+	Role      string `json:"role"`
+	Action    string `json:"action"`
+	Error     string `json:"error,omitempty"`
+	Processed int    `json:"processed"` // Processed is the number of roles imported so far
+}
+
+// importSummary is the last line of an import
+type importSummary struct {
+	Processed int    `json:"processed"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Pending   int    `json:"pending"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error,omitempty"` // Error is why the import stopped early
+}
+
+// roleDecoder decodes the roles of a role export one at a time.  Exports
+// are either a JSON array of roles or an object with a roles array, as
+// returned by SourceRoles.
+type roleDecoder struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func newRoleDecoder(r io.Reader) *roleDecoder {
+	return &roleDecoder{dec: json.NewDecoder(r)}
+}
+
+// start consumes the tokens before the first role
+func (d *roleDecoder) start() error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == json.Delim('{') {
+		for {
+			key, err := d.dec.Token()
+			if err != nil {
+				return err
+			}
+			if key == "roles" {
+				break
+			}
+			if key == json.Delim('}') {
+				return fmt.Errorf("roles export has no roles")
+			}
+			// Skip the values of other members such as links
+			var skip json.RawMessage
+			if err := d.dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+		if tok, err = d.dec.Token(); err != nil {
+			return err
+		}
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("roles export must be an array of roles")
+	}
+	return nil
+}
+
+// Next decodes the next role into req.  io.EOF is returned after the last role.
+func (d *roleDecoder) Next(req *sourceRoleRequest) error {
+	if !d.started {
+		d.started = true
+		if err := d.start(); err != nil {
+			return err
+		}
+	}
+	if !d.dec.More() {
+		return io.EOF
+	}
+	return d.dec.Decode(req)
+}
+
+// importRole creates the role or updates it if it exists.  If role changes
+// require approval the change is proposed instead.
+func (s *Service) importRole(ctx context.Context, srcID int, roles chronograf.RolesStore, req *sourceRoleRequest) (string, error) {
+	if err := req.ValidCreate(); err != nil {
+		return importFailed, err
+	}
+	if err := s.expandClassifications(&req.Permissions); err != nil {
+		return importFailed, err
+	}
+	if s.isProtectedRole(req.Name) {
+		return importFailed, fmt.Errorf("Role %s is a protected system role", req.Name)
+	}
+
+	_, err := roles.Get(ctx, req.Name)
+	exists := err == nil
+	if s.RoleApprovals != nil {
+		s.RoleApprovals.propose(srcID, roleChange{role: req.Role, create: !exists})
+		return importPending, nil
+	}
+	if exists {
+		if err := roles.Update(ctx, &req.Role); err != nil {
+			return importFailed, err
+		}
+		return importUpdated, nil
+	}
+	if _, err := roles.Add(ctx, &req.Role); err != nil {
+		return importFailed, err
+	}
+	return importCreated, nil
+}
+
+// ImportSourceRoles creates or updates each role of a role export.  Roles
+// are decoded and applied one at a time so memory use does not grow with
+// the size of the export.  Progress is streamed as a JSON line per role
+// followed by a summary line.
+func (s *Service) ImportSourceRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	var summary importSummary
+	dec := newRoleDecoder(r.Body)
+	for {
+		req := s.newSourceRoleRequest()
+		if err := dec.Next(&req); err == io.EOF {
+			break
+		} else if err != nil {
+			summary.Error = fmt.Sprintf("Unable to decode role %d: %v", summary.Processed+1, err)
+			break
+		}
+
+		action, err := s.importRole(ctx, srcID, roles, &req)
+		summary.Processed++
+		progress := importProgress{
+			Role:      req.Name,
+			Action:    action,
+			Processed: summary.Processed,
+		}
+		switch action {
+		case importCreated:
+			summary.Created++
+		case importUpdated:
+			summary.Updated++
+		case importPending:
+			summary.Pending++
+		default:
+			summary.Failed++
+			progress.Error = err.Error()
+		}
+
+		if err := enc.Encode(progress); err != nil {
+			s.Logger.Error("Unable to write import progress: ", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("created", summary.Created).
+		WithField("updated", summary.Updated).
+		WithField("pending", summary.Pending).
+		WithField("failed", summary.Failed).
+		Info("Imported roles")
+	if err := enc.Encode(summary); err != nil {
+		s.Logger.Error("Unable to write import summary: ", err)
+	}
+}
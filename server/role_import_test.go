@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_ImportSourceRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantBody string
+	}{
+		{
+			name: "Array of roles",
+			body: `[{"name": "biffsgang", "permissions": []}, {"name": "timetravelers"}, {"name": ""}]`,
+			wantBody: `{"role":"biffsgang","action":"updated","processed":1}
+{"role":"timetravelers","action":"created","processed":2}
+{"role":"","action":"failed","error":"Name is required for a role","processed":3}
+{"processed":3,"created":1,"updated":1,"pending":0,"failed":1}
+`,
+		},
+		{
+			name: "Roles listing",
+			body: `{"links": {"self": "/chronograf/v1/sources/1/roles"}, "roles": [{"name": "timetravelers", "links": {"self": "/chronograf/v1/sources/1/roles/timetravelers"}}]}`,
+			wantBody: `{"role":"timetravelers","action":"created","processed":1}
+{"processed":1,"created":1,"updated":0,"pending":0,"failed":0}
+`,
+		},
+		{
+			name: "Truncated export",
+			body: `[{"name": "timetravelers"}, {"name": "mcfl`,
+			wantBody: `{"role":"timetravelers","action":"created","processed":1}
+{"processed":1,"created":1,"updated":0,"pending":0,"failed":0,"error":"Unable to decode role 2: unexpected EOF"}
+`,
+		},
+		{
+			name: "Not an export",
+			body: `"roles"`,
+			wantBody: `{"processed":0,"created":0,"updated":0,"pending":0,"failed":0,"error":"Unable to decode role 1: roles export must be an array of roles"}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								if name != "biffsgang" {
+									return nil, fmt.Errorf("role %s not found", name)
+								}
+								return &chronograf.Role{Name: name}, nil
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								return role, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								return nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-import", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.ImportSourceRoles(w, r)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("ImportSourceRoles() = \n%s\nwant\n%s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// roleInheritanceRequest is a proposed inheritance of roles from parent
+// roles.  Roles have no parents in the role stores, so the inheritance to
+// check is given rather than read from the source.
+type roleInheritanceRequest struct {
+	Parents map[string][]string `json:"parents"` // Parents are the roles each role inherits from
+}
+
+func (r *roleInheritanceRequest) Valid() error {
+	var errs validationErrors
+	if len(r.Parents) == 0 {
+		errs.add("parents", "Parents of roles required")
+	}
+	for _, role := range inheritingRoles(r.Parents) {
+		if role == "" {
+			errs.add("parents", "Role name required")
+		}
+		for i, parent := range r.Parents[role] {
+			if parent == "" {
+				errs.add(fmt.Sprintf("parents.%s[%d]", role, i), "Parent role name required")
+			}
+		}
+	}
+	return errs.err()
+}
+
+// inheritingRoles returns the sorted names of the roles having parents
+func inheritingRoles(parents map[string][]string) []string {
+	roles := make([]string, 0, len(parents))
+	for role := range parents {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+type missingParent struct {
+	Role   string `json:"role"`
+	Parent string `json:"parent"` // Parent is the role inherited from that the source does not have
+}
+
+type roleInheritanceResponse struct {
+	Valid          bool            `json:"valid"`          // Valid is true if the inheritance has no cycles or missing parents
+	Cycles         [][]string      `json:"cycles"`         // Cycles are the roles of each loop of inheritance, in order of inheritance
+	MissingParents []missingParent `json:"missingParents"` // MissingParents are the inherited roles the source does not have
+}
+
+// inheritanceCycles finds each loop of roles inheriting from each other.
+// A cycle lists its roles in order of inheritance starting with the least
+// name, e.g. [a b c] if a inherits from b, b from c and c from a.
+func inheritanceCycles(parents map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	cycles := [][]string{}
+	seen := map[string]bool{}
+	path := []string{}
+
+	var visit func(role string)
+	visit = func(role string) {
+		state[role] = visiting
+		path = append(path, role)
+
+		ps := append([]string{}, parents[role]...)
+		sort.Strings(ps)
+		for _, parent := range ps {
+			switch state[parent] {
+			case unvisited:
+				visit(parent)
+			case visiting:
+				// The path from parent back to role is a cycle
+				start := len(path) - 1
+				for path[start] != parent {
+					start--
+				}
+				cycle := rotateToLeast(path[start:])
+				key := fmt.Sprintf("%q", cycle)
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[role] = visited
+	}
+
+	for _, role := range inheritingRoles(parents) {
+		if state[role] == unvisited {
+			visit(role)
+		}
+	}
+	return cycles
+}
+
+// rotateToLeast returns a copy of the cycle starting at its least name
+func rotateToLeast(cycle []string) []string {
+	least := 0
+	for i := range cycle {
+		if cycle[i] < cycle[least] {
+			least = i
+		}
+	}
+	return append(append([]string{}, cycle[least:]...), cycle[:least]...)
+}
+
+// CheckSourceRoleInheritance checks a proposed inheritance of the roles of
+// a source for loops of roles inheriting from each other and for parents
+// the source does not have.  Nothing is changed.
+func (s *Service) CheckSourceRoleInheritance(w http.ResponseWriter, r *http.Request) {
+	var req roleInheritanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	existing := map[string]bool{}
+	for _, role := range all {
+		existing[role.Name] = true
+	}
+
+	res := roleInheritanceResponse{
+		Cycles:         inheritanceCycles(req.Parents),
+		MissingParents: []missingParent{},
+	}
+	for _, role := range inheritingRoles(req.Parents) {
+		for _, parent := range req.Parents[role] {
+			if !existing[parent] {
+				res.MissingParents = append(res.MissingParents, missingParent{role, parent})
+			}
+		}
+	}
+	res.Valid = len(res.Cycles) == 0 && len(res.MissingParents) == 0
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_inheritanceCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		parents map[string][]string
+		want    [][]string
+	}{
+		{
+			name: "No cycles",
+			parents: map[string][]string{
+				"marty": {"doc"},
+				"doc":   {"einstein"},
+			},
+			want: [][]string{},
+		},
+		{
+			name: "Role inheriting from itself",
+			parents: map[string][]string{
+				"biff": {"biff"},
+			},
+			want: [][]string{{"biff"}},
+		},
+		{
+			name: "Loop starts at the least name",
+			parents: map[string][]string{
+				"marty":    {"doc"},
+				"doc":      {"jennifer"},
+				"jennifer": {"marty"},
+			},
+			want: [][]string{{"doc", "jennifer", "marty"}},
+		},
+		{
+			name: "Separate loops",
+			parents: map[string][]string{
+				"marty":    {"doc", "george"},
+				"doc":      {"marty"},
+				"george":   {"lorraine"},
+				"lorraine": {"george"},
+			},
+			want: [][]string{{"doc", "marty"}, {"george", "lorraine"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := inheritanceCycles(tt.parents); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. inheritanceCycles() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestService_CheckSourceRoleInheritance(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Valid inheritance",
+			body:       `{"parents": {"timetravelers": ["hillvalley"]}}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"valid":true,"cycles":[],"missingParents":[]}
+`,
+		},
+		{
+			name:       "Cycles and missing parents",
+			body:       `{"parents": {"timetravelers": ["hillvalley"], "hillvalley": ["timetravelers", "biffsgang"]}}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"valid":false,"cycles":[["hillvalley","timetravelers"]],"missingParents":[{"role":"hillvalley","parent":"biffsgang"}]}
+`,
+		},
+		{
+			name:       "Parents required",
+			body:       `{}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Parents of roles required","errors":[{"field":"parents","message":"Parents of roles required"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{Name: "timetravelers"},
+									{Name: "hillvalley"},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-inheritance", bytes.NewBufferString(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.CheckSourceRoleInheritance(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. CheckSourceRoleInheritance() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. CheckSourceRoleInheritance() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
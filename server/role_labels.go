@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+// Limits of the labels of a role
+const (
+	maxRoleLabels     = 64  // maxRoleLabels is the most labels a role may have
+	maxRoleLabelKey   = 63  // maxRoleLabelKey is the longest label key
+	maxRoleLabelValue = 255 // maxRoleLabelValue is the longest label value
+)
+
+// validLabels checks the number of labels and the length of their keys and
+// values
+func validLabels(labels map[string]string, errs *validationErrors) {
+	if len(labels) > maxRoleLabels {
+		errs.add("labels", "Role may have at most %d labels", maxRoleLabels)
+	}
+	for _, key := range sortedLabelKeys(labels) {
+		if key == "" || len(key) > maxRoleLabelKey {
+			errs.add("labels", "Label key %q must be 1 to %d characters", key, maxRoleLabelKey)
+		}
+		if len(labels[key]) > maxRoleLabelValue {
+			errs.add("labels."+key, "Label value must be at most %d characters", maxRoleLabelValue)
+		}
+	}
+}
+
+func sortedLabelKeys(labels map[string]string) []string {
+	keys := make(map[string]bool, len(labels))
+	for k := range labels {
+		keys[k] = true
+	}
+	return sortedKeys(keys)
+}
+
+// parseLabelSelectors parses label query parameters of the form key=value
+func parseLabelSelectors(selectors []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, selector := range selectors {
+		parts := strings.SplitN(selector, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("label must be key=value")
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
+// hasLabels checks if the role has every one of labels
+func hasLabels(role *chronograf.Role, labels map[string]string) bool {
+	for k, v := range labels {
+		if got, ok := role.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+var _ chronograf.RolesStore = &labelingRolesStore{}
+
+// labelingRolesStore keeps the labels of the roles of a source alongside
+// the underlying RolesStore, which does not store them
+type labelingRolesStore struct {
+	chronograf.RolesStore
+	srcID  int
+	labels chronograf.RoleLabelsStore
+	Logger chronograf.Logger
+}
+
+// All returns the roles of the source with their labels
+func (s *labelingRolesStore) All(ctx context.Context) ([]chronograf.Role, error) {
+	roles, err := s.RolesStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.labels.All(ctx, s.srcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].Labels = labels[roles[i].Name]
+	}
+	return roles, nil
+}
+
+// Get returns the role with its labels
+func (s *labelingRolesStore) Get(ctx context.Context, name string) (*chronograf.Role, error) {
+	role, err := s.RolesStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role.Labels, err = s.labels.Get(ctx, s.srcID, role.Name); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Add creates the role then stores its labels
+func (s *labelingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if len(role.Labels) > 0 {
+		if err := s.labels.Put(ctx, s.srcID, role.Name, role.Labels); err != nil {
+			return nil, err
+		}
+	}
+	res.Labels = role.Labels
+	return res, nil
+}
+
+// Update changes the role and replaces its labels.  The labels are kept if
+// the update has none, as with the role's users and permissions.
+func (s *labelingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	return updateRoleEntry(ctx, s.RolesStore, role, role.Labels != nil, func() error {
+		return s.labels.Put(ctx, s.srcID, role.Name, role.Labels)
+	})
+}
+
+// Delete removes the role and its labels
+func (s *labelingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	return deleteRoleEntry(ctx, s.RolesStore, role, s.Logger, "the labels", func() error {
+		return s.labels.Put(ctx, s.srcID, role.Name, nil)
+	})
+}
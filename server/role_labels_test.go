@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+// memRoleLabels is a RoleLabelsStore of a single source kept in memory
+func memRoleLabels(labels map[string]map[string]string) *mocks.RoleLabelsStore {
+	return &mocks.RoleLabelsStore{
+		AllF: func(ctx context.Context, srcID int) (map[string]map[string]string, error) {
+			return labels, nil
+		},
+		GetF: func(ctx context.Context, srcID int, role string) (map[string]string, error) {
+			return labels[role], nil
+		},
+		PutF: func(ctx context.Context, srcID int, role string, l map[string]string) error {
+			if len(l) == 0 {
+				delete(labels, role)
+				return nil
+			}
+			labels[role] = l
+			return nil
+		},
+	}
+}
+
+func TestService_SourceRoles_Label(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Roles with every label",
+			query:      "?label=team=sre&label=env=prod",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"users":[],"name":"oncall","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/oncall"},"labels":{"env":"prod","team":"sre"}}]}
+`,
+		},
+		{
+			name:       "Label with an empty value",
+			query:      "?label=ticket=",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"users":[],"name":"readers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/readers"},"labels":{"team":"sre","ticket":""}}]}
+`,
+		},
+		{
+			name:       "Invalid label",
+			query:      "?label=team",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"label must be key=value"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{Name: "oncall"},
+									{Name: "readers"},
+									{Name: "biffsgang"},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				RoleLabels: memRoleLabels(map[string]map[string]string{
+					"oncall":  {"team": "sre", "env": "prod"},
+					"readers": {"team": "sre", "ticket": ""},
+				}),
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_sourceRoleRequest_Labels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr string
+	}{
+		{
+			name:   "Valid labels",
+			labels: map[string]string{"team": "sre", "ticket": "OPS-1955"},
+		},
+		{
+			name:    "Empty key",
+			labels:  map[string]string{"": "sre"},
+			wantErr: `Label key "" must be 1 to 63 characters`,
+		},
+		{
+			name:    "Long key",
+			labels:  map[string]string{strings.Repeat("k", 64): "sre"},
+			wantErr: `Label key "` + strings.Repeat("k", 64) + `" must be 1 to 63 characters`,
+		},
+		{
+			name:    "Long value",
+			labels:  map[string]string{"team": strings.Repeat("v", 256)},
+			wantErr: "Label value must be at most 255 characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sourceRoleRequest{
+				Role: chronograf.Role{
+					Name:   "oncall",
+					Labels: tt.labels,
+				},
+			}
+			err := r.ValidCreate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidCreate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidCreate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_labelingRolesStore(t *testing.T) {
+	labels := map[string]map[string]string{}
+	store := &labelingRolesStore{
+		RolesStore: &mocks.RolesStore{
+			AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+				return &chronograf.Role{Name: role.Name}, nil
+			},
+			GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+				return &chronograf.Role{Name: name}, nil
+			},
+			UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+			DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+		},
+		srcID:  1,
+		labels: memRoleLabels(labels),
+	}
+	ctx := context.Background()
+
+	added, err := store.Add(ctx, &chronograf.Role{Name: "oncall", Labels: map[string]string{"team": "sre"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"team": "sre"}
+	if !reflect.DeepEqual(added.Labels, want) {
+		t.Errorf("labelingRolesStore.Add() labels = %v, want %v", added.Labels, want)
+	}
+
+	if err := store.Update(ctx, &chronograf.Role{Name: "oncall"}); err != nil {
+		t.Fatal(err)
+	}
+	role, err := store.Get(ctx, "oncall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(role.Labels, want) {
+		t.Errorf("labelingRolesStore.Update() without labels changed them to %v, want %v", role.Labels, want)
+	}
+
+	if err := store.Update(ctx, &chronograf.Role{Name: "oncall", Labels: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := labels["oncall"]; ok {
+		t.Errorf("labelingRolesStore.Update() with empty labels did not remove them")
+	}
+
+	labels["oncall"] = want
+	if err := store.Delete(ctx, &chronograf.Role{Name: "oncall"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := labels["oncall"]; ok {
+		t.Errorf("labelingRolesStore.Delete() did not remove the labels")
+	}
+}
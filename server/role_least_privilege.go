@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+type leastPrivilegeRequest struct {
+	Used chronograf.Permissions `json:"used"` // Used are the permissions exercised by the role's users
+}
+
+type leastPrivilegeResponse struct {
+	Unused    chronograf.Permissions `json:"unused"`    // Unused are the granted allowances that were never used; candidates for removal
+	Suggested sourceRoleResponse     `json:"suggested"` // Suggested is the role without its unused allowances
+}
+
+// unusedPermissions splits the allowances of granted into those exercised
+// by used and those that were not.  An allowance on a database is used if
+// it was used on that database.  An allowance on all databases is used if
+// it was used anywhere.
+func unusedPermissions(granted, used chronograf.Permissions) (kept, unused chronograf.Permissions) {
+	kept, unused = chronograf.Permissions{}, chronograf.Permissions{}
+	for _, perm := range granted {
+		if perm.Deny {
+			// Denies take nothing away from least privilege
+			kept = append(kept, perm)
+			continue
+		}
+		var keep, drop chronograf.Allowances
+		for _, a := range perm.Allowed {
+			if permissionUsed(perm, a, used) {
+				keep = append(keep, a)
+			} else {
+				drop = append(drop, a)
+			}
+		}
+		if len(keep) > 0 {
+			p := perm
+			p.Allowed = keep
+			kept = append(kept, p)
+		}
+		if len(drop) > 0 {
+			unused = append(unused, chronograf.Permission{
+				Scope:   perm.Scope,
+				Name:    perm.Name,
+				Allowed: drop,
+			})
+		}
+	}
+	return kept, unused
+}
+
+func permissionUsed(perm chronograf.Permission, allowance string, used chronograf.Permissions) bool {
+	for _, u := range used {
+		if perm.Scope != chronograf.AllScope && (u.Scope != perm.Scope || u.Name != perm.Name) {
+			continue
+		}
+		for _, a := range u.Allowed {
+			if a == allowance {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SuggestSourceRolePermissions advises which permissions of a role may be
+// removed given the permissions its users actually exercised.  The role is
+// not changed.
+func (s *Service) SuggestSourceRolePermissions(w http.ResponseWriter, r *http.Request) {
+	var req leastPrivilegeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := validPermissions(&req.Used, permissionPolicy{}); err != nil {
+		var errs validationErrors
+		errs.merge("used", err)
+		invalidData(w, errs, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	role, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := leastPrivilegeResponse{
+		Suggested: newSourceRoleResponse(srcID, role),
+	}
+	res.Suggested.Permissions, res.Unused = unusedPermissions(res.Suggested.Permissions, req.Used)
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SuggestSourceRolePermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantBody string
+	}{
+		{
+			name: "Unused allowances",
+			body: `{"used": [{"scope": "database", "name": "pics", "allowed": ["READ"]}]}`,
+			wantBody: `{"unused":[{"scope":"database","name":"pics","allowed":["WRITE"]},{"scope":"database","name":"telegraf","allowed":["READ"]},{"scope":"all","allowed":["WRITE"]}],"suggested":{"users":[],"name":"biffsgang","permissions":[{"scope":"database","name":"pics","allowed":["READ"]},{"scope":"all","allowed":["READ"]}],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}}
+`,
+		},
+		{
+			name: "Nothing used",
+			body: `{"used": []}`,
+			wantBody: `{"unused":[{"scope":"database","name":"pics","allowed":["READ","WRITE"]},{"scope":"database","name":"telegraf","allowed":["READ"]},{"scope":"all","allowed":["READ","WRITE"]}],"suggested":{"users":[],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}}
+`,
+		},
+		{
+			name: "Invalid usage",
+			body: `{"used": [{"scope": "database", "allowed": ["READ"]}]}`,
+			wantBody: `{"code":422,"message":"Database scoped permission requires a name","errors":[{"field":"used[0].name","message":"Database scoped permission requires a name"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{
+									Name: name,
+									Permissions: chronograf.Permissions{
+										{
+											Scope:   chronograf.DBScope,
+											Name:    "pics",
+											Allowed: chronograf.Allowances{"READ", "WRITE"},
+										},
+										{
+											Scope:   chronograf.DBScope,
+											Name:    "telegraf",
+											Allowed: chronograf.Allowances{"READ"},
+										},
+										{
+											Scope:   chronograf.AllScope,
+											Allowed: chronograf.Allowances{"READ", "WRITE"},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles/biffsgang/least-privilege", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: "biffsgang",
+					},
+				}))
+			h.SuggestSourceRolePermissions(w, r)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("SuggestSourceRolePermissions() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
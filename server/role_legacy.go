@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+// LegacyPermissionsProfile is the Accept profile of clients expecting role
+// permissions as strings, e.g. "Accept: application/json;
+// profile=legacy-permissions".  It may be combined with a naming profile,
+// e.g. profile="snake_case legacy-permissions".
+const LegacyPermissionsProfile = "legacy-permissions"
+
+// wantsLegacyPermissions checks if the client accepts the legacy
+// permissions profile
+func wantsLegacyPermissions(r *http.Request) bool {
+	for _, profile := range jsonProfiles(r) {
+		if profile == LegacyPermissionsProfile {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyPermissions describes each allowance of perms as allowance:database.
+// Permissions of all databases use * as the database and denies are
+// prefixed by !, e.g. "ReadData:telegraf", "ViewChronograf:*" and
+// "!WriteData:_internal".  Expiries, notes and classifications are dropped.
+func legacyPermissions(perms chronograf.Permissions) []string {
+	res := []string{}
+	for _, perm := range perms {
+		db := perm.Name
+		if perm.Scope == chronograf.AllScope {
+			db = "*"
+		}
+		prefix := ""
+		if perm.Deny {
+			prefix = "!"
+		}
+		for _, a := range perm.Allowed {
+			res = append(res, prefix+a+":"+db)
+		}
+	}
+	return res
+}
+
+// legacyRoleResponse is a role with its permissions in legacy string form
+type legacyRoleResponse struct {
+	sourceRoleResponse
+	Permissions []string `json:"permissions"`
+}
+
+// legacySnakeRoleResponse is a snake_case role with its permissions in
+// legacy string form
+type legacySnakeRoleResponse struct {
+	snakeRoleResponse
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+// checkRoleLimits counts the roles of a source before a role is created.
+// If the source has reached the hard limit a 403 is written and ok is
+// false.  Once the new role brings the source to the soft limit a warning
+// is returned for the response.
+func (s *Service) checkRoleLimits(ctx context.Context, w http.ResponseWriter, srcID int, roles chronograf.RolesStore) (warning string, ok bool) {
+	if s.RoleSoftLimit <= 0 && s.RoleHardLimit <= 0 {
+		return "", true
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return "", false
+	}
+	count := len(all)
+
+	if s.RoleHardLimit > 0 && count >= s.RoleHardLimit {
+		Error(w, http.StatusForbidden, fmt.Sprintf("Source %d has reached the limit of %d roles", srcID, s.RoleHardLimit), s.Logger)
+		return "", false
+	}
+	if s.RoleSoftLimit > 0 && count+1 >= s.RoleSoftLimit {
+		warning = fmt.Sprintf("Source %d has %d roles; the soft limit is %d", srcID, count+1, s.RoleSoftLimit)
+		s.Logger.
+			WithField("component", "roles").
+			WithField("source", srcID).
+			Info(warning)
+	}
+	return warning, true
+}
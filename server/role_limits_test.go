@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_NewSourceRole_Limits(t *testing.T) {
+	tests := []struct {
+		name       string
+		soft       int
+		hard       int
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Under the limits",
+			soft:       4,
+			hard:       5,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"users":[],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}
+`,
+		},
+		{
+			name:       "Reaching the soft limit",
+			soft:       3,
+			wantStatus: http.StatusCreated,
+			wantBody: `{"users":[],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"warnings":["Source 1 has 3 roles; the soft limit is 3"]}
+`,
+		},
+		{
+			name:       "Reached the hard limit",
+			soft:       1,
+			hard:       2,
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"code":403,"message":"Source 1 has reached the limit of 2 roles"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return nil, fmt.Errorf("role %s not found", name)
+							},
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{{Name: "biffsgang"}, {Name: "mcflys"}}, nil
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								return role, nil
+							},
+						}, nil
+					},
+				},
+				Logger:        log.New(log.DebugLevel),
+				RoleSoftLimit: tt.soft,
+				RoleHardLimit: tt.hard,
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles", strings.NewReader(`{"name": "timetravelers"}`))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.NewSourceRole(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("NewSourceRole() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("NewSourceRole() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// Severities of role lint findings
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+	LintInfo    = "info"
+)
+
+// RoleLintRules configures the best-practice rules roles are linted against
+type RoleLintRules struct {
+	MaxUsers    int            // MaxUsers is the most users a role should have; 0 disables the rule
+	NamePattern *regexp.Regexp // NamePattern is the naming convention of roles; nil disables the rule
+	Disabled    []string       // Disabled are the names of rules that are not run
+}
+
+// NewRoleLintRules creates the rules from their configuration.  pattern is
+// a regular expression role names must match; the empty pattern allows any
+// name.
+func NewRoleLintRules(maxUsers int, pattern string, disabled []string) (RoleLintRules, error) {
+	rules := RoleLintRules{
+		MaxUsers: maxUsers,
+		Disabled: disabled,
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return rules, fmt.Errorf("Invalid role name pattern: %v", err)
+		}
+		rules.NamePattern = re
+	}
+	for _, name := range disabled {
+		if !knownLintRule(name) {
+			return rules, fmt.Errorf("Unknown role lint rule %s", name)
+		}
+	}
+	return rules, nil
+}
+
+func knownLintRule(name string) bool {
+	for _, rule := range roleLintRules {
+		if rule.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lintFinding is a rule broken by a role
+type lintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// lintRule checks a role and returns the findings of the rule
+type lintRule struct {
+	name  string
+	check func(RoleLintRules, *chronograf.Role) []lintFinding
+}
+
+// roleLintRules are all rules in the order they are run
+var roleLintRules = []lintRule{
+	{"all-databases", lintAllDatabases},
+	{"empty-permissions", lintEmptyPermissions},
+	{"max-users", lintMaxUsers},
+	{"name-convention", lintNameConvention},
+}
+
+func lintAllDatabases(_ RoleLintRules, role *chronograf.Role) []lintFinding {
+	findings := []lintFinding{}
+	for i, perm := range role.Permissions {
+		if perm.Scope != chronograf.AllScope || perm.Deny {
+			continue
+		}
+		severity := LintWarning
+		for _, a := range perm.Allowed {
+			if a == "ALL" {
+				severity = LintError
+			}
+		}
+		findings = append(findings, lintFinding{
+			Severity: severity,
+			Field:    fmt.Sprintf("permissions[%d]", i),
+			Message:  "Permission is granted on all databases; prefer database scoped permissions",
+		})
+	}
+	return findings
+}
+
+func lintEmptyPermissions(_ RoleLintRules, role *chronograf.Role) []lintFinding {
+	if len(role.Permissions) == 0 {
+		return []lintFinding{{
+			Severity: LintWarning,
+			Field:    "permissions",
+			Message:  "Role grants no permissions",
+		}}
+	}
+	findings := []lintFinding{}
+	for i, perm := range role.Permissions {
+		if len(perm.Allowed) == 0 {
+			findings = append(findings, lintFinding{
+				Severity: LintWarning,
+				Field:    fmt.Sprintf("permissions[%d].allowed", i),
+				Message:  "Permission allows nothing",
+			})
+		}
+	}
+	return findings
+}
+
+func lintMaxUsers(rules RoleLintRules, role *chronograf.Role) []lintFinding {
+	if rules.MaxUsers <= 0 || len(role.Users) <= rules.MaxUsers {
+		return nil
+	}
+	return []lintFinding{{
+		Severity: LintWarning,
+		Field:    "users",
+		Message:  fmt.Sprintf("Role has %d users; roles should have at most %d", len(role.Users), rules.MaxUsers),
+	}}
+}
+
+func lintNameConvention(rules RoleLintRules, role *chronograf.Role) []lintFinding {
+	if rules.NamePattern == nil || rules.NamePattern.MatchString(role.Name) {
+		return nil
+	}
+	return []lintFinding{{
+		Severity: LintInfo,
+		Field:    "name",
+		Message:  fmt.Sprintf("Name does not match the naming convention %s", rules.NamePattern),
+	}}
+}
+
+// Lint runs the enabled rules against role
+func (rules RoleLintRules) Lint(role *chronograf.Role) []lintFinding {
+	disabled := map[string]bool{}
+	for _, name := range rules.Disabled {
+		disabled[name] = true
+	}
+
+	findings := []lintFinding{}
+	for _, rule := range roleLintRules {
+		if disabled[rule.name] {
+			continue
+		}
+		for _, f := range rule.check(rules, role) {
+			f.Rule = rule.name
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// LintSourceRole checks a role against the best-practice rules.  GET lints
+// the role as stored in the source; POST lints the role of the request body
+// before it is created or updated.
+func (s *Service) LintSourceRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+
+	var role *chronograf.Role
+	if r.Method == http.MethodPost {
+		var req sourceRoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			invalidJSON(w, s.Logger)
+			return
+		}
+		if req.Name == "" {
+			req.Name = rid
+		}
+		role = &req.Role
+	}
+
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	if role == nil {
+		role, err = roles.Get(ctx, rid)
+		if err != nil {
+			Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+			return
+		}
+	}
+
+	res := struct {
+		Name     string        `json:"name"`
+		Findings []lintFinding `json:"findings"`
+	}{
+		Name:     role.Name,
+		Findings: s.RoleLint.Lint(role),
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestRoleLintRules_Lint(t *testing.T) {
+	rules, err := NewRoleLintRules(1, "^[a-z]+$", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	role := &chronograf.Role{
+		Name: "Biffs_Gang",
+		Users: []chronograf.User{
+			{Name: "biff"},
+			{Name: "skinhead"},
+		},
+		Permissions: chronograf.Permissions{
+			{
+				Scope:   chronograf.AllScope,
+				Allowed: chronograf.Allowances{"ALL"},
+			},
+			{
+				Scope: chronograf.DBScope,
+				Name:  "telegraf",
+			},
+		},
+	}
+	want := []lintFinding{
+		{Rule: "all-databases", Severity: LintError, Field: "permissions[0]", Message: "Permission is granted on all databases; prefer database scoped permissions"},
+		{Rule: "empty-permissions", Severity: LintWarning, Field: "permissions[1].allowed", Message: "Permission allows nothing"},
+		{Rule: "max-users", Severity: LintWarning, Field: "users", Message: "Role has 2 users; roles should have at most 1"},
+		{Rule: "name-convention", Severity: LintInfo, Field: "name", Message: "Name does not match the naming convention ^[a-z]+$"},
+	}
+	if got := rules.Lint(role); !reflect.DeepEqual(got, want) {
+		t.Errorf("RoleLintRules.Lint() = %v, want %v", got, want)
+	}
+
+	rules.Disabled = []string{"all-databases", "empty-permissions", "max-users", "name-convention"}
+	if got := rules.Lint(role); len(got) != 0 {
+		t.Errorf("RoleLintRules.Lint() with all rules disabled = %v, want none", got)
+	}
+
+	if _, err := NewRoleLintRules(0, "[", nil); err == nil {
+		t.Errorf("NewRoleLintRules() with invalid pattern expected error")
+	}
+	if _, err := NewRoleLintRules(0, "", []string{"no-such-rule"}); err == nil {
+		t.Errorf("NewRoleLintRules() with unknown rule expected error")
+	}
+}
+
+func TestService_LintSourceRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		wantBody string
+	}{
+		{
+			name:   "Stored role",
+			method: "GET",
+			wantBody: `{"name":"biffsgang","findings":[{"rule":"empty-permissions","severity":"warning","field":"permissions","message":"Role grants no permissions"}]}
+`,
+		},
+		{
+			name:   "Inline role",
+			method: "POST",
+			body:   `{"permissions": [{"scope": "database", "name": "pics", "allowed": ["READ"]}]}`,
+			wantBody: `{"name":"biffsgang","findings":[]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{Name: name}, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "http://server.local/chronograf/v1/sources/1/roles/biffsgang/lint", strings.NewReader(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: "biffsgang",
+					},
+				}))
+			h.LintSourceRole(w, r)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("LintSourceRole() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
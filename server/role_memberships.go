@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// RoleMembershipExpiring is the type of the role event published before the
+// membership of a user in a role expires
+const RoleMembershipExpiring = "expiring"
+
+// validMemberships checks that memberships given an expiry end after now.
+// Memberships without an expiry are permanent.
+func validMemberships(users []chronograf.User, now time.Time, errs *validationErrors) {
+	for i := range users {
+		if users[i].MembershipExpired(now) {
+			errs.add(fmt.Sprintf("users[%d].expiresAt", i), "Membership must expire in the future")
+		}
+	}
+}
+
+// unexpiredUsers returns the users whose membership has not expired by now.
+// expired is true if any user was dropped.
+func unexpiredUsers(users []chronograf.User, now time.Time) (kept []chronograf.User, expired bool) {
+	kept = make([]chronograf.User, 0, len(users))
+	for i := range users {
+		if users[i].MembershipExpired(now) {
+			expired = true
+			continue
+		}
+		kept = append(kept, users[i])
+	}
+	return kept, expired
+}
+
+// hasActiveRoleUser is true if the user named name is a member of role
+// whose membership has not expired by now
+func hasActiveRoleUser(role *chronograf.Role, name string, now time.Time) bool {
+	for i := range role.Users {
+		if role.Users[i].Name == name {
+			return !role.Users[i].MembershipExpired(now)
+		}
+	}
+	return false
+}
+
+// membershipExpiries are the expiries of the expiring memberships of users
+// by user name
+func membershipExpiries(users []chronograf.User) map[string]time.Time {
+	expiries := map[string]time.Time{}
+	for _, u := range users {
+		if u.ExpiresAt != nil {
+			expiries[u.Name] = u.ExpiresAt.UTC()
+		}
+	}
+	return expiries
+}
+
+// withExpiries sets the membership expiry of each user listed in expiries
+func withExpiries(users []chronograf.User, expiries map[string]time.Time) {
+	for i := range users {
+		if at, ok := expiries[users[i].Name]; ok {
+			users[i].ExpiresAt = &at
+		}
+	}
+}
+
+var _ chronograf.RolesStore = &expiringRolesStore{}
+
+// expiringRolesStore keeps when the memberships of the users of the roles of
+// a source expire alongside the underlying RolesStore
+type expiringRolesStore struct {
+	chronograf.RolesStore
+	srcID       int
+	memberships chronograf.RoleMembershipsStore
+	Logger      chronograf.Logger
+}
+
+// All returns the roles of the source with the expiry of their memberships
+func (s *expiringRolesStore) All(ctx context.Context) ([]chronograf.Role, error) {
+	roles, err := s.RolesStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expiries, err := s.memberships.All(ctx, s.srcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		withExpiries(roles[i].Users, expiries[roles[i].Name])
+	}
+	return roles, nil
+}
+
+// Get returns the role with the expiry of its memberships
+func (s *expiringRolesStore) Get(ctx context.Context, name string) (*chronograf.Role, error) {
+	role, err := s.RolesStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	expiries, err := s.memberships.Get(ctx, s.srcID, role.Name)
+	if err != nil {
+		return nil, err
+	}
+	withExpiries(role.Users, expiries)
+	return role, nil
+}
+
+// Add creates the role then stores the expiry of its memberships
+func (s *expiringRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	expiries := membershipExpiries(role.Users)
+	if len(expiries) > 0 {
+		if err := s.memberships.Put(ctx, s.srcID, role.Name, expiries); err != nil {
+			return nil, err
+		}
+	}
+	withExpiries(res.Users, expiries)
+	return res, nil
+}
+
+// Update changes the role and replaces the expiry of its memberships.
+// Updates without users keep them; users listed without an expiry become
+// permanent members.
+func (s *expiringRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	return updateRoleEntry(ctx, s.RolesStore, role, role.Users != nil, func() error {
+		return s.memberships.Put(ctx, s.srcID, role.Name, membershipExpiries(role.Users))
+	})
+}
+
+// Delete removes the role and the expiry of its memberships
+func (s *expiringRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	return deleteRoleEntry(ctx, s.RolesStore, role, s.Logger, "the expiry of the memberships", func() error {
+		return s.memberships.Put(ctx, s.srcID, role.Name, nil)
+	})
+}
+
+// MembershipNotices announces the role memberships about to expire.  Each
+// membership is announced once per expiry, so extending a membership
+// announces it again before its new expiry.
+type MembershipNotices struct {
+	Before time.Duration // Before is how long before its expiry a membership is announced
+
+	mu   sync.Mutex
+	sent map[string]time.Time // sent are the announced expiries by source, role and user
+}
+
+// NewMembershipNotices announces memberships before their expiry
+func NewMembershipNotices(before time.Duration) *MembershipNotices {
+	return &MembershipNotices{
+		Before: before,
+		sent:   map[string]time.Time{},
+	}
+}
+
+// due returns the users of role whose membership expires within the notice
+// period of now and has not yet been announced.  They are marked announced.
+// Announcements of memberships that have since expired are forgotten.
+func (n *MembershipNotices) due(srcID int, role *chronograf.Role, now time.Time) []chronograf.User {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for key, at := range n.sent {
+		if !at.After(now) {
+			delete(n.sent, key)
+		}
+	}
+
+	users := []chronograf.User{}
+	for _, u := range role.Users {
+		if u.ExpiresAt == nil || u.MembershipExpired(now) || u.ExpiresAt.After(now.Add(n.Before)) {
+			continue
+		}
+		key := fmt.Sprintf("%d\x00%s\x00%s", srcID, role.Name, u.Name)
+		if at, ok := n.sent[key]; ok && at.Equal(*u.ExpiresAt) {
+			continue
+		}
+		n.sent[key] = *u.ExpiresAt
+		users = append(users, u)
+	}
+	return users
+}
+
+// announceExpiringMemberships logs and publishes as role events the
+// memberships of role expiring within the notice period of now
+func (s *Service) announceExpiringMemberships(srcID int, role *chronograf.Role, now time.Time) {
+	if s.MembershipNotices == nil {
+		return
+	}
+	for _, u := range s.MembershipNotices.due(srcID, role, now) {
+		s.Logger.
+			WithField("component", "roles").
+			WithField("source", srcID).
+			WithField("role", role.Name).
+			WithField("user", u.Name).
+			WithField("expiresAt", u.ExpiresAt.UTC().Format(time.RFC3339)).
+			Info("Role membership expiring")
+		if s.RoleEvents != nil {
+			at := u.ExpiresAt.UTC()
+			s.RoleEvents.publish(roleEvent{
+				Type:      RoleMembershipExpiring,
+				Source:    srcID,
+				Role:      role.Name,
+				Time:      now.UTC(),
+				User:      u.Name,
+				ExpiresAt: &at,
+			})
+		}
+	}
+}
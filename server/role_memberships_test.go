@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_sourceRoleRequest_Memberships(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		users   []chronograf.User
+		wantErr string
+	}{
+		{
+			name: "Permanent and expiring memberships",
+			users: []chronograf.User{
+				{Name: "billietta"},
+				{Name: "kiwi", ExpiresAt: &future},
+			},
+		},
+		{
+			name:    "Membership expired",
+			users:   []chronograf.User{{Name: "kiwi", ExpiresAt: &past}},
+			wantErr: "Membership must expire in the future",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sourceRoleRequest{
+				Role: chronograf.Role{
+					Name:  "contractors",
+					Users: tt.users,
+				},
+			}
+			err := r.ValidCreate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidCreate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidCreate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_expiringRolesStore(t *testing.T) {
+	expiries := map[string]map[string]time.Time{}
+	users := map[string][]chronograf.User{}
+	store := &expiringRolesStore{
+		RolesStore: &mocks.RolesStore{
+			AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+				users[role.Name] = []chronograf.User{}
+				for _, u := range role.Users {
+					users[role.Name] = append(users[role.Name], chronograf.User{Name: u.Name})
+				}
+				return &chronograf.Role{Name: role.Name, Users: users[role.Name]}, nil
+			},
+			GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+				return &chronograf.Role{Name: name, Users: append([]chronograf.User{}, users[name]...)}, nil
+			},
+			UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+			DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+		},
+		srcID: 1,
+		memberships: &mocks.RoleMembershipsStore{
+			GetF: func(ctx context.Context, srcID int, role string) (map[string]time.Time, error) {
+				return expiries[role], nil
+			},
+			PutF: func(ctx context.Context, srcID int, role string, e map[string]time.Time) error {
+				if len(e) == 0 {
+					delete(expiries, role)
+					return nil
+				}
+				expiries[role] = e
+				return nil
+			},
+		},
+	}
+	ctx := context.Background()
+
+	at := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := store.Add(ctx, &chronograf.Role{
+		Name: "contractors",
+		Users: []chronograf.User{
+			{Name: "billietta"},
+			{Name: "kiwi", ExpiresAt: &at},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(ctx, &chronograf.Role{Name: "contractors"}); err != nil {
+		t.Fatal(err)
+	}
+	role, err := store.Get(ctx, "contractors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role.Users[0].ExpiresAt != nil {
+		t.Errorf("expiringRolesStore.Get() gave permanent member %s an expiry of %v", role.Users[0].Name, role.Users[0].ExpiresAt)
+	}
+	if role.Users[1].ExpiresAt == nil || !role.Users[1].ExpiresAt.Equal(at) {
+		t.Errorf("expiringRolesStore.Get() expiry of %s = %v, want %v", role.Users[1].Name, role.Users[1].ExpiresAt, at)
+	}
+
+	if err := store.Update(ctx, &chronograf.Role{Name: "contractors", Users: []chronograf.User{{Name: "kiwi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := expiries["contractors"]; ok {
+		t.Errorf("expiringRolesStore.Update() with permanent users kept expiries %v", expiries["contractors"])
+	}
+
+	if err := store.Update(ctx, &chronograf.Role{Name: "contractors", Users: []chronograf.User{{Name: "kiwi", ExpiresAt: &at}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, &chronograf.Role{Name: "contractors"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := expiries["contractors"]; ok {
+		t.Errorf("expiringRolesStore.Delete() did not remove the expiries")
+	}
+}
+
+func Test_newSourceRoleResponse_Memberships(t *testing.T) {
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	role := chronograf.Role{
+		Name: "contractors",
+		Users: []chronograf.User{
+			{Name: "billietta"},
+			{Name: "kiwi", ExpiresAt: &past},
+		},
+	}
+	rr := newSourceRoleResponse(1, &role)
+	got, err := json.Marshal(rr.Users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"links":{"self":"/chronograf/v1/sources/1/users/billietta"},"name":"billietta"},{"expired":true,"expiresAt":"2020-01-01T00:00:00Z","links":{"self":"/chronograf/v1/sources/1/users/kiwi"},"name":"kiwi"}]`
+	if string(got) != want {
+		t.Errorf("newSourceRoleResponse() users = %s, want %s", got, want)
+	}
+}
+
+func Test_MembershipNotices_due(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(time.Hour)
+	later := now.Add(72 * time.Hour)
+	role := chronograf.Role{
+		Name: "contractors",
+		Users: []chronograf.User{
+			{Name: "billietta"},
+			{Name: "kiwi", ExpiresAt: &soon},
+			{Name: "lola", ExpiresAt: &later},
+		},
+	}
+	n := NewMembershipNotices(24 * time.Hour)
+
+	due := n.due(1, &role, now)
+	if len(due) != 1 || due[0].Name != "kiwi" {
+		t.Fatalf("MembershipNotices.due() = %v, want kiwi", due)
+	}
+	if due := n.due(1, &role, now.Add(time.Minute)); len(due) != 0 {
+		t.Errorf("MembershipNotices.due() announced %v again", due)
+	}
+
+	extended := now.Add(2 * time.Hour)
+	role.Users[1].ExpiresAt = &extended
+	if due := n.due(1, &role, now.Add(time.Minute)); len(due) != 1 || due[0].Name != "kiwi" {
+		t.Errorf("MembershipNotices.due() after extending = %v, want kiwi", due)
+	}
+}
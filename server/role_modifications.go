@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// RoleModifications remembers when roles were last created or changed
+// through Chronograf.  Sources do not store when their roles change, so
+// roles changed elsewhere, or before the server started, have no time.
+type RoleModifications struct {
+	mu    sync.Mutex
+	times map[roleModificationKey]time.Time
+}
+
+// NewRoleModifications creates an empty record of role modifications
+func NewRoleModifications() *RoleModifications {
+	return &RoleModifications{
+		times: map[roleModificationKey]time.Time{},
+	}
+}
+
+type roleModificationKey struct {
+	source int
+	role   string
+}
+
+func (m *RoleModifications) record(srcID int, role string, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.times[roleModificationKey{srcID, role}] = at
+}
+
+func (m *RoleModifications) forget(srcID int, role string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.times, roleModificationKey{srcID, role})
+}
+
+// updatedAt returns when the role was last modified.  ok is false if the
+// modification is not known.
+func (m *RoleModifications) updatedAt(srcID int, role string) (at time.Time, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	at, ok = m.times[roleModificationKey{srcID, role}]
+	return at, ok
+}
+
+// modifiedSince checks if the role of a source may have changed after
+// since.  Roles without a known modification time may have changed at any
+// time so are always modified.
+func (s *Service) modifiedSince(srcID int, role string, since time.Time) bool {
+	if s.RoleModifications == nil {
+		return true
+	}
+	at, ok := s.RoleModifications.updatedAt(srcID, role)
+	return !ok || at.After(since)
+}
+
+// withUpdatedAt adds the role's last known modification time to the response
+func (s *Service) withUpdatedAt(rr *sourceRoleResponse) {
+	if s.RoleModifications == nil {
+		return
+	}
+	if at, ok := s.RoleModifications.updatedAt(rr.srcID, rr.Name); ok {
+		rr.UpdatedAt = &at
+	}
+}
+
+var _ chronograf.RolesStore = &recordingRolesStore{}
+
+// recordingRolesStore records the time of each change made to the roles of
+// a source through the underlying RolesStore
+type recordingRolesStore struct {
+	chronograf.RolesStore
+	srcID         int
+	modifications *RoleModifications
+}
+
+// Add creates a new role and records its creation
+func (s *recordingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	s.modifications.record(s.srcID, role.Name, time.Now().UTC())
+	return res, nil
+}
+
+// Update changes a role and records the change
+func (s *recordingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	if err := s.RolesStore.Update(ctx, role); err != nil {
+		return err
+	}
+	s.modifications.record(s.srcID, role.Name, time.Now().UTC())
+	return nil
+}
+
+// Delete removes a role and forgets its modification.  A role of the same
+// name created elsewhere is then not mistaken for the removed one.
+func (s *recordingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	if err := s.RolesStore.Delete(ctx, role); err != nil {
+		return err
+	}
+	s.modifications.forget(s.srcID, role.Name)
+	return nil
+}
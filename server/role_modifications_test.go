@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceRoles_ModifiedSince(t *testing.T) {
+	since := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+	mods := NewRoleModifications()
+	mods.record(1, "timetravelers", since.Add(time.Hour))
+	mods.record(1, "biffsgang", since.Add(-time.Hour))
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Roles modified since or without a known modification",
+			query:      "?modifiedSince=2015-10-21T16:29:00Z",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"users":[],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"updatedAt":"2015-10-21T17:29:00Z"},{"users":[],"name":"hillvalley","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/hillvalley"}}]}
+`,
+		},
+		{
+			name:       "Invalid time",
+			query:      "?modifiedSince=yesterday",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"modifiedSince must be an RFC3339 time"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{Name: "timetravelers"},
+									{Name: "biffsgang"},
+									{Name: "hillvalley"},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				RoleModifications: mods,
+				Logger:            log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_recordingRolesStore(t *testing.T) {
+	mods := NewRoleModifications()
+	store := &recordingRolesStore{
+		RolesStore: &mocks.RolesStore{
+			UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+			DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+				return nil
+			},
+		},
+		srcID:         1,
+		modifications: mods,
+	}
+	ctx := context.Background()
+
+	before := time.Now()
+	if err := store.Update(ctx, &chronograf.Role{Name: "timetravelers"}); err != nil {
+		t.Fatal(err)
+	}
+	if at, ok := mods.updatedAt(1, "timetravelers"); !ok || at.Before(before) {
+		t.Errorf("recordingRolesStore.Update() recorded %v, %v; want a time after %v", at, ok, before)
+	}
+	if _, ok := mods.updatedAt(2, "timetravelers"); ok {
+		t.Errorf("recordingRolesStore.Update() recorded the role of another source")
+	}
+
+	if err := store.Delete(ctx, &chronograf.Role{Name: "timetravelers"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mods.updatedAt(1, "timetravelers"); ok {
+		t.Errorf("recordingRolesStore.Delete() did not forget the role")
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bouk/httprouter"
+)
+
+type roleNameResponse struct {
+	Name      string           `json:"name"`
+	Valid     bool             `json:"valid"`     // Valid is true if the name passes the role name rules
+	Available bool             `json:"available"` // Available is true if no role, protected role or pending role has the name
+	Errors    validationErrors `json:"errors"`    // Errors are the rules the name breaks
+}
+
+// CheckSourceRoleName reports whether a new role could be created with a
+// name.  Responses may be cached briefly so clients can check as users type.
+func (s *Service) CheckSourceRoleName(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	name := httprouter.GetParamFromContext(ctx, "rid")
+	req := sourceRoleRequest{}
+	req.Name = name
+
+	res := roleNameResponse{
+		Name:   name,
+		Errors: validationErrors{},
+	}
+	if err, ok := req.ValidCreate().(validationErrors); ok {
+		res.Errors = err
+	}
+	res.Valid = len(res.Errors) == 0
+
+	res.Available = !s.isProtectedRole(name)
+	if res.Available && s.RoleApprovals != nil {
+		_, pending := s.RoleApprovals.pending(srcID, name)
+		res.Available = !pending
+	}
+	if res.Available {
+		_, exists := s.existingRoleName(ctx, roles, name)
+		res.Available = !exists
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=10")
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_CheckSourceRoleName(t *testing.T) {
+	long := strings.Repeat("a", 255)
+	tests := []struct {
+		name     string
+		role     string
+		wantBody string
+	}{
+		{
+			name: "Available name",
+			role: "timetravelers",
+			wantBody: `{"name":"timetravelers","valid":true,"available":true,"errors":[]}
+`,
+		},
+		{
+			name: "Existing role",
+			role: "biffsgang",
+			wantBody: `{"name":"biffsgang","valid":true,"available":false,"errors":[]}
+`,
+		},
+		{
+			name: "Protected role",
+			role: "_admin",
+			wantBody: `{"name":"_admin","valid":true,"available":false,"errors":[]}
+`,
+		},
+		{
+			name: "Name too long",
+			role: long,
+			wantBody: `{"name":"` + long + `","valid":false,"available":true,"errors":[{"field":"name","message":"Name is required for a role"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								if name != "biffsgang" {
+									return nil, fmt.Errorf("role %s not found", name)
+								}
+								return &chronograf.Role{Name: name}, nil
+							},
+						}, nil
+					},
+				},
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/"+tt.role+"/availability", nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: tt.role,
+					},
+				}))
+
+			h.CheckSourceRoleName(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%q. CheckSourceRoleName() = %v, want %v", tt.name, resp.StatusCode, http.StatusOK)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. CheckSourceRoleName() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// opaDataVersion is the version of the shape of opaData.  It changes only
+// if fields are removed or change meaning; fields may be added.
+const opaDataVersion = 1
+
+// opaPermission is a permission flattened for Rego rules.  Every field is
+// always present so rules need not test for missing fields.
+type opaPermission struct {
+	Scope     string   `json:"scope"`      // Scope is all or database
+	Database  string   `json:"database"`   // Database is empty for permissions of all databases
+	Allowed   []string `json:"allowed"`    // Allowed are sorted
+	Deny      bool     `json:"deny"`       // Deny takes the allowances away rather than granting them
+	ExpiresAt string   `json:"expires_at"` // ExpiresAt is an RFC3339 time, or empty if the permission does not expire
+}
+
+type opaRole struct {
+	Users       []string        `json:"users"`
+	Permissions []opaPermission `json:"permissions"`
+}
+
+type opaUser struct {
+	Roles       []string        `json:"roles"`
+	Permissions []opaPermission `json:"permissions"` // Permissions are the effective permissions of every role of the user as of generated_at, without expiry
+}
+
+// opaData is the document OPA loads as data, so roles are data.roles.  Roles
+// and users are keyed by name for lookups such as data.roles[name].
+type opaData struct {
+	Version     int                `json:"version"`
+	Source      int                `json:"source"`
+	GeneratedAt string             `json:"generated_at"`
+	Roles       map[string]opaRole `json:"roles"`
+	Users       map[string]opaUser `json:"users"`
+}
+
+func newOPAPermissions(perms chronograf.Permissions) []opaPermission {
+	res := make([]opaPermission, len(perms))
+	for i, perm := range perms {
+		allowed := append([]string{}, perm.Allowed...)
+		sort.Strings(allowed)
+		res[i] = opaPermission{
+			Scope:    string(perm.Scope),
+			Database: perm.Name,
+			Allowed:  allowed,
+			Deny:     perm.Deny,
+		}
+		if perm.ExpiresAt != nil {
+			res[i].ExpiresAt = perm.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return res
+}
+
+// newOPAData flattens the unexpired permissions of roles and the effective
+// permissions of each of their users
+func newOPAData(srcID int, roles []chronograf.Role, now time.Time) opaData {
+	data := opaData{
+		Version:     opaDataVersion,
+		Source:      srcID,
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+		Roles:       map[string]opaRole{},
+		Users:       map[string]opaUser{},
+	}
+	users := map[string]bool{}
+	for _, role := range roles {
+		perms, _ := unexpiredPermissions(role.Permissions, now)
+		r := opaRole{
+			Users:       []string{},
+			Permissions: newOPAPermissions(perms),
+		}
+		for _, u := range role.Users {
+			r.Users = append(r.Users, u.Name)
+			users[u.Name] = true
+		}
+		sort.Strings(r.Users)
+		data.Roles[role.Name] = r
+	}
+	for name := range users {
+		perms, roleNames := effectivePermissions(roles, name, now)
+		sort.Strings(roleNames)
+		data.Users[name] = opaUser{
+			Roles:       roleNames,
+			Permissions: newOPAPermissions(perms),
+		}
+	}
+	return data
+}
+
+// SourceRolesOPA exports the roles of a source and the effective
+// permissions of their users as a data document for Open Policy Agent.
+// Expired permissions are left out.  Protected system roles are included
+// as they grant permissions like any other role.
+func (s *Service) SourceRolesOPA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	encodeJSON(w, http.StatusOK, newOPAData(srcID, roles, time.Now()), s.Logger)
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+func Test_newOPAData(t *testing.T) {
+	now := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	expired := now.Add(-time.Hour)
+	roles := []chronograf.Role{
+		{
+			Name:  "timetravelers",
+			Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"WRITE", "READ"}},
+				{Scope: chronograf.DBScope, Name: "almanac", Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &later},
+				{Scope: chronograf.DBScope, Name: "flux", Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &expired},
+			},
+		},
+		{
+			Name:  "viewers",
+			Users: []chronograf.User{{Name: "marty"}},
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ViewChronograf"}},
+			},
+		},
+	}
+
+	got, err := json.Marshal(newOPAData(1, roles, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":1,"source":1,"generated_at":"2017-06-01T00:00:00Z",` +
+		`"roles":{` +
+		`"timetravelers":{"users":["doc","marty"],"permissions":[` +
+		`{"scope":"database","database":"delorean","allowed":["READ","WRITE"],"deny":false,"expires_at":""},` +
+		`{"scope":"database","database":"almanac","allowed":["READ"],"deny":false,"expires_at":"2017-06-01T01:00:00Z"}]},` +
+		`"viewers":{"users":["marty"],"permissions":[{"scope":"all","database":"","allowed":["ViewChronograf"],"deny":false,"expires_at":""}]}},` +
+		`"users":{` +
+		`"doc":{"roles":["timetravelers"],"permissions":[` +
+		`{"scope":"database","database":"delorean","allowed":["READ","WRITE"],"deny":false,"expires_at":""},` +
+		`{"scope":"database","database":"almanac","allowed":["READ"],"deny":false,"expires_at":""}]},` +
+		`"marty":{"roles":["timetravelers","viewers"],"permissions":[` +
+		`{"scope":"database","database":"delorean","allowed":["READ","WRITE"],"deny":false,"expires_at":""},` +
+		`{"scope":"database","database":"almanac","allowed":["READ"],"deny":false,"expires_at":""},` +
+		`{"scope":"all","database":"","allowed":["ViewChronograf"],"deny":false,"expires_at":""}]}}}`
+	if string(got) != want {
+		t.Errorf("newOPAData() = \n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RolesStore = &retainingRolesStore{}
+
+// retainingRolesStore keeps the permissions of the roles of a source the
+// underlying RolesStore cannot store, so they read back as they were
+// written.  Sources enforce what they can of them when roles are written,
+// e.g. Enterprise removes denied allowances from its grants.
+type retainingRolesStore struct {
+	chronograf.RolesStore
+	srcID       int
+	permissions chronograf.RolePermissionsStore
+	Logger      chronograf.Logger
+}
+
+// retainedPermissions returns the permissions of perms sources cannot
+// store: denies, and grants with an expiry or a note so neither is lost
+func retainedPermissions(perms chronograf.Permissions) chronograf.Permissions {
+	res := chronograf.Permissions{}
+	for _, perm := range perms {
+		if perm.Deny || perm.ExpiresAt != nil || perm.Note != "" {
+			res = append(res, perm)
+		}
+	}
+	return res
+}
+
+// withRetained returns the permissions read from a source with those
+// retained for it.  Retained denies replace any the source reports, and
+// grants take the expiry and note of the retained grant of the same scope.
+func withRetained(perms, retained chronograf.Permissions) chronograf.Permissions {
+	res := make(chronograf.Permissions, 0, len(perms)+len(retained))
+	for _, perm := range perms {
+		if perm.Deny {
+			continue
+		}
+		for _, r := range retained {
+			if sameScope(perm, r) {
+				perm.ExpiresAt = r.ExpiresAt
+				perm.Note = r.Note
+				break
+			}
+		}
+		res = append(res, perm)
+	}
+	for _, r := range retained {
+		if r.Deny {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// All returns the roles of the source with their retained permissions
+func (s *retainingRolesStore) All(ctx context.Context) ([]chronograf.Role, error) {
+	roles, err := s.RolesStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	retained, err := s.permissions.All(ctx, s.srcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].Permissions = withRetained(roles[i].Permissions, retained[roles[i].Name])
+	}
+	return roles, nil
+}
+
+// Get returns the role with its retained permissions
+func (s *retainingRolesStore) Get(ctx context.Context, name string) (*chronograf.Role, error) {
+	role, err := s.RolesStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	retained, err := s.permissions.Get(ctx, s.srcID, role.Name)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = withRetained(role.Permissions, retained)
+	return role, nil
+}
+
+// Add creates the role then retains the permissions the source cannot store
+func (s *retainingRolesStore) Add(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+	res, err := s.RolesStore.Add(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	retained := retainedPermissions(role.Permissions)
+	if len(retained) > 0 {
+		if err := s.permissions.Put(ctx, s.srcID, role.Name, retained); err != nil {
+			return nil, err
+		}
+	}
+	res.Permissions = withRetained(res.Permissions, retained)
+	return res, nil
+}
+
+// Update changes the role and replaces its retained permissions.  Updates
+// without permissions keep them.
+func (s *retainingRolesStore) Update(ctx context.Context, role *chronograf.Role) error {
+	return updateRoleEntry(ctx, s.RolesStore, role, role.Permissions != nil, func() error {
+		return s.permissions.Put(ctx, s.srcID, role.Name, retainedPermissions(role.Permissions))
+	})
+}
+
+// Delete removes the role and its retained permissions
+func (s *retainingRolesStore) Delete(ctx context.Context, role *chronograf.Role) error {
+	return deleteRoleEntry(ctx, s.RolesStore, role, s.Logger, "the retained permissions", func() error {
+		return s.permissions.Put(ctx, s.srcID, role.Name, nil)
+	})
+}
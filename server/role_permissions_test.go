@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_retainingRolesStore(t *testing.T) {
+	// The source keeps grants only, as Enterprise does
+	stored := map[string]chronograf.Permissions{}
+	grantsOnly := func(perms chronograf.Permissions) chronograf.Permissions {
+		res := chronograf.Permissions{}
+		for _, perm := range perms {
+			if !perm.Deny {
+				res = append(res, perm)
+			}
+		}
+		return res
+	}
+	retained := map[string]chronograf.Permissions{}
+	store := &retainingRolesStore{
+		RolesStore: &mocks.RolesStore{
+			AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+				stored[role.Name] = grantsOnly(role.Permissions)
+				return &chronograf.Role{Name: role.Name, Permissions: stored[role.Name]}, nil
+			},
+			GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+				return &chronograf.Role{Name: name, Permissions: stored[name]}, nil
+			},
+			UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+				if role.Permissions != nil {
+					stored[role.Name] = grantsOnly(role.Permissions)
+				}
+				return nil
+			},
+			DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+				delete(stored, role.Name)
+				return nil
+			},
+		},
+		srcID: 1,
+		permissions: &mocks.RolePermissionsStore{
+			GetF: func(ctx context.Context, srcID int, role string) (chronograf.Permissions, error) {
+				return retained[role], nil
+			},
+			PutF: func(ctx context.Context, srcID int, role string, perms chronograf.Permissions) error {
+				if len(perms) == 0 {
+					delete(retained, role)
+					return nil
+				}
+				retained[role] = perms
+				return nil
+			},
+		},
+	}
+
+	ctx := context.Background()
+	grant := chronograf.Permission{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData", "WriteData"}}
+	deny := chronograf.Permission{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"WriteData"}, Deny: true}
+	added, err := store.Add(ctx, &chronograf.Role{Name: "analysts", Permissions: chronograf.Permissions{grant, deny}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(added.Permissions, chronograf.Permissions{grant, deny}); diff != "" {
+		t.Errorf("Add() permissions:\n-got/+want\ndiff %s", diff)
+	}
+
+	// Updates without permissions keep the denies
+	if err := store.Update(ctx, &chronograf.Role{Name: "analysts", Users: []chronograf.User{{Name: "marty"}}}); err != nil {
+		t.Fatal(err)
+	}
+	role, err := store.Get(ctx, "analysts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(role.Permissions, chronograf.Permissions{grant, deny}); diff != "" {
+		t.Errorf("Get() permissions:\n-got/+want\ndiff %s", diff)
+	}
+
+	if err := store.Update(ctx, &chronograf.Role{Name: "analysts", Permissions: chronograf.Permissions{grant}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := retained["analysts"]; ok {
+		t.Errorf("Update() without denies retained %v", retained["analysts"])
+	}
+
+	// Temporary grants keep their expiry and note
+	expires := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+	temporary := grant
+	temporary.ExpiresAt = &expires
+	temporary.Note = "Needed by the nightly billing export"
+	if err := store.Update(ctx, &chronograf.Role{Name: "analysts", Permissions: chronograf.Permissions{temporary, deny}}); err != nil {
+		t.Fatal(err)
+	}
+	role, err = store.Get(ctx, "analysts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(role.Permissions, chronograf.Permissions{temporary, deny}); diff != "" {
+		t.Errorf("Get() permissions:\n-got/+want\ndiff %s", diff)
+	}
+
+	if err := store.Delete(ctx, &chronograf.Role{Name: "analysts"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := retained["analysts"]; ok {
+		t.Errorf("Delete() retained %v", retained["analysts"])
+	}
+}
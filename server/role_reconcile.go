@@ -0,0 +1,287 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// reconcileRolesRequest is the desired state of the roles of a source
+type reconcileRolesRequest struct {
+	Roles []chronograf.Role `json:"roles"`
+}
+
+type reconcileRolesResponse struct {
+	Created   []string `json:"created"`   // Created are the desired roles the source did not have
+	Updated   []string `json:"updated"`   // Updated are the roles whose users or permissions drifted
+	Deleted   []string `json:"deleted"`   // Deleted are the roles of the source that were not desired
+	Unchanged []string `json:"unchanged"` // Unchanged are the roles already in the desired state
+	Pending   bool     `json:"pending"`   // Pending is true if creations and updates await approval
+}
+
+// desiredRoles validates the desired roles of a reconcile request.  Roles are
+// validated as if each were being created.
+func (s *Service) desiredRoles(req *reconcileRolesRequest) ([]chronograf.Role, error) {
+	var errs validationErrors
+	seen := map[string]bool{}
+	desired := make([]chronograf.Role, len(req.Roles))
+	for i, role := range req.Roles {
+		field := fmt.Sprintf("roles[%d]", i)
+		rr := s.newSourceRoleRequest()
+		rr.Role = role
+		if err := rr.ValidCreate(); err != nil {
+			errs.merge(field, err)
+			continue
+		}
+		if err := s.expandClassifications(&rr.Permissions); err != nil {
+			errs.merge(field+".permissions", err)
+		}
+		if seen[rr.Name] {
+			errs.add(field+".name", "Role %s is listed more than once", rr.Name)
+		}
+		if s.isProtectedRole(rr.Name) {
+			errs.add(field+".name", "Role %s is a protected system role", rr.Name)
+		}
+		seen[rr.Name] = true
+		desired[i] = rr.Role
+	}
+	return desired, errs.err()
+}
+
+// roleDrifted checks if the users or permissions of a role differ from the
+// desired role.  The order of users, permissions and allowances is ignored.
+func roleDrifted(role, desired *chronograf.Role) bool {
+	return !sameStrings(roleUserNames(role), roleUserNames(desired)) ||
+		!sameStrings(permissionKeys(role.Permissions), permissionKeys(desired.Permissions))
+}
+
+// storedRole returns role with its permissions as its source reads them
+// back once written, so desired roles are compared to those of the source
+// without drifting forever.  Denies, expiries and notes are only read back
+// if retained is true.
+func storedRole(role *chronograf.Role, retained bool) *chronograf.Role {
+	stored := *role
+	stored.Permissions = storedPermissions(role.Permissions, retained)
+	return &stored
+}
+
+// storedPermissions returns perms as sources store them: one grant of each
+// scope, the last written, with the allowances of denies taken away and
+// without grants left with no allowances.  Chronograf retains the denies,
+// and the expiry and note of the first grant of each scope having either.
+func storedPermissions(perms chronograf.Permissions, retained bool) chronograf.Permissions {
+	grants := chronograf.Permissions{}
+	index := map[string]int{}
+	for _, perm := range perms {
+		if perm.Deny {
+			continue
+		}
+		stored := chronograf.Permission{
+			Scope:   perm.Scope,
+			Name:    perm.Name,
+			Allowed: perm.Allowed,
+		}
+		if perm.Scope == chronograf.AllScope {
+			stored.Name = ""
+		}
+		key := string(stored.Scope) + "\x00" + stored.Name
+		if i, ok := index[key]; ok {
+			grants[i].Allowed = stored.Allowed
+			continue
+		}
+		if retained {
+			for _, r := range perms {
+				if sameScope(perm, r) && (r.ExpiresAt != nil || r.Note != "") {
+					stored.ExpiresAt, stored.Note = r.ExpiresAt, r.Note
+					break
+				}
+			}
+		}
+		index[key] = len(grants)
+		grants = append(grants, stored)
+	}
+
+	res := chronograf.Permissions{}
+	for _, grant := range grants {
+		for _, deny := range perms {
+			if deny.Deny && narrows(deny, grant) {
+				grant.Allowed = withoutAllowances(grant.Allowed, deny.Allowed)
+			}
+		}
+		if len(grant.Allowed) > 0 {
+			res = append(res, grant)
+		}
+	}
+	if retained {
+		for _, perm := range perms {
+			if perm.Deny {
+				res = append(res, perm)
+			}
+		}
+	}
+	return res
+}
+
+func roleUserNames(role *chronograf.Role) []string {
+	names := make([]string, len(role.Users))
+	for i, u := range role.Users {
+		names[i] = u.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// permissionKeys describes each permission as a string that is the same
+// for equal permissions
+func permissionKeys(perms chronograf.Permissions) []string {
+	keys := make([]string, len(perms))
+	for i, perm := range perms {
+		keys[i] = permissionString(perm)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// permissionString describes a permission as a string ignoring the order of
+// its allowances
+func permissionString(perm chronograf.Permission) string {
+	allowed := append([]string{}, perm.Allowed...)
+	sort.Strings(allowed)
+	var expires string
+	if perm.ExpiresAt != nil {
+		expires = perm.ExpiresAt.UTC().Format(time.RFC3339Nano)
+	}
+	return strings.Join([]string{string(perm.Scope), perm.Name, strings.Join(allowed, ","), strconv.FormatBool(perm.Deny), expires, perm.Note}, "\x00")
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ReconcileSourceRoles makes the roles of a source match a desired list of
+// roles.  Missing roles are created, drifted roles are updated, and roles
+// that are not desired are deleted unless prune=false.  Protected system
+// roles are never deleted.  Changes are applied one role at a time; if a
+// change fails the changes before it remain.
+func (s *Service) ReconcileSourceRoles(w http.ResponseWriter, r *http.Request) {
+	prune := true
+	if p := r.URL.Query().Get("prune"); p != "" {
+		b, err := strconv.ParseBool(p)
+		if err != nil {
+			Error(w, http.StatusUnprocessableEntity, "prune must be a boolean", s.Logger)
+			return
+		}
+		prune = b
+	}
+
+	var req reconcileRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	desired, err := s.desiredRoles(&req)
+	if err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	existing := map[string]*chronograf.Role{}
+	for i := range all {
+		existing[all[i].Name] = &all[i]
+	}
+
+	res := reconcileRolesResponse{
+		Created:   []string{},
+		Updated:   []string{},
+		Deleted:   []string{},
+		Unchanged: []string{},
+		Pending:   s.RoleApprovals != nil,
+	}
+	retained := s.RolePermissions != nil
+	wanted := map[string]bool{}
+	for i := range desired {
+		role := &desired[i]
+		wanted[role.Name] = true
+
+		current, exists := existing[role.Name]
+		switch {
+		case !exists:
+			res.Created = append(res.Created, role.Name)
+		case roleDrifted(storedRole(current, retained), storedRole(role, retained)):
+			res.Updated = append(res.Updated, role.Name)
+		default:
+			res.Unchanged = append(res.Unchanged, role.Name)
+			continue
+		}
+
+		if s.RoleApprovals != nil {
+			s.RoleApprovals.propose(srcID, roleChange{role: *role, create: !exists})
+			continue
+		}
+		if exists {
+			err = roles.Update(ctx, role)
+		} else {
+			_, err = roles.Add(ctx, role)
+		}
+		if err != nil {
+			msg := fmt.Sprintf("Unable to reconcile role %s: %v", role.Name, err)
+			Error(w, http.StatusBadRequest, msg, s.Logger)
+			return
+		}
+	}
+
+	if prune {
+		for i := range all {
+			role := &all[i]
+			if wanted[role.Name] || s.isProtectedRole(role.Name) {
+				continue
+			}
+			if err := roles.Delete(ctx, role); err != nil {
+				msg := fmt.Sprintf("Unable to delete role %s: %v", role.Name, err)
+				Error(w, http.StatusBadRequest, msg, s.Logger)
+				return
+			}
+			res.Deleted = append(res.Deleted, role.Name)
+		}
+	}
+
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("created", len(res.Created)).
+		WithField("updated", len(res.Updated)).
+		WithField("deleted", len(res.Deleted)).
+		Info("Reconciled roles")
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_ReconcileSourceRoles(t *testing.T) {
+	desired := `{"roles":[
+		{"name":"writers","users":[{"name":"doc"}],"permissions":[{"scope":"database","name":"telegraf","allowed":["WRITE","READ"]}]},
+		{"name":"readers","users":[{"name":"marty"},{"name":"doc"}]},
+		{"name":"auditors"}
+	]}`
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantOps    []string
+	}{
+		{
+			name:       "Create missing, update drifted and delete extra roles",
+			body:       desired,
+			wantStatus: http.StatusOK,
+			wantBody: `{"created":["auditors"],"updated":["readers"],"deleted":["biffsgang"],"unchanged":["writers"],"pending":false}
+`,
+			wantOps: []string{"update readers", "add auditors", "delete biffsgang"},
+		},
+		{
+			name:       "Extra roles are kept without pruning",
+			query:      "?prune=false",
+			body:       desired,
+			wantStatus: http.StatusOK,
+			wantBody: `{"created":["auditors"],"updated":["readers"],"deleted":[],"unchanged":["writers"],"pending":false}
+`,
+			wantOps: []string{"update readers", "add auditors"},
+		},
+		{
+			name:       "Roles are compared as the source stores them",
+			query:      "?prune=false",
+			body:       `{"roles":[{"name":"writers","users":[{"name":"doc"}],"permissions":[{"scope":"database","name":"telegraf","allowed":["WRITE","READ","DELETE"],"note":"Needed by the collectors"},{"scope":"database","name":"telegraf","allowed":["DELETE"],"deny":true}]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"created":[],"updated":[],"deleted":[],"unchanged":["writers"],"pending":false}
+`,
+		},
+		{
+			name:       "Desired roles are validated before changes",
+			body:       `{"roles":[{"name":"auditors"},{"name":"auditors"},{"name":""}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Role auditors is listed more than once; Name is required for a role","errors":[{"field":"roles[1].name","message":"Role auditors is listed more than once"},{"field":"roles[2].name","message":"Name is required for a role"}]}
+`,
+		},
+		{
+			name:       "Protected roles cannot be desired",
+			body:       `{"roles":[{"name":"_admin"}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Role _admin is a protected system role","errors":[{"field":"roles[0].name","message":"Role _admin is a protected system role"}]}
+`,
+		},
+		{
+			name:       "Prune must be a boolean",
+			query:      "?prune=sometimes",
+			body:       desired,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"prune must be a boolean"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := []string{}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name:  "writers",
+										Users: []chronograf.User{{Name: "doc"}},
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "telegraf",
+												Allowed: chronograf.Allowances{"READ", "WRITE"},
+											},
+										},
+									},
+									{
+										Name:  "readers",
+										Users: []chronograf.User{{Name: "marty"}},
+									},
+									{
+										Name:  "biffsgang",
+										Users: []chronograf.User{{Name: "biff"}},
+									},
+									{
+										Name: "_admin",
+									},
+								}, nil
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								ops = append(ops, "add "+role.Name)
+								return role, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								ops = append(ops, "update "+role.Name)
+								return nil
+							},
+							DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+								ops = append(ops, "delete "+role.Name)
+								return nil
+							},
+						}, nil
+					},
+				},
+				ProtectedRoles: []string{"_*"},
+				Logger:         log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-reconcile"+tt.query, bytes.NewBufferString(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.ReconcileSourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. ReconcileSourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. ReconcileSourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if tt.wantOps == nil {
+				tt.wantOps = []string{}
+			}
+			if !reflect.DeepEqual(ops, tt.wantOps) {
+				t.Errorf("%q. ReconcileSourceRoles() operations = %v, want %v", tt.name, ops, tt.wantOps)
+			}
+		})
+	}
+}
+
+func Test_storedPermissions(t *testing.T) {
+	expires := time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC)
+	desired := chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData", "WriteData"}, ExpiresAt: &expires, Note: "Incident 88"},
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"WriteData"}, Deny: true},
+	}
+	// Enterprise keeps the grant without the denied allowance and
+	// Chronograf retains the rest
+	readBack := chronograf.Permissions{
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData"}, ExpiresAt: &expires, Note: "Incident 88"},
+		{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"WriteData"}, Deny: true},
+	}
+	for _, retained := range []bool{true, false} {
+		want := storedPermissions(readBack, retained)
+		if got := storedPermissions(desired, retained); !reflect.DeepEqual(got, want) {
+			t.Errorf("storedPermissions(retained=%v) = %v, want %v", retained, got, want)
+		}
+	}
+	if got := storedPermissions(desired, false); !reflect.DeepEqual(got, chronograf.Permissions{{Scope: chronograf.DBScope, Name: "telegraf", Allowed: chronograf.Allowances{"ReadData"}}}) {
+		t.Errorf("storedPermissions() without retained permissions = %v, want the grant alone", got)
+	}
+}
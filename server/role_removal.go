@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+type roleRemovalResponse struct {
+	Role              string                    `json:"role"`
+	OtherRolesChecked bool                      `json:"otherRolesChecked"` // OtherRolesChecked is false if the permissions of other roles were unavailable
+	Users             []roleRemovalUserResponse `json:"users"`
+	Links             selfLinks                 `json:"links"`
+}
+
+type roleRemovalUserResponse struct {
+	Name    string                 `json:"name"`
+	Loses   chronograf.Permissions `json:"loses"`   // Loses are the permissions no other role grants the user
+	Retains []retainedPermission   `json:"retains"` // Retains are the permissions still granted by other roles
+	Regains []retainedPermission   `json:"regains"` // Regains are the permissions the role denies that other roles grant
+}
+
+// retainedPermission is a permission of a removed role and the other roles
+// granting it to a user
+type retainedPermission struct {
+	chronograf.Permission
+	Roles []string `json:"roles"`
+}
+
+// PreviewRemoveSourceRole reports the permissions each user of a role would
+// lose if the role was removed.  Nothing is removed.
+func (s *Service) PreviewRemoveSourceRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	role, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := roleRemovalResponse{
+		Role:              role.Name,
+		OtherRolesChecked: true,
+		Users:             []roleRemovalUserResponse{},
+		Links:             newSelfLinks(srcID, "roles", role.Name),
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		// Without the other roles every permission is reported as lost
+		s.Logger.
+			WithField("component", "roles").
+			WithField("role", role.Name).
+			Error("Unable to retrieve roles to preview removal: ", err)
+		res.OtherRolesChecked = false
+	}
+
+	for _, user := range role.Users {
+		res.Users = append(res.Users, removalImpact(role, user.Name, all))
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
+
+// removalImpact splits the grants of role into those the user would lose
+// and those still granted by the user's other roles.  Allowances the role
+// denies itself are not lost.  The denies of role the user's other roles
+// grant are reported as regained.
+func removalImpact(role *chronograf.Role, user string, all []chronograf.Role) roleRemovalUserResponse {
+	others := []chronograf.Role{}
+	for _, other := range all {
+		if other.Name != role.Name && hasRoleUser(&other, user) {
+			others = append(others, other)
+		}
+	}
+
+	res := roleRemovalUserResponse{
+		Name:    user,
+		Loses:   chronograf.Permissions{},
+		Retains: []retainedPermission{},
+		Regains: []retainedPermission{},
+	}
+	for _, perm := range role.Permissions {
+		lost := chronograf.Allowances{}
+		for _, allowance := range perm.Allowed {
+			if !perm.Deny && !grants(role.Permissions, perm.Scope, perm.Name, allowance) {
+				continue
+			}
+			granting := []string{}
+			for _, other := range others {
+				if grants(other.Permissions, perm.Scope, perm.Name, allowance) {
+					granting = append(granting, other.Name)
+				}
+			}
+
+			if len(granting) == 0 {
+				if !perm.Deny {
+					lost = append(lost, allowance)
+				}
+				continue
+			}
+			granted := retainedPermission{
+				Permission: chronograf.Permission{
+					Scope:   perm.Scope,
+					Name:    perm.Name,
+					Allowed: chronograf.Allowances{allowance},
+				},
+				Roles: granting,
+			}
+			if perm.Deny {
+				res.Regains = append(res.Regains, granted)
+			} else {
+				res.Retains = append(res.Retains, granted)
+			}
+		}
+
+		if len(lost) > 0 {
+			res.Loses = append(res.Loses, chronograf.Permission{
+				Scope:   perm.Scope,
+				Name:    perm.Name,
+				Allowed: lost,
+			})
+		}
+	}
+	return res
+}
+
+// roleForcedRemovalResponse reports a role removed with force although
+// some of its users could not be removed from it first
+type roleForcedRemovalResponse struct {
+	Role           string   `json:"role"`
+	UnremovedUsers []string `json:"unremovedUsers"`
+}
+
+// forceRemoveRole removes the users of the role named name one at a time
+// and deletes the role again, so a user the source cannot remove does not
+// prevent removing the others.  When the role still cannot be deleted its
+// original users are restored, so a failed removal does not lose them.
+// The names of the users that could not be removed are returned.
+func (s *Service) forceRemoveRole(ctx context.Context, srcID int, roles chronograf.RolesStore, name string) ([]string, error) {
+	logger := s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("role", name)
+
+	role, err := roles.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	unremoved := []string{}
+	remaining := append([]chronograf.User{}, role.Users...)
+	for _, u := range role.Users {
+		next := make([]chronograf.User, 0, len(remaining))
+		for _, r := range remaining {
+			if r.Name != u.Name {
+				next = append(next, r)
+			}
+		}
+		if err := roles.Update(ctx, &chronograf.Role{Name: name, Users: next}); err != nil {
+			logger.
+				WithField("user", u.Name).
+				Error("Unable to remove user of role being deleted: ", err)
+			unremoved = append(unremoved, u.Name)
+			continue
+		}
+		remaining = next
+	}
+
+	if err := roles.Delete(ctx, &chronograf.Role{Name: name}); err != nil {
+		if len(remaining) != len(role.Users) {
+			if err := roles.Update(ctx, &chronograf.Role{Name: name, Users: role.Users}); err != nil {
+				logger.Error("Unable to restore users of role not deleted: ", err)
+			}
+		}
+		return unremoved, err
+	}
+	return unremoved, nil
+}
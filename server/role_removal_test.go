@@ -0,0 +1,325 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_PreviewRemoveSourceRole(t *testing.T) {
+	timetravelers := chronograf.Role{
+		Name: "timetravelers",
+		Permissions: chronograf.Permissions{
+			{
+				Scope:   chronograf.DBScope,
+				Name:    "delorean",
+				Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+			},
+		},
+		Users: []chronograf.User{
+			{
+				Name: "marty",
+			},
+			{
+				Name: "doc",
+			},
+		},
+	}
+	type fields struct {
+		Logger     chronograf.Logger
+		TimeSeries TimeSeriesClient
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Users keep permissions granted by other roles",
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								role := timetravelers
+								return &role, nil
+							},
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									timetravelers,
+									{
+										Name: "readers",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"ReadData"},
+											},
+										},
+										Users: []chronograf.User{
+											{
+												Name: "doc",
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"role":"timetravelers","otherRolesChecked":true,"users":[{"name":"marty","loses":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"retains":[],"regains":[]},{"name":"doc","loses":[{"scope":"database","name":"delorean","allowed":["WriteData"]}],"retains":[{"scope":"database","name":"delorean","allowed":["ReadData"],"roles":["readers"]}],"regains":[]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}
+`,
+		},
+		{
+			name: "Other roles unavailable",
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								role := timetravelers
+								role.Users = role.Users[:1]
+								return &role, nil
+							},
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return nil, fmt.Errorf("meta service unavailable")
+							},
+						}, nil
+					},
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"role":"timetravelers","otherRolesChecked":false,"users":[{"name":"marty","loses":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"retains":[],"regains":[]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: tt.fields.TimeSeries,
+				Logger:           tt.fields.Logger,
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/timetravelers/preview-remove", nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: "timetravelers",
+					},
+				}))
+
+			h.PreviewRemoveSourceRole(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. PreviewRemoveSourceRole() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. PreviewRemoveSourceRole() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestService_RemoveSourceRole_Force(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		undeletable bool
+		wantStatus  int
+		wantBody    string
+		wantUsers   []string
+	}{
+		{
+			name:       "Users left in the role fail the removal",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":400,"message":"role biffsgang has users"}`,
+			wantUsers:  []string{"marty", "biff"},
+		},
+		{
+			name:       "Forced removal reports users that cannot be removed",
+			query:      "?force=true",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"role":"biffsgang","unremovedUsers":["biff"]}` + "\n",
+			wantUsers:  []string{"biff"},
+		},
+		{
+			name:        "Failed forced removal restores the users",
+			query:       "?force=true",
+			undeletable: true,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `{"code":400,"message":"role biffsgang is in use; users biff could not be removed"}`,
+			wantUsers:   []string{"marty", "biff"},
+		},
+		{
+			name:       "Invalid force",
+			query:      "?force=maybe",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"force must be a boolean"}`,
+			wantUsers:  []string{"marty", "biff"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// biff was deleted from the source so cannot be removed from the role
+			users := []string{"marty", "biff"}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								role := &chronograf.Role{Name: name}
+								for _, u := range users {
+									role.Users = append(role.Users, chronograf.User{Name: u})
+								}
+								return role, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								next, kept := []string{}, false
+								for _, u := range role.Users {
+									next = append(next, u.Name)
+									kept = kept || u.Name == "biff"
+								}
+								if !kept {
+									return fmt.Errorf("user not found")
+								}
+								users = next
+								return nil
+							},
+							DeleteF: func(ctx context.Context, role *chronograf.Role) error {
+								if tt.undeletable {
+									return fmt.Errorf("role %s is in use", role.Name)
+								}
+								for _, u := range users {
+									if u != "biff" {
+										return fmt.Errorf("role %s has users", role.Name)
+									}
+								}
+								return nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "http://server.local/chronograf/v1/sources/1/roles/biffsgang"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: "biffsgang",
+					},
+				}))
+
+			h.RemoveSourceRole(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. RemoveSourceRole() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. RemoveSourceRole() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if fmt.Sprint(users) != fmt.Sprint(tt.wantUsers) {
+				t.Errorf("%q. RemoveSourceRole() left users %v, want %v", tt.name, users, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func Test_removalImpact(t *testing.T) {
+	role := &chronograf.Role{
+		Name: "dbas",
+		Permissions: chronograf.Permissions{
+			{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"ReadData", "WriteData"}},
+			{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"WriteData"}, Deny: true},
+			{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData", "DropDatabase"}, Deny: true},
+		},
+		Users: []chronograf.User{{Name: "doc"}},
+	}
+	all := []chronograf.Role{
+		*role,
+		{
+			Name: "finance",
+			Permissions: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData"}},
+			},
+			Users: []chronograf.User{{Name: "doc"}},
+		},
+	}
+
+	// The write the role denies itself is not lost, and the read of
+	// payroll it denies is regained through finance
+	want := roleRemovalUserResponse{
+		Name: "doc",
+		Loses: chronograf.Permissions{
+			{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"ReadData"}},
+		},
+		Retains: []retainedPermission{},
+		Regains: []retainedPermission{
+			{
+				Permission: chronograf.Permission{Scope: chronograf.DBScope, Name: "payroll", Allowed: chronograf.Allowances{"ReadData"}},
+				Roles:      []string{"finance"},
+			},
+		},
+	}
+	if got := removalImpact(role, "doc", all); !reflect.DeepEqual(got, want) {
+		t.Errorf("removalImpact() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+)
+
+type renameScopeRequest struct {
+	From string `json:"from"` // From is the database permissions are scoped to before the rename
+	To   string `json:"to"`   // To is the database permissions are scoped to after the rename
+}
+
+func (r *renameScopeRequest) Valid() error {
+	var errs validationErrors
+	if r.From == "" {
+		errs.add("from", "Database to rename required")
+	}
+	if r.To == "" {
+		errs.add("to", "New database name required")
+	}
+	if r.From != "" && r.From == r.To {
+		errs.add("to", "New database name must differ from %s", r.From)
+	}
+	return errs.err()
+}
+
+// roleFailure is why a role could not be changed
+type roleFailure struct {
+	Role    string `json:"role"`
+	Message string `json:"message"`
+}
+
+type renameScopeResponse struct {
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Updated    []string      `json:"updated"`    // Updated are the roles whose permissions now reference the new database
+	Failed     []roleFailure `json:"failed"`     // Failed are the roles that could not be updated or restored
+	RolledBack []string      `json:"rolledBack"` // RolledBack are the roles restored because another role failed
+	Pending    bool          `json:"pending"`    // Pending is true if the updates await approval
+}
+
+// renameScope returns a copy of perms with the permissions of database from
+// scoped to database to.  ok is false if no permission references from.
+func renameScope(perms chronograf.Permissions, from, to string) (renamed chronograf.Permissions, ok bool) {
+	renamed = make(chronograf.Permissions, len(perms))
+	for i, perm := range perms {
+		if perm.Scope == chronograf.DBScope && perm.Name == from {
+			perm.Name = to
+			ok = true
+		}
+		renamed[i] = perm
+	}
+	return renamed, ok
+}
+
+// RenameSourceRoleScope rewrites the permissions of every role of a source
+// scoped to one database to be scoped to another, e.g. after the database is
+// renamed.  Every rewritten role is validated before any is updated.  Updates
+// are all or nothing on a best effort basis: if any role fails to update,
+// the roles already updated are restored and each failure is reported.
+func (s *Service) RenameSourceRoleScope(w http.ResponseWriter, r *http.Request) {
+	var req renameScopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	all, err := roles.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	// Rewrite and validate every affected role before changing any
+	originals := []chronograf.Role{}
+	updates := []chronograf.Role{}
+	var errs validationErrors
+	for _, role := range all {
+		if s.isProtectedRole(role.Name) {
+			continue
+		}
+		perms, ok := renameScope(role.Permissions, req.From, req.To)
+		if !ok {
+			continue
+		}
+		if err := validPermissions(&perms, s.permissionPolicy()); err != nil {
+			errs.merge(fmt.Sprintf("roles.%s.permissions", role.Name), err)
+			continue
+		}
+		originals = append(originals, role)
+		updates = append(updates, chronograf.Role{
+			Name:        role.Name,
+			Permissions: perms,
+		})
+	}
+	if err := errs.err(); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+
+	res := renameScopeResponse{
+		From:       req.From,
+		To:         req.To,
+		Updated:    []string{},
+		Failed:     []roleFailure{},
+		RolledBack: []string{},
+		Pending:    s.RoleApprovals != nil,
+	}
+	updated := []int{}
+	for i := range updates {
+		if s.RoleApprovals != nil {
+			s.RoleApprovals.propose(srcID, roleChange{role: updates[i]})
+			res.Updated = append(res.Updated, updates[i].Name)
+			continue
+		}
+		if err := roles.Update(ctx, &updates[i]); err != nil {
+			res.Failed = append(res.Failed, roleFailure{
+				Role:    updates[i].Name,
+				Message: fmt.Sprintf("Unable to update role: %v", err),
+			})
+			continue
+		}
+		updated = append(updated, i)
+	}
+
+	status := http.StatusOK
+	if len(res.Failed) > 0 {
+		status = http.StatusBadRequest
+	}
+	for _, i := range updated {
+		if status == http.StatusOK {
+			res.Updated = append(res.Updated, updates[i].Name)
+			continue
+		}
+		restore := chronograf.Role{
+			Name:        originals[i].Name,
+			Permissions: originals[i].Permissions,
+		}
+		if err := roles.Update(ctx, &restore); err != nil {
+			res.Updated = append(res.Updated, restore.Name)
+			res.Failed = append(res.Failed, roleFailure{
+				Role:    restore.Name,
+				Message: fmt.Sprintf("Unable to restore role: %v", err),
+			})
+			continue
+		}
+		res.RolledBack = append(res.RolledBack, restore.Name)
+	}
+
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("from", req.From).
+		WithField("to", req.To).
+		WithField("updated", len(res.Updated)).
+		WithField("failed", len(res.Failed)).
+		WithField("rolledBack", len(res.RolledBack)).
+		Info("Renamed database of role permissions")
+	encodeJSON(w, status, res, s.Logger)
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_RenameSourceRoleScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		failUpdate string
+		wantStatus int
+		wantBody   string
+		wantOps    []string
+	}{
+		{
+			name:       "Permissions of the database are renamed",
+			body:       `{"from": "delorean", "to": "timemachine"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"from":"delorean","to":"timemachine","updated":["timetravelers","docs"],"failed":[],"rolledBack":[],"pending":false}
+`,
+			wantOps: []string{"update timetravelers timemachine,timemachine", "update docs timemachine"},
+		},
+		{
+			name:       "Updated roles are restored when a role fails",
+			body:       `{"from": "delorean", "to": "timemachine"}`,
+			failUpdate: "docs",
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{"from":"delorean","to":"timemachine","updated":[],"failed":[{"role":"docs","message":"Unable to update role: docs is locked"}],"rolledBack":["timetravelers"],"pending":false}
+`,
+			wantOps: []string{"update timetravelers timemachine,timemachine", "update timetravelers delorean,delorean"},
+		},
+		{
+			name:       "Renaming to a forbidden database changes nothing",
+			body:       `{"from": "delorean", "to": "_internal"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Database _internal may not be granted to roles; Database _internal may not be granted to roles","errors":[{"field":"roles.timetravelers.permissions[0].name","message":"Database _internal may not be granted to roles"},{"field":"roles.docs.permissions[0].name","message":"Database _internal may not be granted to roles"}]}
+`,
+			wantOps: []string{},
+		},
+		{
+			name:       "Same database",
+			body:       `{"from": "delorean", "to": "delorean"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"New database name must differ from delorean","errors":[{"field":"to","message":"New database name must differ from delorean"}]}
+`,
+			wantOps: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := []string{}
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "timetravelers",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"READ"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"WRITE"},
+												Deny:    true,
+											},
+										},
+									},
+									{
+										Name: "biffsgang",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "almanac",
+												Allowed: chronograf.Allowances{"READ"},
+											},
+										},
+									},
+									{
+										Name: "docs",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"WRITE"},
+											},
+										},
+									},
+								}, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								if role.Name == tt.failUpdate {
+									return fmt.Errorf("%s is locked", role.Name)
+								}
+								op := "update " + role.Name + " "
+								for i, perm := range role.Permissions {
+									if i > 0 {
+										op += ","
+									}
+									op += perm.Name
+								}
+								ops = append(ops, op)
+								return nil
+							},
+						}, nil
+					},
+				},
+				Logger:          log.New(log.DebugLevel),
+				ForbiddenScopes: []string{"_internal"},
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles-rename-scope", bytes.NewReader([]byte(tt.body)))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.RenameSourceRoleScope(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. RenameSourceRoleScope() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. RenameSourceRoleScope() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+			if !reflect.DeepEqual(ops, tt.wantOps) {
+				t.Errorf("%q. RenameSourceRoleScope() ops = %v, want %v", tt.name, ops, tt.wantOps)
+			}
+		})
+	}
+}
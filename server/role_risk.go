@@ -0,0 +1,177 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// Factors of the risk score of a role
+const (
+	riskAllDatabases = "allDatabases" // riskAllDatabases counts the grants of all databases
+	riskDatabases    = "databases"    // riskDatabases counts the distinct databases granted
+	riskWrite        = "write"        // riskWrite counts the granted allowances that write data
+	riskDelete       = "delete"       // riskDelete counts the granted allowances that drop data
+	riskAdmin        = "admin"        // riskAdmin counts the granted allowances that administer the source
+	riskUsers        = "users"        // riskUsers counts the users of the role
+)
+
+// riskFactors are the factors of a risk score in the order they are reported
+var riskFactors = []string{riskAllDatabases, riskDatabases, riskWrite, riskDelete, riskAdmin, riskUsers}
+
+// DefaultRoleRiskWeights are the weights of each factor of a role's risk
+// score unless configured otherwise
+var DefaultRoleRiskWeights = map[string]int{
+	riskAllDatabases: 10,
+	riskDatabases:    1,
+	riskWrite:        3,
+	riskDelete:       5,
+	riskAdmin:        8,
+	riskUsers:        1,
+}
+
+// clusterAllowances are the Enterprise allowances that administer the
+// cluster rather than the data of a database
+var clusterAllowances = []string{
+	"ViewAdmin",
+	"CreateDatabase",
+	"CreateUserAndRole",
+	"AddRemoveNode",
+	"Rebalance",
+	"ManageShard",
+	"CopyShard",
+	"KapacitorConfigAPI",
+}
+
+// riskyAllowances are the OSS and Enterprise allowances counted by each
+// allowance factor.  An allowance may count towards several factors, e.g.
+// ALL writes, drops and administers.
+var riskyAllowances = map[string][]string{
+	riskWrite:  {"ALL", "WRITE", "WriteData"},
+	riskDelete: {"ALL", "DropDatabase", "DropData"},
+	riskAdmin:  append([]string{"ALL"}, clusterAllowances...),
+}
+
+// Breadths of the grants of a role
+const (
+	BroadRole  = "broad"  // BroadRole grants on all databases or administers the cluster
+	NarrowRole = "narrow" // NarrowRole only grants on named databases
+)
+
+// broadGrant is true if perm grants on all databases or grants a cluster
+// allowance.  Such grants weigh most in a role's risk score.
+func broadGrant(perm chronograf.Permission) bool {
+	if perm.Deny {
+		return false
+	}
+	if perm.Scope == chronograf.AllScope {
+		return true
+	}
+	for _, a := range perm.Allowed {
+		if hasAllowance(clusterAllowances, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleBreadth classifies the unexpired grants of role as broad or narrow
+func roleBreadth(role *chronograf.Role, now time.Time) string {
+	perms, _ := unexpiredPermissions(role.Permissions, now)
+	for _, perm := range perms {
+		if broadGrant(perm) {
+			return BroadRole
+		}
+	}
+	return NarrowRole
+}
+
+// NewRoleRiskWeights overrides the default weights of risk factors with
+// pairs of factor:weight, e.g. admin:20.  A weight of 0 ignores the factor.
+func NewRoleRiskWeights(pairs []string) (map[string]int, error) {
+	weights := map[string]int{}
+	for factor, weight := range DefaultRoleRiskWeights {
+		weights[factor] = weight
+	}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Role risk weight %q must be factor:weight", pair)
+		}
+		if _, ok := weights[parts[0]]; !ok {
+			return nil, fmt.Errorf("Unknown role risk factor %s; must be one of %s", parts[0], strings.Join(riskFactors, ", "))
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("Role risk weight of %s must be a non-negative integer", parts[0])
+		}
+		weights[parts[0]] = weight
+	}
+	return weights, nil
+}
+
+// roleRiskFactor is how much one factor contributes to a role's risk score
+type roleRiskFactor struct {
+	Factor string `json:"factor"`
+	Count  int    `json:"count"`
+	Score  int    `json:"score"` // Score is the count times the factor's weight
+}
+
+// roleRisk scores the breadth and power of the grants of a role and the
+// number of its users.  Denies do not add to the risk.  Only the factors
+// present in the role are returned.
+func roleRisk(role *chronograf.Role, weights map[string]int) (int, []roleRiskFactor) {
+	counts := map[string]int{}
+	dbs := map[string]bool{}
+	for _, perm := range role.Permissions {
+		if perm.Deny {
+			continue
+		}
+		if perm.Scope == chronograf.AllScope {
+			counts[riskAllDatabases]++
+		} else if perm.Name != "" {
+			dbs[perm.Name] = true
+		}
+		for _, a := range perm.Allowed {
+			for factor, allowances := range riskyAllowances {
+				if hasAllowance(allowances, a) {
+					counts[factor]++
+				}
+			}
+		}
+	}
+	counts[riskDatabases] = len(dbs)
+	counts[riskUsers] = len(role.Users)
+
+	score := 0
+	factors := []roleRiskFactor{}
+	for _, factor := range riskFactors {
+		if counts[factor] == 0 {
+			continue
+		}
+		f := roleRiskFactor{
+			Factor: factor,
+			Count:  counts[factor],
+			Score:  counts[factor] * weights[factor],
+		}
+		score += f.Score
+		factors = append(factors, f)
+	}
+	return score, factors
+}
+
+// withRisk adds the risk score of the role and its factors to the response
+// using the configured weights.  Expired permissions are not scored.
+func (s *Service) withRisk(rr *sourceRoleResponse, role chronograf.Role) {
+	weights := s.RoleRiskWeights
+	if weights == nil {
+		weights = DefaultRoleRiskWeights
+	}
+	role.Permissions, _ = unexpiredPermissions(role.Permissions, time.Now())
+	score, factors := roleRisk(&role, weights)
+	rr.RiskScore = &score
+	rr.RiskFactors = factors
+}
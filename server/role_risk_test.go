@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestNewRoleRiskWeights(t *testing.T) {
+	got, err := NewRoleRiskWeights([]string{"admin:20", "users:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"allDatabases": 10,
+		"databases":    1,
+		"write":        3,
+		"delete":       5,
+		"admin":        20,
+		"users":        0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewRoleRiskWeights() = %v, want %v", got, want)
+	}
+	if DefaultRoleRiskWeights["admin"] != 8 {
+		t.Errorf("NewRoleRiskWeights() changed the default weights")
+	}
+
+	for _, pairs := range [][]string{{"admin"}, {"power:3"}, {"admin:high"}, {"admin:-1"}} {
+		if _, err := NewRoleRiskWeights(pairs); err == nil {
+			t.Errorf("NewRoleRiskWeights(%q) expected error", pairs)
+		}
+	}
+}
+
+func TestService_SourceRoles_IncludeRisk(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		weights    map[string]int
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Risk of every permission of the roles",
+			query:      "?includeRisk=true&database=delorean",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"riskScore":29,"riskFactors":[{"factor":"allDatabases","count":1,"score":10},{"factor":"databases","count":1,"score":1},{"factor":"write","count":1,"score":3},{"factor":"delete","count":1,"score":5},{"factor":"admin","count":1,"score":8},{"factor":"users","count":2,"score":2}]}]}
+`,
+		},
+		{
+			name:       "Configured weights",
+			query:      "?includeRisk=true&database=delorean",
+			weights:    map[string]int{"allDatabases": 1, "admin": 100},
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"riskScore":101,"riskFactors":[{"factor":"allDatabases","count":1,"score":1},{"factor":"databases","count":1,"score":0},{"factor":"write","count":1,"score":0},{"factor":"delete","count":1,"score":0},{"factor":"admin","count":1,"score":100},{"factor":"users","count":2,"score":0}]}]}
+`,
+		},
+		{
+			name:       "Without risk",
+			query:      "?database=delorean",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}]}
+`,
+		},
+		{
+			name:       "Invalid includeRisk",
+			query:      "?includeRisk=maybe",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"includeRisk must be a boolean"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name:  "timetravelers",
+										Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+											},
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"DropData", "ViewAdmin"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "almanac",
+												Allowed: chronograf.Allowances{"WriteData"},
+												Deny:    true,
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				RoleRiskWeights: tt.weights,
+				Logger:          log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceRoles(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceRoles() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceRoles() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_roleBreadth(t *testing.T) {
+	past := time.Date(1955, time.November, 12, 22, 4, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		perms chronograf.Permissions
+		want  string
+	}{
+		{
+			name: "Grant of all databases",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+			},
+			want: BroadRole,
+		},
+		{
+			name: "Cluster allowance of a database",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"ReadData", "ManageShard"}},
+			},
+			want: BroadRole,
+		},
+		{
+			name: "Database grants and a deny of all databases",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "delorean", Allowed: chronograf.Allowances{"ReadData", "WriteData"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"WRITE"}, Deny: true},
+			},
+			want: NarrowRole,
+		},
+		{
+			name: "Expired grant of all databases",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &past},
+			},
+			want: NarrowRole,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &chronograf.Role{Name: "timetravelers", Permissions: tt.perms}
+			if got := roleBreadth(role, time.Now()); got != tt.want {
+				t.Errorf("roleBreadth() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := validSourceRolesQuery(url.Values{"scope": {"wide"}}); err == nil || err.Error() != "scope must be broad or narrow" {
+		t.Errorf("validSourceRolesQuery() error = %v, want scope must be broad or narrow", err)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/influxdata/chronograf"
+)
+
+// permissionQuery selects the permissions granting an allowance on the
+// databases matching a glob
+type permissionQuery struct {
+	Database  string // Database is a path.Match pattern anchored to the whole database name
+	Allowance string // Allowance must be granted by a matching permission; empty matches any allowance
+}
+
+func validPermissionQuery(q url.Values) (*permissionQuery, error) {
+	pq := &permissionQuery{
+		Database:  q.Get("database"),
+		Allowance: q.Get("allowance"),
+	}
+	if pq.Database == "" {
+		pq.Database = "*"
+	}
+	if _, err := path.Match(pq.Database, ""); err != nil {
+		return nil, fmt.Errorf("database is not a valid glob: %v", err)
+	}
+	return pq, nil
+}
+
+// matches returns the permissions of perms selected by the query.  Only the
+// queried allowance is kept in each matching permission.  Permissions
+// scoped to all databases match any database glob.  Denies grant nothing
+// so never match.
+func (q *permissionQuery) matches(perms chronograf.Permissions) chronograf.Permissions {
+	res := chronograf.Permissions{}
+	for _, perm := range perms {
+		if perm.Deny {
+			continue
+		}
+		if perm.Scope != chronograf.AllScope {
+			if perm.Scope != chronograf.DBScope {
+				continue
+			}
+			if ok, _ := path.Match(q.Database, perm.Name); !ok {
+				continue
+			}
+		}
+
+		allowed := chronograf.Allowances{}
+		for _, a := range perm.Allowed {
+			if q.Allowance == "" || a == q.Allowance {
+				allowed = append(allowed, a)
+			}
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+		perm.Allowed = allowed
+		res = append(res, perm)
+	}
+	return res
+}
+
+type rolePermissionMatch struct {
+	Role    sourceRoleResponse     `json:"role"`
+	Matches chronograf.Permissions `json:"matches"` // Matches are the permissions of the role selected by the query
+}
+
+// SearchSourceRolePermissions lists the roles with a permission granting the
+// allowance query parameter on the databases matching the database glob.
+// Protected system roles are included so audits see every grant.
+func (s *Service) SearchSourceRolePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q, err := validPermissionQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := struct {
+		Roles []rolePermissionMatch `json:"roles"`
+	}{[]rolePermissionMatch{}}
+	for i := range roles {
+		matches := q.matches(roles[i].Permissions)
+		if len(matches) == 0 {
+			continue
+		}
+		res.Roles = append(res.Roles, rolePermissionMatch{
+			Role:    newSourceRoleResponse(srcID, &roles[i]),
+			Matches: matches,
+		})
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SearchSourceRolePermissions(t *testing.T) {
+	roles := []chronograf.Role{
+		{
+			Name: "loggers",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "logs-prod",
+					Allowed: chronograf.Allowances{"READ", "WRITE"},
+				},
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "metrics",
+					Allowed: chronograf.Allowances{"WRITE"},
+				},
+			},
+		},
+		{
+			Name: "readers",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "logs-dev",
+					Allowed: chronograf.Allowances{"READ"},
+				},
+			},
+		},
+		{
+			Name: "admins",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"WRITE"},
+				},
+			},
+		},
+		{
+			Name: "janitors",
+			Permissions: chronograf.Permissions{
+				{
+					Scope:   chronograf.DBScope,
+					Name:    "logs-dev",
+					Allowed: chronograf.Allowances{"DELETE"},
+				},
+				{
+					Scope:   chronograf.AllScope,
+					Allowed: chronograf.Allowances{"DELETE"},
+					Deny:    true,
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Roles granting write on matching databases",
+			query:      "?database=logs-*&allowance=WRITE",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"role":{"users":[],"name":"loggers","permissions":[{"scope":"database","name":"logs-prod","allowed":["READ","WRITE"]},{"scope":"database","name":"metrics","allowed":["WRITE"]}],"links":{"self":"/chronograf/v1/sources/1/roles/loggers"}},"matches":[{"scope":"database","name":"logs-prod","allowed":["WRITE"]}]},{"role":{"users":[],"name":"admins","permissions":[{"scope":"all","allowed":["WRITE"]}],"links":{"self":"/chronograf/v1/sources/1/roles/admins"}},"matches":[{"scope":"all","allowed":["WRITE"]}]}]}
+`,
+		},
+		{
+			name:       "Glob is anchored to the whole database name",
+			query:      "?database=logs&allowance=READ",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[]}
+`,
+		},
+		{
+			name:       "Denies do not grant the allowance",
+			query:      "?allowance=DELETE",
+			wantStatus: http.StatusOK,
+			wantBody: `{"roles":[{"role":{"users":[],"name":"janitors","permissions":[{"scope":"database","name":"logs-dev","allowed":["DELETE"]},{"scope":"all","allowed":["DELETE"],"deny":true}],"links":{"self":"/chronograf/v1/sources/1/roles/janitors"}},"matches":[{"scope":"database","name":"logs-dev","allowed":["DELETE"]}]}]}
+`,
+		},
+		{
+			name:       "Invalid glob",
+			query:      "?database=logs-[",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"code":422,"message":"database is not a valid glob: syntax error in pattern"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								all := make([]chronograf.Role, len(roles))
+								copy(all, roles)
+								return all, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/permissions/roles"+tt.query, nil)
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SearchSourceRolePermissions(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SearchSourceRolePermissions() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SearchSourceRolePermissions() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// absorbedPermission is the allowances of a permission made redundant by
+// another permission of the role
+type absorbedPermission struct {
+	Field   string                `json:"field"`   // Field is the absorbed permission, e.g. permissions[2]
+	Allowed chronograf.Allowances `json:"allowed"` // Allowed are the allowances of Field that were absorbed
+	By      string                `json:"by"`      // By is the permission absorbing them
+}
+
+type permissionSummaryResponse struct {
+	Absorbed   []absorbedPermission `json:"absorbed"`
+	Summarized sourceRoleResponse   `json:"summarized"` // Summarized is the role with its redundant allowances removed
+}
+
+// coversPermission is true if every database perm applies to is one other
+// applies to
+func coversPermission(other, perm chronograf.Permission) bool {
+	return other.Scope == chronograf.AllScope ||
+		(other.Scope == perm.Scope && other.Name == perm.Name)
+}
+
+// outlastsPermission is true if other does not expire before perm
+func outlastsPermission(other, perm chronograf.Permission) bool {
+	if other.ExpiresAt == nil {
+		return true
+	}
+	return perm.ExpiresAt != nil && !other.ExpiresAt.Before(*perm.ExpiresAt)
+}
+
+// absorbs is true if the allowance of perms[i] is redundant given the same
+// allowance of perms[j].  Grants are absorbed by grants and denies by
+// denies, as long as the absorbing permission covers the same databases
+// for at least as long.  Of two permissions covering each other only the
+// later is absorbed.
+func absorbs(perms chronograf.Permissions, j, i int, allowance string) bool {
+	other, perm := perms[j], perms[i]
+	if i == j || other.Deny != perm.Deny || !hasAllowance(other.Allowed, allowance) {
+		return false
+	}
+	if !coversPermission(other, perm) || !outlastsPermission(other, perm) {
+		return false
+	}
+	if coversPermission(perm, other) && outlastsPermission(perm, other) {
+		return j < i
+	}
+	return true
+}
+
+// summarizePermissions removes the allowances of perms absorbed by another
+// permission.  Access is unchanged: a grant is only removed if another
+// grant allows it on the same databases for as long, and likewise for
+// denies.  Each absorbed allowance is attributed to a permission that is
+// kept.  Permissions left with no allowances are removed.
+func summarizePermissions(perms chronograf.Permissions) (chronograf.Permissions, []absorbedPermission) {
+	absorbedBy := func(i int, allowance string) int {
+		for j := range perms {
+			if absorbs(perms, j, i, allowance) {
+				return j
+			}
+		}
+		return -1
+	}
+
+	summarized := chronograf.Permissions{}
+	absorbed := []absorbedPermission{}
+	for i, perm := range perms {
+		var kept chronograf.Allowances
+		by := map[int]chronograf.Allowances{}
+		byOrder := []int{}
+		for _, a := range perm.Allowed {
+			j := absorbedBy(i, a)
+			if j < 0 {
+				if !hasAllowance(kept, a) {
+					kept = append(kept, a)
+				}
+				continue
+			}
+			// Absorption is transitive so follow it to a permission
+			// whose allowance is kept
+			for k := absorbedBy(j, a); k >= 0; k = absorbedBy(j, a) {
+				j = k
+			}
+			if _, ok := by[j]; !ok {
+				byOrder = append(byOrder, j)
+			}
+			by[j] = append(by[j], a)
+		}
+		for _, j := range byOrder {
+			absorbed = append(absorbed, absorbedPermission{
+				Field:   fmt.Sprintf("permissions[%d]", i),
+				Allowed: by[j],
+				By:      fmt.Sprintf("permissions[%d]", j),
+			})
+		}
+		if len(kept) == 0 && len(perm.Allowed) > 0 {
+			continue
+		}
+		p := perm
+		p.Allowed = kept
+		if p.Allowed == nil {
+			p.Allowed = chronograf.Allowances{}
+		}
+		summarized = append(summarized, p)
+	}
+	return summarized, absorbed
+}
+
+// SummarizeSourceRolePermissions returns the permissions of a role with the
+// redundant allowances removed and explains which permission absorbed
+// each.  The summarized permissions grant and deny exactly what the role's
+// do.  The role is not changed.
+func (s *Service) SummarizeSourceRolePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	role, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := permissionSummaryResponse{
+		Summarized: newSourceRoleResponse(srcID, role),
+	}
+	res.Summarized.Permissions, res.Absorbed = summarizePermissions(role.Permissions)
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_summarizePermissions(t *testing.T) {
+	soon := time.Date(1985, time.October, 26, 1, 21, 0, 0, time.UTC)
+	later := soon.Add(time.Hour)
+	tests := []struct {
+		name         string
+		perms        chronograf.Permissions
+		want         chronograf.Permissions
+		wantAbsorbed []absorbedPermission
+	}{
+		{
+			name: "Database grant absorbed by all databases",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ", "WRITE"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+			},
+			want: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"WRITE"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+			},
+			wantAbsorbed: []absorbedPermission{
+				{Field: "permissions[0]", Allowed: chronograf.Allowances{"READ"}, By: "permissions[1]"},
+			},
+		},
+		{
+			name: "Duplicates are absorbed by the first",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+			},
+			want: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+			},
+			wantAbsorbed: []absorbedPermission{
+				{Field: "permissions[1]", Allowed: chronograf.Allowances{"READ"}, By: "permissions[0]"},
+				{Field: "permissions[2]", Allowed: chronograf.Allowances{"READ"}, By: "permissions[0]"},
+			},
+		},
+		{
+			name: "Absorbed by the permission that is kept",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &soon},
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &later},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+			},
+			want: chronograf.Permissions{
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+			},
+			wantAbsorbed: []absorbedPermission{
+				{Field: "permissions[0]", Allowed: chronograf.Allowances{"READ"}, By: "permissions[2]"},
+				{Field: "permissions[1]", Allowed: chronograf.Allowances{"READ"}, By: "permissions[2]"},
+			},
+		},
+		{
+			name: "Expiring permission does not absorb a lasting one",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &soon},
+			},
+			want: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}, ExpiresAt: &soon},
+			},
+			wantAbsorbed: []absorbedPermission{},
+		},
+		{
+			name: "Denies absorb only denies",
+			perms: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"WRITE"}, Deny: true},
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"WRITE"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"WRITE"}, Deny: true},
+			},
+			want: chronograf.Permissions{
+				{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"WRITE"}},
+				{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"WRITE"}, Deny: true},
+			},
+			wantAbsorbed: []absorbedPermission{
+				{Field: "permissions[0]", Allowed: chronograf.Allowances{"WRITE"}, By: "permissions[2]"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, absorbed := summarizePermissions(tt.perms)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizePermissions() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(absorbed, tt.wantAbsorbed) {
+				t.Errorf("summarizePermissions() absorbed = %v, want %v", absorbed, tt.wantAbsorbed)
+			}
+			for _, db := range []string{"pics", "telegraf"} {
+				for _, a := range []string{"READ", "WRITE"} {
+					before, _ := decide(tt.perms, chronograf.DBScope, db, a)
+					after, _ := decide(got, chronograf.DBScope, db, a)
+					if before != after {
+						t.Errorf("summarizePermissions() changed %s on %s from %v to %v", a, db, before, after)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestService_SummarizeSourceRolePermissions(t *testing.T) {
+	h := &Service{
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					return chronograf.Source{
+						ID: 1,
+					}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+						return &chronograf.Role{
+							Name: "biffsgang",
+							Permissions: chronograf.Permissions{
+								{Scope: chronograf.DBScope, Name: "pics", Allowed: chronograf.Allowances{"READ"}},
+								{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"READ"}},
+							},
+						}, nil
+					},
+				}, nil
+			},
+		},
+		Logger: log.New(log.DebugLevel),
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/biffsgang/summary", nil)
+	r = r.WithContext(httprouter.WithParams(
+		context.Background(),
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: "1",
+			},
+			{
+				Key:   "rid",
+				Value: "biffsgang",
+			},
+		}))
+
+	h.SummarizeSourceRolePermissions(w, r)
+
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	want := `{"absorbed":[{"field":"permissions[0]","allowed":["READ"],"by":"permissions[1]"}],"summarized":{"users":[],"name":"biffsgang","permissions":[{"scope":"all","allowed":["READ"]}],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}}
+`
+	if string(body) != want {
+		t.Errorf("SummarizeSourceRolePermissions() = \n***%v***\n,\nwant\n***%v***", string(body), want)
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+// DefaultRoleTemplatePattern replaces every database name of an exported
+// role template with a placeholder
+const DefaultRoleTemplatePattern = `^.+$`
+
+// NewRoleTemplatePattern compiles the pattern of the parts of database
+// names exported role templates replace with placeholders.  Without a
+// group the whole match is replaced; with one group only the group is, and
+// a named group names the placeholder, e.g. ^telegraf_(?P<env>.+)$ turns
+// telegraf_prod into telegraf_:env:.
+func NewRoleTemplatePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid role template pattern: %v", err)
+	}
+	if re.NumSubexp() > 1 {
+		return nil, fmt.Errorf("Role template pattern %s has more than one group", pattern)
+	}
+	return re, nil
+}
+
+// roleTemplateParameter is a placeholder of a role template and the value
+// it replaced in the exported role
+type roleTemplateParameter struct {
+	Placeholder string `json:"placeholder"`
+	Value       string `json:"value"`
+}
+
+// roleTemplate is a role with its database names replaced by placeholders
+// in the syntax of dashboard template variables, e.g. :database:
+type roleTemplate struct {
+	Name        string                  `json:"name"`
+	Permissions chronograf.Permissions  `json:"permissions"`
+	Parameters  []roleTemplateParameter `json:"parameters"`
+}
+
+// placeholders names the placeholders of a template, one per distinct
+// value.  Values of the same kind are numbered, e.g. :database:,
+// :database2:.
+type placeholders struct {
+	params []roleTemplateParameter
+	byKey  map[string]string
+	counts map[string]int
+}
+
+func (p *placeholders) placeholder(kind, value string) string {
+	key := kind + "\x00" + value
+	if ph, ok := p.byKey[key]; ok {
+		return ph
+	}
+	p.counts[kind]++
+	ph := ":" + kind + ":"
+	if n := p.counts[kind]; n > 1 {
+		ph = ":" + kind + strconv.Itoa(n) + ":"
+	}
+	p.byKey[key] = ph
+	p.params = append(p.params, roleTemplateParameter{
+		Placeholder: ph,
+		Value:       value,
+	})
+	return ph
+}
+
+// parameterize replaces the part of db matched by pattern with a
+// placeholder.  Databases the pattern does not match are kept.
+func (p *placeholders) parameterize(db string, pattern *regexp.Regexp) string {
+	m := pattern.FindStringSubmatchIndex(db)
+	if m == nil {
+		return db
+	}
+	start, end, kind := m[0], m[1], "database"
+	if pattern.NumSubexp() == 1 {
+		if m[2] < 0 {
+			return db
+		}
+		start, end = m[2], m[3]
+		if name := pattern.SubexpNames()[1]; name != "" {
+			kind = name
+		}
+	}
+	if start == end {
+		return db
+	}
+	return db[:start] + p.placeholder(kind, db[start:end]) + db[end:]
+}
+
+// newRoleTemplate exports role as a template.  Users are left out, and so
+// are expired permissions; the expiry of temporary permissions is dropped
+// as it is specific to the role.
+func newRoleTemplate(role *chronograf.Role, pattern *regexp.Regexp, now time.Time) roleTemplate {
+	p := &placeholders{
+		params: []roleTemplateParameter{},
+		byKey:  map[string]string{},
+		counts: map[string]int{},
+	}
+	perms, _ := unexpiredPermissions(role.Permissions, now)
+	for i := range perms {
+		perms[i].ExpiresAt = nil
+		if perms[i].Scope == chronograf.DBScope && perms[i].Name != "" {
+			perms[i].Name = p.parameterize(perms[i].Name, pattern)
+		}
+	}
+	return roleTemplate{
+		Name:        role.Name,
+		Permissions: perms,
+		Parameters:  p.params,
+	}
+}
+
+func (s *Service) roleTemplatePattern() *regexp.Regexp {
+	if s.RoleTemplatePattern != nil {
+		return s.RoleTemplatePattern
+	}
+	return regexp.MustCompile(DefaultRoleTemplatePattern)
+}
+
+// ExportSourceRoleTemplate returns a role as a template for creating
+// similar roles elsewhere.  The database names of its permissions are
+// replaced with placeholders and the replaced names listed as parameters.
+func (s *Service) ExportSourceRoleTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	role, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	encodeJSON(w, http.StatusOK, newRoleTemplate(role, s.roleTemplatePattern(), time.Now()), s.Logger)
+}
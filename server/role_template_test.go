@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_newRoleTemplate(t *testing.T) {
+	now := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+	role := &chronograf.Role{
+		Name:  "metrics",
+		Users: []chronograf.User{{Name: "marty"}},
+		Permissions: chronograf.Permissions{
+			{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ViewChronograf"}},
+			{Scope: chronograf.DBScope, Name: "telegraf_prod", Allowed: chronograf.Allowances{"ReadData"}},
+			{Scope: chronograf.DBScope, Name: "telegraf_prod", Allowed: chronograf.Allowances{"WriteData"}, ExpiresAt: &later},
+			{Scope: chronograf.DBScope, Name: "events", Allowed: chronograf.Allowances{"ReadData"}},
+			{Scope: chronograf.DBScope, Name: "old", Allowed: chronograf.Allowances{"ReadData"}, ExpiresAt: &expired},
+		},
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		want    roleTemplate
+	}{
+		{
+			name:    "Whole names",
+			pattern: DefaultRoleTemplatePattern,
+			want: roleTemplate{
+				Name: "metrics",
+				Permissions: chronograf.Permissions{
+					{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ViewChronograf"}},
+					{Scope: chronograf.DBScope, Name: ":database:", Allowed: chronograf.Allowances{"ReadData"}},
+					{Scope: chronograf.DBScope, Name: ":database:", Allowed: chronograf.Allowances{"WriteData"}},
+					{Scope: chronograf.DBScope, Name: ":database2:", Allowed: chronograf.Allowances{"ReadData"}},
+				},
+				Parameters: []roleTemplateParameter{
+					{Placeholder: ":database:", Value: "telegraf_prod"},
+					{Placeholder: ":database2:", Value: "events"},
+				},
+			},
+		},
+		{
+			name:    "Named group",
+			pattern: `^telegraf_(?P<env>.+)$`,
+			want: roleTemplate{
+				Name: "metrics",
+				Permissions: chronograf.Permissions{
+					{Scope: chronograf.AllScope, Allowed: chronograf.Allowances{"ViewChronograf"}},
+					{Scope: chronograf.DBScope, Name: "telegraf_:env:", Allowed: chronograf.Allowances{"ReadData"}},
+					{Scope: chronograf.DBScope, Name: "telegraf_:env:", Allowed: chronograf.Allowances{"WriteData"}},
+					{Scope: chronograf.DBScope, Name: "events", Allowed: chronograf.Allowances{"ReadData"}},
+				},
+				Parameters: []roleTemplateParameter{
+					{Placeholder: ":env:", Value: "prod"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := NewRoleTemplatePattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := newRoleTemplate(role, pattern, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newRoleTemplate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if role.Permissions[1].Name != "telegraf_prod" {
+		t.Errorf("newRoleTemplate() changed the role's permissions")
+	}
+	if _, err := NewRoleTemplatePattern(`^(a)_(b)$`); err == nil {
+		t.Errorf("NewRoleTemplatePattern() of two groups expected error")
+	}
+}
+
+func TestService_ExportSourceRoleTemplate(t *testing.T) {
+	h := &Service{
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					return chronograf.Source{
+						ID: 1,
+					}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+						return &chronograf.Role{
+							Name: name,
+							Permissions: chronograf.Permissions{
+								{Scope: chronograf.DBScope, Name: "db_prod", Allowed: chronograf.Allowances{"ReadData"}},
+							},
+						}, nil
+					},
+				}, nil
+			},
+		},
+		RoleTemplatePattern: regexp.MustCompile(`_(?P<env>[a-z]+)$`),
+		Logger:              log.New(log.DebugLevel),
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/biffsgang/template", nil)
+	r = r.WithContext(httprouter.WithParams(
+		context.Background(),
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: "1",
+			},
+			{
+				Key:   "rid",
+				Value: "biffsgang",
+			},
+		}))
+	h.ExportSourceRoleTemplate(w, r)
+
+	want := `{"name":"biffsgang","permissions":[{"scope":"database","name":"db_:env:","allowed":["ReadData"]}],"parameters":[{"placeholder":":env:","value":"prod"}]}
+`
+	if body, _ := ioutil.ReadAll(w.Result().Body); string(body) != want {
+		t.Errorf("ExportSourceRoleTemplate() = %s, want %s", body, want)
+	}
+}
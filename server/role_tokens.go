@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	uuid "github.com/influxdata/chronograf/id"
+	"github.com/influxdata/chronograf/oauth2"
+)
+
+// roleTokenIssuer is the issuer of role tokens.  It differs from the issuers
+// of user tokens so role tokens are never mistaken for a login.
+const roleTokenIssuer = "chronograf-role-token"
+
+// RoleTokens issues signed API tokens that reference a role of a source.
+// The permissions of a token are those of the role when the token is used.
+// Tokens are only valid while remembered in memory, so all role tokens
+// are revoked when the server restarts.
+type RoleTokens struct {
+	Tokenizer oauth2.Tokenizer
+	TTL       time.Duration
+	Now       func() time.Time
+
+	mu     sync.Mutex
+	issued map[string]roleToken
+}
+
+// NewRoleTokens creates role tokens lasting ttl.  Tokens are signed with a
+// key derived from secret so they cannot be used as user tokens.
+func NewRoleTokens(secret string, ttl time.Duration) *RoleTokens {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(roleTokenIssuer))
+	return &RoleTokens{
+		Tokenizer: oauth2.NewJWT(hex.EncodeToString(mac.Sum(nil)), ""),
+		TTL:       ttl,
+		Now:       time.Now,
+		issued:    map[string]roleToken{},
+	}
+}
+
+// roleToken describes an issued role token
+type roleToken struct {
+	ID        string    `json:"id"`
+	Source    int       `json:"source"`
+	Role      string    `json:"role"`
+	IssuedBy  string    `json:"issuedBy,omitempty"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Issue signs a token for the role of a source
+func (t *RoleTokens) Issue(ctx context.Context, srcID int, role, issuedBy string) (oauth2.Token, roleToken, error) {
+	id, err := (&uuid.UUID{}).Generate()
+	if err != nil {
+		return "", roleToken{}, err
+	}
+
+	now := t.Now()
+	rt := roleToken{
+		ID:        id,
+		Source:    srcID,
+		Role:      role,
+		IssuedBy:  issuedBy,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(t.TTL),
+	}
+	token, err := t.Tokenizer.Create(ctx, oauth2.Principal{
+		Subject:      fmt.Sprintf("%d/%s", srcID, role),
+		Issuer:       roleTokenIssuer,
+		Organization: id,
+		IssuedAt:     rt.IssuedAt,
+		ExpiresAt:    rt.ExpiresAt,
+	})
+	if err != nil {
+		return "", roleToken{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.issued[id] = rt
+	return token, rt, nil
+}
+
+// Validate returns the role token if token is signed, unexpired and not revoked
+func (t *RoleTokens) Validate(ctx context.Context, token oauth2.Token) (roleToken, error) {
+	p, err := t.Tokenizer.ValidPrincipal(ctx, token, t.TTL)
+	if err != nil {
+		return roleToken{}, err
+	}
+	if p.Issuer != roleTokenIssuer {
+		return roleToken{}, fmt.Errorf("not a role token")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rt, ok := t.issued[p.Organization]
+	if !ok || fmt.Sprintf("%d/%s", rt.Source, rt.Role) != p.Subject {
+		return roleToken{}, fmt.Errorf("role token has been revoked")
+	}
+	return rt, nil
+}
+
+// Revoke invalidates the token with id of the role of a source
+func (t *RoleTokens) Revoke(srcID int, role, id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rt, ok := t.issued[id]
+	if !ok || rt.Source != srcID || rt.Role != role {
+		return false
+	}
+	delete(t.issued, id)
+	return true
+}
+
+// roleTokens returns the role tokens or writes an error if they are disabled
+func (s *Service) roleTokens(w http.ResponseWriter) (*RoleTokens, bool) {
+	if s.RoleTokens == nil {
+		Error(w, http.StatusNotFound, "Role tokens require a token secret", s.Logger)
+		return nil, false
+	}
+	return s.RoleTokens, true
+}
+
+// NewSourceRoleToken issues an API token carrying the permissions of a role
+func (s *Service) NewSourceRoleToken(w http.ResponseWriter, r *http.Request) {
+	tokens, ok := s.roleTokens(w)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	role, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	issuedBy := ""
+	if u, ok := hasUserContext(ctx); ok {
+		issuedBy = u.Name
+	}
+	token, rt, err := tokens.Issue(ctx, srcID, role.Name, issuedBy)
+	if err != nil {
+		unknownErrorWithMessage(w, err, s.Logger)
+		return
+	}
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("role", role.Name).
+		WithField("token", rt.ID).
+		WithField("issuedBy", issuedBy).
+		Info("Issued role token")
+
+	res := struct {
+		roleToken
+		Token string    `json:"token"`
+		Links selfLinks `json:"links"`
+	}{
+		roleToken: rt,
+		Token:     string(token),
+		Links:     selfLinks{Self: roleTokenLink(srcID, role.Name, rt.ID)},
+	}
+	location(w, res.Links.Self)
+	encodeJSON(w, http.StatusCreated, res, s.Logger)
+}
+
+// RemoveSourceRoleToken revokes a role token
+func (s *Service) RemoveSourceRoleToken(w http.ResponseWriter, r *http.Request) {
+	tokens, ok := s.roleTokens(w)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	tid := httprouter.GetParamFromContext(ctx, "tid")
+	if !tokens.Revoke(srcID, rid, tid) {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Role %s has no token %s", rid, tid), s.Logger)
+		return
+	}
+
+	revokedBy := ""
+	if u, ok := hasUserContext(ctx); ok {
+		revokedBy = u.Name
+	}
+	s.Logger.
+		WithField("component", "roles").
+		WithField("source", srcID).
+		WithField("role", rid).
+		WithField("token", tid).
+		WithField("revokedBy", revokedBy).
+		Info("Revoked role token")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IntrospectRoleToken validates a role token of the request body and
+// returns the current permissions of its role
+func (s *Service) IntrospectRoleToken(w http.ResponseWriter, r *http.Request) {
+	tokens, ok := s.roleTokens(w)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	rt, err := tokens.Validate(ctx, oauth2.Token(strings.TrimSpace(req.Token)))
+	if err != nil {
+		Error(w, http.StatusUnauthorized, fmt.Sprintf("Invalid role token: %v", err), s.Logger)
+		return
+	}
+
+	// The source and role of the token are used in place of path parameters
+	ctx = httprouter.WithParams(ctx, httprouter.Params{
+		{Key: "id", Value: strconv.Itoa(rt.Source)},
+	})
+	r = r.WithContext(ctx)
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+	role, err := roles.Get(ctx, rt.Role)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+
+	res := struct {
+		roleToken
+		Permissions chronograf.Permissions `json:"permissions"`
+	}{
+		roleToken:   rt,
+		Permissions: newSourceRoleResponse(srcID, role).Permissions,
+	}
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
+
+func roleTokenLink(srcID int, role, id string) string {
+	return fmt.Sprintf("/chronograf/v1/sources/%d/roles/%s/tokens/%s", srcID, role, id)
+}
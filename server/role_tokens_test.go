@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+	"github.com/influxdata/chronograf/oauth2"
+)
+
+func TestService_RoleTokens(t *testing.T) {
+	permissions := chronograf.Permissions{
+		{
+			Scope:   chronograf.DBScope,
+			Name:    "pics",
+			Allowed: chronograf.Allowances{"READ"},
+		},
+	}
+	h := &Service{
+		Store: &mocks.Store{
+			SourcesStore: &mocks.SourcesStore{
+				GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+					return chronograf.Source{
+						ID: 1,
+					}, nil
+				},
+			},
+		},
+		TimeSeriesClient: &mocks.TimeSeries{
+			ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+				return nil
+			},
+			RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+				return &mocks.RolesStore{
+					GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+						if name != "biffsgang" {
+							return nil, fmt.Errorf("role %s not found", name)
+						}
+						return &chronograf.Role{
+							Name:        name,
+							Permissions: permissions,
+						}, nil
+					},
+				}, nil
+			},
+		},
+		Logger:     log.New(log.DebugLevel),
+		RoleTokens: NewRoleTokens("secret", time.Hour),
+	}
+	params := func(r *http.Request, tid string) *http.Request {
+		return r.WithContext(httprouter.WithParams(
+			context.Background(),
+			httprouter.Params{
+				{Key: "id", Value: "1"},
+				{Key: "rid", Value: "biffsgang"},
+				{Key: "tid", Value: tid},
+			}))
+	}
+	introspect := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/role-tokens/introspect", strings.NewReader(`{"token":"`+token+`"}`))
+		h.IntrospectRoleToken(w, r)
+		return w
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles/biffsgang/tokens", nil)
+	h.NewSourceRoleToken(w, params(r, ""))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("NewSourceRoleToken() status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var issued struct {
+		ID    string `json:"id"`
+		Role  string `json:"role"`
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&issued); err != nil {
+		t.Fatal(err)
+	}
+	if issued.Role != "biffsgang" || issued.Token == "" {
+		t.Fatalf("NewSourceRoleToken() issued %+v", issued)
+	}
+	if got, want := w.Header().Get("Location"), "/chronograf/v1/sources/1/roles/biffsgang/tokens/"+issued.ID; got != want {
+		t.Errorf("NewSourceRoleToken() Location = %s, want %s", got, want)
+	}
+
+	w = introspect(issued.Token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("IntrospectRoleToken() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var got struct {
+		Source      int                    `json:"source"`
+		Permissions chronograf.Permissions `json:"permissions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Source != 1 || len(got.Permissions) != 1 || got.Permissions[0].Name != "pics" {
+		t.Errorf("IntrospectRoleToken() = %+v", got)
+	}
+
+	// Role tokens must not be accepted as user tokens
+	if _, err := oauth2.NewJWT("secret", "").ValidPrincipal(context.Background(), oauth2.Token(issued.Token), time.Hour); err == nil {
+		t.Errorf("role token was valid as a user token")
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", "http://server.local/chronograf/v1/sources/1/roles/biffsgang/tokens/"+issued.ID, nil)
+	h.RemoveSourceRoleToken(w, params(r, issued.ID))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("RemoveSourceRoleToken() status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	w = introspect(issued.Token)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("IntrospectRoleToken() of revoked token status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", "http://server.local/chronograf/v1/sources/1/roles/biffsgang/tokens/"+issued.ID, nil)
+	h.RemoveSourceRoleToken(w, params(r, issued.ID))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("RemoveSourceRoleToken() of revoked token status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
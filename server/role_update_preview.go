@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+)
+
+type roleUpdatePreviewResponse struct {
+	Role        string                    `json:"role"`
+	Changed     bool                      `json:"changed"` // Changed is false if the update would leave the role as it is
+	Before      sourceRoleResponse        `json:"before"`
+	After       sourceRoleResponse        `json:"after"`
+	Users       roleUsersDiff             `json:"users"`
+	Permissions roleUpdatePermissionsDiff `json:"permissions"`
+}
+
+type roleUsersDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// roleUpdatePermissionsDiff lists the permissions an update adds and
+// removes.  A permission whose allowances change is both removed and added.
+type roleUpdatePermissionsDiff struct {
+	Added   chronograf.Permissions `json:"added"`
+	Removed chronograf.Permissions `json:"removed"`
+}
+
+// updatedRole is the role as it would be after an update.  Users and
+// permissions missing from the update are left as they are.
+func updatedRole(current *chronograf.Role, update chronograf.Role) chronograf.Role {
+	if update.Permissions == nil {
+		update.Permissions = current.Permissions
+	}
+	if update.Users == nil {
+		update.Users = current.Users
+	}
+	return update
+}
+
+func diffRoleUsers(before, after *chronograf.Role) roleUsersDiff {
+	diff := roleUsersDiff{
+		Added:   []string{},
+		Removed: []string{},
+	}
+	for _, u := range after.Users {
+		if !hasRoleUser(before, u.Name) {
+			diff.Added = append(diff.Added, u.Name)
+		}
+	}
+	for _, u := range before.Users {
+		if !hasRoleUser(after, u.Name) {
+			diff.Removed = append(diff.Removed, u.Name)
+		}
+	}
+	return diff
+}
+
+func diffRolePermissions(before, after chronograf.Permissions) roleUpdatePermissionsDiff {
+	diff := roleUpdatePermissionsDiff{
+		Added:   chronograf.Permissions{},
+		Removed: chronograf.Permissions{},
+	}
+	had := map[string]bool{}
+	for _, perm := range before {
+		had[permissionString(perm)] = true
+	}
+	has := map[string]bool{}
+	for _, perm := range after {
+		has[permissionString(perm)] = true
+		if !had[permissionString(perm)] {
+			diff.Added = append(diff.Added, perm)
+		}
+	}
+	for _, perm := range before {
+		if !has[permissionString(perm)] {
+			diff.Removed = append(diff.Removed, perm)
+		}
+	}
+	return diff
+}
+
+// PreviewSourceRoleUpdate reports how a role would change if the body was
+// sent to UpdateSourceRole.  The body is validated as the update would
+// validate it.  Nothing is changed.
+func (s *Service) PreviewSourceRoleUpdate(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeRoleUpdate(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	roles, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	rid := httprouter.GetParamFromContext(ctx, "rid")
+	if s.isProtectedRole(rid) {
+		protectedRole(w, rid, s.Logger)
+		return
+	}
+	req.Name = rid
+
+	current, err := roles.Get(ctx, rid)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	after := updatedRole(current, req.Role)
+
+	res := roleUpdatePreviewResponse{
+		Role:        current.Name,
+		Before:      newSourceRoleResponse(srcID, current),
+		After:       newSourceRoleResponse(srcID, &after),
+		Users:       diffRoleUsers(current, &after),
+		Permissions: diffRolePermissions(current.Permissions, after.Permissions),
+	}
+	res.Changed = len(res.Users.Added) > 0 || len(res.Users.Removed) > 0 ||
+		len(res.Permissions.Added) > 0 || len(res.Permissions.Removed) > 0
+	encodeJSON(w, http.StatusOK, res, s.Logger)
+}
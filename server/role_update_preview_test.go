@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_PreviewSourceRoleUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		rid        string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Users and permissions change",
+			rid:        "timetravelers",
+			body:       `{"users": [{"name": "marty"}, {"name": "jennifer"}], "permissions": [{"scope": "database", "name": "delorean", "allowed": ["ReadData"]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"role":"timetravelers","changed":true,"before":{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}},"after":{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/jennifer"},"name":"jennifer"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}},"users":{"added":["jennifer"],"removed":["doc"]},"permissions":{"added":[{"scope":"database","name":"delorean","allowed":["ReadData"]}],"removed":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}]}}
+`,
+		},
+		{
+			name:       "Missing users and permissions are unchanged",
+			rid:        "timetravelers",
+			body:       `{"permissions": [{"scope": "database", "name": "delorean", "allowed": ["WriteData", "ReadData"]}]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"role":"timetravelers","changed":false,"before":{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["ReadData","WriteData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}},"after":{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["WriteData","ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}},"users":{"added":[],"removed":[]},"permissions":{"added":[],"removed":[]}}
+`,
+		},
+		{
+			name:       "Invalid update",
+			rid:        "timetravelers",
+			body:       `{"users": [{"name": ""}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Username required","errors":[{"field":"users[0].name","message":"Username required"}]}
+`,
+		},
+		{
+			name:       "Protected role",
+			rid:        "_admin",
+			body:       `{"users": []}`,
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"code":403,"message":"Role _admin is a protected system role"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{
+									Name: name,
+									Permissions: chronograf.Permissions{
+										{
+											Scope:   chronograf.DBScope,
+											Name:    "delorean",
+											Allowed: chronograf.Allowances{"ReadData", "WriteData"},
+										},
+									},
+									Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+								}, nil
+							},
+							UpdateF: func(ctx context.Context, role *chronograf.Role) error {
+								t.Errorf("%q. PreviewSourceRoleUpdate() updated role %s", tt.name, role.Name)
+								return nil
+							},
+						}, nil
+					},
+				},
+				ProtectedRoles: []string{"_*"},
+				Logger:         log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles/"+tt.rid+"/preview-update", bytes.NewBufferString(tt.body))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+					{
+						Key:   "rid",
+						Value: tt.rid,
+					},
+				}))
+
+			h.PreviewSourceRoleUpdate(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. PreviewSourceRoleUpdate() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. PreviewSourceRoleUpdate() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
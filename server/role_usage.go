@@ -0,0 +1,85 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// RoleUsage tracks the queries each source user runs through the query
+// proxy.  The usage of a role is the usage of its users.  Usage is kept in
+// memory and starts over when the server restarts.
+type RoleUsage struct {
+	mu    sync.Mutex
+	users map[roleUsageKey]*userUsage
+}
+
+// NewRoleUsage creates an empty usage tracker
+func NewRoleUsage() *RoleUsage {
+	return &RoleUsage{
+		users: map[roleUsageKey]*userUsage{},
+	}
+}
+
+type roleUsageKey struct {
+	source int
+	user   string
+}
+
+type userUsage struct {
+	lastUsed time.Time
+	count    int
+}
+
+// Record notes a query of a source run as user at time at
+func (u *RoleUsage) Record(srcID int, user string, at time.Time) {
+	if user == "" {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := roleUsageKey{srcID, user}
+	usage, ok := u.users[key]
+	if !ok {
+		usage = &userUsage{}
+		u.users[key] = usage
+	}
+	usage.count++
+	if at.After(usage.lastUsed) {
+		usage.lastUsed = at
+	}
+}
+
+// usage returns the latest query time and the number of queries of the
+// users of a source.  lastUsed is nil if none of the users have queried.
+func (u *RoleUsage) usage(srcID int, users []string) (lastUsed *time.Time, count int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, user := range users {
+		usage, ok := u.users[roleUsageKey{srcID, user}]
+		if !ok {
+			continue
+		}
+		count += usage.count
+		if lastUsed == nil || usage.lastUsed.After(*lastUsed) {
+			at := usage.lastUsed
+			lastUsed = &at
+		}
+	}
+	return lastUsed, count
+}
+
+// withRoleUsage adds the usage of the role's users to rr if usage is tracked
+func (s *Service) withRoleUsage(rr *sourceRoleResponse) {
+	if s.RoleUsage == nil {
+		return
+	}
+	users := make([]string, len(rr.Users))
+	for i := range rr.Users {
+		users[i] = rr.Users[i].Name
+	}
+	lastUsed, count := s.RoleUsage.usage(rr.srcID, users)
+	rr.LastUsed = lastUsed
+	rr.UsageCount = &count
+}
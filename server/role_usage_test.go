@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+)
+
+func TestService_withRoleUsage(t *testing.T) {
+	earlier := time.Date(1955, 11, 5, 6, 0, 0, 0, time.UTC)
+	later := time.Date(1985, 10, 26, 1, 21, 0, 0, time.UTC)
+
+	usage := NewRoleUsage()
+	usage.Record(1, "marty", earlier)
+	usage.Record(1, "doc", later)
+	usage.Record(1, "doc", earlier)
+	usage.Record(2, "biff", later)
+
+	s := &Service{
+		Logger:    log.New(log.DebugLevel),
+		RoleUsage: usage,
+	}
+	tests := []struct {
+		name string
+		role chronograf.Role
+		want string
+	}{
+		{
+			name: "Usage of the role's users",
+			role: chronograf.Role{
+				Name:  "timetravelers",
+				Users: []chronograf.User{{Name: "marty"}, {Name: "doc"}},
+			},
+			want: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"},"lastUsed":"1985-10-26T01:21:00Z","usageCount":3}
+`,
+		},
+		{
+			name: "Unused role",
+			role: chronograf.Role{
+				Name:  "biffsgang",
+				Users: []chronograf.User{{Name: "biff"}},
+			},
+			want: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/biff"},"name":"biff"}],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"},"usageCount":0}
+`,
+		},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles/"+tt.role.Name, nil)
+		s.encodeSourceRole(w, r, 200, newSourceRoleResponse(1, &tt.role))
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("%q. encodeSourceRole() = \n***%v***\n,\nwant\n***%v***", tt.name, got, tt.want)
+		}
+	}
+}
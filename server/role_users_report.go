@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// CSVType is the mimetype of CSV responses
+const CSVType = "text/csv"
+
+// wantsCSV checks if the client accepts CSV responses
+func wantsCSV(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(accept)
+		if err == nil && mediaType == CSVType {
+			return true
+		}
+	}
+	return false
+}
+
+// roleUserRow is a user of a role of a source
+type roleUserRow struct {
+	User   string `json:"user"`
+	Role   string `json:"role"`
+	Source int    `json:"source"`
+}
+
+type roleUsersReport struct {
+	Rows  []roleUserRow `json:"rows"`
+	Links selfLinks     `json:"links"`
+}
+
+// roleUserRows flattens roles into a row per user of each role, ordered by
+// user then role.  Users whose membership has expired by now are left out.
+func roleUserRows(srcID int, roles []chronograf.Role, now time.Time) []roleUserRow {
+	rows := []roleUserRow{}
+	for _, role := range roles {
+		for _, u := range role.Users {
+			if u.MembershipExpired(now) {
+				continue
+			}
+			rows = append(rows, roleUserRow{
+				User:   u.Name,
+				Role:   role.Name,
+				Source: srcID,
+			})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].User != rows[j].User {
+			return rows[i].User < rows[j].User
+		}
+		return rows[i].Role < rows[j].Role
+	})
+	return rows
+}
+
+// SourceRoleUsers reports every user of every role of a source as flat
+// (user, role, source) rows, e.g. for access certification.  Users of many
+// roles have a row per role.  Clients accepting text/csv get the rows as
+// CSV with a header row.
+func (s *Service) SourceRoleUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	srcID, ts, err := s.sourcesSeries(ctx, w, r)
+	if err != nil {
+		return
+	}
+	store, ok := s.hasRoles(ctx, srcID, ts)
+	if !ok {
+		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
+		return
+	}
+
+	roles, err := store.All(ctx)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+		return
+	}
+	rows := roleUserRows(srcID, roles, time.Now())
+
+	if !wantsCSV(r) {
+		res := roleUsersReport{
+			Rows:  rows,
+			Links: selfLinks{Self: fmt.Sprintf("/chronograf/v1/sources/%d/roles-users", srcID)},
+		}
+		encodeJSON(w, http.StatusOK, res, s.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", CSVType)
+	w.WriteHeader(http.StatusOK)
+	out := csv.NewWriter(w)
+	out.Write([]string{"user", "role", "source"})
+	for _, row := range rows {
+		out.Write([]string{row.User, row.Role, strconv.Itoa(row.Source)})
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		s.Logger.Error("Unable to write role users: ", err)
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bouk/httprouter"
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func TestService_SourceRoleUsers(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "JSON rows",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"rows":[{"user":"docbrown","role":"hillvalley","source":1},{"user":"docbrown","role":"timetravelers","source":1},{"user":"marty","role":"timetravelers","source":1}],"links":{"self":"/chronograf/v1/sources/1/roles-users"}}
+`,
+		},
+		{
+			name:            "CSV rows",
+			accept:          "text/csv, application/json;q=0.5",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody: `user,role,source
+docbrown,hillvalley,1
+docbrown,timetravelers,1
+marty,timetravelers,1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: ID,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "timetravelers",
+										Users: []chronograf.User{
+											{Name: "marty"},
+											{Name: "docbrown"},
+										},
+									},
+									{
+										Name: "hillvalley",
+										Users: []chronograf.User{
+											{Name: "docbrown"},
+											{Name: "biff", ExpiresAt: &expired},
+										},
+									},
+									{Name: "empty"},
+								}, nil
+							},
+						}, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://server.local/chronograf/v1/sources/1/roles-users", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+
+			h.SourceRoleUsers(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%q. SourceRoleUsers() = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
+			}
+			if content := resp.Header.Get("Content-Type"); content != tt.wantContentType {
+				t.Errorf("%q. SourceRoleUsers() Content-Type = %v, want %v", tt.name, content, tt.wantContentType)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%q. SourceRoleUsers() = \n***%v***\n,\nwant\n***%v***", tt.name, string(body), tt.wantBody)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/canned"
 	idgen "github.com/influxdata/chronograf/id"
 	"github.com/influxdata/chronograf/influx"
 	"github.com/influxdata/chronograf/kv"
@@ -120,6 +121,38 @@ type Server struct {
 	StatusFeedURL          string            `long:"status-feed-url" description:"URL of a JSON Feed to display as a News Feed on the client Status page." default:"https://influxdata.com/feed/json" env:"STATUS_FEED_URL"`
 	CustomLinks            map[string]string `long:"custom-link" description:"Custom link to be added to the client User menu. Multiple links can be added by using multiple of the same flag with different 'name:url' values, or as an environment variable with comma-separated 'name:url' values. E.g. via flags: '--custom-link=InfluxData:https://www.influxdata.com --custom-link=Chronograf:https://github.com/influxdata/chronograf'. E.g. via environment variable: 'export CUSTOM_LINKS=InfluxData:https://www.influxdata.com,Chronograf:https://github.com/influxdata/chronograf'" env:"CUSTOM_LINKS" env-delim:","`
 	TelegrafSystemInterval time.Duration     `long:"telegraf-system-interval" default:"1m" description:"Duration used in the GROUP BY time interval for the hosts list" env:"TELEGRAF_SYSTEM_INTERVAL"`
+	ProtectedRoles         []string          `long:"protected-role" description:"Role name pattern of a built-in system role that is hidden from role listings and cannot be edited or removed. Multiple patterns can be added by using multiple of the same flag, or as an environment variable with comma-separated values." env:"PROTECTED_ROLES" env-delim:","`
+	PermissionSweep        time.Duration     `long:"permission-sweep-interval" default:"1m" description:"Interval at which expired role permissions and temporary grants are revoked from sources. Only sources with expiring permissions or memberships are connected to. Set to 0 to disable" env:"PERMISSION_SWEEP_INTERVAL"`
+	MembershipNotice       time.Duration     `long:"role-membership-notice" description:"Duration before a source role membership expires at which the expiry is logged and published as a role event. Memberships expire at the expiresAt of the role's user and are revoked by the permission sweep. Set to 0 to disable" env:"ROLE_MEMBERSHIP_NOTICE"`
+	RoleApproval           bool              `long:"role-approval" description:"Require an admin to approve source role creations and updates before they are applied to the source" env:"ROLE_APPROVAL"`
+	IdempotencyKeyTTL      time.Duration     `long:"idempotency-key-ttl" default:"24h" description:"Duration for which responses of source role creations and updates are replayed to retries with the same Idempotency-Key header. Set to 0 to disable" env:"IDEMPOTENCY_KEY_TTL"`
+	PermissionCacheTTL     time.Duration     `long:"effective-permissions-ttl" default:"30s" description:"Duration for which the effective permissions of a user of a source are cached. Changes to roles through Chronograf take effect immediately; changes made elsewhere once the cache expires. Set to 0 to disable" env:"EFFECTIVE_PERMISSIONS_TTL"`
+	PermissionConflicts    string            `long:"permission-conflict-strategy" value-name:"choice" choice:"most-permissive" choice:"least-permissive" default:"most-permissive" description:"Strategy resolving conflicting allowances when permissions of the same scope are merged" env:"PERMISSION_CONFLICT_STRATEGY"`
+	RoleUsageTracking      bool              `long:"role-usage-tracking" description:"Track the queries run through the query proxy to report when source roles were last used" env:"ROLE_USAGE_TRACKING"`
+	DataClassifications    []string          `long:"database-classification" description:"Data classification label of a database as 'label:database'. Source role permissions referencing a label are granted on each database with the label. Multiple databases can be classified by using multiple of the same flag, or as an environment variable with comma-separated values. E.g. '--database-classification=restricted:payroll --database-classification=public:telegraf'" env:"DATABASE_CLASSIFICATIONS" env-delim:","`
+	RoleNamesIgnoreCase    bool              `long:"role-names-ignore-case" description:"Consider source role names differing only by case the same role when creating roles, for backends with case-insensitive role names. Names are stored as given" env:"ROLE_NAMES_IGNORE_CASE"`
+	DuplicateRoleUsers     string            `long:"role-duplicate-users" value-name:"choice" choice:"dedupe" choice:"reject" default:"dedupe" description:"Handling of users listed more than once in a source role creation or update" env:"ROLE_DUPLICATE_USERS"`
+	RoleUsersIgnoreCase    bool              `long:"role-users-ignore-case" description:"Consider source role user names differing only by case duplicates" env:"ROLE_USERS_IGNORE_CASE"`
+	RoleSoftLimit          int               `long:"role-soft-limit" description:"Number of roles of a source at which creating a source role returns a warning. Set to 0 to disable" env:"ROLE_SOFT_LIMIT"`
+	RoleHardLimit          int               `long:"role-hard-limit" description:"Most roles a source may have; creating more source roles is forbidden. Set to 0 to disable" env:"ROLE_HARD_LIMIT"`
+	RoleMaxUsers           int               `long:"role-max-users" description:"Most users a source role may have; creating or updating a role with more users is rejected. Set to 0 to disable" env:"ROLE_MAX_USERS"`
+	PermissionPresets      string            `long:"permission-presets" description:"Path to a JSON file of named permission presets source role requests may reference, mapping each preset name to its permissions" env:"PERMISSION_PRESETS"`
+	RoleRiskWeights        []string          `long:"role-risk-weight" description:"Weight of a factor of source role risk scores as 'factor:weight'. Factors are allDatabases, databases, write, delete, admin and users. Multiple weights can be set by using multiple of the same flag, or as an environment variable with comma-separated values. E.g. '--role-risk-weight=admin:20 --role-risk-weight=users:0'" env:"ROLE_RISK_WEIGHTS" env-delim:","`
+	SeparationOfDuties     string            `long:"separation-of-duties" description:"Path to a JSON file of separation of duties rules. Each rule names duties by their allowances; source roles granting more than one duty of a rule are rejected" env:"SEPARATION_OF_DUTIES"`
+	StrictPermissions      bool              `long:"strict-permissions" description:"Reject source roles whose permissions contradict each other, e.g. granting and denying the same allowance of a database, rather than warning of them" env:"STRICT_PERMISSIONS"`
+	RoleLintMaxUsers       int               `long:"role-lint-max-users" default:"100" description:"Most users a source role should have before the role linter warns. Set to 0 to disable the rule" env:"ROLE_LINT_MAX_USERS"`
+	RoleLintNamePattern    string            `long:"role-lint-name-pattern" description:"Regular expression source role names are expected to match by the role linter" env:"ROLE_LINT_NAME_PATTERN"`
+	RoleLintDisabled       []string          `long:"role-lint-disable" description:"Name of a role linter rule not to run: all-databases, empty-permissions, max-users or name-convention. Multiple rules can be disabled by using multiple of the same flag, or as an environment variable with comma-separated values" env:"ROLE_LINT_DISABLE" env-delim:","`
+	RoleTemplatePattern    string            `long:"role-template-pattern" default:"^.+$" description:"Regular expression matching the database names replaced with placeholders when exporting source roles as templates. With one group only the group is replaced; a named group names the placeholder, e.g. '^telegraf_(?P<env>.+)$'" env:"ROLE_TEMPLATE_PATTERN"`
+	RoleTokenTTL           time.Duration     `long:"role-token-ttl" default:"720h" description:"Duration for which API tokens scoped to a source role are valid. Role tokens require a token secret and are revoked when the server restarts" env:"ROLE_TOKEN_TTL"`
+	ForbiddenScopes        []string          `long:"forbidden-scope" description:"Database name pattern that may never be granted to source roles, e.g. _internal. Multiple patterns can be added by using multiple of the same flag, or as an environment variable with comma-separated values." env:"FORBIDDEN_SCOPES" env-delim:","`
+	ScopeNamePattern       string            `long:"scope-name-pattern" description:"Regular expression the database names of the permissions of source roles must match, e.g. '^(sre|web)_' to require team prefixes. Permissions of other databases are rejected" env:"SCOPE_NAME_PATTERN"`
+	LayoutAccessTracking   bool              `long:"layout-access-tracking" description:"Count the accesses of each layout to find rarely used layouts" env:"LAYOUT_ACCESS_TRACKING"`
+	LayoutGetTimeout       time.Duration     `long:"layout-get-timeout" default:"10s" description:"Duration after which getting a layout from the layout stores fails with 504 Gateway Timeout. Set to 0 to disable" env:"LAYOUT_GET_TIMEOUT"`
+	LayoutAllTimeout       time.Duration     `long:"layout-all-timeout" default:"30s" description:"Duration after which listing the layouts of the layout stores fails with 504 Gateway Timeout. Set to 0 to disable" env:"LAYOUT_ALL_TIMEOUT"`
+	DefaultLayout          string            `long:"default-layout" description:"ID of the layout served in place of a requested layout that does not exist, rather than responding 404, e.g. for kiosk displays" env:"DEFAULT_LAYOUT"`
+	ScopeAliases           []string          `long:"scope-alias" description:"Friendly name of a database as 'alias:database'. Source role permissions may reference the alias instead of the database. Multiple aliases can be added by using multiple of the same flag, or as an environment variable with comma-separated values. E.g. '--scope-alias=prod-metrics:telegraf_prod'" env:"SCOPE_ALIASES" env-delim:","`
+	RoleFieldNaming        string            `long:"role-field-naming" value-name:"choice" choice:"camelCase" choice:"snake_case" default:"camelCase" description:"Default field naming of source role responses. Clients may request a naming with the Accept header profile parameter" env:"ROLE_FIELD_NAMING"`
 
 	HostPageDisabled  bool   `short:"H" long:"host-page-disabled" description:"Disable the host list page" env:"HOST_PAGE_DISABLED"`
 	ReportingDisabled bool   `short:"r" long:"reporting-disabled" description:"Disable reporting of usage stats (os,arch,version,cluster_id,uptime) once every 24hr" env:"REPORTING_DISABLED"`
@@ -128,6 +161,9 @@ type Server struct {
 	ShowVersion       bool   `short:"v" long:"version" description:"Show Chronograf version info"`
 	BuildInfo         chronograf.BuildInfo
 
+	// PermissionValidators enforce custom policies on the permissions of source roles
+	PermissionValidators []PermissionValidator
+
 	oauthClient http.Client
 }
 
@@ -512,6 +548,8 @@ func (s *Server) newBuilders(logger chronograf.Logger) builders {
 			Logger:     logger,
 			UUID:       &idgen.UUID{},
 			CannedPath: s.CannedPath,
+			GetTimeout: s.LayoutGetTimeout,
+			AllTimeout: s.LayoutAllTimeout,
 		},
 		Dashboards: &MultiDashboardBuilder{
 			Logger: logger,
@@ -622,7 +660,88 @@ func (s *Server) Serve(ctx context.Context) {
 		}
 	}
 
-	service := openService(ctx, db, s.newBuilders(logger), logger, s.useAuth())
+	classifications, err := NewClassifications(s.DataClassifications)
+	if err != nil {
+		logger.
+			WithField("component", "server").
+			WithField("DatabaseClassification", "invalid").
+			Error(err)
+		return
+	}
+
+	scopeAliases, err := NewScopeAliases(s.ScopeAliases)
+	if err != nil {
+		logger.
+			WithField("component", "server").
+			WithField("ScopeAlias", "invalid").
+			Error(err)
+		return
+	}
+
+	var presets map[string]chronograf.Permissions
+	if s.PermissionPresets != "" {
+		presets, err = NewPermissionPresets(s.PermissionPresets)
+		if err != nil {
+			logger.
+				WithField("component", "server").
+				WithField("PermissionPresets", "invalid").
+				Error(err)
+			return
+		}
+	}
+
+	var duties SeparationOfDuties
+	if s.SeparationOfDuties != "" {
+		duties, err = NewSeparationOfDuties(s.SeparationOfDuties)
+		if err != nil {
+			logger.
+				WithField("component", "server").
+				WithField("SeparationOfDuties", "invalid").
+				Error(err)
+			return
+		}
+	}
+
+	riskWeights, err := NewRoleRiskWeights(s.RoleRiskWeights)
+	if err != nil {
+		logger.
+			WithField("component", "server").
+			WithField("RoleRiskWeight", "invalid").
+			Error(err)
+		return
+	}
+
+	roleLint, err := NewRoleLintRules(s.RoleLintMaxUsers, s.RoleLintNamePattern, s.RoleLintDisabled)
+	if err != nil {
+		logger.
+			WithField("component", "server").
+			WithField("RoleLint", "invalid").
+			Error(err)
+		return
+	}
+
+	var scopeNamePattern *regexp.Regexp
+	if s.ScopeNamePattern != "" {
+		scopeNamePattern, err = NewScopeNamePattern(s.ScopeNamePattern)
+		if err != nil {
+			logger.
+				WithField("component", "server").
+				WithField("ScopeNamePattern", "invalid").
+				Error(err)
+			return
+		}
+	}
+
+	roleTemplatePattern, err := NewRoleTemplatePattern(s.RoleTemplatePattern)
+	if err != nil {
+		logger.
+			WithField("component", "server").
+			WithField("RoleTemplatePattern", "invalid").
+			Error(err)
+		return
+	}
+
+	service := openService(ctx, db, s.newBuilders(logger), logger, s.useAuth(), s.ProtectedRoles)
 	service.SuperAdminProviderGroups = superAdminProviderGroups{
 		auth0: s.Auth0SuperAdminOrg,
 	}
@@ -630,6 +749,57 @@ func (s *Server) Serve(ctx context.Context) {
 		TelegrafSystemInterval: s.TelegrafSystemInterval,
 		HostPageDisabled:       s.HostPageDisabled,
 	}
+	service.LayoutVersions = NewLayoutVersions(maxLayoutVersions)
+	service.DefaultLayout = s.DefaultLayout
+	if s.LayoutAccessTracking {
+		service.LayoutAccess = NewLayoutAccess(ctx, NewLayoutAccessCounts())
+	}
+	service.Classifications = classifications
+	service.ScopeAliases = scopeAliases
+	service.PermissionPresets = presets
+	service.RoleRiskWeights = riskWeights
+	service.RoleLint = roleLint
+	service.RoleTemplatePattern = roleTemplatePattern
+	service.RoleSoftLimit = s.RoleSoftLimit
+	service.RoleHardLimit = s.RoleHardLimit
+	service.RoleMaxUsers = s.RoleMaxUsers
+	service.StrictPermissions = s.StrictPermissions
+	service.SeparationOfDuties = duties
+	service.RoleNamesIgnoreCase = s.RoleNamesIgnoreCase
+	service.DuplicateRoleUsers = s.DuplicateRoleUsers
+	service.RoleUsersIgnoreCase = s.RoleUsersIgnoreCase
+	service.ForbiddenScopes = s.ForbiddenScopes
+	service.ScopeNamePattern = scopeNamePattern
+	service.PermissionValidators = s.PermissionValidators
+	service.RoleFieldNaming = s.RoleFieldNaming
+	if s.RoleApproval {
+		service.RoleApprovals = NewRoleApprovals()
+	}
+	service.PermissionConflicts = s.PermissionConflicts
+	logger.
+		WithField("component", "server").
+		WithField("strategy", service.permissionConflicts()).
+		Info("Permission conflict strategy")
+	if s.RoleUsageTracking {
+		service.RoleUsage = NewRoleUsage()
+	}
+	service.RoleModifications = NewRoleModifications()
+	service.RoleEvents = NewRoleEvents()
+	if s.TokenSecret != "" && s.RoleTokenTTL > 0 {
+		service.RoleTokens = NewRoleTokens(s.TokenSecret, s.RoleTokenTTL)
+	}
+	if s.IdempotencyKeyTTL > 0 {
+		service.IdempotencyKeys = NewIdempotencyKeys(s.IdempotencyKeyTTL)
+	}
+	if s.PermissionCacheTTL > 0 {
+		service.EffectivePermissions = NewEffectivePermissionsCache(s.PermissionCacheTTL)
+	}
+	if s.MembershipNotice > 0 {
+		service.MembershipNotices = NewMembershipNotices(s.MembershipNotice)
+	}
+	if s.PermissionSweep > 0 {
+		go sweepExpiredPermissions(ctx, &service, s.PermissionSweep)
+	}
 
 	if !validBasepath(s.Basepath) {
 		err := fmt.Errorf("Invalid basepath, must follow format \"/mybasepath\"")
@@ -741,7 +911,7 @@ func (s *Server) Serve(ctx context.Context) {
 		Info("Stopped serving chronograf at ", scheme, "://", listener.Addr())
 }
 
-func openService(ctx context.Context, db kv.Store, builder builders, logger chronograf.Logger, useAuth bool) Service {
+func openService(ctx context.Context, db kv.Store, builder builders, logger chronograf.Logger, useAuth bool, protectedRoles []string) Service {
 	svc, err := kv.NewService(ctx, db, kv.WithLogger(logger))
 	if err != nil {
 		logger.Error("Unable to create kv service", err)
@@ -795,6 +965,19 @@ func openService(ctx context.Context, db kv.Store, builder builders, logger chro
 			Error("Unable to construct a MultiLayoutsStore", err)
 		os.Exit(1)
 	}
+	// The compiled layouts are shared with the layouts store so they are
+	// decoded once.  Restored layouts are written to the first writable
+	// store, which is tried before the compiled layouts.
+	var cannedLayouts *canned.BinLayoutsStore
+	var layoutOverlay LayoutsWriter
+	for _, store := range layouts.Stores {
+		if bin, ok := store.(*canned.BinLayoutsStore); ok {
+			cannedLayouts = bin
+		}
+		if w, ok := store.(LayoutsWriter); ok && layoutOverlay == nil {
+			layoutOverlay = w
+		}
+	}
 
 	return Service{
 		TimeSeriesClient: &InfluxClient{},
@@ -810,9 +993,18 @@ func openService(ctx context.Context, db kv.Store, builder builders, logger chro
 			MappingsStore:           svc.MappingsStore(),
 			OrganizationConfigStore: svc.OrganizationConfigStore(),
 		},
-		Logger:    logger,
-		UseAuth:   useAuth,
-		Databases: &influx.Client{Logger: logger},
+		TemporaryGrants: svc.TemporaryGrantsStore(),
+		RoleLabels:      svc.RoleLabelsStore(),
+		RoleDocs:        svc.RoleDocsStore(),
+		RoleMemberships: svc.RoleMembershipsStore(),
+		RoleDelegations: svc.RoleDelegationsStore(),
+		RolePermissions: svc.RolePermissionsStore(),
+		CannedLayouts:   cannedLayouts,
+		LayoutOverlay:   layoutOverlay,
+		Logger:          logger,
+		UseAuth:         useAuth,
+		ProtectedRoles:  protectedRoles,
+		Databases:       &influx.Client{Logger: logger},
 	}
 }
 
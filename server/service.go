@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"regexp"
 	"strings"
 
 	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/canned"
 	"github.com/influxdata/chronograf/enterprise"
 	"github.com/influxdata/chronograf/influx"
 )
@@ -18,6 +20,48 @@ type Service struct {
 	SuperAdminProviderGroups superAdminProviderGroups
 	Env                      chronograf.Environment
 	Databases                chronograf.Databases
+	ProtectedRoles           []string                          // ProtectedRoles are role name patterns (path.Match syntax) of built-in system roles
+	RoleFieldNaming          string                            // RoleFieldNaming is the default field naming of role responses; either camelCase or snake_case
+	RoleApprovals            *RoleApprovals                    // RoleApprovals holds role changes awaiting approval; nil applies changes immediately
+	IdempotencyKeys          *IdempotencyKeys                  // IdempotencyKeys remembers role mutation responses for retries; nil disables Idempotency-Key support
+	PermissionConflicts      string                            // PermissionConflicts is the strategy merging conflicting permissions; either most-permissive (default) or least-permissive
+	RoleUsage                *RoleUsage                        // RoleUsage tracks the queries of source users to report role usage; nil disables tracking
+	Classifications          map[string][]string               // Classifications are the databases of each data classification label of classified permissions
+	LayoutVersions           *LayoutVersions                   // LayoutVersions remembers served layouts so changes can be sent as JSON patches; nil always sends whole layouts
+	RoleNamesIgnoreCase      bool                              // RoleNamesIgnoreCase makes role names differing only by case conflict when roles are created; names are stored as given
+	DuplicateRoleUsers       string                            // DuplicateRoleUsers is how users listed more than once in a role request are handled; either dedupe (default) or reject
+	RoleUsersIgnoreCase      bool                              // RoleUsersIgnoreCase considers role user names differing only by case duplicates
+	RoleSoftLimit            int                               // RoleSoftLimit is the role count of a source at which role creation warns; 0 disables the warning
+	RoleHardLimit            int                               // RoleHardLimit is the most roles a source may have before role creation is forbidden; 0 disables the limit
+	RoleMaxUsers             int                               // RoleMaxUsers is the most users a role may have when created or updated; 0 disables the limit
+	RoleLint                 RoleLintRules                     // RoleLint are the best-practice rules roles are linted against
+	RoleTokens               *RoleTokens                       // RoleTokens issues API tokens scoped to source roles; nil disables role tokens
+	ForbiddenScopes          []string                          // ForbiddenScopes are database name patterns (path.Match syntax) that may not be granted to roles
+	ScopeNamePattern         *regexp.Regexp                    // ScopeNamePattern is the naming convention the databases of role permissions must follow; nil allows any name
+	LayoutAccess             *LayoutAccess                     // LayoutAccess records the accesses of layouts; nil disables tracking
+	CannedLayouts            *canned.BinLayoutsStore           // CannedLayouts are the layouts compiled into Chronograf; nil disables reporting measurements they do not cover
+	LayoutOverlay            LayoutsWriter                     // LayoutOverlay is the writable layouts store restored layouts are written to; nil disables restoring layouts
+	DefaultLayout            string                            // DefaultLayout is the ID of the layout served in place of missing layouts; empty responds 404
+	ScopeAliases             map[string]string                 // ScopeAliases are the databases named by each alias of aliased permissions
+	StrictPermissions        bool                              // StrictPermissions fails validation of contradicting role permissions rather than warning of them
+	RoleModifications        *RoleModifications                // RoleModifications remembers when roles were changed through Chronograf; nil leaves role modification times unknown
+	RoleEvents               *RoleEvents                       // RoleEvents streams the changes made to roles through Chronograf; nil disables role events
+	EffectivePermissions     *EffectivePermissionsCache        // EffectivePermissions caches the effective permissions of users; nil computes them on every request
+	RoleRiskWeights          map[string]int                    // RoleRiskWeights are the weights of the factors of role risk scores; defaults to DefaultRoleRiskWeights
+	PermissionPresets        map[string]chronograf.Permissions // PermissionPresets are the named permission bundles role requests may reference
+	RoleLabels               chronograf.RoleLabelsStore        // RoleLabels are the labels of source roles; nil disables labels
+	RoleDocs                 chronograf.RoleDocsStore          // RoleDocs are the supporting documents of source roles; nil disables documents
+	RoleDelegations          chronograf.RoleDelegationsStore   // RoleDelegations are the permissions source roles delegate to each other; nil disables delegation
+	RoleMemberships          chronograf.RoleMembershipsStore   // RoleMemberships are the expiries of the users of source roles; nil makes every membership permanent
+	RolePermissions          chronograf.RolePermissionsStore   // RolePermissions are the permissions of source roles the sources cannot store, e.g. denies, expiries and notes; nil drops them
+	MembershipNotices        *MembershipNotices                // MembershipNotices announce memberships about to expire; nil disables notices
+	TemporaryGrants          chronograf.TemporaryGrantsStore   // TemporaryGrants are the temporary permissions awaiting revocation; nil disables temporary grants
+	SeparationOfDuties       SeparationOfDuties                // SeparationOfDuties are the mutually exclusive duties no role may hold together
+	RoleTemplatePattern      *regexp.Regexp                    // RoleTemplatePattern matches the parts of database names role templates replace; defaults to DefaultRoleTemplatePattern
+
+	// PermissionValidators enforce custom policies on the permissions of
+	// roles.  They run in order after the built-in checks.
+	PermissionValidators []PermissionValidator
 }
 
 type superAdminProviderGroups struct {
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/chronograf"
+)
+
+var _ chronograf.RolesStore = &normalizedRolesStore{}
+
+// normalizedRolesStore canonicalizes the permission scopes of roles read
+// from the underlying RolesStore.  Role definitions created by older versions
+// may contain scopes in legacy formats.
+type normalizedRolesStore struct {
+	chronograf.RolesStore
+	Logger chronograf.Logger
+}
+
+// All lists all roles from the RolesStore with canonical scopes
+func (s *normalizedRolesStore) All(ctx context.Context) ([]chronograf.Role, error) {
+	roles, err := s.RolesStore.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		s.normalize(&roles[i])
+	}
+	return roles, nil
+}
+
+// Get retrieves a role with canonical scopes if name exists.
+func (s *normalizedRolesStore) Get(ctx context.Context, name string) (*chronograf.Role, error) {
+	role, err := s.RolesStore.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.normalize(role)
+	return role, nil
+}
+
+func (s *normalizedRolesStore) normalize(role *chronograf.Role) {
+	for i, perm := range role.Permissions {
+		scope, ok := normalizeScope(perm.Scope)
+		if !ok {
+			s.Logger.
+				WithField("component", "roles").
+				WithField("role", role.Name).
+				WithField("scope", perm.Scope).
+				Info("Unrecognized permission scope")
+			continue
+		}
+		role.Permissions[i].Scope = scope
+	}
+}
+
+// legacyScopes are the scope formats of older role definitions keyed by
+// their lowercase form
+var legacyScopes = map[string]chronograf.Scope{
+	"all":      chronograf.AllScope,
+	"allscope": chronograf.AllScope,
+	"cluster":  chronograf.AllScope,
+	"database": chronograf.DBScope,
+	"dbscope":  chronograf.DBScope,
+	"db":       chronograf.DBScope,
+}
+
+// normalizeScope converts a legacy scope into its current form.  ok is false
+// if the scope is not recognized, in which case it is returned unchanged.
+func normalizeScope(scope chronograf.Scope) (chronograf.Scope, bool) {
+	if s, ok := legacyScopes[strings.ToLower(strings.TrimSpace(string(scope)))]; ok {
+		return s, true
+	}
+	return scope, false
+}
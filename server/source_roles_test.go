@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	"github.com/influxdata/chronograf/log"
+	"github.com/influxdata/chronograf/mocks"
+)
+
+func Test_normalizedRolesStore_All(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []chronograf.Role
+		want  []chronograf.Role
+	}{
+		{
+			name: "legacy scopes are canonicalized",
+			roles: []chronograf.Role{
+				{
+					Name: "timetravelers",
+					Permissions: chronograf.Permissions{
+						{
+							Scope:   "AllScope",
+							Allowed: chronograf.Allowances{"ViewChronograf"},
+						},
+						{
+							Scope:   "DB",
+							Name:    "delorean",
+							Allowed: chronograf.Allowances{"ReadData"},
+						},
+						{
+							Scope:   chronograf.DBScope,
+							Name:    "hillvalley",
+							Allowed: chronograf.Allowances{"WriteData"},
+						},
+					},
+				},
+			},
+			want: []chronograf.Role{
+				{
+					Name: "timetravelers",
+					Permissions: chronograf.Permissions{
+						{
+							Scope:   chronograf.AllScope,
+							Allowed: chronograf.Allowances{"ViewChronograf"},
+						},
+						{
+							Scope:   chronograf.DBScope,
+							Name:    "delorean",
+							Allowed: chronograf.Allowances{"ReadData"},
+						},
+						{
+							Scope:   chronograf.DBScope,
+							Name:    "hillvalley",
+							Allowed: chronograf.Allowances{"WriteData"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unrecognized scopes are unchanged",
+			roles: []chronograf.Role{
+				{
+					Name: "timetravelers",
+					Permissions: chronograf.Permissions{
+						{
+							Scope:   "flux-capacitor",
+							Allowed: chronograf.Allowances{"ReadData"},
+						},
+					},
+				},
+			},
+			want: []chronograf.Role{
+				{
+					Name: "timetravelers",
+					Permissions: chronograf.Permissions{
+						{
+							Scope:   "flux-capacitor",
+							Allowed: chronograf.Allowances{"ReadData"},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &normalizedRolesStore{
+				RolesStore: &mocks.RolesStore{
+					AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+						return tt.roles, nil
+					},
+				},
+				Logger: log.New(log.DebugLevel),
+			}
+			got, err := s.All(context.Background())
+			if err != nil {
+				t.Fatalf("normalizedRolesStore.All() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizedRolesStore.All() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
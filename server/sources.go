@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/chronograf/enterprise"
@@ -525,7 +528,7 @@ func (s *Service) NewSourceUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	su := newSourceUserResponse(srcID, res.Name).WithPermissions(res.Permissions)
-	if _, hasRoles := s.hasRoles(ctx, ts); hasRoles {
+	if _, hasRoles := s.hasRoles(ctx, srcID, ts); hasRoles {
 		su.WithRoles(srcID, res.Roles)
 	}
 	location(w, su.Links.Self)
@@ -547,7 +550,7 @@ func (s *Service) SourceUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, hasRoles := s.hasRoles(ctx, ts)
+	_, hasRoles := s.hasRoles(ctx, srcID, ts)
 	ur := make([]sourceUserResponse, len(users))
 	for i, u := range users {
 		usr := newSourceUserResponse(srcID, u.Name).WithPermissions(u.Permissions)
@@ -582,7 +585,7 @@ func (s *Service) SourceUserID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	res := newSourceUserResponse(srcID, u.Name).WithPermissions(u.Permissions)
-	if _, hasRoles := s.hasRoles(ctx, ts); hasRoles {
+	if _, hasRoles := s.hasRoles(ctx, srcID, ts); hasRoles {
 		res.WithRoles(srcID, u.Roles)
 	}
 	encodeJSON(w, http.StatusOK, res, s.Logger)
@@ -645,7 +648,7 @@ func (s *Service) UpdateSourceUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	res := newSourceUserResponse(srcID, u.Name).WithPermissions(u.Permissions)
-	if _, hasRoles := s.hasRoles(ctx, ts); hasRoles {
+	if _, hasRoles := s.hasRoles(ctx, srcID, ts); hasRoles {
 		res.WithRoles(srcID, u.Roles)
 	}
 	location(w, res.Links.Self)
@@ -659,25 +662,34 @@ func (s *Service) sourcesSeries(ctx context.Context, w http.ResponseWriter, r *h
 		return 0, nil, err
 	}
 
+	ts, err := s.connectSource(ctx, w, srcID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return srcID, ts, nil
+}
+
+// connectSource connects to the time series of the source with srcID
+func (s *Service) connectSource(ctx context.Context, w http.ResponseWriter, srcID int) (chronograf.TimeSeries, error) {
 	src, err := s.Store.Sources(ctx).Get(ctx, srcID)
 	if err != nil {
 		notFound(w, srcID, s.Logger)
-		return 0, nil, err
+		return nil, err
 	}
 
 	ts, err := s.TimeSeries(src)
 	if err != nil {
 		msg := fmt.Sprintf("Unable to connect to source %d: %v", srcID, err)
 		Error(w, http.StatusBadRequest, msg, s.Logger)
-		return 0, nil, err
+		return nil, err
 	}
 
 	if err = ts.Connect(ctx, &src); err != nil {
 		msg := fmt.Sprintf("Unable to connect to source %d: %v", srcID, err)
 		Error(w, http.StatusBadRequest, msg, s.Logger)
-		return 0, nil, err
+		return nil, err
 	}
-	return srcID, ts, nil
+	return ts, nil
 }
 
 func (s *Service) sourceUsersStore(ctx context.Context, w http.ResponseWriter, r *http.Request) (int, chronograf.UsersStore, error) {
@@ -690,12 +702,80 @@ func (s *Service) sourceUsersStore(ctx context.Context, w http.ResponseWriter, r
 	return srcID, store, nil
 }
 
-// hasRoles checks if the influx source has roles or not
-func (s *Service) hasRoles(ctx context.Context, ts chronograf.TimeSeries) (chronograf.RolesStore, bool) {
+// hasRoles checks if the influx source has roles or not.  Changes made to
+// the roles are recorded as modifications of the roles of srcID,
+// published as role events and drop the cached effective permissions of
+// their users.
+func (s *Service) hasRoles(ctx context.Context, srcID int, ts chronograf.TimeSeries) (chronograf.RolesStore, bool) {
 	store, err := ts.Roles(ctx)
 	if err != nil {
 		return nil, false
 	}
+	store = &normalizedRolesStore{
+		RolesStore: store,
+		Logger:     s.Logger,
+	}
+	if s.RolePermissions != nil {
+		store = &retainingRolesStore{
+			RolesStore:  store,
+			srcID:       srcID,
+			permissions: s.RolePermissions,
+			Logger:      s.Logger,
+		}
+	}
+	if s.RoleLabels != nil {
+		store = &labelingRolesStore{
+			RolesStore: store,
+			srcID:      srcID,
+			labels:     s.RoleLabels,
+			Logger:     s.Logger,
+		}
+	}
+	if s.RoleDocs != nil {
+		store = &documentingRolesStore{
+			RolesStore: store,
+			srcID:      srcID,
+			docs:       s.RoleDocs,
+			Logger:     s.Logger,
+		}
+	}
+	if s.RoleDelegations != nil {
+		store = &delegatingRolesStore{
+			RolesStore:  store,
+			srcID:       srcID,
+			delegations: s.RoleDelegations,
+			Logger:      s.Logger,
+		}
+	}
+	if s.RoleMemberships != nil {
+		store = &expiringRolesStore{
+			RolesStore:  store,
+			srcID:       srcID,
+			memberships: s.RoleMemberships,
+			Logger:      s.Logger,
+		}
+	}
+	if s.RoleModifications != nil {
+		store = &recordingRolesStore{
+			RolesStore:    store,
+			srcID:         srcID,
+			modifications: s.RoleModifications,
+		}
+	}
+	if s.RoleEvents != nil {
+		store = &publishingRolesStore{
+			RolesStore: store,
+			srcID:      srcID,
+			events:     s.RoleEvents,
+		}
+	}
+	if s.EffectivePermissions != nil {
+		store = &invalidatingRolesStore{
+			RolesStore: store,
+			srcID:      srcID,
+			cache:      s.EffectivePermissions,
+		}
+	}
 	return store, true
 }
 
@@ -707,13 +787,33 @@ type sourceUserRequest struct {
 }
 
 func (r *sourceUserRequest) ValidCreate() error {
+	var errs validationErrors
 	if r.Username == "" {
-		return fmt.Errorf("Username required")
+		errs.add("name", "Username required")
 	}
 	if r.Password == "" {
-		return fmt.Errorf("Password required")
+		errs.add("password", "Password required")
+	}
+	errs.merge("permissions", validPermissions(&r.Permissions, permissionPolicy{}))
+	validUserPermissions(r.Permissions, &errs)
+	return errs.err()
+}
+
+// validUserPermissions rejects what no source stores of the permissions of
+// a user.  InfluxQL cannot grant denies either, so users are denied
+// permissions through their roles instead.
+func validUserPermissions(perms chronograf.Permissions, errs *validationErrors) {
+	for i, perm := range perms {
+		if perm.Deny {
+			errs.add(fmt.Sprintf("permissions[%d].deny", i), "Permissions of users cannot be denied; deny them through a role")
+		}
+		if perm.ExpiresAt != nil {
+			errs.add(fmt.Sprintf("permissions[%d].expiresAt", i), "Permissions of users cannot expire; grant temporary permissions through a role")
+		}
+		if perm.Note != "" {
+			errs.add(fmt.Sprintf("permissions[%d].note", i), "Permissions of users cannot have notes")
+		}
 	}
-	return validPermissions(&r.Permissions)
 }
 
 type sourceUsersResponse struct {
@@ -724,7 +824,10 @@ func (r *sourceUserRequest) ValidUpdate() error {
 	if r.Password == "" && r.Permissions == nil && r.Roles == nil {
 		return fmt.Errorf("No fields to update")
 	}
-	return validPermissions(&r.Permissions)
+	var errs validationErrors
+	errs.merge("permissions", validPermissions(&r.Permissions, permissionPolicy{}))
+	validUserPermissions(r.Permissions, &errs)
+	return errs.err()
 }
 
 type sourceUserResponse struct {
@@ -734,6 +837,9 @@ type sourceUserResponse struct {
 	Links          selfLinks              // Links are URI locations related to user
 	hasPermissions bool
 	hasRoles       bool
+	unresolved     bool
+	expiresAt      *time.Time // expiresAt is when the user's membership of a role ends
+	expired        bool       // expired is true if the membership ended but is not yet swept
 }
 
 func (u *sourceUserResponse) MarshalJSON() ([]byte, error) {
@@ -747,6 +853,13 @@ func (u *sourceUserResponse) MarshalJSON() ([]byte, error) {
 	if u.hasPermissions {
 		res["permissions"] = u.Permissions
 	}
+	if u.unresolved {
+		res["resolved"] = false
+	}
+	if u.expiresAt != nil {
+		res["expiresAt"] = u.expiresAt
+		res["expired"] = u.expired
+	}
 	return json.Marshal(res)
 }
 
@@ -794,16 +907,28 @@ func newSelfLinks(id int, parent, resource string) selfLinks {
 
 // NewSourceRole adds role to source
 func (s *Service) NewSourceRole(w http.ResponseWriter, r *http.Request) {
-	var req sourceRoleRequest
+	req := s.newSourceRoleRequest()
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		invalidJSON(w, s.Logger)
 		return
 	}
 
+	if err := s.expandPresets(&req); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
+	if err := s.expandScopeAliases(req.Permissions); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
 	if err := req.ValidCreate(); err != nil {
 		invalidData(w, err, s.Logger)
 		return
 	}
+	if err := s.expandClassifications(&req.Permissions); err != nil {
+		invalidData(w, err, s.Logger)
+		return
+	}
 
 	ctx := r.Context()
 	srcID, ts, err := s.sourcesSeries(ctx, w, r)
@@ -811,14 +936,28 @@ func (s *Service) NewSourceRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roles, ok := s.hasRoles(ctx, ts)
+	roles, ok := s.hasRoles(ctx, srcID, ts)
 	if !ok {
 		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
 		return
 	}
 
-	if _, err := roles.Get(ctx, req.Name); err == nil {
-		Error(w, http.StatusBadRequest, fmt.Sprintf("Source %d already has role %s", srcID, req.Name), s.Logger)
+	if s.isProtectedRole(req.Name) {
+		protectedRole(w, req.Name, s.Logger)
+		return
+	}
+	if existing, ok := s.existingRoleName(ctx, roles, req.Name); ok {
+		Error(w, http.StatusBadRequest, fmt.Sprintf("Source %d already has role %s", srcID, existing), s.Logger)
+		return
+	}
+
+	warning, ok := s.checkRoleLimits(ctx, w, srcID, roles)
+	if !ok {
+		return
+	}
+
+	if s.RoleApprovals != nil {
+		s.proposeSourceRole(w, r, srcID, nil, roleChange{role: req.Role, create: true})
 		return
 	}
 
@@ -829,19 +968,18 @@ func (s *Service) NewSourceRole(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rr := newSourceRoleResponse(srcID, res)
+	if warning != "" {
+		rr.Warnings = []string{warning}
+	}
+	rr.Warnings = append(rr.Warnings, s.contradictionWarnings(req.Permissions)...)
 	location(w, rr.Links.Self)
-	encodeJSON(w, http.StatusCreated, rr, s.Logger)
+	s.encodeSourceRole(w, r, http.StatusCreated, rr)
 }
 
 // UpdateSourceRole changes the permissions or users of a role
 func (s *Service) UpdateSourceRole(w http.ResponseWriter, r *http.Request) {
-	var req sourceRoleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		invalidJSON(w, s.Logger)
-		return
-	}
-	if err := req.ValidUpdate(); err != nil {
-		invalidData(w, err, s.Logger)
+	req, ok := s.decodeRoleUpdate(w, r)
+	if !ok {
 		return
 	}
 
@@ -851,15 +989,32 @@ func (s *Service) UpdateSourceRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roles, ok := s.hasRoles(ctx, ts)
+	roles, ok := s.hasRoles(ctx, srcID, ts)
 	if !ok {
 		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
 		return
 	}
 
 	rid := httprouter.GetParamFromContext(ctx, "rid")
+	if s.isProtectedRole(rid) {
+		protectedRole(w, rid, s.Logger)
+		return
+	}
 	req.Name = rid
 
+	if s.RoleApprovals != nil {
+		// Roles pending creation do not yet exist on the source
+		var current *chronograf.Role
+		if _, ok := s.RoleApprovals.pending(srcID, rid); !ok {
+			if current, err = roles.Get(ctx, rid); err != nil {
+				Error(w, http.StatusBadRequest, err.Error(), s.Logger)
+				return
+			}
+		}
+		s.proposeSourceRole(w, r, srcID, current, roleChange{role: req.Role})
+		return
+	}
+
 	if err := roles.Update(ctx, &req.Role); err != nil {
 		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
 		return
@@ -871,43 +1026,137 @@ func (s *Service) UpdateSourceRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	rr := newSourceRoleResponse(srcID, role)
+	rr.Warnings = s.contradictionWarnings(req.Permissions)
 	location(w, rr.Links.Self)
-	encodeJSON(w, http.StatusOK, rr, s.Logger)
+	s.encodeSourceRole(w, r, http.StatusOK, rr)
+}
+
+// decodeRoleUpdate decodes and validates the body of a role update.  The
+// response is written if the update is invalid.
+func (s *Service) decodeRoleUpdate(w http.ResponseWriter, r *http.Request) (sourceRoleRequest, bool) {
+	req := s.newSourceRoleRequest()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, s.Logger)
+		return req, false
+	}
+	if err := s.expandPresets(&req); err != nil {
+		invalidData(w, err, s.Logger)
+		return req, false
+	}
+	if err := s.expandScopeAliases(req.Permissions); err != nil {
+		invalidData(w, err, s.Logger)
+		return req, false
+	}
+	if err := req.ValidUpdate(); err != nil {
+		invalidData(w, err, s.Logger)
+		return req, false
+	}
+	if err := s.expandClassifications(&req.Permissions); err != nil {
+		invalidData(w, err, s.Logger)
+		return req, false
+	}
+	return req, true
 }
 
-// SourceRoleID retrieves a role with ID from store.
+// SourceRoleID retrieves a role with ID from store.  With embed=source the
+// response includes a descriptor of the role's source.
 func (s *Service) SourceRoleID(w http.ResponseWriter, r *http.Request) {
+	embedSource, err := validRoleEmbeds(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+
 	ctx := r.Context()
 	srcID, ts, err := s.sourcesSeries(ctx, w, r)
 	if err != nil {
 		return
 	}
 
-	roles, ok := s.hasRoles(ctx, ts)
+	roles, ok := s.hasRoles(ctx, srcID, ts)
 	if !ok {
 		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
 		return
 	}
 
 	rid := httprouter.GetParamFromContext(ctx, "rid")
+	if s.isProtectedRole(rid) {
+		protectedRole(w, rid, s.Logger)
+		return
+	}
 	role, err := roles.Get(ctx, rid)
 	if err != nil {
 		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
 		return
 	}
 	rr := newSourceRoleResponse(srcID, role)
-	encodeJSON(w, http.StatusOK, rr, s.Logger)
+	if embedSource {
+		src, err := s.Store.Sources(ctx).Get(ctx, srcID)
+		if err != nil {
+			notFound(w, srcID, s.Logger)
+			return
+		}
+		rr.Source = newRoleSource(src)
+	}
+	s.encodeSourceRole(w, r, http.StatusOK, rr)
+}
+
+// roleSource is the trimmed descriptor of the source of a role embedded in
+// role responses
+type roleSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Type string `json:"type"`
+}
+
+func newRoleSource(src chronograf.Source) *roleSource {
+	return &roleSource{
+		Name: src.Name,
+		URL:  src.URL,
+		Type: src.Type,
+	}
+}
+
+// validRoleEmbeds checks the comma-separated embed query parameter of a
+// role request.  source is the only resource that may be embedded.
+func validRoleEmbeds(q url.Values) (embedSource bool, err error) {
+	for _, embed := range strings.Split(q.Get("embed"), ",") {
+		switch embed {
+		case "":
+		case "source":
+			embedSource = true
+		default:
+			return false, fmt.Errorf("Unknown embed %s; only source may be embedded", embed)
+		}
+	}
+	return embedSource, nil
 }
 
-// SourceRoles retrieves all roles from the store
+// SourceRoles retrieves all roles from the store.  Protected system roles
+// are omitted unless the includeSystem query parameter is true.  The user
+// query parameter limits the roles to those containing that user.  If rid
+// query parameters are given only those roles are retrieved.  With a limit
+// or cursor query parameter, roles are listed by name a page at a time.
+// The scope query parameter limits the roles to those with broad or narrow
+// grants.
 func (s *Service) SourceRoles(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	q, err := validSourceRolesQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), s.Logger)
+		return
+	}
+	if ids := r.URL.Query()["rid"]; len(ids) > 0 {
+		s.sourceRolesByID(w, r, q, ids)
+		return
+	}
+
 	srcID, ts, err := s.sourcesSeries(ctx, w, r)
 	if err != nil {
 		return
 	}
 
-	store, ok := s.hasRoles(ctx, ts)
+	store, ok := s.hasRoles(ctx, srcID, ts)
 	if !ok {
 		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
 		return
@@ -919,18 +1168,38 @@ func (s *Service) SourceRoles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rr := make([]sourceRoleResponse, len(roles))
-	for i, role := range roles {
-		rr[i] = newSourceRoleResponse(srcID, &role)
+	rr := make([]sourceRoleResponse, 0, len(roles))
+	for _, role := range roles {
+		if !q.IncludeSystem && s.isProtectedRole(role.Name) {
+			continue
+		}
+		// Risk is scored on every permission of the role, not only
+		// those of the queried database
+		whole := role
+		if !q.matches(&role) || !q.scopeToDatabase(&role) {
+			continue
+		}
+		if q.ModifiedSince != nil && !s.modifiedSince(srcID, role.Name, *q.ModifiedSince) {
+			continue
+		}
+		res := newSourceRoleResponse(srcID, &role)
+		if q.IncludeRisk {
+			s.withRisk(&res, whole)
+		}
+		rr = append(rr, res)
 	}
 
-	res := struct {
-		Roles []sourceRoleResponse `json:"roles"`
-	}{rr}
-	encodeJSON(w, http.StatusOK, res, s.Logger)
+	var cursor string
+	if q.paged() {
+		rr, cursor = q.page(rr)
+	}
+	s.encodeSourceRoles(w, r, http.StatusOK, rr, cursor)
 }
 
-// RemoveSourceRole removes role from data source.
+// RemoveSourceRole removes role from data source.  With force=true a role
+// the source fails to delete has its users removed and is deleted again;
+// users that cannot be removed, e.g. users the source already deleted, are
+// reported rather than failing the removal.
 func (s *Service) RemoveSourceRole(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	srcID, ts, err := s.sourcesSeries(ctx, w, r)
@@ -938,70 +1207,367 @@ func (s *Service) RemoveSourceRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roles, ok := s.hasRoles(ctx, ts)
+	roles, ok := s.hasRoles(ctx, srcID, ts)
 	if !ok {
 		Error(w, http.StatusNotFound, fmt.Sprintf("Source %d does not have role capability", srcID), s.Logger)
 		return
 	}
 
 	rid := httprouter.GetParamFromContext(ctx, "rid")
-	if err := roles.Delete(ctx, &chronograf.Role{Name: rid}); err != nil {
+	if s.isProtectedRole(rid) {
+		protectedRole(w, rid, s.Logger)
+		return
+	}
+
+	force := false
+	if f := r.URL.Query().Get("force"); f != "" {
+		if force, err = strconv.ParseBool(f); err != nil {
+			Error(w, http.StatusUnprocessableEntity, "force must be a boolean", s.Logger)
+			return
+		}
+	}
+
+	err = roles.Delete(ctx, &chronograf.Role{Name: rid})
+	if err != nil && force {
+		unremoved, err := s.forceRemoveRole(ctx, srcID, roles, rid)
+		if err != nil {
+			msg := err.Error()
+			if len(unremoved) > 0 {
+				msg = fmt.Sprintf("%s; users %s could not be removed", msg, strings.Join(unremoved, ", "))
+			}
+			Error(w, http.StatusBadRequest, msg, s.Logger)
+			return
+		}
+		if len(unremoved) > 0 {
+			res := roleForcedRemovalResponse{
+				Role:           rid,
+				UnremovedUsers: unremoved,
+			}
+			encodeJSON(w, http.StatusOK, res, s.Logger)
+			return
+		}
+	} else if err != nil {
 		Error(w, http.StatusBadRequest, err.Error(), s.Logger)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// existingRoleName returns the name of the role of the source that name
+// would collide with.  Names are stored as given; when RoleNamesIgnoreCase
+// is set, names differing only by case collide.
+func (s *Service) existingRoleName(ctx context.Context, roles chronograf.RolesStore, name string) (string, bool) {
+	if _, err := roles.Get(ctx, name); err == nil {
+		return name, true
+	}
+	if !s.RoleNamesIgnoreCase {
+		return "", false
+	}
+	all, err := roles.All(ctx)
+	if err != nil {
+		return "", false
+	}
+	for _, role := range all {
+		if strings.EqualFold(role.Name, name) {
+			return role.Name, true
+		}
+	}
+	return "", false
+}
+
+// isProtectedRole checks if name matches any of the protected system role patterns
+func (s *Service) isProtectedRole(name string) bool {
+	for _, pattern := range s.ProtectedRoles {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func protectedRole(w http.ResponseWriter, name string, logger chronograf.Logger) {
+	Error(w, http.StatusForbidden, fmt.Sprintf("Role %s is a protected system role", name), logger)
+}
+
+// sourceRolesQuery are the query parameters used to filter a role listing
+type sourceRolesQuery struct {
+	IncludeSystem bool   // IncludeSystem lists protected system roles; defaults to false
+	User          string // User limits the roles to those containing this user
+	Prefix        string // Prefix limits the roles to those whose name starts with it
+	HasUsers      *bool  // HasUsers limits the roles to those with (true) or without (false) users
+	Database      string // Database limits the roles and their permissions to those scoped to this database
+	ClusterWide   bool   // ClusterWide keeps permissions for all databases when filtering by Database
+	After         string // After is the name of the last role of the previous page, decoded from the cursor
+	Limit         int    // Limit is the most roles of a page; 0 lists every role after the cursor
+	IncludeRisk   bool   // IncludeRisk scores the risk of each role
+	Breadth       string // Breadth limits the roles to those with broad or narrow grants
+
+	// Labels limits the roles to those having every label
+	Labels map[string]string
+
+	// ModifiedSince limits the roles to those changed after it.  Roles
+	// without a known modification time are always listed.
+	ModifiedSince *time.Time
+}
+
+func validSourceRolesQuery(query url.Values) (sourceRolesQuery, error) {
+	var q sourceRolesQuery
+	if include := query.Get("includeSystem"); include != "" {
+		b, err := strconv.ParseBool(include)
+		if err != nil {
+			return q, fmt.Errorf("includeSystem must be a boolean")
+		}
+		q.IncludeSystem = b
+	}
+	if has := query.Get("hasUsers"); has != "" {
+		b, err := strconv.ParseBool(has)
+		if err != nil {
+			return q, fmt.Errorf("hasUsers must be a boolean")
+		}
+		q.HasUsers = &b
+	}
+	if all := query.Get("includeClusterWide"); all != "" {
+		b, err := strconv.ParseBool(all)
+		if err != nil {
+			return q, fmt.Errorf("includeClusterWide must be a boolean")
+		}
+		q.ClusterWide = b
+	}
+	if risk := query.Get("includeRisk"); risk != "" {
+		b, err := strconv.ParseBool(risk)
+		if err != nil {
+			return q, fmt.Errorf("includeRisk must be a boolean")
+		}
+		q.IncludeRisk = b
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, ok := decodeRoleCursor(cursor)
+		if !ok {
+			return q, fmt.Errorf("cursor is invalid")
+		}
+		q.After = after
+	}
+	if limit := query.Get(limitQuery); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("limit must be a positive integer")
+		}
+		q.Limit = n
+	}
+	if since := query.Get("modifiedSince"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return q, fmt.Errorf("modifiedSince must be an RFC3339 time")
+		}
+		q.ModifiedSince = &t
+	}
+	switch breadth := query.Get("scope"); breadth {
+	case "", BroadRole, NarrowRole:
+		q.Breadth = breadth
+	default:
+		return q, fmt.Errorf("scope must be %s or %s", BroadRole, NarrowRole)
+	}
+	labels, err := parseLabelSelectors(query["label"])
+	if err != nil {
+		return q, err
+	}
+	q.Labels = labels
+	q.User = query.Get("user")
+	q.Prefix = query.Get("prefix")
+	q.Database = query.Get("database")
+	return q, nil
+}
+
+// matches checks if the role passes all of the query's filters
+func (q *sourceRolesQuery) matches(role *chronograf.Role) bool {
+	if q.User != "" && !hasRoleUser(role, q.User) {
+		return false
+	}
+	if !strings.HasPrefix(role.Name, q.Prefix) {
+		return false
+	}
+	if q.HasUsers != nil && *q.HasUsers != (len(role.Users) > 0) {
+		return false
+	}
+	if q.Breadth != "" && roleBreadth(role, time.Now()) != q.Breadth {
+		return false
+	}
+	return hasLabels(role, q.Labels)
+}
+
+// scopeToDatabase trims the permissions of role to those scoped to the
+// query's database, and those for all databases if ClusterWide is set.  It
+// reports whether the role has any permission left.  Roles are not trimmed
+// when no database is queried.
+func (q *sourceRolesQuery) scopeToDatabase(role *chronograf.Role) bool {
+	if q.Database == "" {
+		return true
+	}
+	perms := chronograf.Permissions{}
+	for _, perm := range role.Permissions {
+		switch {
+		case perm.Scope == chronograf.DBScope && perm.Name == q.Database:
+		case perm.Scope == chronograf.AllScope && q.ClusterWide:
+		default:
+			continue
+		}
+		perms = append(perms, perm)
+	}
+	role.Permissions = perms
+	return len(perms) > 0
+}
+
+// hasRoleUser checks if the user with name is a member of role
+func hasRoleUser(role *chronograf.Role, name string) bool {
+	for _, u := range role.Users {
+		if u.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // sourceRoleRequest is the format used for both creating and updating roles
 type sourceRoleRequest struct {
 	chronograf.Role
+	Presets []string `json:"presets,omitempty"` // Presets are named permission bundles added to the role's permissions
+
+	duplicates duplicateUsers   // duplicates is how users listed more than once are handled
+	policy     permissionPolicy // policy is the operator's rules for the role's permissions
+	maxUsers   int              // maxUsers is the most users the role may have; 0 is unlimited
 }
 
-func (r *sourceRoleRequest) ValidCreate() error {
-	if r.Name == "" || len(r.Name) > 254 {
-		return fmt.Errorf("Name is required for a role")
+// newSourceRoleRequest returns a role request validated by the configured
+// policies of the service
+func (s *Service) newSourceRoleRequest() sourceRoleRequest {
+	return sourceRoleRequest{
+		duplicates: s.duplicateRoleUsers(),
+		policy:     s.permissionPolicy(),
+		maxUsers:   s.RoleMaxUsers,
 	}
-	for _, user := range r.Users {
-		if user.Name == "" {
-			return fmt.Errorf("Username required")
-		}
+}
+
+const (
+	// DedupeDuplicates removes users listed more than once from role requests
+	DedupeDuplicates = "dedupe"
+	// RejectDuplicates fails validation of role requests listing a user more than once
+	RejectDuplicates = "reject"
+)
+
+// duplicateUsers is the policy for users listed more than once in a role
+// request.  By default, duplicates are removed and names are case-sensitive.
+type duplicateUsers struct {
+	reject     bool // reject fails validation rather than removing duplicates
+	ignoreCase bool // ignoreCase considers names differing only by case duplicates
+}
+
+// duplicateRoleUsers returns the configured policy for duplicate role users
+func (s *Service) duplicateRoleUsers() duplicateUsers {
+	return duplicateUsers{
+		reject:     s.DuplicateRoleUsers == RejectDuplicates,
+		ignoreCase: s.RoleUsersIgnoreCase,
 	}
-	return validPermissions(&r.Permissions)
+}
+
+func (r *sourceRoleRequest) ValidCreate() error {
+	var errs validationErrors
+	if r.Name == "" || len(r.Name) > 254 {
+		errs.add("name", "Name is required for a role")
+	}
+	r.validUsers(&errs)
+	validMemberships(r.Users, time.Now(), &errs)
+	validLabels(r.Labels, &errs)
+	validDocs(r.Docs, &errs)
+	validDelegations(r.Name, r.Delegations, &errs)
+	errs.merge("permissions", validPermissions(&r.Permissions, r.policy))
+	return errs.err()
 }
 
 func (r *sourceRoleRequest) ValidUpdate() error {
+	var errs validationErrors
 	if len(r.Name) > 254 {
-		return fmt.Errorf("Username too long; must be less than 254 characters")
-	}
-	for _, user := range r.Users {
+		errs.add("name", "Username too long; must be less than 254 characters")
+	}
+	r.validUsers(&errs)
+	validMemberships(r.Users, time.Now(), &errs)
+	validLabels(r.Labels, &errs)
+	validDocs(r.Docs, &errs)
+	validDelegations(r.Name, r.Delegations, &errs)
+	errs.merge("permissions", validPermissions(&r.Permissions, r.policy))
+	return errs.err()
+}
+
+func (r *sourceRoleRequest) validUsers(errs *validationErrors) {
+	seen := map[string]bool{}
+	users := make([]chronograf.User, 0, len(r.Users))
+	for i, user := range r.Users {
 		if user.Name == "" {
-			return fmt.Errorf("Username required")
+			errs.add(fmt.Sprintf("users[%d].name", i), "Username required")
+		}
+
+		key := user.Name
+		if r.duplicates.ignoreCase {
+			key = strings.ToLower(key)
+		}
+		if !seen[key] {
+			seen[key] = true
+			users = append(users, user)
+			continue
+		}
+		if r.duplicates.reject {
+			errs.add(fmt.Sprintf("users[%d].name", i), "User %s is listed more than once", user.Name)
 		}
 	}
-	return validPermissions(&r.Permissions)
+	if r.maxUsers > 0 && len(users) > r.maxUsers {
+		errs.add("users", "Role may have at most %d users", r.maxUsers)
+	}
+	if r.Users != nil && !r.duplicates.reject {
+		r.Users = users
+	}
 }
 
 type sourceRoleResponse struct {
-	Users       []*sourceUserResponse  `json:"users"`
-	Name        string                 `json:"name"`
-	Permissions chronograf.Permissions `json:"permissions"`
-	Links       selfLinks              `json:"links"`
+	Users       []*sourceUserResponse       `json:"users"`
+	Name        string                      `json:"name"`
+	Permissions chronograf.Permissions      `json:"permissions"`
+	Links       selfLinks                   `json:"links"`
+	Status      string                      `json:"status,omitempty"`     // Status is the approval state of a role change
+	LastUsed    *time.Time                  `json:"lastUsed,omitempty"`   // LastUsed is the latest query of a user of the role when usage is tracked
+	UsageCount  *int                        `json:"usageCount,omitempty"` // UsageCount is the number of queries of the users of the role when usage is tracked
+	UpdatedAt   *time.Time                  `json:"updatedAt,omitempty"`  // UpdatedAt is the last change of the role made through Chronograf, if known
+	Warnings    []string                    `json:"warnings,omitempty"`   // Warnings are problems that did not prevent the request, e.g. nearing the role limit
+	Source      *roleSource                 `json:"source,omitempty"`     // Source is the role's source when requested with embed=source
+	Labels      map[string]string           `json:"labels,omitempty"`
+	Docs        []chronograf.RoleDoc        `json:"docs,omitempty"`
+	Delegations []chronograf.RoleDelegation `json:"delegations,omitempty"`
+	RiskScore   *int                        `json:"riskScore,omitempty"` // RiskScore is the weighted sum of RiskFactors when requested with includeRisk=true
+	RiskFactors []roleRiskFactor            `json:"riskFactors,omitempty"`
+
+	srcID int
 }
 
 func newSourceRoleResponse(srcID int, res *chronograf.Role) sourceRoleResponse {
+	now := time.Now()
 	su := make([]*sourceUserResponse, len(res.Users))
 	for i := range res.Users {
 		name := res.Users[i].Name
 		su[i] = newSourceUserResponse(srcID, name)
+		// Users that could not be retrieved are still listed by name
+		su[i].unresolved = res.Users[i].Unresolved
+		su[i].expiresAt = res.Users[i].ExpiresAt
+		su[i].expired = res.Users[i].MembershipExpired(now)
 	}
 
-	if res.Permissions == nil {
-		res.Permissions = make(chronograf.Permissions, 0)
-	}
+	// Expired permissions are no longer granted even if not yet swept
+	res.Permissions, _ = unexpiredPermissions(res.Permissions, now)
 	return sourceRoleResponse{
 		Name:        res.Name,
 		Permissions: res.Permissions,
+		Labels:      res.Labels,
+		Docs:        res.Docs,
+		Delegations: res.Delegations,
 		Users:       su,
 		Links:       newSelfLinks(srcID, "roles", res.Name),
+		srcID:       srcID,
 	}
 }
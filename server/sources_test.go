@@ -638,6 +638,25 @@ func TestService_NewSourceUser(t *testing.T) {
 			wantContentType: "application/json",
 			wantBody:        `{"code":422,"message":"Error converting ID BAD"}`,
 		},
+		{
+			name: "Denied, noted or expiring permissions",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"POST",
+					"http://local/chronograf/v1/sources/1",
+					ioutil.NopCloser(
+						bytes.NewReader([]byte(`{"name": "marty", "password": "the_lake", "permissions": [{"scope": "database", "name": "payroll", "allowed": ["ReadData"], "deny": true, "note": "Not for contractors"}, {"scope": "all", "allowed": ["ViewChronograf"], "expiresAt": "2015-10-21T16:29:00Z"}]}`)))),
+			},
+			fields: fields{
+				UseAuth: true,
+				Logger:  log.New(log.DebugLevel),
+			},
+			ID:              "1",
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/json",
+			wantBody:        `{"code":422,"message":"Permissions of users cannot be denied; deny them through a role; Permissions of users cannot have notes; Permissions of users cannot expire; grant temporary permissions through a role","errors":[{"field":"permissions[0].deny","message":"Permissions of users cannot be denied; deny them through a role"},{"field":"permissions[0].note","message":"Permissions of users cannot have notes"},{"field":"permissions[1].expiresAt","message":"Permissions of users cannot expire; grant temporary permissions through a role"}]}` + "\n",
+		},
 		{
 			name: "Bad name",
 			args: args{
@@ -655,7 +674,8 @@ func TestService_NewSourceUser(t *testing.T) {
 			ID:              "BAD",
 			wantStatus:      http.StatusUnprocessableEntity,
 			wantContentType: "application/json",
-			wantBody:        `{"code":422,"message":"Username required"}`,
+			wantBody: `{"code":422,"message":"Username required","errors":[{"field":"name","message":"Username required"}]}
+`,
 		},
 		{
 			name: "Bad JSON",
@@ -1331,9 +1351,10 @@ func TestService_UpdateSourceUser(t *testing.T) {
 
 func TestService_NewSourceRole(t *testing.T) {
 	type fields struct {
-		SourcesStore chronograf.SourcesStore
-		TimeSeries   TimeSeriesClient
-		Logger       chronograf.Logger
+		SourcesStore   chronograf.SourcesStore
+		TimeSeries     TimeSeriesClient
+		Logger         chronograf.Logger
+		ProtectedRoles []string
 	}
 	type args struct {
 		w *httptest.ResponseRecorder
@@ -1381,7 +1402,27 @@ func TestService_NewSourceRole(t *testing.T) {
 			ID:              "1",
 			wantStatus:      http.StatusUnprocessableEntity,
 			wantContentType: "application/json",
-			wantBody:        `{"code":422,"message":"Name is required for a role"}`,
+			wantBody: `{"code":422,"message":"Name is required for a role","errors":[{"field":"name","message":"Name is required for a role"}]}
+`,
+		},
+		{
+			name: "Invalid request lists every problem",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"POST",
+					"http://server.local/chronograf/v1/sources/1/roles",
+					ioutil.NopCloser(
+						bytes.NewReader([]byte(`{"name": "", "users": [{"name": "marty"}, {"name": ""}], "permissions": [{"scope": "database", "allowed": ["READ"]}, {"scope": "galaxy"}]}`)))),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+			},
+			ID:              "1",
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/json",
+			wantBody: `{"code":422,"message":"Name is required for a role; Username required; Database scoped permission requires a name; Invalid permission scope","errors":[{"field":"name","message":"Name is required for a role"},{"field":"users[1].name","message":"Username required"},{"field":"permissions[0].name","message":"Database scoped permission requires a name"},{"field":"permissions[1].scope","message":"Invalid permission scope"}]}
+`,
 		},
 		{
 			name: "Invalid source ID",
@@ -1527,6 +1568,47 @@ func TestService_NewSourceRole(t *testing.T) {
 			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/match"},"name":"match"},{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"},{"links":{"self":"/chronograf/v1/sources/1/users/3-d"},"name":"3-d"}],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}
 `,
 		},
+		{
+			name: "New role with a protected name",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"POST",
+					"http://server.local/chronograf/v1/sources/1/roles",
+					ioutil.NopCloser(
+						bytes.NewReader([]byte(`{"name": "_admin"}`)))),
+			},
+			fields: fields{
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AddF: func(ctx context.Context, u *chronograf.Role) (*chronograf.Role, error) {
+								return nil, fmt.Errorf("protected roles must not be created")
+							},
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return nil, fmt.Errorf("no such role")
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/json",
+			wantBody:        `{"code":403,"message":"Role _admin is a protected system role"}`,
+		},
 	}
 	for _, tt := range tests {
 		h := &Service{
@@ -1535,6 +1617,7 @@ func TestService_NewSourceRole(t *testing.T) {
 			},
 			TimeSeriesClient: tt.fields.TimeSeries,
 			Logger:           tt.fields.Logger,
+			ProtectedRoles:   tt.fields.ProtectedRoles,
 		}
 		tt.args.r = tt.args.r.WithContext(httprouter.WithParams(
 			context.Background(),
@@ -1685,9 +1768,10 @@ func TestService_UpdateSourceRole(t *testing.T) {
 
 func TestService_SourceRoleID(t *testing.T) {
 	type fields struct {
-		SourcesStore chronograf.SourcesStore
-		TimeSeries   TimeSeriesClient
-		Logger       chronograf.Logger
+		SourcesStore   chronograf.SourcesStore
+		TimeSeries     TimeSeriesClient
+		Logger         chronograf.Logger
+		ProtectedRoles []string
 	}
 	type args struct {
 		w *httptest.ResponseRecorder
@@ -1764,9 +1848,159 @@ func TestService_SourceRoleID(t *testing.T) {
 			RoleID:          "biffsgang",
 			wantStatus:      http.StatusOK,
 			wantContentType: "application/json",
-			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/match"},"name":"match"},{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"},{"links":{"self":"/chronograf/v1/sources/1/users/3-d"},"name":"3-d"}],"name":"biffsgang","permissions":[{"scope":"DBScope","name":"grays_sports_almanac","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}
+			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/match"},"name":"match"},{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"},{"links":{"self":"/chronograf/v1/sources/1/users/3-d"},"name":"3-d"}],"name":"biffsgang","permissions":[{"scope":"database","name":"grays_sports_almanac","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}
+`,
+		},
+		{
+			name: "Get role with unresolved user",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles/biffsgang",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{
+									Name: "biffsgang",
+									Users: []chronograf.User{
+										{
+											Name: "match",
+										},
+										{
+											Name:       "skinhead",
+											Unresolved: true,
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			RoleID:          "biffsgang",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/match"},"name":"match"},{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead","resolved":false}],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}
+`,
+		},
+		{
+			name: "Get role with embedded source",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles/biffsgang?embed=source",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID:       1,
+							Name:     "muh source",
+							Type:     chronograf.InfluxEnterprise,
+							Username: "name",
+							Password: "hunter2",
+							URL:      "http://localhost:8086",
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{
+									Name: "biffsgang",
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			RoleID:          "biffsgang",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"users":[],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"},"source":{"name":"muh source","url":"http://localhost:8086","type":"influx-enterprise"}}
 `,
 		},
+		{
+			name: "Unknown embed",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles/biffsgang?embed=users",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+			},
+			ID:              "1",
+			RoleID:          "biffsgang",
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/json",
+			wantBody:        `{"code":422,"message":"Unknown embed users; only source may be embedded"}`,
+		},
+		{
+			name: "Get protected system role",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles/_admin",
+					nil),
+			},
+			fields: fields{
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								return &chronograf.Role{Name: name}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			RoleID:          "_admin",
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/json",
+			wantBody:        `{"code":403,"message":"Role _admin is a protected system role"}`,
+		},
 	}
 	for _, tt := range tests {
 		h := &Service{
@@ -1775,6 +2009,7 @@ func TestService_SourceRoleID(t *testing.T) {
 			},
 			TimeSeriesClient: tt.fields.TimeSeries,
 			Logger:           tt.fields.Logger,
+			ProtectedRoles:   tt.fields.ProtectedRoles,
 		}
 
 		tt.args.r = tt.args.r.WithContext(httprouter.WithParams(
@@ -1810,9 +2045,10 @@ func TestService_SourceRoleID(t *testing.T) {
 
 func TestService_RemoveSourceRole(t *testing.T) {
 	type fields struct {
-		SourcesStore chronograf.SourcesStore
-		TimeSeries   TimeSeriesClient
-		Logger       chronograf.Logger
+		SourcesStore   chronograf.SourcesStore
+		TimeSeries     TimeSeriesClient
+		Logger         chronograf.Logger
+		ProtectedRoles []string
 	}
 	type args struct {
 		w *httptest.ResponseRecorder
@@ -1865,6 +2101,42 @@ func TestService_RemoveSourceRole(t *testing.T) {
 			RoleID:     "biffsgang",
 			wantStatus: http.StatusNoContent,
 		},
+		{
+			name: "remove protected system role",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles/_admin",
+					nil),
+			},
+			fields: fields{
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							DeleteF: func(context.Context, *chronograf.Role) error {
+								return fmt.Errorf("protected roles must not be deleted")
+							},
+						}, nil
+					},
+				},
+			},
+			ID:         "1",
+			RoleID:     "_admin",
+			wantStatus: http.StatusForbidden,
+		},
 	}
 	for _, tt := range tests {
 		h := &Service{
@@ -1873,6 +2145,7 @@ func TestService_RemoveSourceRole(t *testing.T) {
 			},
 			TimeSeriesClient: tt.fields.TimeSeries,
 			Logger:           tt.fields.Logger,
+			ProtectedRoles:   tt.fields.ProtectedRoles,
 		}
 
 		tt.args.r = tt.args.r.WithContext(httprouter.WithParams(
@@ -1899,9 +2172,10 @@ func TestService_RemoveSourceRole(t *testing.T) {
 
 func TestService_SourceRoles(t *testing.T) {
 	type fields struct {
-		SourcesStore chronograf.SourcesStore
-		TimeSeries   TimeSeriesClient
-		Logger       chronograf.Logger
+		SourcesStore   chronograf.SourcesStore
+		TimeSeries     TimeSeriesClient
+		Logger         chronograf.Logger
+		ProtectedRoles []string
 	}
 	type args struct {
 		w *httptest.ResponseRecorder
@@ -1976,19 +2250,436 @@ func TestService_SourceRoles(t *testing.T) {
 			RoleID:          "biffsgang",
 			wantStatus:      http.StatusOK,
 			wantContentType: "application/json",
-			wantBody: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/match"},"name":"match"},{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"},{"links":{"self":"/chronograf/v1/sources/1/users/3-d"},"name":"3-d"}],"name":"biffsgang","permissions":[{"scope":"DBScope","name":"grays_sports_almanac","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}]}
+			wantBody: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/match"},"name":"match"},{"links":{"self":"/chronograf/v1/sources/1/users/skinhead"},"name":"skinhead"},{"links":{"self":"/chronograf/v1/sources/1/users/3-d"},"name":"3-d"}],"name":"biffsgang","permissions":[{"scope":"database","name":"grays_sports_almanac","allowed":["ReadData"]}],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}]}
 `,
 		},
-	}
-	for _, tt := range tests {
-		h := &Service{
-			Store: &mocks.Store{
-				SourcesStore: tt.fields.SourcesStore,
+		{
+			name: "Protected system roles are hidden by default",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles",
+					nil),
 			},
-			TimeSeriesClient: tt.fields.TimeSeries,
-			Logger:           tt.fields.Logger,
-		}
-
+			fields: fields{
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "_admin",
+									},
+									{
+										Name: "biffsgang",
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}]}
+`,
+		},
+		{
+			name: "Protected system roles are included when requested",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?includeSystem=true",
+					nil),
+			},
+			fields: fields{
+				Logger:         log.New(log.DebugLevel),
+				ProtectedRoles: []string{"_*"},
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "_admin",
+									},
+									{
+										Name: "biffsgang",
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[],"name":"_admin","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/_admin"}},{"users":[],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}}]}
+`,
+		},
+		{
+			name: "Invalid includeSystem parameter",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?includeSystem=maybe",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+			},
+			ID:              "1",
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/json",
+			wantBody:        `{"code":422,"message":"includeSystem must be a boolean"}`,
+		},
+		{
+			name: "Filter roles by user",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?user=marty",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "biffsgang",
+										Users: []chronograf.User{
+											{
+												Name: "match",
+											},
+										},
+									},
+									{
+										Name: "timetravelers",
+										Users: []chronograf.User{
+											{
+												Name: "marty",
+											},
+											{
+												Name: "doc",
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[{"links":{"self":"/chronograf/v1/sources/1/users/marty"},"name":"marty"},{"links":{"self":"/chronograf/v1/sources/1/users/doc"},"name":"doc"}],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}]}
+`,
+		},
+		{
+			name: "Filter roles without users by prefix",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?hasUsers=false&prefix=time",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "biffsgang",
+									},
+									{
+										Name: "timetravelers",
+										Users: []chronograf.User{
+											{
+												Name: "marty",
+											},
+										},
+									},
+									{
+										Name: "timekeepers",
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[],"name":"timekeepers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timekeepers"}}]}
+`,
+		},
+		{
+			name: "Scope roles to a database with cluster-wide permissions",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?database=delorean&includeClusterWide=true",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "biffsgang",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "almanac",
+												Allowed: chronograf.Allowances{"READ"},
+											},
+										},
+									},
+									{
+										Name: "timetravelers",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "delorean",
+												Allowed: chronograf.Allowances{"WRITE"},
+											},
+											{
+												Scope:   chronograf.DBScope,
+												Name:    "almanac",
+												Allowed: chronograf.Allowances{"READ"},
+											},
+										},
+									},
+									{
+										Name: "admins",
+										Permissions: chronograf.Permissions{
+											{
+												Scope:   chronograf.AllScope,
+												Allowed: chronograf.Allowances{"ViewChronograf"},
+											},
+										},
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[],"name":"timetravelers","permissions":[{"scope":"database","name":"delorean","allowed":["WRITE"]}],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}},{"users":[],"name":"admins","permissions":[{"scope":"all","allowed":["ViewChronograf"]}],"links":{"self":"/chronograf/v1/sources/1/roles/admins"}}]}
+`,
+		},
+		{
+			name: "First page of roles",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?limit=2",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "timetravelers",
+									},
+									{
+										Name: "biffsgang",
+									},
+									{
+										Name: "hillvalley",
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[],"name":"biffsgang","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/biffsgang"}},{"users":[],"name":"hillvalley","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/hillvalley"}}],"cursor":"aGlsbHZhbGxleQ"}
+`,
+		},
+		{
+			name: "Last page of roles",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?limit=2&cursor=aGlsbHZhbGxleQ",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+				SourcesStore: &mocks.SourcesStore{
+					GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+						return chronograf.Source{
+							ID: 1,
+						}, nil
+					},
+				},
+				TimeSeries: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{
+									{
+										Name: "timetravelers",
+									},
+									{
+										Name: "biffsgang",
+									},
+									{
+										Name: "hillvalley",
+									},
+								}, nil
+							},
+						}, nil
+					},
+				},
+			},
+			ID:              "1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `{"roles":[{"users":[],"name":"timetravelers","permissions":[],"links":{"self":"/chronograf/v1/sources/1/roles/timetravelers"}}]}
+`,
+		},
+		{
+			name: "Invalid cursor parameter",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?cursor=!",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+			},
+			ID:              "1",
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/json",
+			wantBody:        `{"code":422,"message":"cursor is invalid"}`,
+		},
+		{
+			name: "Invalid hasUsers parameter",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(
+					"GET",
+					"http://server.local/chronograf/v1/sources/1/roles?hasUsers=some",
+					nil),
+			},
+			fields: fields{
+				Logger: log.New(log.DebugLevel),
+			},
+			ID:              "1",
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/json",
+			wantBody:        `{"code":422,"message":"hasUsers must be a boolean"}`,
+		},
+	}
+	for _, tt := range tests {
+		h := &Service{
+			Store: &mocks.Store{
+				SourcesStore: tt.fields.SourcesStore,
+			},
+			TimeSeriesClient: tt.fields.TimeSeries,
+			Logger:           tt.fields.Logger,
+			ProtectedRoles:   tt.fields.ProtectedRoles,
+		}
+
 		tt.args.r = tt.args.r.WithContext(httprouter.WithParams(
 			context.Background(),
 			httprouter.Params{
@@ -2019,3 +2710,194 @@ func TestService_SourceRoles(t *testing.T) {
 		}
 	}
 }
+
+func Test_sourceRoleRequest_DuplicateUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		duplicates duplicateUsers
+		users      []string
+		wantUsers  []string
+		wantErr    string
+	}{
+		{
+			name:      "Removes duplicates",
+			users:     []string{"marty", "doc", "marty"},
+			wantUsers: []string{"marty", "doc"},
+		},
+		{
+			name:      "Case-sensitive by default",
+			users:     []string{"marty", "Marty"},
+			wantUsers: []string{"marty", "Marty"},
+		},
+		{
+			name:       "Ignores case",
+			duplicates: duplicateUsers{ignoreCase: true},
+			users:      []string{"marty", "Marty"},
+			wantUsers:  []string{"marty"},
+		},
+		{
+			name:       "Rejects duplicates",
+			duplicates: duplicateUsers{reject: true},
+			users:      []string{"marty", "doc", "marty"},
+			wantErr:    "User marty is listed more than once",
+		},
+		{
+			name:       "Rejects duplicates with a percent sign",
+			duplicates: duplicateUsers{reject: true},
+			users:      []string{"100%d", "100%d"},
+			wantErr:    "User 100%d is listed more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := sourceRoleRequest{duplicates: tt.duplicates}
+			req.Name = "timetravelers"
+			for _, name := range tt.users {
+				req.Users = append(req.Users, chronograf.User{Name: name})
+			}
+
+			for _, valid := range []func() error{req.ValidCreate, req.ValidUpdate} {
+				err := valid()
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("sourceRoleRequest validation error = %v, want %s", err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("sourceRoleRequest validation error = %v", err)
+				}
+				var got []string
+				for _, u := range req.Users {
+					got = append(got, u.Name)
+				}
+				if !reflect.DeepEqual(got, tt.wantUsers) {
+					t.Errorf("sourceRoleRequest users = %v, want %v", got, tt.wantUsers)
+				}
+			}
+		})
+	}
+}
+
+func Test_sourceRoleRequest_MaxUsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxUsers int
+		users    []string
+		wantErr  string
+	}{
+		{
+			name:  "Unlimited by default",
+			users: []string{"marty", "doc", "biff"},
+		},
+		{
+			name:     "Duplicates are counted once",
+			maxUsers: 2,
+			users:    []string{"marty", "doc", "marty"},
+		},
+		{
+			name:     "Too many users",
+			maxUsers: 2,
+			users:    []string{"marty", "doc", "biff"},
+			wantErr:  "Role may have at most 2 users",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, valid := range []func(*sourceRoleRequest) error{(*sourceRoleRequest).ValidCreate, (*sourceRoleRequest).ValidUpdate} {
+				req := sourceRoleRequest{maxUsers: tt.maxUsers}
+				req.Name = "timetravelers"
+				for _, name := range tt.users {
+					req.Users = append(req.Users, chronograf.User{Name: name})
+				}
+
+				err := valid(&req)
+				if tt.wantErr == "" && err != nil {
+					t.Errorf("sourceRoleRequest validation error = %v", err)
+				}
+				if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+					t.Errorf("sourceRoleRequest validation error = %v, want %s", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestService_NewSourceRole_IgnoreCase(t *testing.T) {
+	tests := []struct {
+		name       string
+		ignoreCase bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Case-sensitive names",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Case-insensitive names",
+			ignoreCase: true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":400,"message":"Source 1 already has role admin"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Service{
+				Store: &mocks.Store{
+					SourcesStore: &mocks.SourcesStore{
+						GetF: func(ctx context.Context, ID int) (chronograf.Source, error) {
+							return chronograf.Source{
+								ID: 1,
+							}, nil
+						},
+					},
+				},
+				TimeSeriesClient: &mocks.TimeSeries{
+					ConnectF: func(ctx context.Context, src *chronograf.Source) error {
+						return nil
+					},
+					RolesF: func(ctx context.Context) (chronograf.RolesStore, error) {
+						return &mocks.RolesStore{
+							GetF: func(ctx context.Context, name string) (*chronograf.Role, error) {
+								if name != "admin" {
+									return nil, fmt.Errorf("role %s not found", name)
+								}
+								return &chronograf.Role{Name: name}, nil
+							},
+							AllF: func(ctx context.Context) ([]chronograf.Role, error) {
+								return []chronograf.Role{{Name: "admin"}}, nil
+							},
+							AddF: func(ctx context.Context, role *chronograf.Role) (*chronograf.Role, error) {
+								return role, nil
+							},
+						}, nil
+					},
+				},
+				Logger:              log.New(log.DebugLevel),
+				RoleNamesIgnoreCase: tt.ignoreCase,
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://server.local/chronograf/v1/sources/1/roles", bytes.NewBufferString(`{"name": "Admin"}`))
+			r = r.WithContext(httprouter.WithParams(
+				context.Background(),
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "1",
+					},
+				}))
+			h.NewSourceRole(w, r)
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("NewSourceRole() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("NewSourceRole() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldError is a validation problem of a request field
+type fieldError struct {
+	Field   string `json:"field,omitempty"` // Field is the JSON path of the invalid field, e.g. users[1].name
+	Message string `json:"message"`
+}
+
+// validationErrors accumulates every problem found validating a request so
+// clients can fix them all at once.  invalidData responds with the list.
+type validationErrors []fieldError
+
+// add records a problem with field
+func (e *validationErrors) add(field, format string, args ...interface{}) {
+	*e = append(*e, fieldError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// merge records the problems of err with their fields nested under prefix
+func (e *validationErrors) merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	errs, ok := err.(validationErrors)
+	if !ok {
+		e.add(prefix, "%v", err)
+		return
+	}
+	for _, fe := range errs {
+		switch {
+		case prefix == "":
+		case fe.Field == "":
+			fe.Field = prefix
+		case strings.HasPrefix(fe.Field, "["):
+			fe.Field = prefix + fe.Field
+		default:
+			fe.Field = prefix + "." + fe.Field
+		}
+		*e = append(*e, fe)
+	}
+}
+
+// err returns nil if no problems were recorded
+func (e validationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// describe is like Error with each message prefixed by the path of its
+// field, e.g. "users[2].name: Username required"
+func (e validationErrors) describe() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Message
+		if e[i].Field != "" {
+			msgs[i] = e[i].Field + ": " + e[i].Message
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e validationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Message
+	}
+	return strings.Join(msgs, "; ")
+}
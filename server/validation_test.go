@@ -0,0 +1,38 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_validationErrors_merge(t *testing.T) {
+	var nested validationErrors
+	nested.add("[0].scope", "Invalid permission scope")
+	nested.add("name", "Name required")
+	nested.add("", "Missing")
+
+	var errs validationErrors
+	errs.merge("permissions", nil)
+	errs.merge("permissions", nested)
+	errs.merge("source", fmt.Errorf("Unknown source"))
+
+	want := validationErrors{
+		{Field: "permissions[0].scope", Message: "Invalid permission scope"},
+		{Field: "permissions.name", Message: "Name required"},
+		{Field: "permissions", Message: "Missing"},
+		{Field: "source", Message: "Unknown source"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("validationErrors.merge() = %v, want %v", errs, want)
+	}
+	if got := errs.Error(); got != "Invalid permission scope; Name required; Missing; Unknown source" {
+		t.Errorf("validationErrors.Error() = %q", got)
+	}
+	if got := errs.describe(); got != "permissions[0].scope: Invalid permission scope; permissions.name: Name required; permissions: Missing; source: Unknown source" {
+		t.Errorf("validationErrors.describe() = %q", got)
+	}
+	if err := (validationErrors{}).err(); err != nil {
+		t.Errorf("validationErrors.err() = %v, want nil", err)
+	}
+}